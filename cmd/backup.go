@@ -0,0 +1,418 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	azurepkg "github.com/DevExpGBB/gh-devlake/internal/azure"
+	"github.com/DevExpGBB/gh-devlake/internal/backup"
+	"github.com/DevExpGBB/gh-devlake/internal/devlake"
+	dockerpkg "github.com/DevExpGBB/gh-devlake/internal/docker"
+	"github.com/DevExpGBB/gh-devlake/internal/token"
+	"github.com/spf13/cobra"
+)
+
+// backupPassphraseEnv supplies the archive passphrase when --passphrase is omitted.
+const backupPassphraseEnv = "DEVLAKE_BACKUP_PASSPHRASE"
+
+// azureMySQLAdminUser is the administrator login set by the Bicep templates.
+const azureMySQLAdminUser = "merico"
+
+// publicIPURL returns the caller's public IP as plain text. Tests may replace it.
+var publicIPURL = "https://api.ipify.org"
+
+type backupOpts struct {
+	Output     string
+	Passphrase string
+	Azure      bool
+	Local      bool
+	StateFile  string
+	ClientIP   string
+}
+
+type backupResult struct {
+	Archive   string `json:"archive"`
+	Method    string `json:"method"`
+	Encrypted bool   `json:"encrypted"`
+	Bytes     int64  `json:"bytes"`
+}
+
+func newBackupCmd() *cobra.Command {
+	var opts backupOpts
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Back up the DevLake database, secrets, and state",
+		Long: `Writes a backup archive (.tar.gz) containing:
+  • a consistent mysqldump of the DevLake database
+  • the ENCRYPTION_SECRET (.env for local, the Key Vault secret for Azure)
+  • the deployment state file
+
+For local deployments the dump runs inside the compose mysql service via
+'docker compose exec'. For Azure, the MySQL password is read from Key Vault and
+a temporary firewall rule admits this machine's public IP for the duration.
+That IP is looked up at api.ipify.org unless --client-ip gives it.
+
+With --passphrase (or $DEVLAKE_BACKUP_PASSPHRASE) the secrets in the archive are
+encrypted. The database dump itself is not — store the archive accordingly.
+
+Examples:
+  gh devlake backup
+  gh devlake backup --output ./backups/devlake.tar.gz
+  gh devlake backup --azure --passphrase env:BACKUP_PASS`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var prog io.Writer = os.Stdout
+			if outputJSON {
+				prog = os.Stderr
+			}
+			res, err := runBackup(opts, prog)
+			if err != nil {
+				return err
+			}
+			if outputJSON {
+				return printJSON(res)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&opts.Output, "output", "o", "", "Archive path (default devlake-backup-<mode>-<timestamp>.tar.gz)")
+	cmd.Flags().StringVar(&opts.Passphrase, "passphrase", "", "Encrypt secrets in the archive (accepts env:, file:, cmd:, azkv: references)")
+	cmd.Flags().BoolVar(&opts.Azure, "azure", false, "Force Azure mode")
+	cmd.Flags().BoolVar(&opts.Local, "local", false, "Force local (Docker Compose) mode")
+	cmd.Flags().StringVar(&opts.StateFile, "state-file", "", "Path to state file (auto-detected if omitted)")
+	cmd.Flags().StringVar(&opts.ClientIP, "client-ip", "", "Public IP to admit through the Azure MySQL firewall (default: looked up at api.ipify.org)")
+	return cmd
+}
+
+// runBackup dumps the database of the detected deployment and writes the
+// archive. Progress goes to prog.
+func runBackup(opts backupOpts, prog io.Writer) (*backupResult, error) {
+	mode := detectDeploymentMode(opts.Azure, opts.Local, opts.StateFile)
+	if mode == "" {
+		return nil, fmt.Errorf("no deployment found — no state file or docker-compose.yml in current directory")
+	}
+	passphrase, err := backupPassphrase(opts.Passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintln(prog)
+	fmt.Fprintln(prog, "════════════════════════════════════════")
+	fmt.Fprintf(prog, "  DevLake — Backup (%s)\n", mode)
+	fmt.Fprintln(prog, "════════════════════════════════════════")
+
+	workDir, err := os.MkdirTemp("", "devlake-backup-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(workDir)
+	dumpPath := filepath.Join(workDir, backup.DumpName)
+
+	var (
+		entries []backup.Entry
+		version string
+	)
+	switch mode {
+	case "local":
+		entries, version, err = dumpLocal(opts.StateFile, dumpPath, prog)
+	case "azure":
+		entries, version, err = dumpAzure(opts.StateFile, opts.ClientIP, dumpPath, prog)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	output := opts.Output
+	if output == "" {
+		output = fmt.Sprintf("devlake-backup-%s-%s.tar.gz", mode, time.Now().Format("20060102-150405"))
+	}
+	fmt.Fprintf(prog, "\n📦 Writing %s...\n", output)
+	manifest := backup.Manifest{Method: mode, DevLakeVersion: version, Database: backup.DefaultDB}
+	if err := backup.Write(output, manifest, entries, passphrase); err != nil {
+		return nil, fmt.Errorf("writing backup archive: %w", err)
+	}
+	info, err := os.Stat(output)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(prog, "   ✅ %s (%s)\n", output, humanBytes(info.Size()))
+	if passphrase != "" {
+		fmt.Fprintln(prog, "   🔐 Secrets encrypted with the passphrase")
+	} else {
+		fmt.Fprintln(prog, "   ⚠️  ENCRYPTION_SECRET is stored unencrypted — keep this file private")
+	}
+	fmt.Fprintf(prog, "\nRestore with: gh devlake restore %s\n", output)
+	return &backupResult{Archive: output, Method: mode, Encrypted: passphrase != "", Bytes: info.Size()}, nil
+}
+
+// dumpLocal dumps the compose mysql service and collects .env and the state file.
+func dumpLocal(stateFile, dumpPath string, prog io.Writer) ([]backup.Entry, string, error) {
+	dir := deploymentDir(stateFile)
+	if stateFile == "" {
+		stateFile = filepath.Join(dir, ".devlake-local.json")
+	}
+
+	fmt.Fprintln(prog, "\n🐳 Checking Docker...")
+	if err := dockerpkg.CheckAvailable(); err != nil {
+		return nil, "", fmt.Errorf("Docker is not available: %w\nMake sure Docker Desktop or the Docker daemon is running", err)
+	}
+
+	fmt.Fprintln(prog, "\n🗄️  Dumping the mysql service...")
+	out, err := os.OpenFile(dumpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, "", err
+	}
+	err = dockerpkg.ComposeExec(dir, "mysql", nil, out, backup.ComposeDumpCommand(backup.DefaultDB)...)
+	out.Close()
+	if err != nil {
+		return nil, "", fmt.Errorf("%w\nIs the mysql service running? Try 'gh devlake start'", err)
+	}
+	fmt.Fprintln(prog, "   ✅ Database dumped")
+
+	entries := []backup.Entry{{Name: backup.DumpName, Path: dumpPath}}
+	if data, err := os.ReadFile(filepath.Join(dir, ".env")); err == nil {
+		entries = append(entries, backup.Entry{Name: backup.EnvName, Data: data, Secret: true})
+	}
+	if data, err := os.ReadFile(stateFile); err == nil {
+		entries = append(entries, backup.Entry{Name: filepath.Base(stateFile), Data: data})
+	}
+
+	version := ""
+	if disc, err := devlake.Discover(cfgURL); err == nil {
+		version = backendVersion(disc.URL)
+	}
+	return entries, version, nil
+}
+
+// dumpAzure dumps the Flexible Server using the Key Vault password and
+// collects the encryption secret and the state file.
+func dumpAzure(stateFile, clientIP, dumpPath string, prog io.Writer) ([]backup.Entry, string, error) {
+	if stateFile == "" {
+		stateFile = ".devlake-azure.json"
+	}
+	state, raw, err := loadAzureState(stateFile)
+	if err != nil {
+		return nil, "", err
+	}
+	conn, encSecret, err := azureMySQLConn(state, prog)
+	if err != nil {
+		return nil, "", err
+	}
+
+	fmt.Fprintln(prog, "\n🗄️  Dumping Azure MySQL...")
+	err = withAzureMySQLAccess(state, "gh-devlake-backup", clientIP, prog, func() error {
+		out, err := os.OpenFile(dumpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+		return backup.DumpRemote(conn, backup.DefaultDB, out)
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	fmt.Fprintln(prog, "   ✅ Database dumped")
+
+	entries := []backup.Entry{
+		{Name: backup.DumpName, Path: dumpPath},
+		{Name: backup.SecretName, Data: []byte(encSecret), Secret: true},
+		{Name: filepath.Base(stateFile), Data: raw},
+	}
+	return entries, backendVersion(state.Endpoints.Backend), nil
+}
+
+// loadAzureState reads and validates an Azure state file, returning the raw
+// bytes alongside the parsed data.
+func loadAzureState(path string) (*azureStateData, []byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, fmt.Errorf("state file not found: %s\nUse --state-file to specify the path", path)
+		}
+		return nil, nil, err
+	}
+	var state azureStateData
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, nil, fmt.Errorf("invalid state file: %w", err)
+	}
+	if state.ResourceGroup == "" || state.Resources.MySQL == "" || state.Resources.KeyVault == "" {
+		return nil, nil, fmt.Errorf("state file %s does not record the MySQL server and Key Vault — was the deployment completed?", path)
+	}
+	if state.Private {
+		return nil, nil, fmt.Errorf("MySQL server %s has no public endpoint (deployed with --private) — use the server's automated backups or run mysqldump from inside the VNet", state.Resources.MySQL)
+	}
+	return &state, data, nil
+}
+
+// azureMySQLConn checks the Azure login and server state, then reads the
+// admin password and encryption secret from Key Vault.
+func azureMySQLConn(state *azureStateData, prog io.Writer) (backup.MySQLConn, string, error) {
+	fmt.Fprintln(prog, "\n🔑 Checking Azure login...")
+	if _, err := azurepkg.CheckLogin(); err != nil {
+		return backup.MySQLConn{}, "", fmt.Errorf("not logged in to Azure CLI — run 'az login' first")
+	}
+	if st, err := azurepkg.MySQLState(state.Resources.MySQL, state.ResourceGroup); err == nil && st != "Ready" {
+		return backup.MySQLConn{}, "", fmt.Errorf("MySQL server %s is %s — run 'gh devlake start --azure' first", state.Resources.MySQL, st)
+	}
+
+	fmt.Fprintf(prog, "   Reading secrets from Key Vault %s...\n", state.Resources.KeyVault)
+	password, err := azurepkg.KeyVaultSecret(state.Resources.KeyVault, "db-admin-password")
+	if err != nil {
+		return backup.MySQLConn{}, "", err
+	}
+	encSecret, err := azurepkg.KeyVaultSecret(state.Resources.KeyVault, "encryption-secret")
+	if err != nil {
+		return backup.MySQLConn{}, "", err
+	}
+	host, err := azurepkg.MySQLFQDN(state.Resources.MySQL, state.ResourceGroup)
+	if err != nil {
+		return backup.MySQLConn{}, "", err
+	}
+	return backup.MySQLConn{Host: host, User: azureMySQLAdminUser, Password: password, TLS: true}, encSecret, nil
+}
+
+// withAzureMySQLAccess opens the server firewall to clientIP (see
+// azureClientIP) while fn runs. The deployment only admits Azure services by
+// default.
+func withAzureMySQLAccess(state *azureStateData, rule, clientIP string, prog io.Writer, fn func() error) error {
+	ip, err := azureClientIP(clientIP, prog)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(prog, "   Allowing %s through the MySQL firewall (temporary)...\n", ip)
+	if err := azurepkg.MySQLAllowIP(state.Resources.MySQL, state.ResourceGroup, rule, ip); err != nil {
+		return err
+	}
+	defer func() {
+		if err := azurepkg.MySQLDeleteFirewallRule(state.Resources.MySQL, state.ResourceGroup, rule); err != nil {
+			fmt.Fprintf(prog, "   ⚠️  Could not remove firewall rule %s: %v\n", rule, err)
+		}
+	}()
+	return fn()
+}
+
+// azureClientIP returns the IP the temporary firewall rule admits: explicit
+// (--client-ip) when set, otherwise this machine's public IP as reported by
+// publicIPURL. The outbound lookup is announced, never silent.
+func azureClientIP(explicit string, prog io.Writer) (string, error) {
+	if explicit != "" {
+		if ip := net.ParseIP(explicit); ip == nil || ip.To4() == nil {
+			return "", fmt.Errorf("--client-ip %q is not an IPv4 address", explicit)
+		}
+		return explicit, nil
+	}
+	fmt.Fprintf(prog, "   Looking up this machine's public IP at %s (pass --client-ip to skip)...\n", publicIPURL)
+	ip, err := clientPublicIP()
+	if err != nil {
+		return "", fmt.Errorf("could not determine this machine's public IP: %w — pass --client-ip", err)
+	}
+	return ip, nil
+}
+
+func clientPublicIP() (string, error) {
+	c := &http.Client{Timeout: 10 * time.Second}
+	resp, err := c.Get(publicIPURL)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s returned %d", publicIPURL, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(body)), nil
+}
+
+// backendVersion returns the normalized DevLake version, or "" if the
+// backend is unreachable.
+func backendVersion(url string) string {
+	if url == "" {
+		return ""
+	}
+	v, err := devlake.NewClient(url).Version()
+	if err != nil {
+		return ""
+	}
+	return normalizeVersion(v.Version)
+}
+
+// backupPassphrase resolves --passphrase, falling back to the environment.
+func backupPassphrase(flag string) (string, error) {
+	if flag == "" {
+		flag = os.Getenv(backupPassphraseEnv)
+	}
+	if flag == "" {
+		return "", nil
+	}
+	return token.ResolveSecretRef(flag)
+}
+
+// detectDeploymentMode determines whether a command targets a local (Docker
+// Compose) or Azure deployment. Priority: explicit flags → explicit state file
+// (inspected for method) → well-known state files → docker-compose.yml.
+func detectDeploymentMode(forceAzure, forceLocal bool, stateFile string) string {
+	if forceAzure {
+		return "azure"
+	}
+	if forceLocal {
+		return "local"
+	}
+	if stateFile != "" {
+		var meta struct {
+			Method        string `json:"method"`
+			ResourceGroup string `json:"resourceGroup"`
+		}
+		if data, err := os.ReadFile(stateFile); err == nil && json.Unmarshal(data, &meta) == nil {
+			if strings.ToLower(meta.Method) == "local" || meta.ResourceGroup == "" {
+				return "local"
+			}
+			return "azure"
+		}
+		return ""
+	}
+	if _, err := os.Stat(".devlake-azure.json"); err == nil {
+		return "azure"
+	}
+	if _, err := os.Stat(".devlake-local.json"); err == nil {
+		return "local"
+	}
+	if _, err := os.Stat("docker-compose.yml"); err == nil {
+		return "local"
+	}
+	return ""
+}
+
+// deploymentDir is the directory holding a local deployment: the state
+// file's directory when given, otherwise the current directory.
+func deploymentDir(stateFile string) string {
+	if stateFile != "" {
+		if abs, err := filepath.Abs(stateFile); err == nil {
+			return filepath.Dir(abs)
+		}
+	}
+	cwd, _ := os.Getwd()
+	return cwd
+}
+
+func humanBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
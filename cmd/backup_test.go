@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DevExpGBB/gh-devlake/internal/backup"
+	"github.com/DevExpGBB/gh-devlake/internal/devlake"
+)
+
+func TestDetectDeploymentMode(t *testing.T) {
+	dir := t.TempDir()
+	origWd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(origWd) })
+
+	if got := detectDeploymentMode(false, false, ""); got != "" {
+		t.Errorf("empty dir = %q, want empty", got)
+	}
+	if err := os.WriteFile("docker-compose.yml", []byte("services: {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := detectDeploymentMode(false, false, ""); got != "local" {
+		t.Errorf("compose only = %q, want local", got)
+	}
+	if err := os.WriteFile(".devlake-azure.json", []byte(`{"method":"bicep","resourceGroup":"rg"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := detectDeploymentMode(false, false, ""); got != "azure" {
+		t.Errorf("azure state = %q, want azure", got)
+	}
+	if got := detectDeploymentMode(false, true, ""); got != "local" {
+		t.Errorf("--local = %q, want local", got)
+	}
+
+	explicit := filepath.Join(dir, "custom.json")
+	if err := os.WriteFile(explicit, []byte(`{"method":"local","endpoints":{"backend":"http://localhost:8080"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := detectDeploymentMode(false, false, explicit); got != "local" {
+		t.Errorf("explicit local state = %q, want local", got)
+	}
+	if got := detectDeploymentMode(false, false, filepath.Join(dir, "missing.json")); got != "" {
+		t.Errorf("missing state file = %q, want empty", got)
+	}
+}
+
+func TestSetEnvValue(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+		changed        bool
+	}{
+		{"replace", "A=1\nENCRYPTION_SECRET=old\nB=2\n", "A=1\nENCRYPTION_SECRET=new\nB=2\n", true},
+		{"unchanged", "ENCRYPTION_SECRET=new\n", "ENCRYPTION_SECRET=new\n", false},
+		{"append", "A=1", "A=1\nENCRYPTION_SECRET=new\n", true},
+		{"empty", "", "ENCRYPTION_SECRET=new\n", true},
+		{"ignores comment", "# ENCRYPTION_SECRET=x\n", "# ENCRYPTION_SECRET=x\nENCRYPTION_SECRET=new\n", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, changed := setEnvValue(tt.in, "ENCRYPTION_SECRET", "new")
+			if got != tt.want || changed != tt.changed {
+				t.Errorf("setEnvValue = %q, %v; want %q, %v", got, changed, tt.want, tt.changed)
+			}
+		})
+	}
+}
+
+func TestArchiveEncryptionSecret(t *testing.T) {
+	local := t.TempDir()
+	if err := os.WriteFile(filepath.Join(local, backup.EnvName), []byte("PORT=1\nENCRYPTION_SECRET=FROMENV\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if got := archiveEncryptionSecret(local); got != "FROMENV" {
+		t.Errorf("local = %q", got)
+	}
+
+	az := t.TempDir()
+	if err := os.WriteFile(filepath.Join(az, backup.SecretName), []byte("FROMKV\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if got := archiveEncryptionSecret(az); got != "FROMKV" {
+		t.Errorf("azure = %q", got)
+	}
+
+	if got := archiveEncryptionSecret(t.TempDir()); got != "" {
+		t.Errorf("empty = %q", got)
+	}
+}
+
+func TestMergeRestoredState(t *testing.T) {
+	work := t.TempDir()
+	archived := &devlake.State{
+		Method:      "local",
+		Endpoints:   devlake.StateEndpoints{Backend: "http://localhost:8080"},
+		Connections: []devlake.StateConnection{{Plugin: "github", ConnectionID: 3, Name: "GitHub - acme"}},
+		Project:     &devlake.StateProject{Name: "acme", BlueprintID: 2},
+	}
+	if err := devlake.SaveState(filepath.Join(work, ".devlake-local.json"), archived); err != nil {
+		t.Fatal(err)
+	}
+
+	target := filepath.Join(t.TempDir(), ".devlake-azure.json")
+	if err := os.WriteFile(target, []byte(`{"method":"bicep","resourceGroup":"rg","endpoints":{"backend":"https://az"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := mergeRestoredState(target, work)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("copied = %d, want 1", n)
+	}
+	got, err := devlake.LoadState(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Endpoints.Backend != "https://az" || got.Method != "bicep" {
+		t.Errorf("target deployment fields overwritten: %+v", got)
+	}
+	if len(got.Connections) != 1 || got.Connections[0].ConnectionID != 3 || got.Project == nil || got.Project.Name != "acme" {
+		t.Errorf("connections/project not copied: %+v", got)
+	}
+	data, _ := os.ReadFile(target)
+	if !strings.Contains(string(data), `"resourceGroup": "rg"`) {
+		t.Errorf("unmodelled fields lost:\n%s", data)
+	}
+}
+
+func TestClientPublicIP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "203.0.113.7\n")
+	}))
+	defer srv.Close()
+	orig := publicIPURL
+	publicIPURL = srv.URL
+	t.Cleanup(func() { publicIPURL = orig })
+
+	ip, err := clientPublicIP()
+	if err != nil || ip != "203.0.113.7" {
+		t.Errorf("clientPublicIP = %q, %v", ip, err)
+	}
+}
+
+func TestAzureClientIP(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprint(w, "203.0.113.7")
+	}))
+	defer srv.Close()
+	orig := publicIPURL
+	publicIPURL = srv.URL
+	t.Cleanup(func() { publicIPURL = orig })
+
+	var out strings.Builder
+	if ip, err := azureClientIP("198.51.100.4", &out); err != nil || ip != "198.51.100.4" || calls != 0 || out.Len() != 0 {
+		t.Errorf("--client-ip = %q, %v (lookups %d, output %q)", ip, err, calls, out.String())
+	}
+	if _, err := azureClientIP("not-an-ip", &out); err == nil {
+		t.Error("expected an error for an invalid --client-ip")
+	}
+	ip, err := azureClientIP("", &out)
+	if err != nil || ip != "203.0.113.7" || calls != 1 {
+		t.Errorf("lookup = %q, %v (lookups %d)", ip, err, calls)
+	}
+	if !strings.Contains(out.String(), srv.URL) {
+		t.Errorf("the lookup should be announced, got %q", out.String())
+	}
+}
+
+func TestBackupPassphrase(t *testing.T) {
+	t.Setenv(backupPassphraseEnv, "")
+	if p, err := backupPassphrase(""); err != nil || p != "" {
+		t.Errorf("none = %q, %v", p, err)
+	}
+	t.Setenv(backupPassphraseEnv, "from-env")
+	if p, _ := backupPassphrase(""); p != "from-env" {
+		t.Errorf("env fallback = %q", p)
+	}
+	t.Setenv("MY_PASS", "from-ref")
+	if p, _ := backupPassphrase("env:MY_PASS"); p != "from-ref" {
+		t.Errorf("reference = %q", p)
+	}
+}
+
+func TestHumanBytes(t *testing.T) {
+	for in, want := range map[int64]string{512: "512 B", 2048: "2.0 KiB", 5 << 20: "5.0 MiB"} {
+		if got := humanBytes(in); got != want {
+			t.Errorf("humanBytes(%d) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestLoadAzureStateRejectsPrivate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".devlake-azure.json")
+	state := `{"resourceGroup":"rg","private":true,"resources":{"mysql":"devlakemysqlabc12","keyVault":"devlakekvabc12"}}`
+	if err := os.WriteFile(path, []byte(state), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, _, err := loadAzureState(path)
+	if err == nil || !strings.Contains(err.Error(), "no public endpoint") {
+		t.Errorf("got %v, want a no-public-endpoint error", err)
+	}
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/DevExpGBB/gh-devlake/internal/bundle"
+	dockerpkg "github.com/DevExpGBB/gh-devlake/internal/docker"
+	"github.com/spf13/cobra"
+)
+
+var (
+	bundleVersion  string
+	bundleOutput   string
+	bundleInsecure bool
+)
+
+func newBundleCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bundle",
+		Short: "Package a DevLake release for offline deployment",
+		Long: `Package a DevLake release, images included, so it can be deployed on a
+machine without internet access.
+
+Example:
+  gh devlake bundle create --version v1.0.2
+  gh devlake deploy local --bundle devlake-bundle-v1.0.2.tar`,
+	}
+	cmd.GroupID = "deploy"
+	cmd.AddCommand(newBundleCreateCmd())
+	return cmd
+}
+
+func init() {
+	rootCmd.AddCommand(newBundleCmd())
+}
+
+func newBundleCreateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create an offline bundle of a DevLake release",
+		Long: `Downloads a DevLake release's docker-compose.yml and env.example, pulls the
+images the compose file uses and saves them with docker save, then writes a
+tar holding all three and a manifest with their SHA-256 checksums. The
+downloaded files are verified the same way as in 'gh devlake deploy local'.
+
+Run it on a machine with internet access and Docker, copy the bundle across,
+and deploy it with 'gh devlake deploy local --bundle'. That verifies the
+checksums, loads the images and deploys without network access.
+
+Example:
+  gh devlake bundle create --version v1.0.2
+  gh devlake bundle create --version v1.0.2 --output /media/usb/devlake.tar`,
+		Args: cobra.NoArgs,
+		RunE: runBundleCreate,
+	}
+
+	cmd.Flags().StringVar(&bundleVersion, "version", "latest", "DevLake version to bundle (e.g. v1.0.2)")
+	cmd.Flags().StringVar(&bundleOutput, "output", "", "Bundle file to write (default: devlake-bundle-<version>.tar)")
+	cmd.Flags().BoolVar(&bundleInsecure, "insecure-skip-verify", false, "Do not verify downloaded release files against their checksums")
+
+	return cmd
+}
+
+func runBundleCreate(cmd *cobra.Command, args []string) error {
+	printBanner("DevLake Offline Bundle")
+
+	fmt.Println("\n🐳 Checking Docker...")
+	if err := dockerpkg.CheckAvailable(); err != nil {
+		return fmt.Errorf("Docker is not available — it is needed to pull and save the images: %w", err)
+	}
+	fmt.Println("   ✅ Docker found")
+
+	version, err := resolveReleaseVersion(bundleVersion)
+	if err != nil {
+		return err
+	}
+	output := bundleOutput
+	if output == "" {
+		output = fmt.Sprintf("devlake-bundle-%s.tar", version)
+	}
+
+	workDir, err := os.MkdirTemp("", "devlake-bundle-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(workDir)
+
+	if err := downloadReleaseFiles(version, workDir, bundleInsecure); err != nil {
+		return err
+	}
+
+	compose, err := os.ReadFile(filepath.Join(workDir, bundle.ComposeName))
+	if err != nil {
+		return err
+	}
+	images, err := bundle.ComposeImages(compose)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n🐳 Pulling %d images...\n", len(images))
+	for _, image := range images {
+		fmt.Printf("   %s...", image)
+		if err := dockerpkg.Pull(image); err != nil {
+			return fmt.Errorf("\n   %w", err)
+		}
+		fmt.Println(" ✅")
+	}
+	fmt.Println("\n💾 Saving images (this can take a few minutes)...")
+	imagesPath := filepath.Join(workDir, bundle.ImagesName)
+	if err := dockerpkg.Save(imagesPath, images...); err != nil {
+		return err
+	}
+	fmt.Println("   ✅ Images saved")
+
+	fmt.Printf("\n📦 Writing %s...\n", output)
+	entries := []bundle.Entry{
+		{Name: bundle.ComposeName, Path: filepath.Join(workDir, bundle.ComposeName)},
+		{Name: bundle.EnvName, Path: filepath.Join(workDir, bundle.EnvName)},
+		{Name: bundle.ImagesName, Path: imagesPath},
+	}
+	if err := bundle.Write(output, bundle.Manifest{Version: version, Images: images}, entries); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	if info, err := os.Stat(output); err == nil {
+		fmt.Printf("   ✅ %s (%.1f GB)\n", output, float64(info.Size())/(1<<30))
+	}
+
+	printBanner("✅ Bundle Created!")
+	fmt.Println("\nCopy it to the offline machine and run:")
+	fmt.Printf("  gh devlake deploy local --bundle %s\n", filepath.Base(output))
+	fmt.Println()
+	return nil
+}
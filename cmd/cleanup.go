@@ -5,9 +5,13 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/DevExpGBB/gh-devlake/internal/azure"
+	"github.com/DevExpGBB/gh-devlake/internal/devlake"
 	dockerpkg "github.com/DevExpGBB/gh-devlake/internal/docker"
+	"github.com/DevExpGBB/gh-devlake/internal/instance"
 	"github.com/DevExpGBB/gh-devlake/internal/prompt"
 	"github.com/spf13/cobra"
 )
@@ -18,8 +22,19 @@ var (
 	cleanupKeepRG   bool
 	cleanupAzure    bool
 	cleanupLocal    bool
+	cleanupK8s      bool
 	cleanupRG       string
 	cleanupKeepData bool
+	cleanupBackup   bool
+	cleanupInstance string
+	cleanupOrphans  bool
+)
+
+// Deleting individual Azure resources is retried, since one can be blocked
+// by another that is still being deleted.
+var (
+	azureDeletePasses    = 3
+	azureDeleteRetryWait = 30 * time.Second
 )
 
 func newCleanupCmd() *cobra.Command {
@@ -30,11 +45,27 @@ func newCleanupCmd() *cobra.Command {
 
 For local: stops Docker Compose containers.
 For Azure: deletes the resource group (or individual resources with --keep-resource-group).
+With --keep-resource-group, resources are found both in the state file and by
+their gh-devlake-instance tag, so a partial deployment is cleaned up too.
+Failed deletions are retried; what still fails is listed with the reason and
+the state file is kept so cleanup can be run again.
+--list-orphans lists tagged Azure deployments whose state file is gone,
+without deleting anything.
+For Kubernetes: deletes the DevLake objects, the MySQL volume and (if deploy
+created it) the namespace. --keep-data keeps the volume and Secret.
+
+Before deleting data, an interactive cleanup offers to back up the database
+first (see 'gh devlake backup'). Pass --backup to always do so, including
+with --force.
 
 Example:
   gh devlake cleanup
   gh devlake cleanup --azure --force
-  gh devlake cleanup --local`,
+  gh devlake cleanup --azure --keep-resource-group
+  gh devlake cleanup --list-orphans
+  gh devlake cleanup --local --backup
+  gh devlake cleanup --k8s --keep-data
+  gh devlake cleanup --instance staging`,
 		RunE: runCleanup,
 	}
 
@@ -43,22 +74,35 @@ Example:
 	cmd.Flags().BoolVar(&cleanupKeepRG, "keep-resource-group", false, "Delete resources but keep the Azure resource group")
 	cmd.Flags().BoolVar(&cleanupAzure, "azure", false, "Force Azure cleanup mode")
 	cmd.Flags().BoolVar(&cleanupLocal, "local", false, "Force local cleanup mode")
+	cmd.Flags().BoolVar(&cleanupK8s, "k8s", false, "Force Kubernetes cleanup mode")
 	cmd.Flags().StringVar(&cleanupRG, "resource-group", "", "Azure resource group name (overrides state file)")
-	cmd.Flags().BoolVar(&cleanupKeepData, "keep-data", false, "Preserve Docker data volumes (database, Grafana dashboards)")
+	cmd.Flags().BoolVar(&cleanupKeepData, "keep-data", false, "Preserve data volumes (database, Grafana dashboards)")
+	cmd.Flags().BoolVar(&cleanupBackup, "backup", false, "Back up the database before tearing down (no prompt)")
+	cmd.Flags().StringVar(&cleanupInstance, "instance", "", "Tear down a named local instance and unregister it")
+	cmd.Flags().BoolVar(&cleanupOrphans, "list-orphans", false, "List tagged Azure resources whose state file is gone (deletes nothing)")
 
 	return cmd
 }
 
 // azureStateData holds the parsed Azure state file.
 type azureStateData struct {
-	DeployedAt    string `json:"deployedAt"`
-	ResourceGroup string `json:"resourceGroup"`
-	Region        string `json:"region"`
-	Resources     struct {
-		ACR        any      `json:"acr"`
-		KeyVault   string   `json:"keyVault"`
-		MySQL      string   `json:"mysql"`
-		Containers []string `json:"containers"`
+	DeployedAt     string          `json:"deployedAt"`
+	ResourceGroup  string          `json:"resourceGroup"`
+	Region         string          `json:"region"`
+	SubscriptionID string          `json:"subscriptionId"`
+	Suffix         string          `json:"suffix"`
+	BaseName       string          `json:"baseName"`
+	Private        bool            `json:"private"`
+	Runtime        string          `json:"runtime"` // "aca" or "aci"; empty means aci
+	Sizing         azure.Sizing    `json:"sizing"`
+	Schedule       *azure.Schedule `json:"schedule,omitempty"` // gh devlake schedule azure
+	Resources      struct {
+		ACR         any                    `json:"acr"`
+		KeyVault    string                 `json:"keyVault"`
+		MySQL       string                 `json:"mysql"`
+		Containers  []string               `json:"containers"` // container groups, or container apps with runtime aca
+		Environment string                 `json:"environment"`
+		Network     azure.PrivateResources `json:"network"`
 	} `json:"resources"`
 	Endpoints struct {
 		Backend  string `json:"backend"`
@@ -67,11 +111,102 @@ type azureStateData struct {
 	} `json:"endpoints"`
 }
 
+// stateResources returns the resources named in the state file, for
+// deployments made before resources were tagged.
+func (s *azureStateData) stateResources(subscription string) []azure.Resource {
+	var rs []azure.Resource
+	add := func(typ, name string) {
+		if name == "" {
+			return
+		}
+		rs = append(rs, azure.Resource{
+			ID:            azure.ResourceID(subscription, s.ResourceGroup, typ, name),
+			Name:          name,
+			Type:          typ,
+			ResourceGroup: s.ResourceGroup,
+			Location:      s.Region,
+		})
+	}
+	if s.Schedule != nil {
+		for _, w := range s.Schedule.Workflows {
+			add("Microsoft.Logic/workflows", w)
+		}
+	}
+	containerType := "Microsoft.ContainerInstance/containerGroups"
+	if s.Runtime == azure.RuntimeACA {
+		containerType = "Microsoft.App/containerApps"
+	}
+	for _, c := range s.Resources.Containers {
+		add(containerType, c)
+	}
+	add("Microsoft.App/managedEnvironments", s.Resources.Environment)
+	add("Microsoft.DBforMySQL/flexibleServers", s.Resources.MySQL)
+	if acrName, ok := s.Resources.ACR.(string); ok {
+		add("Microsoft.ContainerRegistry/registries", acrName)
+	}
+	add("Microsoft.KeyVault/vaults", s.Resources.KeyVault)
+	network := s.Resources.Network
+	add("Microsoft.Network/applicationGateways", network.Gateway)
+	add("Microsoft.Network/publicIPAddresses", network.PublicIP)
+	add("Microsoft.Network/virtualNetworks", network.VNet)
+	add("Microsoft.Network/networkSecurityGroups", network.NSG)
+	add("Microsoft.Network/privateDnsZones", network.DNSZone)
+	if network.DNSZone != "" && network.DNSLink != "" {
+		add("Microsoft.Network/privateDnsZones/virtualNetworkLinks", network.DNSZone+"/"+network.DNSLink)
+	}
+	add("Microsoft.Network/privateDnsZones", network.ServiceZone)
+	if network.ServiceZone != "" && network.DNSLink != "" {
+		add("Microsoft.Network/privateDnsZones/virtualNetworkLinks", network.ServiceZone+"/"+network.DNSLink)
+	}
+	add("Microsoft.ManagedIdentity/userAssignedIdentities", network.GatewayIdentity)
+	return rs
+}
+
+// startContainer starts a container group, or activates a container app.
+func (s *azureStateData) startContainer(name string) error {
+	if s.Runtime == azure.RuntimeACA {
+		return azure.ContainerAppStart(name, s.ResourceGroup)
+	}
+	return azure.ContainerStart(name, s.ResourceGroup)
+}
+
+// stopContainer stops a container group, or deactivates a container app.
+func (s *azureStateData) stopContainer(name string) error {
+	if s.Runtime == azure.RuntimeACA {
+		return azure.ContainerAppStop(name, s.ResourceGroup)
+	}
+	return azure.ContainerStop(name, s.ResourceGroup)
+}
+
 func runCleanup(cmd *cobra.Command, args []string) error {
+	if cleanupOrphans {
+		if cleanupLocal || cleanupK8s || cleanupInstance != "" {
+			return fmt.Errorf("--list-orphans only applies to Azure deployments")
+		}
+		return runAzureListOrphans()
+	}
+
+	// A named instance is cleaned up from its own directory
+	if cleanupInstance != "" {
+		inst, err := instance.Get(cleanupInstance)
+		if err != nil {
+			return err
+		}
+		if err := os.Chdir(inst.Dir); err != nil {
+			if !os.IsNotExist(err) {
+				return fmt.Errorf("failed to change directory to %s: %w", inst.Dir, err)
+			}
+			fmt.Printf("Directory %s no longer exists — unregistering instance %q\n", inst.Dir, inst.Name)
+			return instance.Remove(inst.Name)
+		}
+		cleanupLocal = true
+		cleanupState = ""
+	}
+
 	// Determine mode
 	mode := detectCleanupMode()
 	if mode == "" {
-		return fmt.Errorf("could not determine deployment type.\nUse --azure or --local, or ensure a state file exists")
+		return fmt.Errorf("could not determine deployment type.\nUse --azure, --local or --k8s, or ensure a state file exists")
 	}
 
 	switch mode {
@@ -79,6 +214,8 @@ func runCleanup(cmd *cobra.Command, args []string) error {
 		return runAzureCleanup()
 	case "local":
 		return runLocalCleanup()
+	case "k8s":
+		return runK8sCleanup()
 	default:
 		return fmt.Errorf("unknown cleanup mode: %s", mode)
 	}
@@ -91,6 +228,9 @@ func detectCleanupMode() string {
 	if cleanupLocal {
 		return "local"
 	}
+	if cleanupK8s {
+		return "k8s"
+	}
 
 	// Check for state files
 	if cleanupState != "" {
@@ -99,6 +239,9 @@ func detectCleanupMode() string {
 			if strings.Contains(cleanupState, "azure") {
 				return "azure"
 			}
+			if strings.Contains(cleanupState, "k8s") {
+				return "k8s"
+			}
 			return "local"
 		}
 	}
@@ -106,6 +249,9 @@ func detectCleanupMode() string {
 	if _, err := os.Stat(".devlake-azure.json"); err == nil {
 		return "azure"
 	}
+	if _, err := os.Stat(".devlake-k8s.json"); err == nil {
+		return "k8s"
+	}
 	if _, err := os.Stat(".devlake-local.json"); err == nil {
 		return "local"
 	}
@@ -160,6 +306,23 @@ func runAzureCleanup() error {
 	for _, c := range state.Resources.Containers {
 		fmt.Printf("  Container:   %s\n", c)
 	}
+	if state.Resources.Environment != "" {
+		fmt.Printf("  Environment: %s\n", state.Resources.Environment)
+	}
+	if network := state.Resources.Network; network.Gateway != "" {
+		fmt.Printf("  App Gateway: %s (public IP %s)\n", network.Gateway, network.PublicIP)
+		fmt.Printf("  VNet:        %s\n", network.VNet)
+		fmt.Printf("  DNS Zone:    %s\n", network.DNSZone)
+	}
+	if state.Schedule != nil {
+		fmt.Printf("  Schedule:    %s\n", strings.Join(state.Schedule.Workflows, ", "))
+	}
+	if state.Suffix == "" {
+		state.Suffix = azure.Suffix(state.ResourceGroup)
+	}
+	if cleanupKeepRG {
+		fmt.Printf("  ...and anything else tagged %s=%s\n", azure.InstanceTag, state.Suffix)
+	}
 
 	fmt.Printf("\n🌐 Endpoints that will be removed:\n")
 	fmt.Printf("  Backend:  %s\n", state.Endpoints.Backend)
@@ -173,6 +336,10 @@ func runAzureCleanup() error {
 		}
 	}
 
+	if err := backupBeforeCleanup(backupOpts{Azure: true, StateFile: stateFile}, true); err != nil {
+		return err
+	}
+
 	// Check Azure login
 	fmt.Println("\n🔑 Checking Azure CLI login...")
 	_, err = azure.CheckLogin()
@@ -184,25 +351,10 @@ func runAzureCleanup() error {
 	}
 
 	if cleanupKeepRG {
-		// Delete individual resources
-		for _, container := range state.Resources.Containers {
-			fmt.Printf("\n   Deleting container %s...\n", container)
-			_ = azure.DeleteResource("container", container, state.ResourceGroup)
-		}
-		fmt.Println("\n   Deleting MySQL server...")
-		_ = azure.DeleteResource("mysql", state.Resources.MySQL, state.ResourceGroup)
-
-		if acrName, ok := state.Resources.ACR.(string); ok && acrName != "" {
-			fmt.Println("\n   Deleting Container Registry...")
-			_ = azure.DeleteResource("acr", acrName, state.ResourceGroup)
+		if err := deleteAzureResources(&state); err != nil {
+			fmt.Printf("\n   State file %s kept so cleanup can be run again.\n", stateFile)
+			return err
 		}
-
-		fmt.Println("\n   Deleting Key Vault...")
-		_ = azure.DeleteResource("keyvault", state.Resources.KeyVault, state.ResourceGroup)
-
-		fmt.Println("   Purging soft-deleted Key Vault...")
-		_ = azure.PurgeKeyVault(state.Resources.KeyVault, state.Region)
-
 		fmt.Printf("\n   Resource group %q kept.\n", state.ResourceGroup)
 	} else {
 		fmt.Printf("\n   Deleting resource group %q...\n", state.ResourceGroup)
@@ -219,6 +371,9 @@ func runAzureCleanup() error {
 	} else {
 		fmt.Println("   ✅ State file removed")
 	}
+	if err := azure.ForgetStateFile(state.Suffix); err != nil {
+		fmt.Printf("   ⚠️  Could not update the state file index: %v\n", err)
+	}
 
 	// Clean up .devlake.env if present
 	if _, err := os.Stat(".devlake.env"); err == nil {
@@ -237,6 +392,70 @@ func runAzureCleanup() error {
 	return nil
 }
 
+// deleteAzureResources deletes a deployment's resources but not its resource
+// group: those tagged with its suffix plus those named in the state file.
+func deleteAzureResources(state *azureStateData) error {
+	subscription := state.SubscriptionID
+	if subscription == "" {
+		acct, err := azure.CheckLogin()
+		if err != nil {
+			return fmt.Errorf("not logged in to Azure CLI — run 'az login' first")
+		}
+		subscription = acct.ID
+	}
+
+	fmt.Printf("\n🔍 Finding resources tagged %s=%s...\n", azure.InstanceTag, state.Suffix)
+	var resources []azure.Resource
+	tagged, err := azure.TaggedResources(state.Suffix)
+	if err != nil {
+		fmt.Printf("   ⚠️  %v\n", err)
+		fmt.Println("   Deleting only the resources named in the state file")
+	}
+	for _, r := range tagged {
+		if !r.IsResourceGroup() && strings.EqualFold(r.ResourceGroup, state.ResourceGroup) {
+			resources = append(resources, r)
+		}
+	}
+	fmt.Printf("   Found %d tagged resource(s)\n", len(resources))
+	resources = azure.MergeResources(resources, state.stateResources(subscription))
+
+	fmt.Printf("\n🗑️  Deleting %d resource(s), retrying failures up to %d times...\n", len(resources), azureDeletePasses-1)
+	failures := azure.DeleteResources(resources, azureDeletePasses, azureDeleteRetryWait, func(r azure.Resource, err error) {
+		if err != nil {
+			fmt.Printf("   ⚠️  %s: %s\n", r.Name, azure.ErrorReason(err))
+		} else {
+			fmt.Printf("   ✅ %s (%s)\n", r.Name, r.Type)
+		}
+	})
+	if len(failures) == 0 {
+		return nil
+	}
+
+	fmt.Printf("\n❌ %d resource(s) could not be deleted:\n\n", len(failures))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  NAME\tTYPE\tREASON")
+	for _, f := range failures {
+		fmt.Fprintf(w, "  %s\t%s\t%s\n", f.Resource.Name, f.Resource.Type, f.Reason())
+	}
+	w.Flush()
+	return fmt.Errorf("%d Azure resource(s) could not be deleted — fix the causes above and run cleanup again", len(failures))
+}
+
+// backupBeforeCleanup runs a backup when --backup is set, or offers one
+// interactively when the cleanup will delete the database. A failed backup
+// aborts the cleanup.
+func backupBeforeCleanup(opts backupOpts, deletesData bool) error {
+	if !cleanupBackup {
+		if cleanupForce || !deletesData || !prompt.Confirm("Back up the database first?") {
+			return nil
+		}
+	}
+	if _, err := runBackup(opts, os.Stdout); err != nil {
+		return fmt.Errorf("backup failed — cleanup aborted, nothing was removed: %w", err)
+	}
+	return nil
+}
+
 func runLocalCleanup() error {
 	printBanner("DevLake Local Cleanup")
 
@@ -245,9 +464,16 @@ func runLocalCleanup() error {
 		stateFile = ".devlake-local.json"
 	}
 
+	// Remember the instance name before the state file is removed
+	instanceName := cleanupInstance
+	if s, err := devlake.LoadState(stateFile); err == nil && s != nil && s.Instance != "" {
+		instanceName = s.Instance
+	}
+
 	// Build list of files/dirs that will be removed
 	filesToRemove := []string{
 		"docker-compose.yml",
+		"docker-compose.override.yml",
 		".env",
 		".env.bak",
 		stateFile,
@@ -280,6 +506,10 @@ func runLocalCleanup() error {
 		}
 	}
 
+	if err := backupBeforeCleanup(backupOpts{Local: true, StateFile: cleanupState}, !cleanupKeepData); err != nil {
+		return err
+	}
+
 	// Stop and remove containers, volumes, and images
 	fmt.Println("\n🐳 Running docker compose down...")
 	cwd, _ := os.Getwd()
@@ -317,6 +547,14 @@ func runLocalCleanup() error {
 		}
 	}
 
+	if instanceName != "" {
+		if err := instance.Remove(instanceName); err != nil {
+			fmt.Printf("   ⚠️  Could not unregister instance %q: %v\n", instanceName, err)
+		} else {
+			fmt.Printf("   ✅ Instance %q unregistered\n", instanceName)
+		}
+	}
+
 	printBanner("✅ Cleanup Complete!")
 
 	if cleanupKeepData {
@@ -327,3 +565,97 @@ func runLocalCleanup() error {
 
 	return nil
 }
+
+func runK8sCleanup() error {
+	printBanner("DevLake Kubernetes Cleanup")
+
+	if cleanupBackup {
+		return fmt.Errorf("--backup is not supported for Kubernetes deployments — dump the database with 'kubectl exec' first, or use --keep-data")
+	}
+
+	stateFile := cleanupState
+	if stateFile == "" {
+		stateFile = ".devlake-k8s.json"
+	}
+	state, err := loadK8sState(stateFile)
+	if err != nil {
+		return err
+	}
+	kc := state.kubectl()
+	deleteNamespace := state.CreatedNamespace && !cleanupKeepData
+
+	fmt.Printf("\n📋 Deployment found:\n")
+	fmt.Printf("  Deployed:  %s\n", state.DeployedAt)
+	if state.Context != "" {
+		fmt.Printf("  Context:   %s\n", state.Context)
+	}
+	fmt.Printf("  Namespace: %s\n", state.Namespace)
+
+	fmt.Println("\n🗑️  The following will be removed:")
+	if deleteNamespace {
+		fmt.Printf("  • Namespace %s and everything in it\n", state.Namespace)
+	} else {
+		fmt.Printf("  • %s StatefulSet, Deployments and Services\n", state.Name)
+		if !cleanupKeepData {
+			fmt.Printf("  • %s-secrets Secret and MySQL volume\n", state.Name)
+		}
+	}
+	if state.ManifestsDir != "" && !cleanupKeepData {
+		fmt.Printf("  • %s (rendered manifests)\n", state.ManifestsDir)
+	}
+	fmt.Printf("  • %s\n", stateFile)
+
+	if !cleanupForce {
+		if !prompt.Confirm("\nProceed with cleanup?") {
+			fmt.Println("Cleanup cancelled.")
+			return nil
+		}
+	}
+
+	fmt.Println("\n☸️  Checking kubectl...")
+	if err := kc.CheckAvailable(); err != nil {
+		return err
+	}
+
+	if deleteNamespace {
+		fmt.Printf("\n   Deleting namespace %s...\n", state.Namespace)
+		if err := kc.DeleteNamespace(); err != nil {
+			return fmt.Errorf("failed to delete namespace: %w", err)
+		}
+		fmt.Println("   Deletion initiated (running in background)")
+	} else {
+		kinds := []string{"statefulset", "deployment", "service"}
+		if !cleanupKeepData {
+			kinds = append(kinds, "secret", "pvc")
+		}
+		fmt.Printf("\n   Deleting %s...\n", strings.Join(kinds, ", "))
+		if err := kc.Delete(state.selector(""), kinds...); err != nil {
+			return fmt.Errorf("failed to delete resources: %w", err)
+		}
+		fmt.Println("   ✅ Resources deleted")
+	}
+
+	// The rendered Secret holds the passwords for a kept volume
+	if state.ManifestsDir != "" && !cleanupKeepData {
+		if err := os.RemoveAll(state.ManifestsDir); err != nil {
+			fmt.Printf("   ⚠️  Could not remove %s: %v\n", state.ManifestsDir, err)
+		} else {
+			fmt.Printf("   ✅ Removed %s\n", state.ManifestsDir)
+		}
+	}
+	if err := os.Remove(stateFile); err != nil && !os.IsNotExist(err) {
+		fmt.Printf("   ⚠️  Could not remove state file: %v\n", err)
+	} else {
+		fmt.Println("   ✅ State file removed")
+	}
+
+	printBanner("✅ Cleanup Complete!")
+
+	if cleanupKeepData {
+		fmt.Printf("  Note: the MySQL volume and %s-secrets were preserved.\n", state.Name)
+		fmt.Println("  Re-run 'gh devlake deploy k8s' with the same --namespace and --name to reattach them.")
+		fmt.Println()
+	}
+
+	return nil
+}
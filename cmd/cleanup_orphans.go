@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/DevExpGBB/gh-devlake/internal/azure"
+)
+
+// azureOrphan is a tagged deployment whose state file is gone.
+type azureOrphan struct {
+	azure.Instance
+	Reason  string `json:"reason"`
+	Cleanup string `json:"cleanup"` // command that removes it
+}
+
+// runAzureListOrphans lists the deployments in the current subscription
+// whose resources are tagged but whose state file is gone. Tags hold only the
+// deployment suffix; the state file of each suffix comes from the index deploy
+// azure keeps on this machine. It deletes nothing.
+func runAzureListOrphans() error {
+	if !outputJSON {
+		printBanner("DevLake Azure Orphans")
+		fmt.Println("\n🔑 Checking Azure login...")
+	}
+	acct, err := azure.CheckLogin()
+	if err != nil {
+		return fmt.Errorf("not logged in to Azure CLI — run 'az login' first")
+	}
+	if !outputJSON {
+		fmt.Printf("   ✅ Subscription: %s\n", acct.Name)
+		fmt.Printf("\n🔍 Finding resources tagged %s...\n", azure.InstanceTag)
+	}
+	tagged, err := azure.TaggedResources("")
+	if err != nil {
+		return err
+	}
+	stateFiles, err := azure.StateFiles()
+	if err != nil {
+		return err
+	}
+	orphans := findAzureOrphans(tagged, stateFiles)
+
+	if outputJSON {
+		return printJSON(orphans)
+	}
+	if len(orphans) == 0 {
+		fmt.Println("\n✅ No orphans — every tagged deployment still has its state file.")
+		return nil
+	}
+
+	fmt.Printf("\n⚠️  %d orphaned deployment(s):\n\n", len(orphans))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  RESOURCE GROUP\tINSTANCE\tRESOURCES\tSTATE FILE\tREASON")
+	for _, o := range orphans {
+		stateFile := o.StateFile
+		if stateFile == "" {
+			stateFile = "-"
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%d\t%s\t%s\n", o.ResourceGroup, o.Suffix, len(o.Resources), stateFile, o.Reason)
+	}
+	w.Flush()
+
+	fmt.Println("\nA state file on another machine looks missing here — check before deleting.")
+	fmt.Println("To remove an orphan:")
+	for _, o := range orphans {
+		fmt.Printf("  %s\n", o.Cleanup)
+	}
+	return nil
+}
+
+// findAzureOrphans groups tagged resources into deployments and returns the
+// ones without a state file that records them. stateFiles maps suffixes to
+// state file paths.
+func findAzureOrphans(tagged []azure.Resource, stateFiles map[string]string) []azureOrphan {
+	orphans := []azureOrphan{}
+	for _, inst := range azure.GroupInstances(tagged) {
+		if path := stateFiles[inst.Suffix]; path != "" {
+			inst.StateFile = path
+		}
+		reason := azureOrphanReason(inst)
+		if reason == "" {
+			continue
+		}
+		// Delete the whole group only if deploy created (and tagged) it
+		cleanup := "gh devlake cleanup --azure --resource-group " + inst.ResourceGroup + " --keep-resource-group"
+		for _, r := range inst.Resources {
+			if r.IsResourceGroup() {
+				cleanup = "gh devlake cleanup --azure --resource-group " + inst.ResourceGroup
+				break
+			}
+		}
+		orphans = append(orphans, azureOrphan{Instance: inst, Reason: reason, Cleanup: cleanup})
+	}
+	return orphans
+}
+
+// azureOrphanReason returns why a tagged deployment is an orphan, or "" when
+// its state file still records it.
+func azureOrphanReason(inst azure.Instance) string {
+	if inst.StateFile == "" {
+		return "no state file on this machine records it"
+	}
+	if _, err := os.Stat(inst.StateFile); os.IsNotExist(err) {
+		return "state file is gone"
+	}
+	state, err := loadAzureStateData(inst.StateFile)
+	if err != nil {
+		return "state file is unreadable"
+	}
+	if !strings.EqualFold(state.ResourceGroup, inst.ResourceGroup) {
+		return "state file now records " + state.ResourceGroup
+	}
+	if state.Suffix != "" && state.Suffix != inst.Suffix {
+		return "state file now records instance " + state.Suffix
+	}
+	return ""
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DevExpGBB/gh-devlake/internal/azure"
+)
+
+func taggedResource(rg, typ, name, suffix string) azure.Resource {
+	return azure.Resource{
+		ID:            azure.ResourceID("sub", rg, typ, name),
+		Name:          name,
+		Type:          typ,
+		ResourceGroup: rg,
+		Tags:          azure.InstanceTags(nil, suffix),
+	}
+}
+
+func TestFindAzureOrphans(t *testing.T) {
+	dir := t.TempDir()
+	live := filepath.Join(dir, "live.json")
+	moved := filepath.Join(dir, "moved.json")
+	legacy := filepath.Join(dir, "legacy.json")
+	os.WriteFile(live, []byte(`{"resourceGroup": "live-rg", "suffix": "aaaaa", "partial": true}`), 0644)
+	os.WriteFile(moved, []byte(`{"resourceGroup": "new-rg"}`), 0644)
+	os.WriteFile(legacy, []byte(`{"resourceGroup": "legacy-rg"}`), 0644)
+
+	// Deployed before the state path left the tags
+	legacyVault := taggedResource("legacy-rg", "Microsoft.KeyVault/vaults", "kv5", "eeeee")
+	legacyVault.Tags["gh-devlake-state"] = legacy
+
+	tagged := []azure.Resource{
+		taggedResource("live-rg", "Microsoft.KeyVault/vaults", "kv1", "aaaaa"),
+		taggedResource("gone-rg", "Microsoft.KeyVault/vaults", "kv2", "bbbbb"),
+		{ID: "/subscriptions/sub/resourceGroups/gone-rg", Name: "gone-rg", Type: "Microsoft.Resources/resourceGroups", ResourceGroup: "gone-rg",
+			Tags: azure.InstanceTags(nil, "bbbbb")},
+		taggedResource("old-rg", "Microsoft.DBforMySQL/flexibleServers", "mysql3", "ccccc"),
+		taggedResource("bare-rg", "Microsoft.DBforMySQL/flexibleServers", "mysql4", "ddddd"),
+		legacyVault,
+	}
+	stateFiles := map[string]string{
+		"aaaaa": live,
+		"bbbbb": filepath.Join(dir, "gone.json"),
+		"ccccc": moved,
+	}
+	orphans := findAzureOrphans(tagged, stateFiles)
+	if len(orphans) != 3 {
+		t.Fatalf("orphans = %+v", orphans)
+	}
+	want := map[string]string{
+		"bare-rg": "no state file on this machine records it",
+		"gone-rg": "state file is gone",
+		"old-rg":  "state file now records new-rg",
+	}
+	for _, o := range orphans {
+		if o.Reason != want[o.ResourceGroup] {
+			t.Errorf("%s reason = %q, want %q", o.ResourceGroup, o.Reason, want[o.ResourceGroup])
+		}
+	}
+	// The group is deleted only when deploy created it
+	for _, o := range orphans {
+		keep := strings.HasSuffix(o.Cleanup, "--keep-resource-group")
+		if keep != (o.ResourceGroup != "gone-rg") {
+			t.Errorf("%s cleanup = %q", o.ResourceGroup, o.Cleanup)
+		}
+	}
+}
+
+func TestAzureStateResources(t *testing.T) {
+	var state azureStateData
+	state.ResourceGroup = "devlake-rg"
+	state.Region = "eastus"
+	state.Runtime = azure.RuntimeACA
+	state.Resources.ACR = "devlakeacrabc12"
+	state.Resources.KeyVault = "devlakekvabc12"
+	state.Resources.Containers = []string{"devlake-backend-abc12"}
+	state.Resources.Network = azure.PrivateResourceNames("devlake", "abc12")
+	state.Schedule = &azure.Schedule{Workflows: []string{"devlake-down-abc12"}}
+
+	byType := map[string]azure.Resource{}
+	for _, r := range state.stateResources("sub") {
+		byType[r.Type] = r
+	}
+	if len(byType) != 10 {
+		t.Errorf("resources = %v", byType)
+	}
+	if r := byType["Microsoft.App/containerApps"]; r.ID != "/subscriptions/sub/resourceGroups/devlake-rg/providers/Microsoft.App/containerApps/devlake-backend-abc12" {
+		t.Errorf("container app ID = %s", r.ID)
+	}
+	if r := byType["Microsoft.KeyVault/vaults"]; r.Location != "eastus" {
+		t.Error("Key Vault needs its location to be purged")
+	}
+	if r := byType["Microsoft.Network/privateDnsZones/virtualNetworkLinks"]; !strings.HasSuffix(r.ID, "/virtualNetworkLinks/devlake-vnet-link") {
+		t.Errorf("DNS link ID = %s", r.ID)
+	}
+	// Nothing recorded, nothing named: MySQL and the environment are left out
+	if _, ok := byType["Microsoft.DBforMySQL/flexibleServers"]; ok {
+		t.Error("empty MySQL name produced a resource")
+	}
+}
@@ -23,6 +23,12 @@ var (
 	connName       string
 	connProxy      string
 	connEndpoint   string
+
+	connAppID          string
+	connInstallationID int
+	connPrivateKeyFile string
+
+	connSkipTokenCheck bool
 )
 
 var addConnectionCmd = &cobra.Command{
@@ -35,20 +41,36 @@ add connections for additional plugins.
 
 Shared flags (all plugins):
   --plugin       Plugin to configure
-  --token        Personal access token (or password for BasicAuth plugins)
+  --token        Personal access token (or password for BasicAuth plugins);
+                 accepts env:NAME, file:/path, cmd:<command>, azkv:<vault>/<secret>
   --username     Username for BasicAuth plugins (Jenkins, Bitbucket, Jira)
   --name         Connection display name
   --endpoint     API endpoint override
   --proxy        HTTP proxy URL
   --env-file     Path to env file containing PAT
   --skip-cleanup Do not delete .devlake.env after setup
+  --skip-token-check  Skip the GitHub PAT scope pre-flight check
 
 GitHub Copilot-specific flags:
   --enterprise   Enterprise slug
   --org          Organization slug (required unless enterprise provided)
 
+GitHub App auth (GitHub and GitHub Copilot, instead of a PAT):
+  --app-id            GitHub App ID
+  --installation-id   Installation ID of the app on your org
+  --private-key-file  Path to the app's private key (PEM)
+
 Token resolution order:
-  --token flag → .devlake.env → environment variable → masked prompt
+  --token flag → .devlake.env → environment variable → OS keychain
+  → gh auth token (GitHub/Copilot) → masked prompt
+
+For GitHub and GitHub Copilot, the PAT is inspected via the GitHub API before
+the connection is created: missing scopes (or failed fine-grained permission
+probes), pending SAML SSO authorization, and upcoming expiry are reported.
+
+GitHub App values may also come from GITHUB_APP_ID, GITHUB_APP_INSTALLATION_ID,
+and GITHUB_APP_PRIVATE_KEY / GITHUB_APP_PRIVATE_KEY_FILE in .devlake.env or the
+environment.
 
 Example (GitHub):
   gh devlake configure connection add --plugin github --token ghp_xxx --org my-org
@@ -56,8 +78,14 @@ Example (GitHub):
 Example (Copilot):
   gh devlake configure connection add --plugin gh-copilot --token ghp_xxx --org my-org --enterprise my-ent
 
+Example (GitHub App):
+  gh devlake configure connection add --plugin github --org my-org --app-id 123456 --installation-id 7890123 --private-key-file app.pem
+
 Example (Jenkins):
-  gh devlake configure connection add --plugin jenkins --username admin --token mypassword`,
+  gh devlake configure connection add --plugin jenkins --username admin --token mypassword
+
+Example (token from Azure Key Vault):
+  gh devlake configure connection add --plugin sonarqube --endpoint https://sonar.example.com/api/ --token azkv:my-kv/sonar-token`,
 	RunE: runAddConnection,
 }
 
@@ -65,13 +93,17 @@ func init() {
 	addConnectionCmd.Flags().StringVar(&connPlugin, "plugin", "", fmt.Sprintf("Plugin to configure (%s)", strings.Join(availablePluginSlugs(), ", ")))
 	addConnectionCmd.Flags().StringVar(&connOrg, "org", "", "Organization slug")
 	addConnectionCmd.Flags().StringVar(&connEnterprise, "enterprise", "", "Enterprise slug")
-	addConnectionCmd.Flags().StringVar(&connToken, "token", "", "Personal access token (used as password for BasicAuth plugins)")
+	addConnectionCmd.Flags().StringVar(&connToken, "token", "", "Personal access token (used as password for BasicAuth plugins); accepts env:, file:, cmd:, azkv: references")
 	addConnectionCmd.Flags().StringVar(&connUsername, "username", "", "Username for BasicAuth plugins (Jenkins, Bitbucket, Jira)")
 	addConnectionCmd.Flags().StringVar(&connEnvFile, "env-file", ".devlake.env", "Path to env file containing PAT")
 	addConnectionCmd.Flags().BoolVar(&connSkipClean, "skip-cleanup", false, "Do not delete .devlake.env after setup")
 	addConnectionCmd.Flags().StringVar(&connName, "name", "", "Connection display name (defaults to \"Plugin - org\")")
 	addConnectionCmd.Flags().StringVar(&connProxy, "proxy", "", "HTTP proxy URL")
 	addConnectionCmd.Flags().StringVar(&connEndpoint, "endpoint", "", "API endpoint override")
+	addConnectionCmd.Flags().StringVar(&connAppID, "app-id", "", "GitHub App ID (GitHub App auth instead of a PAT)")
+	addConnectionCmd.Flags().IntVar(&connInstallationID, "installation-id", 0, "GitHub App installation ID")
+	addConnectionCmd.Flags().StringVar(&connPrivateKeyFile, "private-key-file", "", "Path to the GitHub App private key (PEM)")
+	addConnectionCmd.Flags().BoolVar(&connSkipTokenCheck, "skip-token-check", false, "Skip the GitHub PAT scope pre-flight check")
 	configureConnectionsCmd.AddCommand(addConnectionCmd)
 }
 
@@ -125,31 +157,40 @@ func runAddConnection(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	// ── Resolve token ──
-	fmt.Printf("\n🔑 Resolving %s PAT...\n", def.DisplayName)
-	tokResult, err := token.Resolve(token.ResolveOpts{
-		FlagValue:   connToken,
-		EnvFilePath: connEnvFile,
-		EnvFileKeys: def.EnvFileKeys,
-		EnvVarNames: def.EnvVarNames,
-		DisplayName: def.DisplayName,
-		ScopeHint:   def.ScopeHint,
-	})
-	if err != nil {
-		return err
-	}
-	fmt.Printf("   Token loaded from: %s\n", tokResult.Source)
-
-	// ── Create connection ──
-	fmt.Printf("\n📡 Creating %s connection...\n", def.DisplayName)
 	params := ConnectionParams{
-		Token:      tokResult.Token,
 		Org:        org,
 		Enterprise: connEnterprise,
 		Name:       connName,
 		Proxy:      connProxy,
 		Endpoint:   connEndpoint,
+
+		SkipTokenCheck: connSkipTokenCheck,
+	}
+
+	// ── Resolve credentials (GitHub App or PAT) ──
+	var cleanupEnvFile string
+	app, err := resolveAppAuth(def, connAppID, connInstallationID, connPrivateKeyFile, connEnvFile)
+	if err != nil {
+		return err
 	}
+	if app != nil {
+		fmt.Printf("\n🔑 Using GitHub App auth (app ID %s, installation %d)\n", app.AppID, app.InstallationID)
+		fmt.Printf("   Private key loaded from: %s\n", app.KeySource)
+		app.apply(&params)
+		cleanupEnvFile = app.EnvFilePath
+	} else {
+		fmt.Printf("\n🔑 Resolving %s PAT...\n", def.DisplayName)
+		tokResult, err := token.Resolve(def.tokenResolveOpts(connToken, connEnvFile))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("   Token loaded from: %s\n", tokResult.Describe())
+		params.Token = tokResult.Token
+		cleanupEnvFile = tokResult.EnvFilePath
+	}
+
+	// ── Create connection ──
+	fmt.Printf("\n📡 Creating %s connection...\n", def.DisplayName)
 
 	// Resolve username for BasicAuth plugins
 	if def.NeedsUsername {
@@ -194,9 +235,9 @@ func runAddConnection(cmd *cobra.Command, args []string) error {
 	}
 
 	// ── Cleanup env file ──
-	if !connSkipClean && tokResult.EnvFilePath != "" {
-		fmt.Printf("\n🧹 Cleaning up %s...\n", tokResult.EnvFilePath)
-		if err := os.Remove(tokResult.EnvFilePath); err != nil && !os.IsNotExist(err) {
+	if !connSkipClean && cleanupEnvFile != "" {
+		fmt.Printf("\n🧹 Cleaning up %s...\n", cleanupEnvFile)
+		if err := os.Remove(cleanupEnvFile); err != nil && !os.IsNotExist(err) {
 			fmt.Fprintf(os.Stderr, "⚠️  Could not delete env file: %v\n", err)
 		} else {
 			fmt.Println("   ✅ Env file deleted")
@@ -7,6 +7,7 @@ import (
 
 	"github.com/DevExpGBB/gh-devlake/internal/devlake"
 	"github.com/DevExpGBB/gh-devlake/internal/prompt"
+	"github.com/DevExpGBB/gh-devlake/internal/token"
 	"github.com/spf13/cobra"
 )
 
@@ -19,6 +20,10 @@ var (
 	updateConnName       string
 	updateConnEndpoint   string
 	updateConnProxy      string
+
+	updateConnAppID          string
+	updateConnInstallationID int
+	updateConnPrivateKeyFile string
 )
 
 var updateConnectionCmd = &cobra.Command{
@@ -32,6 +37,8 @@ without recreating the connection (which would lose scope configs and blueprints
 Flag-based (non-interactive):
   gh devlake configure connection update --plugin github --id 1 --token <new-token>
   gh devlake configure connection update --plugin gh-copilot --id 2 --org new-org
+  gh devlake configure connection update --plugin jira --id 3 --token azkv:my-kv/jira-token
+  gh devlake configure connection update --plugin github --id 1 --app-id 123456 --installation-id 7890123 --private-key-file app.pem
 
 Interactive (no flags required):
   gh devlake configure connection update
@@ -43,12 +50,15 @@ In interactive mode, current values are shown as defaults. Press Enter to keep.`
 func init() {
 	updateConnectionCmd.Flags().StringVar(&updateConnPlugin, "plugin", "", fmt.Sprintf("Plugin slug (%s)", strings.Join(availablePluginSlugs(), ", ")))
 	updateConnectionCmd.Flags().IntVar(&updateConnID, "id", 0, "Connection ID to update")
-	updateConnectionCmd.Flags().StringVar(&updateConnToken, "token", "", "New personal access token for rotation")
+	updateConnectionCmd.Flags().StringVar(&updateConnToken, "token", "", "New personal access token for rotation (or env:, file:, cmd:, azkv: reference)")
 	updateConnectionCmd.Flags().StringVar(&updateConnOrg, "org", "", "Organization slug")
 	updateConnectionCmd.Flags().StringVar(&updateConnEnterprise, "enterprise", "", "Enterprise slug")
 	updateConnectionCmd.Flags().StringVar(&updateConnName, "name", "", "Connection display name")
 	updateConnectionCmd.Flags().StringVar(&updateConnEndpoint, "endpoint", "", "API endpoint URL")
 	updateConnectionCmd.Flags().StringVar(&updateConnProxy, "proxy", "", "HTTP proxy URL")
+	updateConnectionCmd.Flags().StringVar(&updateConnAppID, "app-id", "", "GitHub App ID (switches to GitHub App auth)")
+	updateConnectionCmd.Flags().IntVar(&updateConnInstallationID, "installation-id", 0, "GitHub App installation ID")
+	updateConnectionCmd.Flags().StringVar(&updateConnPrivateKeyFile, "private-key-file", "", "Path to the GitHub App private key (PEM)")
 	configureConnectionsCmd.AddCommand(updateConnectionCmd)
 }
 
@@ -103,7 +113,15 @@ func runUpdateConnection(cmd *cobra.Command, args []string) error {
 	// ── Build PATCH request ──
 	var req *devlake.ConnectionUpdateRequest
 	if flagMode {
+		if cmd.Flags().Changed("token") {
+			if updateConnToken, err = token.ResolveSecretRef(updateConnToken); err != nil {
+				return err
+			}
+		}
 		req = buildUpdateRequestFromFlags(cmd, current)
+		if err := applyAppAuthUpdate(cmd, current, def, req); err != nil {
+			return err
+		}
 	} else {
 		req, err = buildUpdateRequestInteractive(current, def)
 		if err != nil {
@@ -124,6 +142,9 @@ func runUpdateConnection(cmd *cobra.Command, args []string) error {
 	if current.Token != "" {
 		fmt.Printf("   Token:      %s\n", maskToken(current.Token))
 	}
+	if current.AppID != "" {
+		fmt.Printf("   App ID:     %s (installation %d)\n", current.AppID, current.InstallationID)
+	}
 
 	// ── Apply PATCH ──
 	fmt.Printf("\n📡 Updating %s connection (ID=%d)...\n", plugin, connID)
@@ -194,6 +215,46 @@ func buildUpdateRequestFromFlags(cmd *cobra.Command, current *devlake.Connection
 	return req
 }
 
+// applyAppAuthUpdate adds GitHub App credentials to a flag-mode PATCH request
+// when --app-id, --installation-id, or --private-key-file is set. Values not
+// given on the command line are taken from the current connection; the private
+// key is required when switching a PAT connection to app auth.
+func applyAppAuthUpdate(cmd *cobra.Command, current *devlake.Connection, def *ConnectionDef, req *devlake.ConnectionUpdateRequest) error {
+	flags := cmd.Flags()
+	if !flags.Changed("app-id") && !flags.Changed("installation-id") && !flags.Changed("private-key-file") {
+		return nil
+	}
+	if def == nil || !def.SupportsAppAuth {
+		return fmt.Errorf("GitHub App authentication is only supported for GitHub and GitHub Copilot connections")
+	}
+	if flags.Changed("token") {
+		return fmt.Errorf("--token cannot be combined with GitHub App flags")
+	}
+
+	req.AuthMethod = appAuthMethod
+	req.AppID = current.AppID
+	if flags.Changed("app-id") {
+		req.AppID = updateConnAppID
+	}
+	req.InstallationID = current.InstallationID
+	if flags.Changed("installation-id") {
+		req.InstallationID = updateConnInstallationID
+	}
+	if req.AppID == "" || req.InstallationID <= 0 {
+		return fmt.Errorf("--app-id and --installation-id are required when switching to GitHub App auth")
+	}
+
+	switching := current.AuthMethod != appAuthMethod
+	if flags.Changed("private-key-file") || switching {
+		key, err := token.ResolveAppPrivateKey(token.AppKeyOpts{FilePath: updateConnPrivateKeyFile})
+		if err != nil {
+			return err
+		}
+		req.SecretKey = key.Token
+	}
+	return nil
+}
+
 // buildUpdateRequestInteractive prompts the user for each field, showing current values as defaults.
 func buildUpdateRequestInteractive(current *devlake.Connection, def *ConnectionDef) (*devlake.ConnectionUpdateRequest, error) {
 	req := &devlake.ConnectionUpdateRequest{}
@@ -241,7 +302,11 @@ func buildUpdateRequestInteractive(current *devlake.Connection, def *ConnectionD
 	fmt.Println()
 	newToken := prompt.ReadSecret(fmt.Sprintf("   Token [%s] (Enter to keep)", tokenDisplay))
 	if newToken != "" {
-		req.Token = newToken
+		tok, err := token.ResolveSecretRef(newToken)
+		if err != nil {
+			return nil, err
+		}
+		req.Token = tok
 		req.AuthMethod = "AccessToken"
 	}
 
@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -107,6 +109,9 @@ func resetUpdateConnFlags() {
 	updateConnName = ""
 	updateConnEndpoint = ""
 	updateConnProxy = ""
+	updateConnAppID = ""
+	updateConnInstallationID = 0
+	updateConnPrivateKeyFile = ""
 }
 
 func TestBuildUpdateRequestFromFlags_OnlyChangedFlags(t *testing.T) {
@@ -198,3 +203,74 @@ func TestMaskToken(t *testing.T) {
 		}
 	}
 }
+
+func TestApplyAppAuthUpdate(t *testing.T) {
+	keyPath := filepath.Join(t.TempDir(), "app.pem")
+	if err := os.WriteFile(keyPath, []byte(testAppPEM), 0600); err != nil {
+		t.Fatal(err)
+	}
+	newCmd := func() *cobra.Command {
+		resetUpdateConnFlags()
+		cmd := &cobra.Command{}
+		cmd.Flags().StringVar(&updateConnToken, "token", "", "")
+		cmd.Flags().StringVar(&updateConnAppID, "app-id", "", "")
+		cmd.Flags().IntVar(&updateConnInstallationID, "installation-id", 0, "")
+		cmd.Flags().StringVar(&updateConnPrivateKeyFile, "private-key-file", "", "")
+		return cmd
+	}
+	t.Cleanup(resetUpdateConnFlags)
+	github := FindConnectionDef("github")
+
+	t.Run("no app flags leaves request untouched", func(t *testing.T) {
+		req := &devlake.ConnectionUpdateRequest{}
+		if err := applyAppAuthUpdate(newCmd(), &devlake.Connection{}, github, req); err != nil {
+			t.Fatal(err)
+		}
+		if req.AuthMethod != "" || req.AppID != "" {
+			t.Errorf("unexpected app fields: %+v", req)
+		}
+	})
+
+	t.Run("switch PAT connection to app", func(t *testing.T) {
+		cmd := newCmd()
+		_ = cmd.Flags().Set("app-id", "123")
+		_ = cmd.Flags().Set("installation-id", "456")
+		_ = cmd.Flags().Set("private-key-file", keyPath)
+		req := &devlake.ConnectionUpdateRequest{}
+		if err := applyAppAuthUpdate(cmd, &devlake.Connection{AuthMethod: "AccessToken"}, github, req); err != nil {
+			t.Fatal(err)
+		}
+		if req.AuthMethod != "AppKey" || req.AppID != "123" || req.InstallationID != 456 || req.SecretKey == "" {
+			t.Errorf("req = %+v", req)
+		}
+	})
+
+	t.Run("installation change on app connection keeps key", func(t *testing.T) {
+		cmd := newCmd()
+		_ = cmd.Flags().Set("installation-id", "789")
+		current := &devlake.Connection{AuthMethod: "AppKey", AppID: "123", InstallationID: 456}
+		req := &devlake.ConnectionUpdateRequest{}
+		if err := applyAppAuthUpdate(cmd, current, github, req); err != nil {
+			t.Fatal(err)
+		}
+		if req.AppID != "123" || req.InstallationID != 789 || req.SecretKey != "" {
+			t.Errorf("req = %+v", req)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		cmd := newCmd()
+		_ = cmd.Flags().Set("app-id", "123")
+		if err := applyAppAuthUpdate(cmd, &devlake.Connection{}, FindConnectionDef("jenkins"), &devlake.ConnectionUpdateRequest{}); err == nil {
+			t.Error("expected unsupported plugin error")
+		}
+		if err := applyAppAuthUpdate(cmd, &devlake.Connection{}, github, &devlake.ConnectionUpdateRequest{}); err == nil {
+			t.Error("expected missing installation ID error")
+		}
+		_ = cmd.Flags().Set("token", "ghp_x")
+		_ = cmd.Flags().Set("installation-id", "1")
+		if err := applyAppAuthUpdate(cmd, &devlake.Connection{}, github, &devlake.ConnectionUpdateRequest{}); err == nil {
+			t.Error("expected --token conflict error")
+		}
+	})
+}
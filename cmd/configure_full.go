@@ -31,7 +31,7 @@ For scripted/CI use, chain individual commands instead:
 }
 
 func init() {
-	configureFullCmd.Flags().StringVar(&fullToken, "token", "", "Personal access token (seeds token resolution; may still prompt per plugin)")
+	configureFullCmd.Flags().StringVar(&fullToken, "token", "", "Personal access token (seeds token resolution; may still prompt per plugin); accepts env:, file:, cmd:, azkv: references")
 	configureFullCmd.Flags().StringVar(&fullEnvFile, "env-file", ".devlake.env", "Path to env file containing PAT")
 	configureFullCmd.Flags().BoolVar(&fullSkipClean, "skip-cleanup", false, "Do not delete .devlake.env after setup")
 }
@@ -67,21 +67,27 @@ func runConnectionsInternal(defs []*ConnectionDef, org, enterprise, tokenVal, en
 	for _, def := range defs {
 		fmt.Printf("\n📡 Setting up %s connection...\n", def.DisplayName)
 
-		// Resolve token per-plugin
-		fmt.Printf("\n🔑 Resolving %s token...\n", def.DisplayName)
-		tokResult, err := token.Resolve(token.ResolveOpts{
-			FlagValue:   tokenVal,
-			EnvFilePath: envFile,
-			EnvFileKeys: def.EnvFileKeys,
-			EnvVarNames: def.EnvVarNames,
-			DisplayName: def.DisplayName,
-			ScopeHint:   def.ScopeHint,
-		})
+		// GitHub App credentials in the env file take precedence over a PAT
+		app, err := resolveAppAuth(def, "", 0, "", envFile)
 		if err != nil {
-			fmt.Printf("   ⚠️  Could not resolve token for %s: %v\n", def.DisplayName, err)
+			fmt.Printf("   ⚠️  Could not resolve GitHub App credentials for %s: %v\n", def.DisplayName, err)
 			continue
 		}
-		fmt.Printf("   Token loaded from: %s\n", tokResult.Source)
+
+		// Resolve token per-plugin
+		tokResult := &token.ResolveResult{}
+		if app != nil {
+			fmt.Printf("\n🔑 Using GitHub App auth for %s (app ID %s)\n", def.DisplayName, app.AppID)
+			tokResult.EnvFilePath = app.EnvFilePath
+		} else {
+			fmt.Printf("\n🔑 Resolving %s token...\n", def.DisplayName)
+			tokResult, err = token.Resolve(def.tokenResolveOpts(tokenVal, envFile))
+			if err != nil {
+				fmt.Printf("   ⚠️  Could not resolve token for %s: %v\n", def.DisplayName, err)
+				continue
+			}
+			fmt.Printf("   Token loaded from: %s\n", tokResult.Describe())
+		}
 		if tokResult.EnvFilePath != "" {
 			cleanupEnvFile = tokResult.EnvFilePath
 		}
@@ -133,6 +139,9 @@ func runConnectionsInternal(defs []*ConnectionDef, org, enterprise, tokenVal, en
 			Org:        pluginOrg,
 			Enterprise: pluginEnterprise,
 		}
+		if app != nil {
+			app.apply(&params)
+		}
 
 		// Resolve username per-plugin for BasicAuth
 		if def.NeedsUsername {
@@ -0,0 +1,560 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/DevExpGBB/gh-devlake/internal/devlake"
+	"github.com/DevExpGBB/gh-devlake/internal/gh"
+	"github.com/DevExpGBB/gh-devlake/internal/repofile"
+)
+
+// defaultCatalogColumns returns the catalog columns mapped by default: each
+// importable plugin's CatalogColumn. Override or extend with --column.
+func defaultCatalogColumns() map[string]string {
+	cols := map[string]string{}
+	for _, def := range importablePlugins() {
+		if def.CatalogColumn != "" {
+			cols[def.CatalogColumn] = def.Plugin
+		}
+	}
+	return cols
+}
+
+// importablePlugins returns the available plugins whose scopes can be created
+// from a plain identifier without interactive browsing.
+func importablePlugins() []*ConnectionDef {
+	var out []*ConnectionDef
+	for _, def := range AvailableConnections() {
+		if def.ImportScopeFunc != nil {
+			out = append(out, def)
+		}
+	}
+	return out
+}
+
+// catalogColumnHelp lists the default column mapping for the help text.
+func catalogColumnHelp() string {
+	var b strings.Builder
+	for _, def := range importablePlugins() {
+		if def.CatalogColumn != "" {
+			fmt.Fprintf(&b, "  %-18s %-10s (%s)\n", def.CatalogColumn, def.Plugin, def.CatalogHint)
+		}
+	}
+	return b.String()
+}
+
+// scopeImportOpts holds the flags for `configure scope import`.
+type scopeImportOpts struct {
+	ScopeOpts
+	File           string
+	Columns        []string
+	Connections    []string
+	TeamColumn     string
+	ProjectPerTeam bool
+}
+
+func newScopeImportCmd() *cobra.Command {
+	var opts scopeImportOpts
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Add scopes for many plugins from a service catalog CSV",
+		Long: `Reads a service catalog CSV and adds the scopes named in each row to the
+matching plugin connections. The first row must be a header.
+
+Default column mapping:
+` + catalogColumnHelp() + `
+A cell may hold several values separated by ";". Other columns are ignored
+unless mapped with --column. With --project-per-team, one DevLake project is
+created (or updated) per distinct value in the team column.
+
+Examples:
+  gh devlake configure scope import --file catalog.csv
+  gh devlake configure scope import --file catalog.csv --connection github=1 --connection jenkins=3
+  gh devlake configure scope import --file catalog.csv --column service_repo=github --project-per-team`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runScopeImport(cmd, &opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.File, "file", "", "Path to the catalog CSV (required)")
+	cmd.Flags().StringArrayVar(&opts.Columns, "column", nil, "Map a column to a plugin, as column=plugin (repeatable)")
+	cmd.Flags().StringArrayVar(&opts.Connections, "connection", nil, "Connection ID for a plugin, as plugin=id (repeatable; auto-detected if omitted)")
+	cmd.Flags().StringVar(&opts.TeamColumn, "team-column", "team", "Column holding the team name")
+	cmd.Flags().BoolVar(&opts.ProjectPerTeam, "project-per-team", false, "Create or update one project per team")
+	cmd.Flags().StringVar(&opts.Cron, "cron", "0 0 * * *", "Blueprint cron schedule for new team projects")
+	cmd.Flags().StringVar(&opts.TimeAfter, "time-after", "", "Only collect data after this date for new team projects (default: 6 months ago)")
+	cmd.Flags().StringVar(&opts.DeployPattern, "deployment-pattern", "(?i)deploy", "Regex to match deployment workflows")
+	cmd.Flags().StringVar(&opts.ProdPattern, "production-pattern", "(?i)prod", "Regex to match production environment")
+	cmd.Flags().StringVar(&opts.IncidentLabel, "incident-label", "incident", "Issue label for incidents")
+
+	return cmd
+}
+
+// parseCatalogColumns returns the column→plugin mapping after applying
+// column=plugin overrides to the defaults. Mapping a column to "" or "none"
+// disables it.
+func parseCatalogColumns(overrides []string) (map[string]string, error) {
+	cols := defaultCatalogColumns()
+	for _, o := range overrides {
+		name, plugin, ok := strings.Cut(o, "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		plugin = strings.TrimSpace(plugin)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid --column %q — expected column=plugin", o)
+		}
+		if plugin == "" || strings.EqualFold(plugin, "none") {
+			delete(cols, name)
+			continue
+		}
+		def := FindConnectionDef(plugin)
+		if def == nil || !def.Available || def.ImportScopeFunc == nil {
+			var slugs []string
+			for _, d := range importablePlugins() {
+				slugs = append(slugs, d.Plugin)
+			}
+			return nil, fmt.Errorf("--column %q: plugin %q cannot be imported (choose: %s)", o, plugin, strings.Join(slugs, ", "))
+		}
+		cols[name] = def.Plugin
+	}
+	return cols, nil
+}
+
+// parsePluginConnectionIDs parses plugin=id pairs into a map.
+func parsePluginConnectionIDs(pairs []string) (map[string]int, error) {
+	ids := make(map[string]int, len(pairs))
+	for _, p := range pairs {
+		plugin, idStr, ok := strings.Cut(p, "=")
+		id, err := strconv.Atoi(strings.TrimSpace(idStr))
+		if !ok || err != nil || id <= 0 {
+			return nil, fmt.Errorf("invalid --connection %q — expected plugin=id", p)
+		}
+		def, err := requirePlugin(strings.TrimSpace(plugin))
+		if err != nil {
+			return nil, err
+		}
+		ids[def.Plugin] = id
+	}
+	return ids, nil
+}
+
+// splitCatalogCell splits a cell on ";" and drops empty entries.
+func splitCatalogCell(v string) []string {
+	var out []string
+	for _, s := range strings.Split(v, ";") {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// catalogCellResult records the outcome of importing one value.
+type catalogCellResult struct {
+	Column  string `json:"column"`
+	Plugin  string `json:"plugin"`
+	Value   string `json:"value"`
+	ScopeID string `json:"scopeId,omitempty"`
+	Error   string `json:"error,omitempty"`
+
+	scope  devlake.BlueprintScope
+	connID int
+}
+
+// catalogRowResult records the outcome of importing one catalog row.
+type catalogRowResult struct {
+	Line  int                 `json:"line"`
+	Team  string              `json:"team,omitempty"`
+	OK    bool                `json:"ok"`
+	Cells []catalogCellResult `json:"cells"`
+}
+
+// scopeImportOutput is the JSON shape for `configure scope import --json`.
+type scopeImportOutput struct {
+	Rows     []catalogRowResult `json:"rows"`
+	Projects []string           `json:"projects,omitempty"`
+}
+
+// catalogImporter creates scopes one value at a time, caching per-connection
+// lookups (scope configs, remote-scope listings) across rows.
+type catalogImporter struct {
+	client        *devlake.Client
+	opts          *ScopeOpts
+	scopeConfigs  map[int]int
+	remoteByConn  map[string]map[string]*devlake.RemoteScopeChild
+	remoteListErr map[string]error
+}
+
+func newCatalogImporter(client *devlake.Client, opts *ScopeOpts) *catalogImporter {
+	return &catalogImporter{
+		client:        client,
+		opts:          opts,
+		scopeConfigs:  map[int]int{},
+		remoteByConn:  map[string]map[string]*devlake.RemoteScopeChild{},
+		remoteListErr: map[string]error{},
+	}
+}
+
+// importValue adds a single scope through the plugin's ImportScopeFunc and
+// returns its blueprint entry.
+func (im *catalogImporter) importValue(plugin string, connID int, value string) (devlake.BlueprintScope, error) {
+	def := FindConnectionDef(plugin)
+	if def == nil || def.ImportScopeFunc == nil {
+		return devlake.BlueprintScope{}, fmt.Errorf("plugin %q cannot be imported", plugin)
+	}
+	return def.ImportScopeFunc(im, connID, value)
+}
+
+// importGitHubScope is the ScopeImporter for the github plugin: value is an
+// owner/repo looked up with the gh CLI.
+func importGitHubScope(im *catalogImporter, connID int, value string) (devlake.BlueprintScope, error) {
+	if !gh.IsAvailable() {
+		return devlake.BlueprintScope{}, fmt.Errorf("gh CLI is required to look up repositories")
+	}
+	d, err := gh.GetRepoDetails(value)
+	if err != nil {
+		return devlake.BlueprintScope{}, fmt.Errorf("repo lookup failed: %w", err)
+	}
+	cfgID, ok := im.scopeConfigs[connID]
+	if !ok {
+		cfgID, err = ensureScopeConfig(im.client, "github", connID, im.opts)
+		if err != nil {
+			return devlake.BlueprintScope{}, fmt.Errorf("creating scope config: %w", err)
+		}
+		im.scopeConfigs[connID] = cfgID
+	}
+	if err := putGitHubScopes(im.client, connID, cfgID, []*gh.RepoDetails{d}); err != nil {
+		return devlake.BlueprintScope{}, err
+	}
+	return devlake.BlueprintScope{ScopeID: strconv.Itoa(d.ID), ScopeName: d.FullName}, nil
+}
+
+// importJenkinsScope is the ScopeImporter for the jenkins plugin: value is a
+// job full name.
+func importJenkinsScope(im *catalogImporter, connID int, value string) (devlake.BlueprintScope, error) {
+	data := []any{devlake.JenkinsJobScope{ConnectionID: connID, FullName: value, Name: value}}
+	if err := im.client.PutScopes("jenkins", connID, &devlake.ScopeBatchRequest{Data: data}); err != nil {
+		return devlake.BlueprintScope{}, err
+	}
+	return devlake.BlueprintScope{ScopeID: value, ScopeName: value}, nil
+}
+
+// importSonarQubeScope is the ScopeImporter for the sonarqube plugin: value
+// is a project key or name.
+func importSonarQubeScope(im *catalogImporter, connID int, value string) (devlake.BlueprintScope, error) {
+	child, err := im.findRemote("sonarqube", connID, value)
+	if err != nil {
+		return devlake.BlueprintScope{}, err
+	}
+	data := []any{devlake.SonarQubeProjectScope{ConnectionID: connID, ProjectKey: child.ID, Name: child.Name}}
+	if err := im.client.PutScopes("sonarqube", connID, &devlake.ScopeBatchRequest{Data: data}); err != nil {
+		return devlake.BlueprintScope{}, err
+	}
+	return devlake.BlueprintScope{ScopeID: child.ID, ScopeName: child.Name}, nil
+}
+
+// importPagerDutyScope is the ScopeImporter for the pagerduty plugin: value
+// is a service ID or name.
+func importPagerDutyScope(im *catalogImporter, connID int, value string) (devlake.BlueprintScope, error) {
+	child, err := im.findRemote("pagerduty", connID, value)
+	if err != nil {
+		return devlake.BlueprintScope{}, err
+	}
+	scope := pagerDutyServiceFromChild(child, connID)
+	if err := im.client.PutScopes("pagerduty", connID, &devlake.ScopeBatchRequest{Data: []any{scope}}); err != nil {
+		return devlake.BlueprintScope{}, err
+	}
+	return devlake.BlueprintScope{ScopeID: scope.ID, ScopeName: scope.Name}, nil
+}
+
+// findRemote looks up a remote scope by ID or name (case-insensitive),
+// listing the connection's remote scopes once.
+func (im *catalogImporter) findRemote(plugin string, connID int, value string) (*devlake.RemoteScopeChild, error) {
+	key := fmt.Sprintf("%s/%d", plugin, connID)
+	if err := im.remoteListErr[key]; err != nil {
+		return nil, err
+	}
+	index, ok := im.remoteByConn[key]
+	if !ok {
+		children, err := listAllRemoteScopeChildren(im.client, plugin, connID)
+		if err != nil {
+			err = fmt.Errorf("listing remote scopes: %w", err)
+			im.remoteListErr[key] = err
+			return nil, err
+		}
+		index = map[string]*devlake.RemoteScopeChild{}
+		for i := range children {
+			c := &children[i]
+			if c.Type != "scope" || c.ID == "" {
+				continue
+			}
+			index[strings.ToLower(c.ID)] = c
+			if c.Name != "" {
+				if _, taken := index[strings.ToLower(c.Name)]; !taken {
+					index[strings.ToLower(c.Name)] = c
+				}
+			}
+		}
+		im.remoteByConn[key] = index
+	}
+	child, ok := index[strings.ToLower(value)]
+	if !ok {
+		return nil, fmt.Errorf("%q not found on %s connection %d", value, plugin, connID)
+	}
+	return child, nil
+}
+
+// listAllRemoteScopeChildren aggregates every page of top-level remote scopes.
+func listAllRemoteScopeChildren(client *devlake.Client, plugin string, connID int) ([]devlake.RemoteScopeChild, error) {
+	var all []devlake.RemoteScopeChild
+	pageToken := ""
+	for {
+		resp, err := client.ListRemoteScopes(plugin, connID, "", pageToken)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, resp.Children...)
+		if resp.NextPageToken == "" {
+			return all, nil
+		}
+		pageToken = resp.NextPageToken
+	}
+}
+
+// teamBlueprintConnections groups successfully imported scopes by team and
+// plugin connection. Rows without a team are skipped.
+func teamBlueprintConnections(rows []catalogRowResult) map[string][]devlake.BlueprintConnection {
+	byTeam := map[string][]devlake.BlueprintConnection{}
+	for _, row := range rows {
+		if row.Team == "" {
+			continue
+		}
+		for _, cell := range row.Cells {
+			if cell.Error != "" {
+				continue
+			}
+			byTeam[row.Team] = mergeBlueprintScopes(byTeam[row.Team], cell.Plugin, cell.connID, []devlake.BlueprintScope{cell.scope}, nil)
+		}
+	}
+	return byTeam
+}
+
+func runScopeImport(cmd *cobra.Command, opts *scopeImportOpts) error {
+	if opts.File == "" {
+		return fmt.Errorf("--file is required")
+	}
+	columns, err := parseCatalogColumns(opts.Columns)
+	if err != nil {
+		return err
+	}
+	connFlags, err := parsePluginConnectionIDs(opts.Connections)
+	if err != nil {
+		return err
+	}
+	catalog, err := repofile.ParseCatalog(opts.File)
+	if err != nil {
+		return fmt.Errorf("failed to read catalog: %w", err)
+	}
+
+	// Only columns present in the header take part in the import.
+	var used []string
+	for col := range columns {
+		if catalog.HasColumn(col) {
+			used = append(used, col)
+		}
+	}
+	sort.Strings(used)
+	if len(used) == 0 {
+		return fmt.Errorf("catalog has no mapped columns (header: %s)", strings.Join(catalog.Columns, ", "))
+	}
+	if opts.ProjectPerTeam && !catalog.HasColumn(opts.TeamColumn) {
+		return fmt.Errorf("--project-per-team requires a %q column", opts.TeamColumn)
+	}
+
+	// In JSON mode, progress goes to stderr to keep stdout clean for JSON.
+	var prog io.Writer = os.Stdout
+	if outputJSON {
+		prog = os.Stderr
+	}
+	fmt.Fprintln(prog)
+	fmt.Fprintln(prog, "════════════════════════════════════════")
+	fmt.Fprintln(prog, "  DevLake — Import Scopes")
+	fmt.Fprintln(prog, "════════════════════════════════════════")
+	fmt.Fprintf(prog, "\n📄 Loaded %d row(s) from %s\n", len(catalog.Rows), opts.File)
+	for _, col := range used {
+		fmt.Fprintf(prog, "   %s → %s\n", col, columns[col])
+	}
+
+	disc, err := devlake.Discover(cfgURL)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(prog, "\n🔍 Backend API: %s (via %s)\n", disc.URL, disc.Source)
+	client := devlake.NewClient(disc.URL)
+	_, state := devlake.FindStateFile(disc.URL, disc.GrafanaURL)
+
+	fmt.Fprintln(prog, "\n🔗 Resolving connections...")
+	connIDs := map[string]int{}
+	connErrs := map[string]error{}
+	for _, col := range used {
+		plugin := columns[col]
+		if _, done := connIDs[plugin]; done || connErrs[plugin] != nil {
+			continue
+		}
+		id, err := resolveConnectionID(client, state, plugin, connFlags[plugin])
+		if err != nil {
+			connErrs[plugin] = err
+			fmt.Fprintf(prog, "   ⚠️  %s: %v\n", pluginDisplayName(plugin), err)
+			continue
+		}
+		connIDs[plugin] = id
+		fmt.Fprintf(prog, "   %s connection ID: %d\n", pluginDisplayName(plugin), id)
+	}
+
+	fmt.Fprintln(prog, "\n📝 Importing scopes...")
+	im := newCatalogImporter(client, &opts.ScopeOpts)
+	var rows []catalogRowResult
+	failed := 0
+	for _, row := range catalog.Rows {
+		res := catalogRowResult{Line: row.Line, Team: row.Get(opts.TeamColumn), OK: true, Cells: []catalogCellResult{}}
+		for _, col := range used {
+			plugin := columns[col]
+			for _, value := range splitCatalogCell(row.Get(col)) {
+				cell := catalogCellResult{Column: col, Plugin: plugin, Value: value}
+				if err := connErrs[plugin]; err != nil {
+					cell.Error = err.Error()
+				} else {
+					cell.connID = connIDs[plugin]
+					scope, err := im.importValue(plugin, cell.connID, value)
+					if err != nil {
+						cell.Error = err.Error()
+					} else {
+						cell.scope = scope
+						cell.ScopeID = scope.ScopeID
+					}
+				}
+				if cell.Error != "" {
+					res.OK = false
+				}
+				res.Cells = append(res.Cells, cell)
+			}
+		}
+		if !res.OK {
+			failed++
+		}
+		rows = append(rows, res)
+	}
+
+	var projects []string
+	if opts.ProjectPerTeam {
+		byTeam := teamBlueprintConnections(rows)
+		for team := range byTeam {
+			projects = append(projects, team)
+		}
+		sort.Strings(projects)
+		if len(projects) > 0 {
+			fmt.Fprintln(prog, "\n🏗️  Updating team projects...")
+		}
+		for _, team := range projects {
+			if err := upsertTeamProject(client, team, byTeam[team], &opts.ScopeOpts); err != nil {
+				fmt.Fprintf(prog, "   ⚠️  %s: %v\n", team, err)
+				failed++
+				continue
+			}
+			fmt.Fprintf(prog, "   ✅ %s (%d connection(s))\n", team, len(byTeam[team]))
+		}
+	}
+
+	if outputJSON {
+		if err := printJSON(scopeImportOutput{Rows: rows, Projects: projects}); err != nil {
+			return err
+		}
+	} else {
+		printCatalogReport(cmd.OutOrStdout(), rows)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d row(s) or project(s) failed to import", failed)
+	}
+	return nil
+}
+
+// upsertTeamProject creates the team's project if needed and merges the
+// imported scopes into its blueprint, preserving existing connections.
+func upsertTeamProject(client *devlake.Client, team string, conns []devlake.BlueprintConnection, opts *ScopeOpts) error {
+	var plugins []string
+	for _, c := range conns {
+		plugins = append(plugins, c.PluginName)
+	}
+	blueprintID, err := ensureProjectWithFlags(client, team, plugins)
+	if err != nil {
+		return err
+	}
+	project, err := client.GetProject(team)
+	if err != nil {
+		return fmt.Errorf("reading project: %w", err)
+	}
+	var merged []devlake.BlueprintConnection
+	patch := &devlake.BlueprintPatch{}
+	if project.Blueprint != nil {
+		merged = project.Blueprint.Connections
+		if project.Blueprint.CronConfig == "" {
+			patch.CronConfig = opts.Cron
+		}
+		if project.Blueprint.TimeAfter == "" {
+			patch.TimeAfter = opts.TimeAfter
+			if patch.TimeAfter == "" {
+				patch.TimeAfter = time.Now().AddDate(0, -6, 0).Format("2006-01-02T00:00:00Z")
+			}
+		}
+	}
+	for _, c := range conns {
+		merged = mergeBlueprintScopes(merged, c.PluginName, c.ConnectionID, c.Scopes, nil)
+	}
+	enable := true
+	patch.Enable = &enable
+	patch.Mode = "NORMAL"
+	patch.Connections = merged
+	if _, err := client.PatchBlueprint(blueprintID, patch); err != nil {
+		return fmt.Errorf("updating blueprint: %w", err)
+	}
+	return nil
+}
+
+// printCatalogReport prints one line per imported value and a summary.
+func printCatalogReport(out io.Writer, rows []catalogRowResult) {
+	fmt.Fprintln(out)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Line\tTeam\tPlugin\tValue\tResult")
+	fmt.Fprintln(w, "────\t────────────\t──────────\t──────────────────────────────\t──────────")
+	ok := 0
+	for _, row := range rows {
+		if row.OK {
+			ok++
+		}
+		if len(row.Cells) == 0 {
+			fmt.Fprintf(w, "%d\t%s\t\t\t⚠️  no mapped values\n", row.Line, row.Team)
+			continue
+		}
+		for _, c := range row.Cells {
+			result := "✅ scope " + c.ScopeID
+			if c.Error != "" {
+				result = "❌ " + c.Error
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", row.Line, row.Team, c.Plugin, c.Value, result)
+		}
+	}
+	w.Flush()
+
+	fmt.Fprintln(out, "\n"+strings.Repeat("─", 40))
+	fmt.Fprintf(out, "Rows: %d succeeded, %d failed\n", ok, len(rows)-ok)
+	fmt.Fprintln(out, strings.Repeat("─", 40))
+	fmt.Fprintln(out)
+}
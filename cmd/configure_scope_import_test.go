@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DevExpGBB/gh-devlake/internal/devlake"
+)
+
+func TestParseCatalogColumns(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		cols, err := parseCatalogColumns(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(cols, defaultCatalogColumns()) {
+			t.Errorf("cols = %v, want defaults", cols)
+		}
+	})
+
+	t.Run("override and disable", func(t *testing.T) {
+		cols, err := parseCatalogColumns([]string{"Build_Job=jenkins", "sonar_project=none", "repo="})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := map[string]string{
+			"jenkins_job":       "jenkins",
+			"pagerduty_service": "pagerduty",
+			"build_job":         "jenkins",
+		}
+		if !reflect.DeepEqual(cols, want) {
+			t.Errorf("cols = %v, want %v", cols, want)
+		}
+	})
+
+	for _, bad := range []string{"repo", "=github", "repo=jira", "repo=nope"} {
+		t.Run("invalid "+bad, func(t *testing.T) {
+			if _, err := parseCatalogColumns([]string{bad}); err == nil {
+				t.Errorf("expected error for %q", bad)
+			}
+		})
+	}
+}
+
+func TestParsePluginConnectionIDs(t *testing.T) {
+	ids, err := parsePluginConnectionIDs([]string{"github=1", " jenkins = 3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ids, map[string]int{"github": 1, "jenkins": 3}) {
+		t.Errorf("ids = %v", ids)
+	}
+	for _, bad := range []string{"github", "github=x", "github=0", "nope=1"} {
+		if _, err := parsePluginConnectionIDs([]string{bad}); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestSplitCatalogCell(t *testing.T) {
+	got := splitCatalogCell(" a ; ;b;")
+	if !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("got %v, want [a b]", got)
+	}
+	if got := splitCatalogCell(""); got != nil {
+		t.Errorf("empty cell = %v, want nil", got)
+	}
+}
+
+func TestTeamBlueprintConnections(t *testing.T) {
+	rows := []catalogRowResult{
+		{Team: "payments", Cells: []catalogCellResult{
+			{Plugin: "github", connID: 1, scope: devlake.BlueprintScope{ScopeID: "10", ScopeName: "org/api"}},
+			{Plugin: "jenkins", connID: 3, scope: devlake.BlueprintScope{ScopeID: "pay/build"}},
+			{Plugin: "sonarqube", connID: 4, Error: "not found"},
+		}},
+		{Team: "payments", Cells: []catalogCellResult{
+			{Plugin: "github", connID: 1, scope: devlake.BlueprintScope{ScopeID: "11", ScopeName: "org/worker"}},
+		}},
+		{Team: "", Cells: []catalogCellResult{
+			{Plugin: "github", connID: 1, scope: devlake.BlueprintScope{ScopeID: "12"}},
+		}},
+	}
+
+	got := teamBlueprintConnections(rows)
+	if len(got) != 1 {
+		t.Fatalf("teams = %d, want 1", len(got))
+	}
+	conns := got["payments"]
+	if len(conns) != 2 {
+		t.Fatalf("payments connections = %d, want 2", len(conns))
+	}
+	if conns[0].PluginName != "github" || len(conns[0].Scopes) != 2 {
+		t.Errorf("github connection = %+v", conns[0])
+	}
+	if conns[1].PluginName != "jenkins" || conns[1].ConnectionID != 3 {
+		t.Errorf("jenkins connection = %+v", conns[1])
+	}
+}
+
+func TestCatalogImporter_RemoteLookup(t *testing.T) {
+	var (
+		listCalls int
+		puts      []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/remote-scopes"):
+			listCalls++
+			if r.URL.Query().Get("pageToken") == "" {
+				fmt.Fprint(w, `{"children":[{"type":"scope","id":"api-key","name":"API"}],"nextPageToken":"p2"}`)
+				return
+			}
+			fmt.Fprint(w, `{"children":[{"type":"group","id":"g"},{"type":"scope","id":"web-key","name":"Web"}]}`)
+		case r.Method == http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			puts = append(puts, r.URL.Path+" "+string(body))
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprint(w, `[]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	im := newCatalogImporter(devlake.NewClient(srv.URL), &ScopeOpts{})
+
+	scope, err := im.importValue("sonarqube", 4, "WEB")
+	if err != nil {
+		t.Fatalf("by name: %v", err)
+	}
+	if scope.ScopeID != "web-key" || scope.ScopeName != "Web" {
+		t.Errorf("scope = %+v", scope)
+	}
+	if _, err := im.importValue("sonarqube", 4, "api-key"); err != nil {
+		t.Fatalf("by key: %v", err)
+	}
+	if _, err := im.importValue("sonarqube", 4, "missing"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("missing key err = %v", err)
+	}
+	if listCalls != 2 {
+		t.Errorf("remote-scope list calls = %d, want 2 (one listing, two pages)", listCalls)
+	}
+
+	if len(puts) != 2 {
+		t.Fatalf("PUT calls = %d, want 2", len(puts))
+	}
+	var req struct {
+		Data []devlake.SonarQubeProjectScope `json:"data"`
+	}
+	body := puts[0][strings.Index(puts[0], " ")+1:]
+	if err := json.Unmarshal([]byte(body), &req); err != nil {
+		t.Fatal(err)
+	}
+	if len(req.Data) != 1 || req.Data[0].ProjectKey != "web-key" || req.Data[0].ConnectionID != 4 {
+		t.Errorf("PUT data = %+v", req.Data)
+	}
+}
+
+func TestCatalogImporter_Jenkins(t *testing.T) {
+	var gotPath string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		fmt.Fprint(w, `[]`)
+	}))
+	defer srv.Close()
+
+	im := newCatalogImporter(devlake.NewClient(srv.URL), &ScopeOpts{})
+	scope, err := im.importValue("jenkins", 3, "team/build")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scope.ScopeID != "team/build" {
+		t.Errorf("ScopeID = %q", scope.ScopeID)
+	}
+	if gotPath != "/plugins/jenkins/connections/3/scopes" {
+		t.Errorf("path = %q", gotPath)
+	}
+}
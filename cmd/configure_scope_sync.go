@@ -0,0 +1,455 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/DevExpGBB/gh-devlake/internal/devlake"
+	"github.com/DevExpGBB/gh-devlake/internal/gh"
+)
+
+// scopeSyncOpts holds the flags for `configure scope sync`.
+type scopeSyncOpts struct {
+	ScopeOpts
+	Rule    string
+	Limit   int
+	Prune   bool
+	DryRun  bool
+	Project string
+}
+
+func newScopeSyncCmd() *cobra.Command {
+	var opts scopeSyncOpts
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Reconcile connection scopes against a repo selector rule",
+		Long: `Re-evaluates a repository selector rule and brings the connection's scopes
+in line with it. New repos that match the rule are added; with --prune, scopes
+that no longer match are removed. Pruning only looks at repos of the synced
+owners (--org, or the owners the rule names); other scopes are left alone.
+
+A rule is a comma-separated list of owner/repo glob patterns (matched
+case-insensitively). Prefix a pattern with ! to exclude matches:
+  my-org/*                    every repo in my-org
+  my-org/api-*,!my-org/*-old  api-* repos except those ending in -old
+
+When --project is set (or a project is recorded in the state file), the
+project's blueprint is updated so new scopes are collected on the next run.
+
+The command never prompts, so it is safe to run from cron or CI.
+
+Examples:
+  gh devlake configure scope sync --plugin github --connection-id 1 --rule "my-org/*"
+  gh devlake configure scope sync --plugin github --connection-id 1 --rule "my-org/svc-*,!my-org/svc-legacy" --prune
+  gh devlake configure scope sync --plugin github --connection-id 1 --rule "my-org/*" --project my-team --dry-run`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runScopeSync(cmd, &opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Plugin, "plugin", "github", fmt.Sprintf("Plugin to sync (%s)", strings.Join(syncPluginSlugs(), ", ")))
+	cmd.Flags().IntVar(&opts.ConnectionID, "connection-id", 0, "Connection ID (required)")
+	cmd.Flags().StringVar(&opts.Rule, "rule", "", "Repo selector: comma-separated owner/repo globs, ! to exclude (required)")
+	cmd.Flags().StringVar(&opts.Org, "org", "", "Organization to list repos from (default: owners named in --rule)")
+	cmd.Flags().IntVar(&opts.Limit, "limit", 1000, "Maximum repos to list per owner")
+	cmd.Flags().BoolVar(&opts.Prune, "prune", false, "Remove scopes that no longer match the rule")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Show what would change without applying it")
+	cmd.Flags().StringVar(&opts.Project, "project", "", "Project whose blueprint should follow the sync (default: project in state file)")
+	cmd.Flags().StringVar(&opts.DeployPattern, "deployment-pattern", "(?i)deploy", "Regex to match deployment workflows")
+	cmd.Flags().StringVar(&opts.ProdPattern, "production-pattern", "(?i)prod", "Regex to match production environment")
+	cmd.Flags().StringVar(&opts.IncidentLabel, "incident-label", "incident", "Issue label for incidents")
+
+	return cmd
+}
+
+// scopeRule is a parsed repo selector.
+type scopeRule struct {
+	Include []string
+	Exclude []string
+}
+
+// parseScopeRule parses a comma-separated list of owner/repo glob patterns.
+// Patterns prefixed with ! are exclusions. At least one include is required.
+func parseScopeRule(rule string) (*scopeRule, error) {
+	r := &scopeRule{}
+	for _, p := range strings.Split(rule, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		exclude := strings.HasPrefix(p, "!")
+		p = strings.ToLower(strings.TrimPrefix(p, "!"))
+		if !strings.Contains(p, "/") {
+			return nil, fmt.Errorf("invalid pattern %q — expected owner/repo", p)
+		}
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", p, err)
+		}
+		if exclude {
+			r.Exclude = append(r.Exclude, p)
+		} else {
+			r.Include = append(r.Include, p)
+		}
+	}
+	if len(r.Include) == 0 {
+		return nil, fmt.Errorf("rule must contain at least one include pattern")
+	}
+	return r, nil
+}
+
+// Matches reports whether fullName (owner/repo) is selected by the rule.
+func (r *scopeRule) Matches(fullName string) bool {
+	name := strings.ToLower(fullName)
+	included := false
+	for _, p := range r.Include {
+		if ok, _ := path.Match(p, name); ok {
+			included = true
+			break
+		}
+	}
+	if !included {
+		return false
+	}
+	for _, p := range r.Exclude {
+		if ok, _ := path.Match(p, name); ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Owners returns the distinct owners named by include patterns. It fails
+// when an owner segment is itself a glob, since owners cannot be enumerated.
+func (r *scopeRule) Owners() ([]string, error) {
+	seen := map[string]bool{}
+	var owners []string
+	for _, p := range r.Include {
+		owner := strings.SplitN(p, "/", 2)[0]
+		if strings.ContainsAny(owner, "*?[") {
+			return nil, fmt.Errorf("pattern %q has a wildcard owner — pass --org", p)
+		}
+		if !seen[owner] {
+			seen[owner] = true
+			owners = append(owners, owner)
+		}
+	}
+	return owners, nil
+}
+
+// existingScope is a scope already configured on the connection.
+type existingScope struct {
+	ID       string
+	FullName string
+}
+
+// diffScopes compares the existing scopes against the repos currently
+// available from owners. It returns the repos to add (matching, not yet
+// scoped) and the scopes to remove (scoped under one of owners, no longer
+// matching the rule). Scopes of other owners are outside the rule and are
+// never removed.
+func diffScopes(rule *scopeRule, owners []string, existing []existingScope, available []string) (toAdd []string, toRemove []existingScope) {
+	have := make(map[string]bool, len(existing))
+	for _, s := range existing {
+		have[strings.ToLower(s.FullName)] = true
+	}
+	for _, repo := range available {
+		if rule.Matches(repo) && !have[strings.ToLower(repo)] {
+			toAdd = append(toAdd, repo)
+			have[strings.ToLower(repo)] = true
+		}
+	}
+	synced := make(map[string]bool, len(owners))
+	for _, o := range owners {
+		synced[strings.ToLower(o)] = true
+	}
+	for _, s := range existing {
+		owner := strings.ToLower(strings.SplitN(s.FullName, "/", 2)[0])
+		if synced[owner] && !rule.Matches(s.FullName) {
+			toRemove = append(toRemove, s)
+		}
+	}
+	sort.Strings(toAdd)
+	return toAdd, toRemove
+}
+
+// mergeBlueprintScopes adds and removes scopes on the blueprint connection
+// matching plugin/connID, appending a new connection entry when none exists.
+func mergeBlueprintScopes(conns []devlake.BlueprintConnection, plugin string, connID int, add []devlake.BlueprintScope, removeIDs []string) []devlake.BlueprintConnection {
+	remove := make(map[string]bool, len(removeIDs))
+	for _, id := range removeIDs {
+		remove[id] = true
+	}
+	merged := make([]devlake.BlueprintConnection, 0, len(conns)+1)
+	found := false
+	for _, c := range conns {
+		if c.PluginName != plugin || c.ConnectionID != connID {
+			merged = append(merged, c)
+			continue
+		}
+		found = true
+		seen := map[string]bool{}
+		var scopes []devlake.BlueprintScope
+		for _, s := range c.Scopes {
+			if remove[s.ScopeID] || seen[s.ScopeID] {
+				continue
+			}
+			seen[s.ScopeID] = true
+			scopes = append(scopes, s)
+		}
+		for _, s := range add {
+			if !seen[s.ScopeID] {
+				seen[s.ScopeID] = true
+				scopes = append(scopes, s)
+			}
+		}
+		c.Scopes = scopes
+		merged = append(merged, c)
+	}
+	if !found && len(add) > 0 {
+		merged = append(merged, devlake.BlueprintConnection{
+			PluginName:   plugin,
+			ConnectionID: connID,
+			Scopes:       add,
+		})
+	}
+	return merged
+}
+
+// syncPluginSlugs returns the plugins whose scopes configure scope sync can
+// reconcile.
+func syncPluginSlugs() []string {
+	var slugs []string
+	for _, def := range AvailableConnections() {
+		if def.SupportsSync {
+			slugs = append(slugs, def.Plugin)
+		}
+	}
+	return slugs
+}
+
+// scopeSyncResult is the JSON summary of a sync run.
+type scopeSyncResult struct {
+	Plugin       string   `json:"plugin"`
+	ConnectionID int      `json:"connectionId"`
+	Rule         string   `json:"rule"`
+	DryRun       bool     `json:"dryRun"`
+	Matched      int      `json:"matched"`
+	Added        []string `json:"added"`
+	Removed      []string `json:"removed"`
+	Failed       []string `json:"failed,omitempty"`
+	Project      string   `json:"project,omitempty"`
+}
+
+func runScopeSync(cmd *cobra.Command, opts *scopeSyncOpts) error {
+	if opts.ConnectionID == 0 {
+		return fmt.Errorf("--connection-id is required")
+	}
+	if strings.TrimSpace(opts.Rule) == "" {
+		return fmt.Errorf("--rule is required")
+	}
+	def, err := requirePlugin(opts.Plugin)
+	if err != nil {
+		return err
+	}
+	if !def.SupportsSync {
+		return fmt.Errorf("scope sync is not supported for %s — choose: %s", def.DisplayName, strings.Join(syncPluginSlugs(), ", "))
+	}
+	rule, err := parseScopeRule(opts.Rule)
+	if err != nil {
+		return err
+	}
+	owners := []string{opts.Org}
+	if opts.Org == "" {
+		if owners, err = rule.Owners(); err != nil {
+			return err
+		}
+	}
+	if !gh.IsAvailable() {
+		return fmt.Errorf("gh CLI is required to list repositories")
+	}
+
+	// In JSON mode, progress goes to stderr to keep stdout clean for JSON.
+	var prog io.Writer = os.Stdout
+	if outputJSON {
+		prog = os.Stderr
+	}
+	fmt.Fprintln(prog)
+	fmt.Fprintln(prog, "════════════════════════════════════════")
+	fmt.Fprintln(prog, "  DevLake — Sync Scopes")
+	fmt.Fprintln(prog, "════════════════════════════════════════")
+
+	disc, err := devlake.Discover(cfgURL)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(prog, "\n🔍 Backend API: %s (via %s)\n", disc.URL, disc.Source)
+	client := devlake.NewClient(disc.URL)
+
+	projectName := opts.Project
+	if projectName == "" {
+		if _, state := devlake.FindStateFile(disc.URL, disc.GrafanaURL); state != nil && state.Project != nil {
+			projectName = state.Project.Name
+		}
+	}
+
+	fmt.Fprintf(prog, "\n📋 Listing scopes on %s connection ID=%d...\n", def.DisplayName, opts.ConnectionID)
+	scopes, err := client.ListAllScopes(def.Plugin, opts.ConnectionID)
+	if err != nil {
+		return fmt.Errorf("failed to list scopes: %w", err)
+	}
+	var existing []existingScope
+	for _, s := range scopes {
+		existing = append(existing, existingScope{
+			ID:       devlake.ExtractScopeID(s.RawScope, def.ScopeIDField),
+			FullName: s.ScopeName(),
+		})
+	}
+	fmt.Fprintf(prog, "   %d existing scope(s)\n", len(existing))
+
+	fmt.Fprintln(prog, "\n📦 Evaluating rule...")
+	var available []string
+	for _, owner := range owners {
+		repos, err := gh.ListRepos(owner, opts.Limit)
+		if err != nil {
+			return fmt.Errorf("failed to list repos in %q: %w", owner, err)
+		}
+		available = append(available, repos...)
+	}
+	matched := 0
+	for _, r := range available {
+		if rule.Matches(r) {
+			matched++
+		}
+	}
+	toAdd, toRemove := diffScopes(rule, owners, existing, available)
+	if !opts.Prune {
+		toRemove = nil
+	}
+	fmt.Fprintf(prog, "   %d repo(s) match, %d to add, %d to remove\n", matched, len(toAdd), len(toRemove))
+
+	result := scopeSyncResult{
+		Plugin:       def.Plugin,
+		ConnectionID: opts.ConnectionID,
+		Rule:         opts.Rule,
+		DryRun:       opts.DryRun,
+		Matched:      matched,
+		Added:        []string{},
+		Removed:      []string{},
+		Project:      projectName,
+	}
+
+	if opts.DryRun {
+		for _, r := range toAdd {
+			fmt.Fprintf(prog, "   + %s\n", r)
+		}
+		for _, s := range toRemove {
+			fmt.Fprintf(prog, "   - %s\n", s.FullName)
+		}
+		result.Added = append(result.Added, toAdd...)
+		for _, s := range toRemove {
+			result.Removed = append(result.Removed, s.FullName)
+		}
+		return finishScopeSync(prog, &result)
+	}
+
+	var added []devlake.BlueprintScope
+	if len(toAdd) > 0 {
+		fmt.Fprintln(prog, "\n🔎 Looking up repo details...")
+		var details []*gh.RepoDetails
+		for _, repo := range toAdd {
+			d, err := gh.GetRepoDetails(repo)
+			if err != nil {
+				fmt.Fprintf(prog, "   ⚠️  Could not fetch details for %q: %v\n", repo, err)
+				result.Failed = append(result.Failed, repo)
+				continue
+			}
+			details = append(details, d)
+		}
+		if len(details) > 0 {
+			scopeConfigID, err := ensureScopeConfig(client, def.Plugin, opts.ConnectionID, &opts.ScopeOpts)
+			if err != nil {
+				fmt.Fprintf(prog, "   ⚠️  Could not create scope config: %v\n", err)
+			}
+			fmt.Fprintln(prog, "\n📝 Adding repository scopes...")
+			if err := putGitHubScopes(client, opts.ConnectionID, scopeConfigID, details); err != nil {
+				return fmt.Errorf("failed to add repo scopes: %w", err)
+			}
+			for _, d := range details {
+				fmt.Fprintf(prog, "   + %s\n", d.FullName)
+				result.Added = append(result.Added, d.FullName)
+				added = append(added, devlake.BlueprintScope{ScopeID: strconv.Itoa(d.ID), ScopeName: d.FullName})
+			}
+		}
+	}
+
+	var removeIDs []string
+	for _, s := range toRemove {
+		removeIDs = append(removeIDs, s.ID)
+	}
+
+	// Update the blueprint before deleting scopes so DevLake does not reject
+	// the delete for scopes still referenced by a blueprint.
+	if projectName != "" && (len(added) > 0 || len(removeIDs) > 0) {
+		fmt.Fprintf(prog, "\n📋 Updating blueprint for project %q...\n", projectName)
+		project, err := client.GetProject(projectName)
+		if err != nil {
+			return fmt.Errorf("failed to get project %q: %w", projectName, err)
+		}
+		if project.Blueprint == nil {
+			return fmt.Errorf("project %q has no blueprint", projectName)
+		}
+		conns := mergeBlueprintScopes(project.Blueprint.Connections, def.Plugin, opts.ConnectionID, added, removeIDs)
+		if _, err := client.PatchBlueprint(project.Blueprint.ID, &devlake.BlueprintPatch{Connections: conns}); err != nil {
+			return fmt.Errorf("failed to update blueprint: %w", err)
+		}
+		fmt.Fprintf(prog, "   ✅ Blueprint %d updated\n", project.Blueprint.ID)
+	}
+
+	if len(toRemove) > 0 {
+		fmt.Fprintln(prog, "\n🗑️  Pruning scopes that no longer match...")
+		for _, s := range toRemove {
+			if err := client.DeleteScope(def.Plugin, opts.ConnectionID, s.ID); err != nil {
+				fmt.Fprintf(prog, "   ⚠️  Could not delete %s: %v\n", s.FullName, err)
+				result.Failed = append(result.Failed, s.FullName)
+				continue
+			}
+			fmt.Fprintf(prog, "   - %s\n", s.FullName)
+			result.Removed = append(result.Removed, s.FullName)
+		}
+	}
+
+	if err := finishScopeSync(prog, &result); err != nil {
+		return err
+	}
+	if len(result.Failed) > 0 {
+		return fmt.Errorf("%d scope change(s) failed", len(result.Failed))
+	}
+	return nil
+}
+
+// finishScopeSync prints the sync summary (or JSON result).
+func finishScopeSync(prog io.Writer, result *scopeSyncResult) error {
+	if outputJSON {
+		return printJSON(result)
+	}
+	verb := "Scopes synced"
+	if result.DryRun {
+		verb = "Dry run complete (no changes applied)"
+	}
+	fmt.Fprintln(prog, "\n"+strings.Repeat("─", 40))
+	fmt.Fprintf(prog, "✅ %s\n", verb)
+	fmt.Fprintf(prog, "   Matched: %d | Added: %d | Removed: %d\n", result.Matched, len(result.Added), len(result.Removed))
+	if len(result.Failed) > 0 {
+		fmt.Fprintf(prog, "   Failed:  %s\n", strings.Join(result.Failed, ", "))
+	}
+	fmt.Fprintln(prog, strings.Repeat("─", 40))
+	fmt.Fprintln(prog)
+	return nil
+}
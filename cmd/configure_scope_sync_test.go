@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DevExpGBB/gh-devlake/internal/devlake"
+)
+
+func TestParseScopeRule(t *testing.T) {
+	tests := []struct {
+		name        string
+		rule        string
+		wantInclude []string
+		wantExclude []string
+		wantErr     string
+	}{
+		{
+			name:        "single include",
+			rule:        "my-org/*",
+			wantInclude: []string{"my-org/*"},
+		},
+		{
+			name:        "include and exclude with spaces",
+			rule:        " My-Org/api-* , !my-org/*-old ",
+			wantInclude: []string{"my-org/api-*"},
+			wantExclude: []string{"my-org/*-old"},
+		},
+		{
+			name:    "exclude only",
+			rule:    "!my-org/legacy",
+			wantErr: "at least one include",
+		},
+		{
+			name:    "missing owner",
+			rule:    "api-*",
+			wantErr: "expected owner/repo",
+		},
+		{
+			name:    "bad glob",
+			rule:    "my-org/[abc",
+			wantErr: "invalid pattern",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := parseScopeRule(tt.rule)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(r.Include, tt.wantInclude) {
+				t.Errorf("Include = %v, want %v", r.Include, tt.wantInclude)
+			}
+			if !reflect.DeepEqual(r.Exclude, tt.wantExclude) {
+				t.Errorf("Exclude = %v, want %v", r.Exclude, tt.wantExclude)
+			}
+		})
+	}
+}
+
+func TestScopeRuleMatches(t *testing.T) {
+	r, err := parseScopeRule("my-org/svc-*,other/app,!my-org/svc-legacy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]bool{
+		"my-org/svc-api":    true,
+		"My-Org/SVC-Web":    true,
+		"my-org/svc-legacy": false,
+		"my-org/docs":       false,
+		"other/app":         true,
+		"other/app2":        false,
+	}
+	for repo, want := range tests {
+		if got := r.Matches(repo); got != want {
+			t.Errorf("Matches(%q) = %v, want %v", repo, got, want)
+		}
+	}
+}
+
+func TestScopeRuleOwners(t *testing.T) {
+	r, _ := parseScopeRule("a/*,b/x,a/y,!c/*")
+	owners, err := r.Owners()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(owners, []string{"a", "b"}) {
+		t.Errorf("Owners() = %v, want [a b]", owners)
+	}
+
+	r, _ = parseScopeRule("*/api")
+	if _, err := r.Owners(); err == nil || !strings.Contains(err.Error(), "--org") {
+		t.Errorf("expected wildcard owner error, got %v", err)
+	}
+}
+
+func TestDiffScopes(t *testing.T) {
+	r, _ := parseScopeRule("org/*,!org/old-*")
+	existing := []existingScope{
+		{ID: "1", FullName: "org/api"},
+		{ID: "2", FullName: "org/old-web"},
+		{ID: "3", FullName: "elsewhere/tool"},
+	}
+	available := []string{"org/api", "org/web", "org/old-cli", "org/cli", "org/web"}
+
+	toAdd, toRemove := diffScopes(r, []string{"Org"}, existing, available)
+
+	if !reflect.DeepEqual(toAdd, []string{"org/cli", "org/web"}) {
+		t.Errorf("toAdd = %v, want [org/cli org/web]", toAdd)
+	}
+	var removedIDs []string
+	for _, s := range toRemove {
+		removedIDs = append(removedIDs, s.ID)
+	}
+	// elsewhere/tool is outside the rule's owners, so it is left alone
+	if !reflect.DeepEqual(removedIDs, []string{"2"}) {
+		t.Errorf("toRemove IDs = %v, want [2]", removedIDs)
+	}
+}
+
+func TestMergeBlueprintScopes(t *testing.T) {
+	conns := []devlake.BlueprintConnection{
+		{PluginName: "gh-copilot", ConnectionID: 2, Scopes: []devlake.BlueprintScope{{ScopeID: "org"}}},
+		{PluginName: "github", ConnectionID: 1, Scopes: []devlake.BlueprintScope{
+			{ScopeID: "10", ScopeName: "org/api"},
+			{ScopeID: "11", ScopeName: "org/old"},
+		}},
+	}
+	add := []devlake.BlueprintScope{
+		{ScopeID: "10", ScopeName: "org/api"},
+		{ScopeID: "12", ScopeName: "org/web"},
+	}
+
+	t.Run("existing connection", func(t *testing.T) {
+		got := mergeBlueprintScopes(conns, "github", 1, add, []string{"11"})
+		if len(got) != 2 {
+			t.Fatalf("len = %d, want 2", len(got))
+		}
+		if !reflect.DeepEqual(got[0], conns[0]) {
+			t.Errorf("unrelated connection modified: %+v", got[0])
+		}
+		var ids []string
+		for _, s := range got[1].Scopes {
+			ids = append(ids, s.ScopeID)
+		}
+		if !reflect.DeepEqual(ids, []string{"10", "12"}) {
+			t.Errorf("scope IDs = %v, want [10 12]", ids)
+		}
+	})
+
+	t.Run("new connection appended", func(t *testing.T) {
+		got := mergeBlueprintScopes(conns, "github", 5, add, nil)
+		if len(got) != 3 {
+			t.Fatalf("len = %d, want 3", len(got))
+		}
+		if got[2].PluginName != "github" || got[2].ConnectionID != 5 || len(got[2].Scopes) != 2 {
+			t.Errorf("appended connection = %+v", got[2])
+		}
+	})
+}
+
+func TestRunScopeSyncRejectsUnsupportedPlugin(t *testing.T) {
+	opts := &scopeSyncOpts{Rule: "my-org/*"}
+	opts.Plugin = "jenkins"
+	opts.ConnectionID = 1
+	err := runScopeSync(nil, opts)
+	if err == nil || !strings.Contains(err.Error(), "not supported for Jenkins") {
+		t.Errorf("err = %v, want unsupported plugin error", err)
+	}
+	if got := syncPluginSlugs(); len(got) != 1 || got[0] != "github" {
+		t.Errorf("syncPluginSlugs() = %v, want [github]", got)
+	}
+}
@@ -42,10 +42,10 @@ func newConfigureScopesCmd() *cobra.Command {
 		Short:   "Manage scopes on DevLake connections",
 		Long: `Manage scopes (repos, orgs) on existing DevLake connections.
 
-Use subcommands to add, list, or delete scopes.`,
+Use subcommands to add, list, delete, sync, or import scopes.`,
 	}
 
-	cmd.AddCommand(newScopeAddCmd(), newScopeListCmd(), newScopeDeleteCmd())
+	cmd.AddCommand(newScopeAddCmd(), newScopeListCmd(), newScopeDeleteCmd(), newScopeSyncCmd(), newScopeImportCmd())
 
 	return cmd
 }
@@ -3,11 +3,13 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/DevExpGBB/gh-devlake/internal/devlake"
 	"github.com/DevExpGBB/gh-devlake/internal/envfile"
 	"github.com/DevExpGBB/gh-devlake/internal/prompt"
+	"github.com/DevExpGBB/gh-devlake/internal/token"
 )
 
 // ScopeHandler is a function that configures scopes for a connection.
@@ -17,6 +19,10 @@ import (
 // It returns the BlueprintConnection entry (for project creation) and an error.
 type ScopeHandler func(client *devlake.Client, connID int, org, enterprise string, opts *ScopeOpts) (*devlake.BlueprintConnection, error)
 
+// ScopeImporter creates one scope from a plain identifier taken from a
+// service catalog (see configure scope import) and returns its blueprint entry.
+type ScopeImporter func(im *catalogImporter, connID int, value string) (devlake.BlueprintScope, error)
+
 // FlagDef describes a plugin-specific flag for documentation and runtime validation.
 // When collected from the registry via collectAllScopeFlagDefs or collectAllConnectionFlagDefs,
 // the Plugins field is populated automatically to indicate which plugins use the flag.
@@ -48,6 +54,7 @@ type ConnectionDef struct {
 	ScopeFunc        ScopeHandler // nil = scope configuration not yet supported
 	ScopeIDField     string       // JSON field name for the scope ID (e.g. "githubId", "id")
 	HasRepoScopes    bool         // true = scopes carry a FullName that should be tracked as repos
+	SupportsSync     bool         // true = configure scope sync can reconcile repo scopes listed with gh
 
 	// Auth fields
 	AuthMethod          string   // "AccessToken" (default when empty), "BasicAuth", etc.
@@ -56,11 +63,26 @@ type ConnectionDef struct {
 	UsernameEnvVars     []string // environment variable names for username resolution
 	UsernameEnvFileKeys []string // .devlake.env keys for username resolution
 	NeedsTokenExpiry    bool     // true = apply zero-date token expiry workaround on create
+	SupportsAppAuth     bool     // true = GitHub App auth (--app-id etc.) is accepted
+
+	// TokenProbes returns GitHub API probes used to pre-flight a PAT before
+	// the connection is created. nil = no pre-flight check for this plugin.
+	TokenProbes func(org, enterprise string) []token.Probe
+	// GHAuthFallback lets token resolution fall back to 'gh auth token'.
+	GHAuthFallback bool
 
 	// ConnectionFlags declares plugin-specific flags for the connection add command.
 	ConnectionFlags []FlagDef
 	// ScopeFlags declares plugin-specific flags for the scope add command.
 	ScopeFlags []FlagDef
+
+	// ImportScopeFunc creates a scope from a catalog value for configure
+	// scope import. nil = the plugin cannot be imported.
+	ImportScopeFunc ScopeImporter
+	// CatalogColumn is the catalog column mapped to this plugin by default,
+	// and CatalogHint describes the value it holds (e.g. "owner/repo").
+	CatalogColumn string
+	CatalogHint   string
 }
 
 // MenuLabel returns the label for interactive menus.
@@ -71,6 +93,21 @@ func (d *ConnectionDef) MenuLabel() string {
 	return d.DisplayName
 }
 
+// tokenResolveOpts returns the token.Resolve lookup data for this plugin.
+// Stored keychain tokens are keyed by plugin slug.
+func (d *ConnectionDef) tokenResolveOpts(flagValue, envFilePath string) token.ResolveOpts {
+	return token.ResolveOpts{
+		FlagValue:       flagValue,
+		EnvFilePath:     envFilePath,
+		EnvFileKeys:     d.EnvFileKeys,
+		EnvVarNames:     d.EnvVarNames,
+		DisplayName:     d.DisplayName,
+		ScopeHint:       d.ScopeHint,
+		KeychainAccount: d.Plugin,
+		UseGHAuth:       d.GHAuthFallback,
+	}
+}
+
 // scopeHintSuffix returns a formatted scope hint string for appending to error messages,
 // or an empty string if no ScopeHint is set.
 func (d *ConnectionDef) scopeHintSuffix() string {
@@ -97,8 +134,24 @@ type ConnectionParams struct {
 	Name       string // override default connection name
 	Proxy      string // HTTP proxy URL
 	Endpoint   string // override default endpoint (e.g. GitHub Enterprise Server)
+
+	// GitHub App auth. When AppID is set the connection uses the "AppKey"
+	// auth method and Token is ignored.
+	AppID          string
+	InstallationID int
+	PrivateKey     string // PEM-encoded app private key
+
+	SkipTokenCheck bool // skip the PAT capability pre-flight check
 }
 
+// usesAppAuth reports whether params carry GitHub App credentials.
+func (p ConnectionParams) usesAppAuth() bool {
+	return p.AppID != ""
+}
+
+// appAuthMethod is DevLake's auth method name for GitHub App credentials.
+const appAuthMethod = "AppKey"
+
 // rateLimitOrDefault returns the configured rate limit or a sensible default.
 func (d *ConnectionDef) rateLimitOrDefault() int {
 	if d.RateLimitPerHour > 0 {
@@ -129,7 +182,12 @@ func (d *ConnectionDef) BuildCreateRequest(name string, params ConnectionParams)
 		RateLimitPerHour: d.rateLimitOrDefault(),
 		EnableGraphql:    d.EnableGraphql,
 	}
-	if d.NeedsUsername && params.Username != "" {
+	if d.SupportsAppAuth && params.usesAppAuth() {
+		req.AuthMethod = appAuthMethod
+		req.AppID = params.AppID
+		req.InstallationID = params.InstallationID
+		req.SecretKey = params.PrivateKey
+	} else if d.NeedsUsername && params.Username != "" {
 		// BasicAuth-style plugins (e.g., Jenkins, Bitbucket, Jira) expect credentials
 		// in username/password fields, not in the token field.
 		req.Username = params.Username
@@ -160,7 +218,12 @@ func (d *ConnectionDef) BuildTestRequest(name string, params ConnectionParams) *
 		Proxy:            params.Proxy,
 		EnableGraphql:    d.EnableGraphql,
 	}
-	if d.NeedsUsername && params.Username != "" {
+	if d.SupportsAppAuth && params.usesAppAuth() {
+		req.AuthMethod = appAuthMethod
+		req.AppID = params.AppID
+		req.InstallationID = params.InstallationID
+		req.SecretKey = params.PrivateKey
+	} else if d.NeedsUsername && params.Username != "" {
 		// BasicAuth-style plugins (e.g., Jenkins, Bitbucket, Jira) expect credentials
 		// in username/password fields, not in the token field.
 		req.Username = params.Username
@@ -177,6 +240,14 @@ func (d *ConnectionDef) BuildTestRequest(name string, params ConnectionParams) *
 	return req
 }
 
+// appAuthFlagDefs are the connection flags for GitHub App authentication,
+// shared by the GitHub-family plugins.
+var appAuthFlagDefs = []FlagDef{
+	{Name: "app-id", Description: "GitHub App ID (uses GitHub App auth instead of a PAT)"},
+	{Name: "installation-id", Description: "GitHub App installation ID"},
+	{Name: "private-key-file", Description: "Path to the GitHub App private key (PEM)"},
+}
+
 // connectionRegistry is the ordered list of all known plugin connection types.
 var connectionRegistry = []*ConnectionDef{
 	{
@@ -195,7 +266,12 @@ var connectionRegistry = []*ConnectionDef{
 		ScopeFunc:        scopeGitHubHandler,
 		ScopeIDField:     "githubId",
 		HasRepoScopes:    true,
+		SupportsSync:     true,
 		NeedsTokenExpiry: true,
+		SupportsAppAuth:  true,
+		TokenProbes:      githubTokenProbes,
+		GHAuthFallback:   true,
+		ConnectionFlags:  appAuthFlagDefs,
 		ScopeFlags: []FlagDef{
 			{Name: "repos", Description: "Comma-separated repos (owner/repo)"},
 			{Name: "repos-file", Description: "Path to file with repos (one per line)"},
@@ -203,6 +279,10 @@ var connectionRegistry = []*ConnectionDef{
 			{Name: "production-pattern", Description: "Regex to match production environment"},
 			{Name: "incident-label", Description: "Issue label for incidents"},
 		},
+
+		ImportScopeFunc: importGitHubScope,
+		CatalogColumn:   "repo",
+		CatalogHint:     "owner/repo",
 	},
 	{
 		Plugin:           "gh-copilot",
@@ -222,9 +302,12 @@ var connectionRegistry = []*ConnectionDef{
 		ScopeFunc:        scopeCopilotHandler,
 		ScopeIDField:     "id",
 		NeedsTokenExpiry: true,
-		ConnectionFlags: []FlagDef{
+		SupportsAppAuth:  true,
+		TokenProbes:      copilotTokenProbes,
+		GHAuthFallback:   true,
+		ConnectionFlags: append([]FlagDef{
 			{Name: "enterprise", Description: "Enterprise slug"},
-		},
+		}, appAuthFlagDefs...),
 		ScopeFlags: []FlagDef{
 			{Name: "enterprise", Description: "Enterprise slug (enables enterprise-level metrics)"},
 		},
@@ -250,6 +333,10 @@ var connectionRegistry = []*ConnectionDef{
 		ScopeFlags: []FlagDef{
 			{Name: "jobs", Description: "Comma-separated Jenkins job full names"},
 		},
+
+		ImportScopeFunc: importJenkinsScope,
+		CatalogColumn:   "jenkins_job",
+		CatalogHint:     "job full name",
 	},
 	{
 		Plugin:       "circleci",
@@ -358,6 +445,10 @@ var connectionRegistry = []*ConnectionDef{
 		EnvFileKeys:  []string{"PAGERDUTY_TOKEN", "PAGERDUTY_API_KEY"},
 		ScopeFunc:    scopePagerDutyHandler,
 		ScopeIDField: "id",
+
+		ImportScopeFunc: importPagerDutyScope,
+		CatalogColumn:   "pagerduty_service",
+		CatalogHint:     "service ID or name",
 	},
 	{
 		Plugin:           "sonarqube",
@@ -379,6 +470,10 @@ var connectionRegistry = []*ConnectionDef{
 		ScopeFlags: []FlagDef{
 			{Name: "projects", Description: "Comma-separated SonarQube project keys"},
 		},
+
+		ImportScopeFunc: importSonarQubeScope,
+		CatalogColumn:   "sonar_project",
+		CatalogHint:     "project key",
 	},
 	{
 		Plugin:           "argocd",
@@ -476,6 +571,12 @@ func buildAndCreateConnection(client *devlake.Client, def *ConnectionDef, params
 		connName = newName
 	}
 
+	if def.TokenProbes != nil && !params.usesAppAuth() && !params.SkipTokenCheck && params.Token != "" {
+		if !preflightToken(def, params, interactive) {
+			return nil, fmt.Errorf("aborted — update the token's scopes and try again")
+		}
+	}
+
 	if def.SupportsTest {
 		fmt.Println("   🔑 Testing connection...")
 		testReq := def.BuildTestRequest(connName, params)
@@ -558,6 +659,71 @@ func resolveUsername(def *ConnectionDef, flagValue string, envFilePath string) s
 	return prompt.ReadLine(label)
 }
 
+// appAuthCreds holds resolved GitHub App credentials.
+type appAuthCreds struct {
+	AppID          string
+	InstallationID int
+	PrivateKey     string
+	KeySource      string // "file", "envfile", or "environment"
+	EnvFilePath    string // non-empty if the key came from an env file (for cleanup)
+}
+
+// resolveAppAuth resolves GitHub App credentials from flags, falling back to
+// GITHUB_APP_* keys in the env file or environment. It returns nil (and no
+// error) when no app ID is configured, meaning PAT auth should be used.
+func resolveAppAuth(def *ConnectionDef, appIDFlag string, installationIDFlag int, keyFileFlag, envFilePath string) (*appAuthCreds, error) {
+	appID := appIDFlag
+	if appID == "" {
+		if installationIDFlag != 0 || keyFileFlag != "" {
+			return nil, fmt.Errorf("--app-id is required with --installation-id and --private-key-file")
+		}
+		if !def.SupportsAppAuth {
+			return nil, nil
+		}
+		appID = token.LookupAppValue(envFilePath, token.AppIDKey)
+		if appID == "" {
+			return nil, nil
+		}
+	}
+	if !def.SupportsAppAuth {
+		return nil, fmt.Errorf("%s does not support GitHub App authentication", def.DisplayName)
+	}
+
+	installationID := installationIDFlag
+	if installationID == 0 {
+		if v := token.LookupAppValue(envFilePath, token.AppInstallationIDKey); v != "" {
+			id, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s %q: %w", token.AppInstallationIDKey, v, err)
+			}
+			installationID = id
+		}
+	}
+	if installationID <= 0 {
+		return nil, fmt.Errorf("--installation-id is required for GitHub App auth (or set %s)", token.AppInstallationIDKey)
+	}
+
+	key, err := token.ResolveAppPrivateKey(token.AppKeyOpts{FilePath: keyFileFlag, EnvFilePath: envFilePath})
+	if err != nil {
+		return nil, err
+	}
+	return &appAuthCreds{
+		AppID:          appID,
+		InstallationID: installationID,
+		PrivateKey:     key.Token,
+		KeySource:      key.Source,
+		EnvFilePath:    key.EnvFilePath,
+	}, nil
+}
+
+// apply copies the app credentials into connection params.
+func (c *appAuthCreds) apply(params *ConnectionParams) {
+	params.AppID = c.AppID
+	params.InstallationID = c.InstallationID
+	params.PrivateKey = c.PrivateKey
+	params.Token = ""
+}
+
 // aggregateScopeHints merges scope hints from multiple connection defs into one string.
 func aggregateScopeHints(defs []*ConnectionDef) string {
 	seen := make(map[string]bool)
@@ -10,16 +10,21 @@ import (
 
 var deployCmd = &cobra.Command{
 	Use:   "deploy",
-	Short: "Deploy a DevLake instance (local Docker or Azure)",
-	Long: `Deploy an Apache DevLake stack to your local machine (Docker Compose)
-or to Azure (Container Apps + Azure Database for MySQL).
+	Short: "Deploy a DevLake instance (local Docker, Azure or Kubernetes)",
+	Long: `Deploy an Apache DevLake stack to your local machine (Docker Compose),
+to Azure (Container Instances or Container Apps, with Azure Database for
+MySQL) or to a Kubernetes cluster (kubectl or Helm values).
 
 Run without a subcommand for an interactive target prompt.`,
 	RunE: runDeploy,
 }
 
 func runDeploy(cmd *cobra.Command, args []string) error {
-	targets := []string{"local - Docker Compose on this machine", "azure - Azure Container Apps"}
+	targets := []string{
+		"local - Docker Compose on this machine",
+		"azure - Azure Container Instances or Container Apps",
+		"k8s   - Kubernetes cluster (kubectl)",
+	}
 	choice := prompt.Select("\nWhere would you like to deploy DevLake?", targets)
 	if choice == "" {
 		return fmt.Errorf("deployment target is required")
@@ -32,6 +37,8 @@ func runDeploy(cmd *cobra.Command, args []string) error {
 		return runDeployLocal(cmd, args)
 	case "azure":
 		return runDeployAzure(cmd, args)
+	case "k8s":
+		return runDeployK8s(cmd, args)
 	default:
 		return fmt.Errorf("unknown target: %s", target)
 	}
@@ -42,6 +49,7 @@ func init() {
 	rootCmd.AddCommand(deployCmd)
 	deployCmd.AddCommand(newDeployAzureCmd())
 	deployCmd.AddCommand(newDeployLocalCmd())
+	deployCmd.AddCommand(newDeployK8sCmd())
 
 	initCmd := newInitCmd()
 	initCmd.GroupID = "deploy"
@@ -58,4 +66,20 @@ func init() {
 	stopCmd := newStopCmd()
 	stopCmd.GroupID = "operate"
 	rootCmd.AddCommand(stopCmd)
+
+	upgradeCmd := newUpgradeCmd()
+	upgradeCmd.GroupID = "operate"
+	rootCmd.AddCommand(upgradeCmd)
+
+	backupCmd := newBackupCmd()
+	backupCmd.GroupID = "operate"
+	rootCmd.AddCommand(backupCmd)
+
+	restoreCmd := newRestoreCmd()
+	restoreCmd.GroupID = "operate"
+	rootCmd.AddCommand(restoreCmd)
+
+	migrateCmd := newMigrateCmd()
+	migrateCmd.GroupID = "operate"
+	rootCmd.AddCommand(migrateCmd)
 }
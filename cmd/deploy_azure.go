@@ -13,31 +13,72 @@ import (
 	dockerpkg "github.com/DevExpGBB/gh-devlake/internal/docker"
 	"github.com/DevExpGBB/gh-devlake/internal/gitclone"
 	"github.com/DevExpGBB/gh-devlake/internal/prompt"
-	"github.com/DevExpGBB/gh-devlake/internal/secrets"
+	"github.com/DevExpGBB/gh-devlake/internal/token"
 	"github.com/spf13/cobra"
 )
 
 var (
-	azureRG             string
-	azureLocation       string
-	azureBaseName       string
-	azureSkipImageBuild bool
-	azureRepoURL        string
-	azureOfficial       bool
-	deployAzureDir      string
-	deployAzureQuiet    bool // suppress "Next Steps" when called from init wizard
+	azureRG              string
+	azureLocation        string
+	azureBaseName        string
+	azureSkipImageBuild  bool
+	azureRepoURL         string
+	azureOfficial        bool
+	deployAzureDir       string
+	azureRuntime         string
+	azureWhatIf          bool
+	azureUpdate          bool
+	azureImageTag        string
+	azureMySQLSKU        string
+	azureMySQLStorageGB  int
+	azureBackendCPU      float64
+	azureBackendMemory   float64
+	azureTags            []string
+	azureParamsFile      string
+	azurePrivate         bool
+	azureCustomDomain    string
+	azureTLSCert         string
+	azureTLSCertPassword string
+	azureTLSCertSecretID string
+	azureAllowIPs        []string
 )
 
+// azurePrivateFlags only apply to --private deployments.
+var azurePrivateFlags = []string{"custom-domain", "tls-cert", "tls-cert-password", "tls-cert-secret-id", "allow-ip"}
+
 func newDeployAzureCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "azure",
-		Short: "Deploy DevLake to Azure Container Apps",
+		Short: "Deploy DevLake to Azure Container Instances or Container Apps",
 		Long: `Provisions DevLake on Azure using Container Instances, Azure Database for MySQL,
 and (optionally) Azure Container Registry.
 
+With --runtime aca, the containers run as Azure Container Apps instead: managed
+HTTPS ingress, revisions, and Config UI and Grafana scale to zero when idle.
+
+With --private, MySQL and the containers run inside a VNet with no public
+endpoint. An Application Gateway terminates TLS and is the only way in;
+--allow-ip restricts who can reach it. The certificate is either a PFX file
+(--tls-cert) or a Key Vault certificate (--tls-cert-secret-id), which the
+gateway reads with a managed identity and picks up again when it is renewed.
+
+With --update, an existing deployment (read from .devlake-azure.json) is
+redeployed in place: its MySQL password and ENCRYPTION_SECRET are read back
+from Key Vault, so only images and parameters change.
+
 Example:
   gh devlake deploy azure --resource-group devlake-rg --location eastus
-  gh devlake deploy azure --resource-group devlake-rg --location eastus --official`,
+  gh devlake deploy azure --resource-group devlake-rg --location eastus --official
+  gh devlake deploy azure --resource-group devlake-rg --location eastus --official --runtime aca
+  gh devlake deploy azure --resource-group devlake-rg --location eastus --official --what-if
+  gh devlake deploy azure --resource-group devlake-rg --location eastus --official \
+    --mysql-sku Standard_D2ds_v4 --mysql-storage-gb 128 --backend-cpu 4 --backend-memory 8 --tags team=platform
+  gh devlake deploy azure --resource-group devlake-rg --location eastus --official \
+    --private --custom-domain devlake.example.com --tls-cert devlake.pfx --allow-ip 203.0.113.0/24
+  gh devlake deploy azure --resource-group devlake-rg --location eastus --official \
+    --private --custom-domain devlake.example.com \
+    --tls-cert-secret-id https://corp-kv.vault.azure.net/secrets/devlake-example-com
+  gh devlake deploy azure --update --image-tag v1.0.2`,
 		RunE: runDeployAzure,
 	}
 
@@ -48,6 +89,22 @@ Example:
 	cmd.Flags().StringVar(&azureRepoURL, "repo-url", "", "Clone a remote DevLake repository for building")
 	cmd.Flags().BoolVar(&azureOfficial, "official", false, "Use official Apache images from Docker Hub (no ACR)")
 	cmd.Flags().StringVar(&deployAzureDir, "dir", ".", "Directory to save deployment state (.devlake-azure.json)")
+	cmd.Flags().BoolVar(&azureWhatIf, "what-if", false, "Preview the resource changes and estimated monthly cost without deploying")
+	cmd.Flags().BoolVar(&azureUpdate, "update", false, "Update the deployment in .devlake-azure.json in place, reusing its Key Vault secrets")
+	cmd.Flags().StringVar(&azureImageTag, "image-tag", "latest", "DevLake image tag (official images) or tag to push custom builds as")
+	cmd.Flags().StringVar(&azureMySQLSKU, "mysql-sku", azure.DefaultMySQLSKU, "MySQL Flexible Server SKU (e.g. Standard_B2ms, Standard_D2ds_v4)")
+	cmd.Flags().IntVar(&azureMySQLStorageGB, "mysql-storage-gb", azure.DefaultMySQLStorageGB, "MySQL storage size in GB")
+	cmd.Flags().Float64Var(&azureBackendCPU, "backend-cpu", 2, "vCPUs for the DevLake backend container")
+	cmd.Flags().Float64Var(&azureBackendMemory, "backend-memory", 4, "Memory in GB for the DevLake backend container")
+	cmd.Flags().StringSliceVar(&azureTags, "tags", nil, "Azure tags as key=value; repeatable")
+	cmd.Flags().StringVar(&azureParamsFile, "parameters-file", "", "JSON file with sizing and tags (same keys as \"sizing\" in .devlake-azure.json)")
+	cmd.Flags().StringVar(&azureRuntime, "runtime", azure.RuntimeACI, "Container runtime: aci (Container Instances) or aca (Container Apps)")
+	cmd.Flags().BoolVar(&azurePrivate, "private", false, "Deploy into a VNet behind an Application Gateway with TLS (no public MySQL or containers)")
+	cmd.Flags().StringVar(&azureCustomDomain, "custom-domain", "", "Host name to serve DevLake on, e.g. devlake.example.com (requires --private)")
+	cmd.Flags().StringVar(&azureTLSCert, "tls-cert", "", "PFX certificate for the HTTPS listener (requires --private)")
+	cmd.Flags().StringVar(&azureTLSCertPassword, "tls-cert-password", "", "Password of the --tls-cert PFX file (prompted if omitted; accepts env:, file:, cmd:, azkv: references)")
+	cmd.Flags().StringVar(&azureTLSCertSecretID, "tls-cert-secret-id", "", "Key Vault secret ID of the HTTPS certificate, instead of --tls-cert (requires --private)")
+	cmd.Flags().StringSliceVar(&azureAllowIPs, "allow-ip", nil, "IP or CIDR allowed to reach the gateway; repeatable (requires --private; default: any)")
 
 	return cmd
 }
@@ -59,9 +116,22 @@ var azureRegions = []string{
 	"southeastasia", "australiaeast", "uksouth",
 }
 
+// deployAzureOpts are set by the commands that deploy on the user's behalf
+// (init, migrate) rather than through flags.
+type deployAzureOpts struct {
+	Quiet bool // suppress the directory suggestion and "Next steps"
+	// EncryptionSecret reuses an existing ENCRYPTION_SECRET so connection
+	// tokens encrypted elsewhere stay readable; empty generates one.
+	EncryptionSecret string
+}
+
 func runDeployAzure(cmd *cobra.Command, args []string) error {
+	return deployAzure(cmd, deployAzureOpts{})
+}
+
+func deployAzure(cmd *cobra.Command, opts deployAzureOpts) error {
 	// Suggest a dedicated directory unless already in the right place or called from init
-	if !deployAzureQuiet {
+	if !opts.Quiet {
 		if suggestDedicatedDir("azure", "gh devlake deploy azure") {
 			return nil
 		}
@@ -73,37 +143,52 @@ func runDeployAzure(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to create directory %s: %w", deployAzureDir, err)
 	}
 
-	// ── Interactive image-source prompt (when no explicit flag set) ──
-	if !cmd.Flags().Changed("official") && !cmd.Flags().Changed("repo-url") {
-		imageChoices := []string{
-			"official - Apache DevLake images from Docker Hub (recommended)",
-			"fork    - Clone a DevLake repo and build from source",
-			"custom  - Use a local repo or pre-built images",
-		}
-		fmt.Println()
-		imgChoice := prompt.Select("Which DevLake images to use?", imageChoices)
-		if imgChoice == "" {
-			return fmt.Errorf("image choice is required")
+	// ── With --update, the previous deployment decides where to deploy ──
+	var prev *azurePrevDeployment
+	if azureUpdate {
+		var err error
+		if prev, err = applyAzureUpdateState(cmd); err != nil {
+			return err
 		}
-		switch {
-		case strings.HasPrefix(imgChoice, "official"):
-			azureOfficial = true
-		case strings.HasPrefix(imgChoice, "fork"):
-			azureOfficial = false
-			if azureRepoURL == "" {
-				azureRepoURL = prompt.ReadLine(fmt.Sprintf("Repository URL [%s]", gitclone.DefaultForkURL))
-				if azureRepoURL == "" {
-					azureRepoURL = gitclone.DefaultForkURL
-				}
+	}
+
+	// ── Validate sizing and private-network options before anything is created ──
+	sizing, err := resolveAzureSizing(cmd)
+	if err != nil {
+		return err
+	}
+	var privateParams map[string]string
+	if azurePrivate {
+		if azureTLSCert != "" {
+			azureTLSCertPassword, err = resolveTLSCertPassword(azureTLSCertPassword, cmd.Flags().Changed("tls-cert-password"))
+			if err != nil {
+				return err
 			}
-		default: // custom
-			azureOfficial = false
-			if azureRepoURL == "" {
-				azureRepoURL = prompt.ReadLine("Path or URL to DevLake repo (leave blank to auto-detect)")
+		}
+		privateParams, err = azure.PrivateOptions{
+			CustomDomain: azureCustomDomain,
+			CertFile:     azureTLSCert,
+			CertPassword: azureTLSCertPassword,
+			CertSecretID: azureTLSCertSecretID,
+			AllowedIPs:   azureAllowIPs,
+		}.Params()
+		if err != nil {
+			return err
+		}
+	} else {
+		for _, f := range azurePrivateFlags {
+			if cmd.Flags().Changed(f) {
+				return fmt.Errorf("--%s requires --private", f)
 			}
 		}
 	}
 
+	// ── Image source, then the template for it ──
+	templateName, err := chooseAzureTemplate(cmd)
+	if err != nil {
+		return err
+	}
+
 	// ── Interactive prompts for missing required flags ──
 	if azureLocation == "" {
 		azureLocation = prompt.SelectWithOther("Select Azure region", azureRegions, true)
@@ -120,13 +205,20 @@ func runDeployAzure(cmd *cobra.Command, args []string) error {
 
 	suffix := azure.Suffix(azureRG)
 	acrName := "devlakeacr" + suffix
+	resolveAzureImageTag(cmd, prev)
 
 	fmt.Println()
-	if azureOfficial {
+	switch {
+	case azureUpdate:
+		printBanner("DevLake Azure Update")
+		if azureOfficial {
+			azureSkipImageBuild = true
+		}
+	case azureOfficial:
 		printBanner("DevLake Azure Deployment (Official)")
 		fmt.Println("\nUsing official Apache DevLake images from Docker Hub")
 		azureSkipImageBuild = true
-	} else {
+	default:
 		printBanner("DevLake Azure Deployment")
 	}
 
@@ -139,6 +231,24 @@ func runDeployAzure(cmd *cobra.Command, args []string) error {
 	} else {
 		fmt.Println("  Images:         Official (Docker Hub)")
 	}
+	fmt.Printf("  Image Tag:      %s\n", azureImageTag)
+	if azureRuntime == azure.RuntimeACA {
+		fmt.Println("  Runtime:        Container Apps")
+	}
+	fmt.Printf("  MySQL:          %s, %d GB\n", sizing.MySQLSKU, sizing.MySQLStorageGB)
+	fmt.Printf("  Backend:        %g vCPU / %g GB\n", sizing.BackendCPU, sizing.BackendMemoryGB)
+	if len(sizing.Tags) > 0 {
+		fmt.Printf("  Tags:           %s\n", azure.FormatTags(sizing.Tags))
+	}
+	if azurePrivate {
+		fmt.Println("  Network:        Private (VNet + Application Gateway)")
+		if azureCustomDomain != "" {
+			fmt.Printf("  Custom Domain:  %s\n", azureCustomDomain)
+		}
+		if len(azureAllowIPs) > 0 {
+			fmt.Printf("  Allowed IPs:    %s\n", strings.Join(azureAllowIPs, ", "))
+		}
+	}
 
 	// ── Check Azure login ──
 	fmt.Println("\n🔑 Checking Azure CLI login...")
@@ -155,27 +265,51 @@ func runDeployAzure(cmd *cobra.Command, args []string) error {
 	}
 	fmt.Printf("   Logged in as: %s\n", acct.User.Name)
 
-	// ── Create Resource Group ──
-	fmt.Println("\n📦 Creating Resource Group...")
-	if err := azure.CreateResourceGroup(azureRG, azureLocation); err != nil {
-		return err
+	if azureWhatIf {
+		if azureTLSCertSecretID != "" {
+			privateParams["gatewayIdentityId"] = azure.ResourceID(acct.ID, azureRG,
+				"Microsoft.ManagedIdentity/userAssignedIdentities", azure.GatewayIdentityName(azureBaseName, suffix))
+		}
+		return runAzureWhatIf(templateName, suffix, sizing, privateParams, prev, opts.EncryptionSecret)
 	}
-	fmt.Println("   ✅ Resource Group created")
 
-	// ── Write early checkpoint — ensures cleanup works even if deployment fails ──
-	savePartialAzureState(azureRG, azureLocation)
+	if azureUpdate {
+		// ── Reuse the existing resource group and secrets ──
+		exists, err := azure.ResourceGroupExists(azureRG)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("resource group %s no longer exists — run deploy azure without --update to recreate it", azureRG)
+		}
+		fmt.Printf("\n🔐 Reading secrets from Key Vault %s...\n", prev.Resources.KeyVault)
+	} else {
+		// ── Create Resource Group ──
+		// Only a group this deploy creates is tagged; an existing one keeps its tags.
+		fmt.Println("\n📦 Creating Resource Group...")
+		var rgTags map[string]string
+		if exists, err := azure.ResourceGroupExists(azureRG); err == nil && !exists {
+			rgTags = azureResourceTags(suffix, sizing)
+		}
+		if err := azure.CreateResourceGroup(azureRG, azureLocation, rgTags); err != nil {
+			return err
+		}
+		fmt.Println("   ✅ Resource Group created")
+
+		// ── Write early checkpoint — ensures cleanup works even if deployment fails ──
+		savePartialAzureState(azureRG, azureLocation, suffix)
 
-	// ── Generate secrets ──
-	fmt.Println("\n🔐 Generating secrets...")
-	mysqlPwd, err := secrets.MySQLPassword()
-	if err != nil {
-		return err
+		fmt.Println("\n🔐 Generating secrets...")
 	}
-	encSecret, err := secrets.EncryptionSecret(32)
+	mysqlPwd, encSecret, err := azureDeploySecrets(prev, opts.EncryptionSecret)
 	if err != nil {
 		return err
 	}
-	fmt.Println("   ✅ Secrets generated")
+	if azureUpdate {
+		fmt.Println("   ✅ Existing secrets reused")
+	} else {
+		fmt.Println("   ✅ Secrets generated")
+	}
 
 	// ── Build and push images (if needed) ──
 	if !azureSkipImageBuild {
@@ -195,7 +329,7 @@ func runDeployAzure(cmd *cobra.Command, args []string) error {
 
 		// Create ACR (idempotent — safe for re-runs)
 		fmt.Println("   Creating Container Registry...")
-		if err := azure.CreateACR(acrName, azureRG, azureLocation); err != nil {
+		if err := azure.CreateACR(acrName, azureRG, azureLocation, azureResourceTags(suffix, sizing)); err != nil {
 			return fmt.Errorf("failed to create ACR: %w", err)
 		}
 		fmt.Println("   ✅ Container Registry ready")
@@ -226,7 +360,7 @@ func runDeployAzure(cmd *cobra.Command, args []string) error {
 				fmt.Fprintf(os.Stderr, "   official Apache DevLake images from Docker Hub instead.\n")
 				return fmt.Errorf("docker build failed for %s: %w", img.name, err)
 			}
-			remoteTag := acrServer + "/" + localTag
+			remoteTag := acrServer + "/" + img.name + ":" + azureImageTag
 			fmt.Printf("   Pushing %s...\n", img.name)
 			if err := dockerpkg.TagAndPush(localTag, remoteTag); err != nil {
 				return err
@@ -265,28 +399,32 @@ func runDeployAzure(cmd *cobra.Command, args []string) error {
 		fmt.Println("   ✅ Key Vault purged")
 	}
 
+	// ── Identity the gateway reads its Key Vault certificate with ──
+	var gatewayIdentity string
+	if azureTLSCertSecretID != "" {
+		vault, _, _ := azure.ParseKeyVaultSecretID(azureTLSCertSecretID) // validated by PrivateOptions.Params
+		gatewayIdentity = azure.GatewayIdentityName(azureBaseName, suffix)
+		fmt.Printf("\n🪪 Granting the gateway access to Key Vault %s...\n", vault)
+		id, principalID, err := azure.CreateGatewayIdentity(gatewayIdentity, azureRG, azureLocation, azureResourceTags(suffix, sizing))
+		if err != nil {
+			return err
+		}
+		if err := azure.GrantKeyVaultSecretRead(vault, principalID); err != nil {
+			return fmt.Errorf("could not let %s read the certificate in %s — grant it secret read access yourself and re-run: %w", gatewayIdentity, vault, err)
+		}
+		privateParams["gatewayIdentityId"] = id
+		fmt.Printf("   ✅ %s can read secrets in %s\n", gatewayIdentity, vault)
+	}
+
 	// ── Deploy infrastructure ──
 	fmt.Println("\n🚀 Deploying infrastructure with Bicep...")
-	templateName := "main.bicep"
-	if azureOfficial {
-		templateName = "main-official.bicep"
-	}
 	templatePath, cleanup, err := azure.WriteTemplate(templateName)
 	if err != nil {
 		return err
 	}
 	defer cleanup()
 
-	params := map[string]string{
-		"baseName":           azureBaseName,
-		"uniqueSuffix":       suffix,
-		"mysqlAdminPassword": mysqlPwd,
-		"encryptionSecret":   encSecret,
-	}
-	if !azureOfficial {
-		params["acrName"] = acrName
-	}
-
+	params := azureDeployParams(suffix, mysqlPwd, encSecret, sizing, privateParams)
 	deployment, err := azure.DeployBicep(azureRG, templatePath, params)
 	if err != nil {
 		return fmt.Errorf("Bicep deployment failed: %w", err)
@@ -299,23 +437,17 @@ func runDeployAzure(cmd *cobra.Command, args []string) error {
 	fmt.Printf("  Grafana:     %s\n", deployment.GrafanaEndpoint)
 
 	// ── Wait for backend and trigger migration ──
-	fmt.Println("\n⏳ Waiting for backend to start...")
-	backendReady := waitForReady(deployment.BackendEndpoint, 30, 10*time.Second) == nil
-
-	if backendReady {
-		fmt.Println("   ✅ Backend is responding!")
-		fmt.Println("\n🔄 Triggering database migration...")
-		httpClient := &http.Client{Timeout: 5 * time.Second}
-		resp, err := httpClient.Get(deployment.BackendEndpoint + "/proceed-db-migration")
-		if err == nil {
-			resp.Body.Close()
-			fmt.Println("   ✅ Migration triggered")
-		} else {
-			fmt.Printf("   ⚠️  Migration may need manual trigger: %v\n", err)
+	// A private deployment is only reachable through the gateway, which may
+	// not be in DNS yet or may not admit this machine.
+	if azurePrivate {
+		fmt.Printf("\n🌐 Application Gateway: %s (%s)\n", deployment.GatewayIP, deployment.GatewayFQDN)
+		if azureCustomDomain != "" {
+			fmt.Println("   Create this DNS record before opening DevLake:")
+			fmt.Printf("     %s  A  %s\n", azureCustomDomain, deployment.GatewayIP)
 		}
+		fmt.Println("   Open Config UI once to run the first database migration.")
 	} else {
-		fmt.Println("   Backend not ready after 30 attempts.")
-		fmt.Printf("   Trigger migration manually: GET %s/proceed-db-migration\n", deployment.BackendEndpoint)
+		triggerAzureMigration(deployment.BackendEndpoint)
 	}
 
 	// ── Save state file ──
@@ -341,6 +473,10 @@ func runDeployAzure(cmd *cobra.Command, args []string) error {
 		"region":            azureLocation,
 		"suffix":            suffix,
 		"useOfficialImages": azureOfficial,
+		"baseName":          azureBaseName,
+		"runtime":           azureRuntime,
+		"sizing":            sizing,
+		"images":            azureImageRefs(acrName),
 		"resources": map[string]any{
 			"acr":        conditionalACR(),
 			"keyVault":   kvName,
@@ -354,12 +490,39 @@ func runDeployAzure(cmd *cobra.Command, args []string) error {
 			"configUi": deployment.ConfigUIEndpoint,
 		},
 	}
+	if azureRuntime == azure.RuntimeACA {
+		combinedState["resources"].(map[string]any)["environment"] = deployment.EnvironmentName
+	}
+	if azurePrivate {
+		combinedState["private"] = true
+		combinedState["ingressIp"] = deployment.GatewayIP
+		if allowed, _ := azure.NormalizeAllowlist(azureAllowIPs); len(allowed) > 0 {
+			combinedState["allowedIps"] = allowed // already validated by PrivateOptions.Params
+		}
+		if azureCustomDomain != "" {
+			combinedState["customDomain"] = strings.ToLower(azureCustomDomain)
+		}
+		network := azure.PrivateResourceNames(azureBaseName, suffix)
+		network.GatewayIdentity = gatewayIdentity
+		combinedState["resources"].(map[string]any)["network"] = network
+		if azureTLSCertSecretID != "" {
+			combinedState["tlsCertSecretId"] = azureTLSCertSecretID
+		}
+	}
+
+	if prev != nil {
+		combinedState = mergeAzureUpdateState(prev, combinedState)
+		if azureTLSCertSecretID == "" {
+			delete(combinedState, "tlsCertSecretId") // switched to a PFX
+		}
+	}
 
 	data, _ := json.MarshalIndent(combinedState, "", "  ")
 	if err := os.WriteFile(stateFile, data, 0644); err != nil {
 		fmt.Fprintf(os.Stderr, "⚠️  Could not save state file: %v\n", err)
 	} else {
 		fmt.Printf("\n💾 State saved to %s\n", stateFile)
+		rememberAzureStateFile(suffix, stateFile)
 		if deployAzureDir != "." {
 			fmt.Println("   Next commands should be run from this directory:")
 			fmt.Println("   PowerShell:")
@@ -369,10 +532,14 @@ func runDeployAzure(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	if !deployAzureQuiet {
+	if !opts.Quiet {
 		fmt.Println("\nNext steps:")
 		fmt.Println("  1. Wait 2-3 minutes for containers to start")
-		fmt.Printf("  2. Open Config UI: %s\n", deployment.ConfigUIEndpoint)
+		if azurePrivate && azureCustomDomain != "" {
+			fmt.Printf("  2. Point %s at %s, then open Config UI: %s\n", azureCustomDomain, deployment.GatewayIP, deployment.ConfigUIEndpoint)
+		} else {
+			fmt.Printf("  2. Open Config UI: %s\n", deployment.ConfigUIEndpoint)
+		}
 		fmt.Println("  3. Configure your data sources")
 		fmt.Printf("\nTo cleanup: gh devlake cleanup --azure\n")
 	}
@@ -380,6 +547,192 @@ func runDeployAzure(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// chooseAzureTemplate asks which images to use when no flag decided it, then
+// picks the Bicep template. The template depends on the answer, so it must
+// never be chosen before the prompt.
+func chooseAzureTemplate(cmd *cobra.Command) (string, error) {
+	if !azureUpdate && !cmd.Flags().Changed("official") && !cmd.Flags().Changed("repo-url") {
+		imageChoices := []string{
+			"official - Apache DevLake images from Docker Hub (recommended)",
+			"fork    - Clone a DevLake repo and build from source",
+			"custom  - Use a local repo or pre-built images",
+		}
+		fmt.Println()
+		imgChoice := prompt.Select("Which DevLake images to use?", imageChoices)
+		if imgChoice == "" {
+			return "", fmt.Errorf("image choice is required")
+		}
+		switch {
+		case strings.HasPrefix(imgChoice, "official"):
+			azureOfficial = true
+		case strings.HasPrefix(imgChoice, "fork"):
+			azureOfficial = false
+			if azureRepoURL == "" {
+				azureRepoURL = prompt.ReadLine(fmt.Sprintf("Repository URL [%s]", gitclone.DefaultForkURL))
+				if azureRepoURL == "" {
+					azureRepoURL = gitclone.DefaultForkURL
+				}
+			}
+		default: // custom
+			azureOfficial = false
+			if azureRepoURL == "" {
+				azureRepoURL = prompt.ReadLine("Path or URL to DevLake repo (leave blank to auto-detect)")
+			}
+		}
+	}
+	return azure.TemplateName(azureRuntime, azureOfficial, azurePrivate)
+}
+
+// resolveAzureSizing layers the sizing: template defaults, then what an
+// existing .devlake-azure.json recorded (so a redeploy reproduces it), then
+// --parameters-file, then explicitly set flags.
+func resolveAzureSizing(cmd *cobra.Command) (azure.Sizing, error) {
+	sizing := azure.DefaultSizing()
+
+	if data, err := os.ReadFile(filepath.Join(deployAzureDir, ".devlake-azure.json")); err == nil {
+		var prev struct {
+			Sizing azure.Sizing `json:"sizing"`
+		}
+		if json.Unmarshal(data, &prev) == nil {
+			sizing = sizing.Merge(prev.Sizing)
+		}
+	}
+
+	if azureParamsFile != "" {
+		fromFile, err := azure.LoadSizingFile(azureParamsFile)
+		if err != nil {
+			return sizing, err
+		}
+		sizing = sizing.Merge(fromFile)
+	}
+
+	var flags azure.Sizing
+	if cmd.Flags().Changed("mysql-sku") {
+		flags.MySQLSKU = azureMySQLSKU
+	}
+	if cmd.Flags().Changed("mysql-storage-gb") {
+		flags.MySQLStorageGB = azureMySQLStorageGB
+	}
+	if cmd.Flags().Changed("backend-cpu") {
+		flags.BackendCPU = azureBackendCPU
+	}
+	if cmd.Flags().Changed("backend-memory") {
+		flags.BackendMemoryGB = azureBackendMemory
+	}
+	tags, err := azure.ParseTags(azureTags)
+	if err != nil {
+		return sizing, err
+	}
+	flags.Tags = tags
+	sizing = sizing.Merge(flags)
+
+	return sizing, sizing.Validate(azureRuntime)
+}
+
+// azureDeployParams returns the Bicep parameters for the chosen template.
+func azureDeployParams(suffix, mysqlPwd, encSecret string, sizing azure.Sizing, privateParams map[string]string) map[string]string {
+	sizing.Tags = azureResourceTags(suffix, sizing)
+	params := sizing.Params()
+	params["baseName"] = azureBaseName
+	params["uniqueSuffix"] = suffix
+	params["mysqlAdminPassword"] = mysqlPwd
+	params["encryptionSecret"] = encSecret
+	if azureOfficial {
+		params["imageTag"] = azureImageTag
+	} else {
+		params["acrName"] = "devlakeacr" + suffix
+		params["backendImage"] = "devlake-backend:" + azureImageTag
+		params["configUiImage"] = "devlake-config-ui:" + azureImageTag
+		params["grafanaImage"] = "devlake-grafana:" + azureImageTag
+	}
+	for k, v := range privateParams {
+		params[k] = v
+	}
+	return params
+}
+
+// runAzureWhatIf previews a deployment: the resource changes from the Bicep
+// what-if operation and an estimated monthly cost. Nothing is created.
+func runAzureWhatIf(templateName, suffix string, sizing azure.Sizing, privateParams map[string]string, prev *azurePrevDeployment, encryptionSecret string) error {
+	fmt.Println("\n🔍 Previewing changes (what-if)...")
+	exists, err := azure.ResourceGroupExists(azureRG)
+	if err != nil {
+		return err
+	}
+
+	var changes []azure.Change
+	if exists {
+		templatePath, cleanup, err := azure.WriteTemplate(templateName)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+
+		// Secure parameters must be set for what-if. An update previews with
+		// the Key Vault secrets; otherwise these throwaway values are never
+		// deployed and Key Vault secrets may show as modified.
+		mysqlPwd, encSecret, err := azureDeploySecrets(prev, encryptionSecret)
+		if err != nil {
+			return err
+		}
+		out, err := azure.WhatIf(azureRG, templatePath, azureDeployParams(suffix, mysqlPwd, encSecret, sizing, privateParams))
+		if err != nil {
+			return err
+		}
+		if changes, err = azure.ParseWhatIf(out); err != nil {
+			return err
+		}
+	} else {
+		fmt.Printf("   Resource group %q does not exist yet — it and everything in %s would be created.\n", azureRG, templateName)
+		if changes, err = azure.TemplateResources(templateName); err != nil {
+			return err
+		}
+	}
+	fmt.Println()
+	azure.RenderChanges(os.Stdout, changes)
+
+	lines, err := azure.EstimateCost(azure.CostInput{
+		Runtime:        azureRuntime,
+		MySQLSKU:       sizing.MySQLSKU,
+		MySQLStorageGB: sizing.MySQLStorageGB,
+		Containers:     sizing.Containers(azureRuntime),
+		ACR:            !azureOfficial,
+		Private:        azurePrivate,
+	})
+	if err != nil {
+		fmt.Printf("\n⚠️  No cost estimate: %v\n", err)
+	} else {
+		fmt.Println("\n💰 Estimated monthly cost (USD, pay-as-you-go list prices):")
+		azure.RenderCost(os.Stdout, lines)
+		fmt.Println("\n   Prices vary by region and exclude data transfer, Key Vault operations and")
+		fmt.Println("   Container Apps usage above the free grant.")
+	}
+
+	fmt.Println("\nNo changes were made. Re-run without --what-if to deploy.")
+	return nil
+}
+
+// triggerAzureMigration waits for the backend to answer, then asks it to run
+// the database migration.
+func triggerAzureMigration(backendURL string) {
+	fmt.Println("\n⏳ Waiting for backend to start...")
+	if waitForReady(backendURL, 30, 10*time.Second) != nil {
+		fmt.Println("   Backend not ready after 30 attempts.")
+		fmt.Printf("   Trigger migration manually: GET %s/proceed-db-migration\n", backendURL)
+		return
+	}
+	fmt.Println("   ✅ Backend is responding!")
+	fmt.Println("\n🔄 Triggering database migration...")
+	httpClient := &http.Client{Timeout: 5 * time.Second}
+	resp, err := httpClient.Get(backendURL + "/proceed-db-migration")
+	if err == nil {
+		resp.Body.Close()
+		fmt.Println("   ✅ Migration triggered")
+	} else {
+		fmt.Printf("   ⚠️  Migration may need manual trigger: %v\n", err)
+	}
+}
+
 func findRepoRoot() (string, error) {
 	if azureRepoURL != "" {
 		tmpDir, err := os.MkdirTemp("", "devlake-clone-*")
@@ -421,20 +774,51 @@ func conditionalACR() any {
 	return "devlakeacr" + azure.Suffix(azureRG)
 }
 
+// azureResourceTags returns the --tags of the deployment plus the instance
+// tag that lets cleanup find its resources.
+func azureResourceTags(suffix string, sizing azure.Sizing) map[string]string {
+	return azure.InstanceTags(sizing.Tags, suffix)
+}
+
+// resolveTLSCertPassword returns the password of the --tls-cert PFX. A flag
+// value may be a secret reference, so the password need not be typed on the
+// command line; without the flag it is prompted for.
+func resolveTLSCertPassword(flag string, set bool) (string, error) {
+	if !set {
+		return prompt.ReadSecret("PFX certificate password (blank if none)"), nil
+	}
+	pwd, err := token.ResolveSecretRef(flag)
+	if err != nil {
+		return "", fmt.Errorf("--tls-cert-password: %w", err)
+	}
+	return pwd, nil
+}
+
+// rememberAzureStateFile records where the state file of a deployment is,
+// so cleanup --list-orphans can match its tagged resources to it.
+func rememberAzureStateFile(suffix, stateFile string) {
+	if err := azure.RememberStateFile(suffix, stateFile); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Could not record the state file location: %v\n", err)
+	}
+}
+
 // savePartialAzureState writes a minimal state file immediately after the
 // Resource Group is created so that cleanup --azure always has a breadcrumb,
 // even when the deployment fails mid-flight (e.g. Docker build errors).
 // The full state write at the end of a successful deployment overwrites this.
-func savePartialAzureState(rg, region string) {
-	stateFile := ".devlake-azure.json"
+func savePartialAzureState(rg, region, suffix string) {
+	stateFile := filepath.Join(deployAzureDir, ".devlake-azure.json")
 	partial := map[string]any{
 		"deployedAt":    time.Now().Format(time.RFC3339),
 		"resourceGroup": rg,
 		"region":        region,
+		"suffix":        suffix,
 		"partial":       true,
 	}
 	data, _ := json.MarshalIndent(partial, "", "  ")
 	if err := os.WriteFile(stateFile, data, 0644); err != nil {
 		fmt.Fprintf(os.Stderr, "⚠️  Could not save early state checkpoint: %v\n", err)
+		return
 	}
+	rememberAzureStateFile(suffix, stateFile)
 }
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// withStdin feeds input to the interactive prompts for the rest of the test.
+func withStdin(t *testing.T, input string) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.WriteString(input); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	orig := os.Stdin
+	os.Stdin = r
+	t.Cleanup(func() {
+		os.Stdin = orig
+		r.Close()
+	})
+}
+
+func TestChooseAzureTemplateInteractive(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		runtime  string
+		want     string
+		official bool
+	}{
+		{"official", "1\n", "aci", "main-official.bicep", true},
+		{"fork", "2\n\n", "aci", "main.bicep", false},
+		{"custom", "3\n/src/devlake\n", "aci", "main.bicep", false},
+		{"official on container apps", "1\n", "aca", "main-aca.bicep", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetAzureDeployFlags(t)
+			origRepo, origUpdate := azureRepoURL, azureUpdate
+			t.Cleanup(func() { azureRepoURL, azureUpdate = origRepo, origUpdate })
+			azureUpdate, azureRepoURL = false, ""
+			cmd := newDeployAzureCmd()
+			if err := cmd.Flags().Set("runtime", tt.runtime); err != nil {
+				t.Fatal(err)
+			}
+			withStdin(t, tt.input)
+
+			got, err := chooseAzureTemplate(cmd)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want || azureOfficial != tt.official {
+				t.Errorf("template = %s, official = %v; want %s, %v", got, azureOfficial, tt.want, tt.official)
+			}
+		})
+	}
+}
+
+func TestChooseAzureTemplateSkipsPromptWithFlag(t *testing.T) {
+	resetAzureDeployFlags(t)
+	withStdin(t, "3\n") // would pick custom images if read
+	cmd := newDeployAzureCmd()
+	if err := cmd.Flags().Set("official", "true"); err != nil {
+		t.Fatal(err)
+	}
+	got, err := chooseAzureTemplate(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "main-official.bicep" {
+		t.Errorf("template = %s, want main-official.bicep", got)
+	}
+}
+
+func TestResolveTLSCertPassword(t *testing.T) {
+	t.Setenv("DEVLAKE_TEST_PFX_PASSWORD", "pfx-secret")
+
+	if got, err := resolveTLSCertPassword("env:DEVLAKE_TEST_PFX_PASSWORD", true); err != nil || got != "pfx-secret" {
+		t.Errorf("reference = %q, %v", got, err)
+	}
+	if got, err := resolveTLSCertPassword("plain", true); err != nil || got != "plain" {
+		t.Errorf("literal = %q, %v", got, err)
+	}
+	// A blank flag means a PFX without a password.
+	if got, err := resolveTLSCertPassword("", true); err != nil || got != "" {
+		t.Errorf("blank = %q, %v", got, err)
+	}
+	if _, err := resolveTLSCertPassword("env:DEVLAKE_TEST_UNSET", true); err == nil || !strings.Contains(err.Error(), "--tls-cert-password") {
+		t.Errorf("unresolvable reference err = %v", err)
+	}
+
+	withStdin(t, "typed\n")
+	if got, err := resolveTLSCertPassword("", false); err != nil || got != "typed" {
+		t.Errorf("prompted = %q, %v", got, err)
+	}
+}
@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/DevExpGBB/gh-devlake/internal/azure"
+	"github.com/DevExpGBB/gh-devlake/internal/secrets"
+	"github.com/spf13/cobra"
+)
+
+// azurePrevDeployment is the part of .devlake-azure.json that
+// deploy azure --update needs to redeploy into the same resources.
+type azurePrevDeployment struct {
+	ResourceGroup     string            `json:"resourceGroup"`
+	Region            string            `json:"region"`
+	Suffix            string            `json:"suffix"`
+	BaseName          string            `json:"baseName"`
+	UseOfficialImages bool              `json:"useOfficialImages"`
+	Runtime           string            `json:"runtime"`
+	Private           bool              `json:"private"`
+	CustomDomain      string            `json:"customDomain"`
+	TLSCertSecretID   string            `json:"tlsCertSecretId"`
+	AllowedIPs        []string          `json:"allowedIps"`
+	Images            map[string]string `json:"images"`
+	Partial           bool              `json:"partial"`
+	Resources         struct {
+		KeyVault string `json:"keyVault"`
+		MySQL    string `json:"mysql"`
+	} `json:"resources"`
+
+	raw map[string]any // the whole file, so fields written by other commands survive
+}
+
+// applyAzureUpdateState loads the deployment in deployAzureDir and points the
+// deploy flags at it. Flags that would move the deployment to other
+// resources are refused; flags that only change images or parameters win
+// over the recorded values.
+func applyAzureUpdateState(cmd *cobra.Command) (*azurePrevDeployment, error) {
+	stateFile := filepath.Join(deployAzureDir, ".devlake-azure.json")
+	data, err := os.ReadFile(stateFile)
+	if err != nil {
+		return nil, fmt.Errorf("--update needs an existing deployment, but %s could not be read — run deploy azure without --update first", stateFile)
+	}
+	prev := &azurePrevDeployment{}
+	if err := json.Unmarshal(data, prev); err != nil {
+		return nil, fmt.Errorf("invalid state file %s: %w", stateFile, err)
+	}
+	if err := json.Unmarshal(data, &prev.raw); err != nil {
+		return nil, fmt.Errorf("invalid state file %s: %w", stateFile, err)
+	}
+	if prev.Partial || prev.Resources.KeyVault == "" {
+		return nil, fmt.Errorf("the deployment in %s never completed — run deploy azure without --update to finish it", stateFile)
+	}
+
+	if prev.BaseName == "" {
+		// State written before baseName was recorded: <base>mysql<suffix>
+		prev.BaseName = strings.TrimSuffix(prev.Resources.MySQL, "mysql"+prev.Suffix)
+	}
+	if prev.Runtime == "" {
+		prev.Runtime = azure.RuntimeACI
+	}
+	runtime := azureRuntime
+	if runtime == "" {
+		runtime = azure.RuntimeACI
+	}
+
+	fixed := []struct {
+		flag, got, want string
+	}{
+		{"resource-group", azureRG, prev.ResourceGroup},
+		{"base-name", azureBaseName, prev.BaseName},
+		{"runtime", runtime, prev.Runtime},
+		{"private", fmt.Sprint(azurePrivate), fmt.Sprint(prev.Private)},
+	}
+	for _, f := range fixed {
+		if cmd.Flags().Changed(f.flag) && f.got != f.want {
+			return nil, fmt.Errorf("--update cannot change --%s (deployed with %s) — deploy a new instance instead", f.flag, f.want)
+		}
+	}
+
+	azureRG = prev.ResourceGroup
+	azureLocation = prev.Region
+	azureBaseName = prev.BaseName
+	azureRuntime = prev.Runtime
+	azurePrivate = prev.Private
+	if !cmd.Flags().Changed("official") && !cmd.Flags().Changed("repo-url") {
+		azureOfficial = prev.UseOfficialImages
+	}
+	if prev.Private {
+		if !cmd.Flags().Changed("custom-domain") {
+			azureCustomDomain = prev.CustomDomain
+		}
+		if !cmd.Flags().Changed("allow-ip") {
+			azureAllowIPs = prev.AllowedIPs
+		}
+		if azureTLSCert == "" && azureTLSCertSecretID == "" {
+			if prev.TLSCertSecretID == "" {
+				return nil, fmt.Errorf("--update of a private deployment needs --tls-cert again — the gateway certificate is not kept in the state file")
+			}
+			azureTLSCertSecretID = prev.TLSCertSecretID
+		}
+	}
+	return prev, nil
+}
+
+// resolveAzureImageTag picks the tag to deploy. Builds get a fresh tag so the
+// containers pick up the new images; otherwise an update keeps the tag that
+// is running unless --image-tag moves it.
+func resolveAzureImageTag(cmd *cobra.Command, prev *azurePrevDeployment) {
+	switch {
+	case cmd.Flags().Changed("image-tag"):
+	case !azureOfficial && !azureSkipImageBuild:
+		azureImageTag = time.Now().UTC().Format("20060102-150405")
+	case prev != nil && prev.UseOfficialImages == azureOfficial:
+		if tag := imageTag(prev.Images["backend"]); tag != "" {
+			azureImageTag = tag
+		}
+	}
+}
+
+// azureImageRefs returns the image each container runs.
+func azureImageRefs(acrName string) map[string]string {
+	if azureOfficial {
+		return map[string]string{
+			"backend":  "apache/devlake:" + azureImageTag,
+			"configUi": "apache/devlake-config-ui:" + azureImageTag,
+			"grafana":  "apache/devlake-dashboard:" + azureImageTag,
+		}
+	}
+	registry := acrName + ".azurecr.io/"
+	return map[string]string{
+		"backend":  registry + "devlake-backend:" + azureImageTag,
+		"configUi": registry + "devlake-config-ui:" + azureImageTag,
+		"grafana":  registry + "devlake-grafana:" + azureImageTag,
+	}
+}
+
+// azureDeploySecrets returns the MySQL password and ENCRYPTION_SECRET to
+// deploy with. A new deployment uses encryptionSecret when set. An update
+// reads both back from the deployment's Key Vault: new values would lock
+// DevLake out of its database and make the stored connection tokens
+// undecryptable.
+func azureDeploySecrets(prev *azurePrevDeployment, encryptionSecret string) (string, string, error) {
+	if prev == nil {
+		mysqlPwd, err := secrets.MySQLPassword()
+		if err != nil {
+			return "", "", err
+		}
+		encSecret := encryptionSecret
+		if encSecret == "" {
+			if encSecret, err = secrets.EncryptionSecret(32); err != nil {
+				return "", "", err
+			}
+		}
+		return mysqlPwd, encSecret, nil
+	}
+
+	kv := prev.Resources.KeyVault
+	mysqlPwd, err := azure.KeyVaultSecret(kv, "db-admin-password")
+	if err != nil {
+		return "", "", fmt.Errorf("could not read the MySQL password from Key Vault %s: %w", kv, err)
+	}
+	encSecret, err := azure.KeyVaultSecret(kv, "encryption-secret")
+	if err != nil {
+		return "", "", fmt.Errorf("could not read ENCRYPTION_SECRET from Key Vault %s: %w", kv, err)
+	}
+	if encryptionSecret != "" && encryptionSecret != encSecret {
+		return "", "", fmt.Errorf("the encryption secret given does not match the one in Key Vault %s", kv)
+	}
+	return mysqlPwd, encSecret, nil
+}
+
+// mergeAzureUpdateState overlays the new deployment fields onto the previous
+// state, keeping connections, project and the original deployedAt.
+func mergeAzureUpdateState(prev *azurePrevDeployment, next map[string]any) map[string]any {
+	merged := make(map[string]any, len(prev.raw)+len(next))
+	for k, v := range prev.raw {
+		merged[k] = v
+	}
+	for k, v := range next {
+		merged[k] = v
+	}
+	if at, ok := prev.raw["deployedAt"]; ok {
+		merged["deployedAt"] = at
+	}
+	merged["updatedAt"] = time.Now().Format(time.RFC3339)
+	delete(merged, "partial")
+	return merged
+}
@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+const updateTestState = `{
+  "deployedAt": "2026-01-05T10:00:00Z",
+  "method": "bicep",
+  "resourceGroup": "devlake-rg",
+  "region": "eastus",
+  "suffix": "abc12",
+  "useOfficialImages": true,
+  "resources": {"keyVault": "devlakekvabc12", "mysql": "teammysqlabc12"},
+  "images": {"backend": "apache/devlake:v1.0.1"},
+  "connections": [{"plugin": "github", "connectionId": 1, "name": "GitHub"}]
+}`
+
+// resetAzureDeployFlags restores the package-level deploy azure flags after a test.
+func resetAzureDeployFlags(t *testing.T) {
+	t.Helper()
+	origRG, origLoc, origBase, origRuntime := azureRG, azureLocation, azureBaseName, azureRuntime
+	origOfficial, origPrivate, origDir, origTag := azureOfficial, azurePrivate, deployAzureDir, azureImageTag
+	origSkip, origCert, origSecretID := azureSkipImageBuild, azureTLSCert, azureTLSCertSecretID
+	t.Cleanup(func() {
+		azureRG, azureLocation, azureBaseName, azureRuntime = origRG, origLoc, origBase, origRuntime
+		azureOfficial, azurePrivate, deployAzureDir, azureImageTag = origOfficial, origPrivate, origDir, origTag
+		azureSkipImageBuild, azureTLSCert, azureTLSCertSecretID = origSkip, origCert, origSecretID
+	})
+}
+
+// updateCmd returns a deploy azure command whose --dir holds the given state.
+func updateCmd(t *testing.T, state string) *cobra.Command {
+	t.Helper()
+	dir := t.TempDir()
+	if state != "" {
+		if err := os.WriteFile(filepath.Join(dir, ".devlake-azure.json"), []byte(state), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cmd := newDeployAzureCmd()
+	if err := cmd.Flags().Set("dir", dir); err != nil {
+		t.Fatal(err)
+	}
+	return cmd
+}
+
+func TestApplyAzureUpdateState(t *testing.T) {
+	resetAzureDeployFlags(t)
+	cmd := updateCmd(t, updateTestState)
+	prev, err := applyAzureUpdateState(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if azureRG != "devlake-rg" || azureLocation != "eastus" || !azureOfficial || azureRuntime != "aci" {
+		t.Errorf("flags = rg %q, location %q, official %v, runtime %q", azureRG, azureLocation, azureOfficial, azureRuntime)
+	}
+	// baseName is derived from the MySQL name when the state predates it
+	if azureBaseName != "team" || prev.BaseName != "team" {
+		t.Errorf("base name = %q, want team", azureBaseName)
+	}
+
+	resolveAzureImageTag(cmd, prev)
+	if azureImageTag != "v1.0.1" {
+		t.Errorf("image tag = %q, want the deployed v1.0.1", azureImageTag)
+	}
+	_ = cmd.Flags().Set("image-tag", "v1.0.2")
+	resolveAzureImageTag(cmd, prev)
+	if azureImageTag != "v1.0.2" {
+		t.Errorf("image tag = %q, want v1.0.2 from the flag", azureImageTag)
+	}
+}
+
+func TestApplyAzureUpdateStateRefusesMoves(t *testing.T) {
+	resetAzureDeployFlags(t)
+	cmd := updateCmd(t, updateTestState)
+	_ = cmd.Flags().Set("runtime", "aca")
+	if _, err := applyAzureUpdateState(cmd); err == nil || !strings.Contains(err.Error(), "cannot change --runtime") {
+		t.Errorf("runtime change error = %v", err)
+	}
+
+	cmd = updateCmd(t, updateTestState)
+	_ = cmd.Flags().Set("resource-group", "other-rg")
+	if _, err := applyAzureUpdateState(cmd); err == nil || !strings.Contains(err.Error(), "cannot change --resource-group") {
+		t.Errorf("resource group change error = %v", err)
+	}
+
+	cmd = updateCmd(t, `{"resourceGroup": "devlake-rg", "region": "eastus", "partial": true}`)
+	if _, err := applyAzureUpdateState(cmd); err == nil || !strings.Contains(err.Error(), "never completed") {
+		t.Errorf("partial state error = %v", err)
+	}
+
+	cmd = updateCmd(t, strings.Replace(updateTestState, `"useOfficialImages": true`, `"useOfficialImages": true, "private": true`, 1))
+	if _, err := applyAzureUpdateState(cmd); err == nil || !strings.Contains(err.Error(), "--tls-cert") {
+		t.Errorf("private without certificate error = %v", err)
+	}
+
+	if _, err := applyAzureUpdateState(updateCmd(t, "")); err == nil || !strings.Contains(err.Error(), "existing deployment") {
+		t.Errorf("missing state error = %v", err)
+	}
+}
+
+func TestApplyAzureUpdateStateReusesKeyVaultCert(t *testing.T) {
+	resetAzureDeployFlags(t)
+	const secretID = "https://corp-kv.vault.azure.net/secrets/devlake-tls"
+	state := strings.Replace(updateTestState, `"useOfficialImages": true`,
+		`"useOfficialImages": true, "private": true, "tlsCertSecretId": "`+secretID+`"`, 1)
+	if _, err := applyAzureUpdateState(updateCmd(t, state)); err != nil {
+		t.Fatal(err)
+	}
+	if azureTLSCertSecretID != secretID {
+		t.Errorf("tls-cert-secret-id = %q, want the recorded %s", azureTLSCertSecretID, secretID)
+	}
+}
+
+func TestResolveAzureImageTagForBuilds(t *testing.T) {
+	resetAzureDeployFlags(t)
+	azureOfficial, azureSkipImageBuild = false, false
+	azureImageTag = "latest"
+
+	resolveAzureImageTag(newDeployAzureCmd(), nil)
+	if azureImageTag == "latest" || len(azureImageTag) != len("20060102-150405") {
+		t.Errorf("build tag = %q, want a timestamp", azureImageTag)
+	}
+	refs := azureImageRefs("devlakeacrabc12")
+	if refs["grafana"] != "devlakeacrabc12.azurecr.io/devlake-grafana:"+azureImageTag {
+		t.Errorf("grafana image = %q", refs["grafana"])
+	}
+}
+
+func TestMergeAzureUpdateState(t *testing.T) {
+	resetAzureDeployFlags(t)
+	prev, err := applyAzureUpdateState(updateCmd(t, updateTestState))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	merged := mergeAzureUpdateState(prev, map[string]any{
+		"deployedAt": "2026-03-01T00:00:00Z",
+		"images":     map[string]string{"backend": "apache/devlake:v1.0.2"},
+	})
+	if merged["deployedAt"] != "2026-01-05T10:00:00Z" {
+		t.Errorf("deployedAt = %v, want the original", merged["deployedAt"])
+	}
+	if merged["updatedAt"] == nil {
+		t.Error("updatedAt not set")
+	}
+	if merged["connections"] == nil {
+		t.Error("connections were dropped")
+	}
+	if merged["images"].(map[string]string)["backend"] != "apache/devlake:v1.0.2" {
+		t.Errorf("images = %v", merged["images"])
+	}
+}
+
+func TestAzureDeploySecretsReusesEncryptionSecret(t *testing.T) {
+	pwd, secret, err := azureDeploySecrets(nil, "migrated-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret != "migrated-secret" || pwd == "" {
+		t.Errorf("secrets = %q, %q; want a new password and the given encryption secret", pwd, secret)
+	}
+	if _, secret, _ := azureDeploySecrets(nil, ""); secret == "" || secret == "migrated-secret" {
+		t.Errorf("generated secret = %q", secret)
+	}
+}
@@ -0,0 +1,436 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/DevExpGBB/gh-devlake/internal/devlake"
+	"github.com/DevExpGBB/gh-devlake/internal/download"
+	"github.com/DevExpGBB/gh-devlake/internal/k8s"
+	"github.com/DevExpGBB/gh-devlake/internal/secrets"
+	"github.com/spf13/cobra"
+)
+
+var (
+	deployK8sDir          string
+	deployK8sOutput       string // manifests directory (default <dir>/k8s)
+	deployK8sVersion      string
+	deployK8sNamespace    string
+	deployK8sName         string
+	deployK8sContext      string
+	deployK8sStorageClass string
+	deployK8sStorageSize  string
+	deployK8sServiceType  string
+	deployK8sCreateNS     bool
+	deployK8sRenderOnly   bool // write YAML without applying it
+	deployK8sHelmValues   bool // write values.yaml for the upstream chart instead of manifests
+)
+
+// k8sRolloutTimeout bounds each 'kubectl rollout status' wait. MySQL needs a
+// volume provisioned and the images are pulled on first deploy.
+const k8sRolloutTimeout = "10m"
+
+// k8sStateData holds the parsed Kubernetes state file.
+type k8sStateData struct {
+	DeployedAt       string                 `json:"deployedAt"`
+	Method           string                 `json:"method"`
+	Version          string                 `json:"version,omitempty"`
+	Context          string                 `json:"context,omitempty"`
+	Namespace        string                 `json:"namespace"`
+	Name             string                 `json:"name"`
+	ManifestsDir     string                 `json:"manifestsDir,omitempty"`
+	ServiceType      string                 `json:"serviceType,omitempty"`
+	CreatedNamespace bool                   `json:"createdNamespace,omitempty"`
+	Endpoints        devlake.StateEndpoints `json:"endpoints"`
+}
+
+// kubectl returns a runner pinned to the deployment's context and namespace.
+func (s *k8sStateData) kubectl() k8s.Kubectl {
+	return k8s.Kubectl{Context: s.Context, Namespace: s.Namespace}
+}
+
+// selector matches every object of the deployment, or only one component
+// when service is set.
+func (s *k8sStateData) selector(service string) string {
+	sel := k8s.Config{Name: s.Name}.Selector()
+	if service != "" {
+		sel += ",app.kubernetes.io/name=" + k8sComponent(service)
+	}
+	return sel
+}
+
+// k8sComponent maps a compose service name (as used by --service) to the
+// component label of the rendered manifests.
+func k8sComponent(service string) string {
+	if service == "devlake" {
+		return "backend"
+	}
+	return service
+}
+
+// k8sWorkloads lists the workloads of a deployment in start order.
+func k8sWorkloads(name string) [][2]string {
+	return [][2]string{
+		{"statefulset", name + "-mysql"},
+		{"deployment", name + "-backend"},
+		{"deployment", name + "-grafana"},
+		{"deployment", name + "-ui"},
+	}
+}
+
+func loadK8sState(path string) (*k8sStateData, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("state file not found: %s\nUse --state-file to specify the path", path)
+		}
+		return nil, fmt.Errorf("failed to read state file %s: %w", path, err)
+	}
+	var state k8sStateData
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("invalid state file: %w", err)
+	}
+	if state.Namespace == "" || state.Name == "" {
+		return nil, fmt.Errorf("state file %s has no namespace or name — cannot manage Kubernetes resources", path)
+	}
+	return &state, nil
+}
+
+// saveK8sState writes the Kubernetes state file, keeping fields it does not
+// model (connections, project) from an existing file.
+func saveK8sState(path string, state *k8sStateData) error {
+	existing := make(map[string]any)
+	if data, err := os.ReadFile(path); err == nil {
+		_ = json.Unmarshal(data, &existing)
+	}
+	stateBytes, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	var stateMap map[string]any
+	if err := json.Unmarshal(stateBytes, &stateMap); err != nil {
+		return err
+	}
+	for k, v := range stateMap {
+		existing[k] = v
+	}
+	data, err := json.MarshalIndent(existing, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+func newDeployK8sCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "k8s",
+		Short: "Deploy DevLake to a Kubernetes cluster",
+		Long: `Deploys Apache DevLake (MySQL, backend, Config UI and Grafana) to a
+Kubernetes cluster using the official images.
+
+Manifests are rendered into --output (default ./k8s) and applied with
+kubectl against the current context (or --context). The MySQL passwords and
+ENCRYPTION_SECRET are generated on first deploy and reused from the rendered
+Secret, or from the cluster, on later runs.
+
+  --render-only  write the manifests without applying them
+  --helm-values  write values.yaml for the upstream Apache DevLake Helm chart
+                 instead of manifests (nothing is applied)
+
+Services default to ClusterIP; reach them with 'kubectl port-forward' (the
+commands are printed). With --service-type LoadBalancer the external
+addresses are recorded in .devlake-k8s.json for the other commands.
+
+Example:
+  gh devlake deploy k8s
+  gh devlake deploy k8s --namespace devlake --version v1.0.2 --service-type LoadBalancer
+  gh devlake deploy k8s --render-only --output ./manifests
+  gh devlake deploy k8s --helm-values`,
+		RunE: runDeployK8s,
+	}
+
+	cmd.Flags().StringVar(&deployK8sDir, "dir", ".", "Directory to save deployment state (.devlake-k8s.json)")
+	cmd.Flags().StringVar(&deployK8sOutput, "output", "", "Directory for rendered YAML (default <dir>/k8s)")
+	cmd.Flags().StringVar(&deployK8sVersion, "version", "latest", "DevLake version to deploy (e.g. v1.0.2)")
+	cmd.Flags().StringVar(&deployK8sNamespace, "namespace", "devlake", "Kubernetes namespace")
+	cmd.Flags().StringVar(&deployK8sName, "name", "devlake", "Name prefix for the Kubernetes objects")
+	cmd.Flags().StringVar(&deployK8sContext, "context", "", "kubeconfig context (default: current context)")
+	cmd.Flags().StringVar(&deployK8sStorageClass, "storage-class", "", "StorageClass for the MySQL volume (default: cluster default)")
+	cmd.Flags().StringVar(&deployK8sStorageSize, "storage-size", "20Gi", "Size of the MySQL volume")
+	cmd.Flags().StringVar(&deployK8sServiceType, "service-type", "ClusterIP", "Service type for backend, Grafana and Config UI: ClusterIP, NodePort or LoadBalancer")
+	cmd.Flags().BoolVar(&deployK8sCreateNS, "create-namespace", true, "Render and apply the namespace")
+	cmd.Flags().BoolVar(&deployK8sRenderOnly, "render-only", false, "Write the YAML to --output without applying it")
+	cmd.Flags().BoolVar(&deployK8sHelmValues, "helm-values", false, "Write Helm values for the upstream chart instead of manifests")
+
+	return cmd
+}
+
+func runDeployK8s(cmd *cobra.Command, args []string) error {
+	printBanner("Apache DevLake — Kubernetes Setup")
+
+	apply := !deployK8sRenderOnly && !deployK8sHelmValues
+	if apply && !cmd.Flags().Changed("dir") {
+		if suggestDedicatedDir("k8s", "gh devlake deploy k8s") {
+			return nil
+		}
+	}
+
+	absDir, err := filepath.Abs(deployK8sDir)
+	if err != nil {
+		return err
+	}
+	outDir := deployK8sOutput
+	if outDir == "" {
+		outDir = filepath.Join(absDir, "k8s")
+	}
+	if outDir, err = filepath.Abs(outDir); err != nil {
+		return err
+	}
+	kc := k8s.Kubectl{Context: deployK8sContext, Namespace: deployK8sNamespace}
+
+	// ── Check kubectl ──
+	if apply {
+		fmt.Println("\n☸️  Checking kubectl...")
+		if err := kc.CheckAvailable(); err != nil {
+			fmt.Println("   Install kubectl: https://kubernetes.io/docs/tasks/tools/")
+			fmt.Println("   Or write the YAML only: gh devlake deploy k8s --render-only")
+			return err
+		}
+		kctx, _ := kc.CurrentContext()
+		fmt.Printf("   ✅ Connected (context %s)\n", kctx)
+		if kc.Context == "" {
+			kc.Context = kctx
+		}
+	}
+
+	version := deployK8sVersion
+	if version == "latest" {
+		fmt.Println("\n🔍 Fetching latest release version...")
+		tag, err := download.GitHubLatestTag("apache", "incubator-devlake")
+		if err != nil {
+			return fmt.Errorf("failed to fetch latest release: %w", err)
+		}
+		version = tag
+		fmt.Printf("   Latest version: %s\n", version)
+	}
+
+	cfg := k8s.Config{
+		Namespace:       deployK8sNamespace,
+		CreateNamespace: deployK8sCreateNS,
+		Name:            deployK8sName,
+		ImageTag:        version,
+		StorageClass:    deployK8sStorageClass,
+		StorageSize:     deployK8sStorageSize,
+		ServiceType:     deployK8sServiceType,
+	}.WithDefaults()
+
+	// ── Secrets ──
+	fmt.Println("\n🔐 Preparing secrets...")
+	if err := resolveK8sSecrets(&cfg, outDir, kc, apply); err != nil {
+		return err
+	}
+
+	if deployK8sHelmValues {
+		values, err := k8s.RenderHelmValues(cfg)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(outDir, 0755); err != nil {
+			return err
+		}
+		path := filepath.Join(outDir, "values.yaml")
+		if err := os.WriteFile(path, values, 0600); err != nil {
+			return err
+		}
+		printBanner("✅ Helm Values Written")
+		fmt.Printf("\n  %s (contains secrets — keep it private)\n", path)
+		fmt.Println("\nInstall the upstream chart:")
+		fmt.Println("  helm repo add devlake https://apache.github.io/incubator-devlake-helm-chart")
+		fmt.Printf("  helm install %s devlake/devlake -n %s --create-namespace -f %s\n", cfg.Name, cfg.Namespace, path)
+		return nil
+	}
+
+	manifests, err := k8s.Render(cfg)
+	if err != nil {
+		return err
+	}
+	if err := k8s.WriteManifests(outDir, manifests); err != nil {
+		return fmt.Errorf("failed to write manifests: %w", err)
+	}
+	fmt.Printf("\n📝 Rendered %d manifests into %s\n", len(manifests), outDir)
+	for _, m := range manifests {
+		fmt.Printf("   • %s\n", m.Name)
+	}
+
+	if deployK8sRenderOnly {
+		printBanner("✅ Manifests Written")
+		fmt.Printf("\n  %s contains the DevLake secrets — keep it out of version control.\n", k8s.SecretsFile)
+		fmt.Println("\nApply them with:")
+		fmt.Printf("  kubectl apply -f %s\n", outDir)
+		return nil
+	}
+
+	// ── Apply ──
+	fmt.Println("\n☸️  Applying manifests...")
+	if err := kc.Apply(outDir); err != nil {
+		return err
+	}
+	fmt.Println("   ✅ Applied")
+
+	fmt.Println("\n⏳ Waiting for rollouts (first start pulls images and provisions the MySQL volume)...")
+	for _, w := range k8sWorkloads(cfg.Name) {
+		if err := kc.RolloutStatus(w[0], w[1], k8sRolloutTimeout); err != nil {
+			fmt.Printf("   ⚠️  %s/%s not ready: %v\n", w[0], w[1], err)
+			fmt.Printf("   Inspect with: kubectl -n %s describe %s %s\n", cfg.Namespace, w[0], w[1])
+			continue
+		}
+		fmt.Printf("   ✅ %s ready\n", w[1])
+	}
+
+	endpoints := k8sEndpoints(kc, cfg)
+
+	// ── Migration (only when the backend is reachable from here) ──
+	if cfg.ServiceType == "LoadBalancer" && endpoints.Backend != "" {
+		fmt.Println("\n🔄 Triggering database migration...")
+		if err := waitForReady(endpoints.Backend, 30, 10*time.Second); err != nil {
+			fmt.Printf("   ⚠️  Backend not reachable at %s yet\n", endpoints.Backend)
+			fmt.Printf("   Trigger migration manually: GET %s/proceed-db-migration\n", endpoints.Backend)
+		} else if err := devlake.NewClient(endpoints.Backend).TriggerMigration(); err != nil {
+			fmt.Printf("   ⚠️  Migration may need manual trigger: %v\n", err)
+		} else {
+			fmt.Println("   ✅ Migration triggered")
+			if err := waitForMigration(endpoints.Backend, 60, 5*time.Second); err != nil {
+				fmt.Printf("   ⚠️  %v\n", err)
+			}
+		}
+	}
+
+	// ── Save state file ──
+	stateFile := filepath.Join(absDir, ".devlake-k8s.json")
+	state := &k8sStateData{
+		DeployedAt:       time.Now().Format(time.RFC3339),
+		Method:           "k8s",
+		Version:          version,
+		Context:          kc.Context,
+		Namespace:        cfg.Namespace,
+		Name:             cfg.Name,
+		ManifestsDir:     outDir,
+		ServiceType:      cfg.ServiceType,
+		CreatedNamespace: cfg.CreateNamespace,
+		Endpoints:        endpoints,
+	}
+	if err := saveK8sState(stateFile, state); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Could not save state file: %v\n", err)
+	} else {
+		fmt.Printf("\n💾 State saved to %s\n", stateFile)
+	}
+
+	printBanner("✅ DevLake is deployed!")
+	fmt.Printf("\n  Namespace:   %s\n", cfg.Namespace)
+	if cfg.ServiceType != "LoadBalancer" {
+		fmt.Println("\nReach the services with port-forwards (one terminal each):")
+		fmt.Printf("  kubectl -n %s port-forward svc/%s-backend 8080:8080\n", cfg.Namespace, cfg.Name)
+		fmt.Printf("  kubectl -n %s port-forward svc/%s-ui 4000:4000\n", cfg.Namespace, cfg.Name)
+		fmt.Printf("  kubectl -n %s port-forward svc/%s-grafana 3002:3000\n", cfg.Namespace, cfg.Name)
+		fmt.Println("\nThen open the Config UI to run the database migration.")
+	}
+	fmt.Printf("\n  Backend API: %s\n", endpoints.Backend)
+	fmt.Printf("  Config UI:   %s\n", endpoints.ConfigUI)
+	fmt.Printf("  Grafana:     %s (admin/admin)\n", endpoints.Grafana)
+	fmt.Println("\nTo remove DevLake:")
+	fmt.Printf("  cd \"%s\" && gh devlake cleanup --k8s\n", absDir)
+	return nil
+}
+
+// resolveK8sSecrets fills the MySQL passwords and ENCRYPTION_SECRET, reusing
+// a Secret rendered earlier into outDir or, when applying, the one already
+// in the cluster. Changing ENCRYPTION_SECRET would make stored connection
+// tokens unreadable, so new values are only generated when none exist.
+func resolveK8sSecrets(cfg *k8s.Config, outDir string, kc k8s.Kubectl, checkCluster bool) error {
+	existing, err := k8s.ReadSecrets(outDir)
+	if err != nil {
+		return fmt.Errorf("failed to read rendered secrets: %w", err)
+	}
+	source := "rendered " + k8s.SecretsFile
+	if existing["ENCRYPTION_SECRET"] == "" && checkCluster {
+		secretName := cfg.Name + "-secrets"
+		for _, key := range []string{"ENCRYPTION_SECRET", "MYSQL_PASSWORD", "MYSQL_ROOT_PASSWORD"} {
+			v, err := kc.SecretValue(secretName, key)
+			if err != nil {
+				return fmt.Errorf("failed to read secret %s from the cluster: %w", secretName, err)
+			}
+			existing[key] = v
+		}
+		source = "cluster secret " + secretName
+	}
+
+	cfg.EncryptionSecret = existing["ENCRYPTION_SECRET"]
+	cfg.MySQLPassword = existing["MYSQL_PASSWORD"]
+	cfg.MySQLRootPassword = existing["MYSQL_ROOT_PASSWORD"]
+	if cfg.EncryptionSecret != "" && cfg.MySQLPassword != "" && cfg.MySQLRootPassword != "" {
+		fmt.Printf("   ✅ Reusing secrets from %s\n", source)
+		return nil
+	}
+
+	if cfg.EncryptionSecret == "" {
+		if cfg.EncryptionSecret, err = secrets.EncryptionSecret(128); err != nil {
+			return fmt.Errorf("failed to generate secret: %w", err)
+		}
+	}
+	if cfg.MySQLPassword == "" {
+		if cfg.MySQLPassword, err = secrets.MySQLPassword(); err != nil {
+			return fmt.Errorf("failed to generate MySQL password: %w", err)
+		}
+	}
+	if cfg.MySQLRootPassword == "" {
+		if cfg.MySQLRootPassword, err = secrets.MySQLPassword(); err != nil {
+			return fmt.Errorf("failed to generate MySQL password: %w", err)
+		}
+	}
+	fmt.Println("   ✅ ENCRYPTION_SECRET and MySQL passwords generated")
+	return nil
+}
+
+// k8sEndpoints returns the service URLs to record: external addresses for
+// LoadBalancer services, otherwise the local ends of the suggested
+// port-forwards.
+func k8sEndpoints(kc k8s.Kubectl, cfg k8s.Config) devlake.StateEndpoints {
+	ep := devlake.StateEndpoints{
+		Backend:  "http://localhost:8080",
+		Grafana:  "http://localhost:3002",
+		ConfigUI: "http://localhost:4000",
+	}
+	if cfg.ServiceType != "LoadBalancer" {
+		return ep
+	}
+
+	fmt.Println("\n🌐 Waiting for load balancer addresses...")
+	services := []struct {
+		name string
+		port int
+		url  *string
+	}{
+		{cfg.Name + "-backend", 8080, &ep.Backend},
+		{cfg.Name + "-grafana", 3000, &ep.Grafana},
+		{cfg.Name + "-ui", 4000, &ep.ConfigUI},
+	}
+	for _, svc := range services {
+		addr := ""
+		for attempt := 0; attempt < 30 && addr == ""; attempt++ {
+			if attempt > 0 {
+				time.Sleep(10 * time.Second)
+			}
+			addr, _ = kc.ServiceAddress(svc.name)
+		}
+		if addr == "" {
+			fmt.Printf("   ⚠️  %s has no external address yet — using a port-forward URL\n", svc.name)
+			continue
+		}
+		*svc.url = fmt.Sprintf("http://%s:%d", addr, svc.port)
+		fmt.Printf("   ✅ %s → %s\n", svc.name, *svc.url)
+	}
+	return ep
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DevExpGBB/gh-devlake/internal/k8s"
+)
+
+func TestResolveK8sSecrets_ReusesRendered(t *testing.T) {
+	dir := t.TempDir()
+	rendered := k8s.Config{
+		ImageTag:          "v1.0.2",
+		MySQLPassword:     "mysqlpw",
+		MySQLRootPassword: "rootpw",
+		EncryptionSecret:  "KEEPME",
+	}.WithDefaults()
+	ms, err := k8s.Render(rendered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := k8s.WriteManifests(dir, ms); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := k8s.Config{ImageTag: "v1.0.3"}.WithDefaults()
+	if err := resolveK8sSecrets(&cfg, dir, k8s.Kubectl{}, false); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.EncryptionSecret != "KEEPME" || cfg.MySQLPassword != "mysqlpw" || cfg.MySQLRootPassword != "rootpw" {
+		t.Errorf("secrets not reused: %+v", cfg)
+	}
+}
+
+func TestResolveK8sSecrets_Generates(t *testing.T) {
+	cfg := k8s.Config{ImageTag: "v1.0.2"}.WithDefaults()
+	if err := resolveK8sSecrets(&cfg, t.TempDir(), k8s.Kubectl{}, false); err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.EncryptionSecret) != 128 || cfg.MySQLPassword == "" || cfg.MySQLRootPassword == "" {
+		t.Errorf("secrets not generated: %+v", cfg)
+	}
+	if cfg.MySQLPassword == cfg.MySQLRootPassword {
+		t.Error("MySQL user and root passwords should differ")
+	}
+}
+
+func TestK8sStateRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".devlake-k8s.json")
+	// Connections written by configure must survive a redeploy.
+	if err := os.WriteFile(path, []byte(`{"connections":[{"plugin":"github","connectionId":1,"name":"gh"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveK8sState(path, &k8sStateData{Method: "k8s", Namespace: "metrics", Name: "lake"}); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), `"plugin": "github"`) {
+		t.Errorf("connections dropped:\n%s", data)
+	}
+
+	state, err := loadK8sState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "app.kubernetes.io/part-of=devlake,app.kubernetes.io/instance=lake,app.kubernetes.io/name=backend"
+	if got := state.selector("devlake"); got != want {
+		t.Errorf("selector = %q, want %q", got, want)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"method":"k8s"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadK8sState(path); err == nil {
+		t.Error("expected error for state without namespace")
+	}
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -8,13 +9,16 @@ import (
 	"strings"
 	"time"
 
+	"github.com/DevExpGBB/gh-devlake/internal/bundle"
 	"github.com/DevExpGBB/gh-devlake/internal/devlake"
 	dockerpkg "github.com/DevExpGBB/gh-devlake/internal/docker"
 	"github.com/DevExpGBB/gh-devlake/internal/download"
 	"github.com/DevExpGBB/gh-devlake/internal/gitclone"
+	"github.com/DevExpGBB/gh-devlake/internal/instance"
 	"github.com/DevExpGBB/gh-devlake/internal/prompt"
 	"github.com/DevExpGBB/gh-devlake/internal/secrets"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 const (
@@ -26,12 +30,18 @@ var (
 	deployLocalVersion string
 	deployLocalRepoURL string // fork/clone URL for "fork" source mode
 	deployLocalStart   bool   // start containers after setup
-	deployLocalQuiet   bool   // suppress summary when called from init wizard
 	// deployLocalSource is set by flag or interactive prompt:
 	//   "official" — download Apache release (default)
 	//   "fork"     — clone a repo and build from source
 	//   "custom"   — user provides their own docker-compose.yml
+	//   "bundle"   — offline bundle given with --bundle
 	deployLocalSource string
+	// deployLocalOverride holds port, memory and network settings written to
+	// docker-compose.override.yml.
+	deployLocalOverride dockerpkg.Override
+	deployLocalInstance string // named instance: own directory, project name and port block
+	deployLocalBundle   string // offline bundle from 'gh devlake bundle create'
+	deployLocalInsecure bool   // skip checksum verification of downloads
 )
 
 func newDeployLocalCmd() *cobra.Command {
@@ -45,9 +55,34 @@ Image source (interactive prompt or flags):
   fork      Clone a DevLake repo and build images from source
   custom    Use your own docker-compose.yml already in the target directory
 
+Official release files are checked against the SHA-256 checksums pinned in
+this CLI or published with the release before they are used. A file with
+no checksum is used with a warning; --insecure-skip-verify turns the check
+off.
+
+With --bundle, everything comes from a bundle made by 'gh devlake bundle
+create' instead: its checksums are verified, its images are loaded with
+docker load, and nothing is downloaded.
+
+Ports, resources and networking (written to docker-compose.override.yml):
+  --backend-port, --grafana-port, --ui-port, --mysql-port  publish on other host ports
+  --bind-address  publish only on this host address, e.g. 127.0.0.1 (default: all interfaces, as upstream)
+  --memory-limit  cap the devlake container's memory (e.g. 4g)
+  --network       join an existing Docker network instead of the project default
+The chosen ports are recorded in .devlake-local.json so other commands find them.
+
+Named instances (--instance NAME) run side by side: each gets its own
+directory (default ./devlake-NAME), compose project devlake-NAME (so its own
+containers and volumes), a free block of host ports (8090/3012/4010/3316 for
+the first, +10 for each next) and state file. Target one from anywhere with
+'gh devlake start|stop|status|cleanup --instance NAME'.
+
 Example:
   gh devlake deploy local
   gh devlake deploy local --version v1.0.2 --dir ./devlake
+  gh devlake deploy local --backend-port 18080 --grafana-port 13002 --ui-port 14000
+  gh devlake deploy local --instance staging --version v1.0.3
+  gh devlake deploy local --bundle devlake-bundle-v1.0.2.tar
   gh devlake deploy local --source fork --repo-url https://github.com/DevExpGBB/incubator-devlake`,
 		RunE: runDeployLocal,
 	}
@@ -57,20 +92,59 @@ Example:
 	cmd.Flags().StringVar(&deployLocalSource, "source", "", "Image source: official, fork, or custom")
 	cmd.Flags().StringVar(&deployLocalRepoURL, "repo-url", "", "Repository URL to clone (for fork source)")
 	cmd.Flags().BoolVar(&deployLocalStart, "start", true, "Start containers after setup")
+	cmd.Flags().IntVar(&deployLocalOverride.BackendPort, "backend-port", 0, "Host port for the DevLake API (default 8080)")
+	cmd.Flags().IntVar(&deployLocalOverride.GrafanaPort, "grafana-port", 0, "Host port for Grafana (default 3002)")
+	cmd.Flags().IntVar(&deployLocalOverride.UIPort, "ui-port", 0, "Host port for Config UI (default 4000)")
+	cmd.Flags().IntVar(&deployLocalOverride.MySQLPort, "mysql-port", 0, "Host port for MySQL (default 3306)")
+	cmd.Flags().StringVar(&deployLocalOverride.BindAddress, "bind-address", "", "Host address to publish the ports on, e.g. 127.0.0.1 (default: all interfaces)")
+	cmd.Flags().StringVar(&deployLocalOverride.MemoryLimit, "memory-limit", "", "Memory limit for the devlake container (e.g. 4g)")
+	cmd.Flags().StringVar(&deployLocalOverride.Network, "network", "", "Existing Docker network to attach the services to")
+	cmd.Flags().StringVar(&deployLocalInstance, "instance", "", "Deploy a named instance alongside others (own project, ports and state)")
+	cmd.Flags().StringVar(&deployLocalBundle, "bundle", "", "Deploy offline from a bundle made by 'gh devlake bundle create'")
+	cmd.Flags().BoolVar(&deployLocalInsecure, "insecure-skip-verify", false, "Do not verify downloaded release files against their checksums")
 
 	return cmd
 }
 
+// deployLocalOpts are set by the commands that deploy on the user's behalf
+// (init, migrate) rather than through flags.
+type deployLocalOpts struct {
+	Quiet bool // suppress the directory suggestion and the summary
+}
+
 func runDeployLocal(cmd *cobra.Command, args []string) error {
+	return deployLocal(cmd, deployLocalOpts{})
+}
+
+func deployLocal(cmd *cobra.Command, opts deployLocalOpts) error {
 	printBanner("Apache DevLake — Local Docker Setup")
 
-	// Suggest a dedicated directory unless already in the right place or called from init
-	if !deployLocalQuiet {
+	var inst *instance.Instance
+	if deployLocalInstance != "" {
+		var err error
+		if inst, err = prepareLocalInstance(cmd, deployLocalInstance); err != nil {
+			return err
+		}
+	}
+	if err := deployLocalOverride.Validate(); err != nil {
+		return err
+	}
+
+	// Suggest a dedicated directory unless already in the right place, called
+	// from init, or deploying a named instance (which has its own directory)
+	if !opts.Quiet && inst == nil {
 		if suggestDedicatedDir("local", "gh devlake deploy local") {
 			return nil
 		}
 	}
 
+	if deployLocalBundle != "" {
+		if deployLocalSource != "" && deployLocalSource != "bundle" {
+			return fmt.Errorf("--bundle cannot be combined with --source %s", deployLocalSource)
+		}
+		deployLocalSource = "bundle"
+	}
+
 	// ── Interactive image-source prompt (when no explicit flag set) ──
 	if deployLocalSource == "" {
 		imageChoices := []string{
@@ -101,6 +175,7 @@ func runDeployLocal(cmd *cobra.Command, args []string) error {
 	fmt.Printf("\nTarget directory: %s\n", absDir)
 
 	envPath := filepath.Join(absDir, ".env")
+	bundleDir := ""
 
 	switch deployLocalSource {
 	case "official":
@@ -108,6 +183,13 @@ func runDeployLocal(cmd *cobra.Command, args []string) error {
 			return err
 		}
 
+	case "bundle":
+		var err error
+		if bundleDir, err = deployLocalBundle_extract(absDir, envPath); err != nil {
+			return err
+		}
+		defer os.RemoveAll(bundleDir)
+
 	case "fork":
 		if err := deployLocalFork_clone(absDir); err != nil {
 			return err
@@ -116,6 +198,9 @@ func runDeployLocal(cmd *cobra.Command, args []string) error {
 		envPath = filepath.Join(absDir, ".env")
 
 	case "custom":
+		if !deployLocalOverride.IsZero() {
+			return fmt.Errorf("port, memory and network flags are not supported with --source custom — set them in your own compose file")
+		}
 		fmt.Println("\n📂 Using existing docker-compose.yml in target directory")
 		// Verify docker-compose exists
 		composePath := filepath.Join(absDir, "docker-compose.yml")
@@ -173,6 +258,38 @@ func runDeployLocal(cmd *cobra.Command, args []string) error {
 		fmt.Println("   ✅ ENCRYPTION_SECRET generated and saved")
 	}
 
+	// ── Ports, resources and networking ──
+	if err := dockerpkg.WriteOverride(absDir, deployLocalOverride); err != nil {
+		return err
+	}
+	if !deployLocalOverride.IsZero() {
+		fmt.Printf("\n⚙️  Wrote %s\n", dockerpkg.OverrideFile)
+		if deployLocalOverride.MemoryLimit != "" {
+			fmt.Printf("   Memory limit (devlake): %s\n", deployLocalOverride.MemoryLimit)
+		}
+		if deployLocalOverride.Network != "" {
+			fmt.Printf("   Network: %s (must already exist)\n", deployLocalOverride.Network)
+		}
+	}
+	endpoints := localOverrideEndpoints(deployLocalOverride)
+	instanceName := ""
+	if inst != nil {
+		instanceName = inst.Name
+		if err := instance.Register(*inst); err != nil {
+			return fmt.Errorf("failed to register instance %q: %w", inst.Name, err)
+		}
+		fmt.Printf("\n🏷️  Instance %q registered (compose project %s, port block %d)\n", inst.Name, inst.ProjectName(), inst.PortBlock)
+	}
+	if deployLocalSource != "custom" {
+		if err := recordLocalEndpoints(absDir, endpoints, instanceName); err != nil {
+			fmt.Printf("   ⚠️  Could not record endpoints in state file: %v\n", err)
+		}
+	}
+	cleanupHint := fmt.Sprintf("cd \"%s\" && gh devlake cleanup", absDir)
+	if inst != nil {
+		cleanupHint = "gh devlake cleanup --instance " + inst.Name
+	}
+
 	// ── Check Docker ──
 	fmt.Println("\n🐳 Checking Docker...")
 	if err := dockerpkg.CheckAvailable(); err != nil {
@@ -183,6 +300,14 @@ func runDeployLocal(cmd *cobra.Command, args []string) error {
 	}
 	fmt.Println("   ✅ Docker found")
 
+	if bundleDir != "" {
+		fmt.Println("\n📦 Loading images from the bundle...")
+		if err := dockerpkg.Load(filepath.Join(bundleDir, bundle.ImagesName)); err != nil {
+			return err
+		}
+		fmt.Println("   ✅ Images loaded")
+	}
+
 	// ── Start containers (unless --start=false) ──
 	if deployLocalStart {
 		buildImages := deployLocalSource == "fork"
@@ -209,17 +334,18 @@ func runDeployLocal(cmd *cobra.Command, args []string) error {
 			}
 		}
 
-		if !deployLocalQuiet {
+		if !opts.Quiet {
 			printBanner("✅ DevLake is running!")
+			grafanaURL, configUIURL := localCompanionURLsIn(absDir, backendURL)
 			fmt.Printf("\n  Backend API: %s\n", backendURL)
-			fmt.Println("  Config UI:   http://localhost:4000")
-			fmt.Println("  Grafana:     http://localhost:3002 (admin/admin)")
+			fmt.Printf("  Config UI:   %s\n", configUIURL)
+			fmt.Printf("  Grafana:     %s (admin/admin)\n", grafanaURL)
 			fmt.Println("\nTo stop/remove DevLake:")
-			fmt.Printf("  cd \"%s\" && gh devlake cleanup\n", absDir)
+			fmt.Printf("  %s\n", cleanupHint)
 		}
 	} else {
 		// Print manual instructions
-		if !deployLocalQuiet {
+		if !opts.Quiet {
 			printBanner("✅ Setup Complete!")
 			fmt.Printf("\nFiles prepared in: %s\n", absDir)
 			fmt.Println("  • .env (with ENCRYPTION_SECRET)")
@@ -227,50 +353,212 @@ func runDeployLocal(cmd *cobra.Command, args []string) error {
 			fmt.Printf("  1. cd %s\n", absDir)
 			fmt.Println("  2. docker compose up -d")
 			fmt.Println("  3. Wait 2-3 minutes for services to start")
-			fmt.Println("  4. Backend API:    http://localhost:8080")
-			fmt.Println("  5. Open Config UI: http://localhost:4000")
-			fmt.Println("  6. Open Grafana:   http://localhost:3002 (admin/admin)")
+			fmt.Printf("  4. Backend API:    %s\n", endpoints.Backend)
+			fmt.Printf("  5. Open Config UI: %s\n", endpoints.ConfigUI)
+			fmt.Printf("  6. Open Grafana:   %s (admin/admin)\n", endpoints.Grafana)
 			fmt.Println("\nTo stop/remove DevLake later:")
-			fmt.Printf("  cd \"%s\" && gh devlake cleanup\n", absDir)
+			fmt.Printf("  %s\n", cleanupHint)
 		}
 	}
 
 	return nil
 }
 
-// deployLocalOfficial_download downloads the official Apache release files.
-func deployLocalOfficial_download(absDir, envPath string) error {
-	version := deployLocalVersion
-	if version == "latest" {
-		fmt.Println("\n🔍 Fetching latest release version...")
-		tag, err := download.GitHubLatestTag("apache", "incubator-devlake")
-		if err != nil {
-			return fmt.Errorf("failed to fetch latest release: %w", err)
+// localOverrideEndpoints returns the local endpoints for the chosen host
+// address and ports, falling back to the upstream compose ports.
+func localOverrideEndpoints(o dockerpkg.Override) devlake.StateEndpoints {
+	port := func(p, def int) int {
+		if p == 0 {
+			return def
+		}
+		return p
+	}
+	host := o.Host()
+	ep := devlake.StateEndpoints{
+		Backend:  fmt.Sprintf("http://%s:%d", host, port(o.BackendPort, dockerpkg.DefaultBackendHostPort)),
+		Grafana:  fmt.Sprintf("http://%s:%d", host, port(o.GrafanaPort, dockerpkg.DefaultGrafanaHostPort)),
+		ConfigUI: fmt.Sprintf("http://%s:%d", host, port(o.UIPort, dockerpkg.DefaultConfigUIHostPort)),
+	}
+	if o.MySQLPort != 0 {
+		ep.MySQL = fmt.Sprintf("%s:%d", host, o.MySQLPort)
+	}
+	return ep
+}
+
+// recordLocalEndpoints writes the endpoints (and instance name, if any) into
+// the local state file so discovery, start and status use the chosen ports.
+func recordLocalEndpoints(absDir string, ep devlake.StateEndpoints, instanceName string) error {
+	path := filepath.Join(absDir, ".devlake-local.json")
+	state, err := devlake.LoadState(path)
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		state = &devlake.State{DeployedAt: time.Now().Format(time.RFC3339), Method: "local"}
+	}
+	state.Endpoints = ep
+	state.Instance = instanceName
+	return devlake.SaveState(path, state)
+}
+
+// prepareLocalInstance resolves the directory and port block of a named
+// instance and fills the override with its project name and any ports not
+// set by flags.
+func prepareLocalInstance(cmd *cobra.Command, name string) (*instance.Instance, error) {
+	if err := instance.ValidateName(name); err != nil {
+		return nil, err
+	}
+	if deployLocalSource == "custom" {
+		return nil, fmt.Errorf("--instance is not supported with --source custom — set the project name and ports in your own compose file")
+	}
+	if !cmd.Flags().Changed("dir") {
+		deployLocalDir = instance.ProjectName(name)
+	}
+	absDir, err := filepath.Abs(deployLocalDir)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := instance.List()
+	if err != nil {
+		return nil, err
+	}
+	inst := instance.Instance{Name: name, Dir: absDir}
+	for _, existing := range list {
+		switch {
+		case existing.Name == name && existing.Dir != absDir:
+			return nil, fmt.Errorf("instance %q already exists in %s — redeploy there or remove it with 'gh devlake cleanup --instance %s'", name, existing.Dir, name)
+		case existing.Name == name:
+			inst = existing
+		case existing.Dir == absDir:
+			return nil, fmt.Errorf("%s already holds instance %q — choose another --dir", absDir, existing.Name)
 		}
-		version = tag
-		fmt.Printf("   Latest version: %s\n", version)
+	}
+	if inst.PortBlock == 0 {
+		inst.PortBlock = instance.NextPortBlock(list)
 	}
 
-	baseURL := fmt.Sprintf("https://github.com/apache/incubator-devlake/releases/download/%s", version)
-	files := []struct {
-		name string
-		url  string
+	offset := inst.PortBlock * instance.PortBlockStep
+	for _, p := range []struct {
+		port *int
+		base int
 	}{
-		{"docker-compose.yml", baseURL + "/docker-compose.yml"},
-		{"env.example", baseURL + "/env.example"},
+		{&deployLocalOverride.BackendPort, dockerpkg.DefaultBackendHostPort},
+		{&deployLocalOverride.GrafanaPort, dockerpkg.DefaultGrafanaHostPort},
+		{&deployLocalOverride.UIPort, dockerpkg.DefaultConfigUIHostPort},
+		{&deployLocalOverride.MySQLPort, dockerpkg.DefaultMySQLHostPort},
+	} {
+		if *p.port == 0 {
+			*p.port = p.base + offset
+		}
+	}
+	deployLocalOverride.ProjectName = inst.ProjectName()
+	return &inst, nil
+}
+
+// deployLocalOfficial_download downloads the official Apache release files.
+func deployLocalOfficial_download(absDir, envPath string) error {
+	version, err := resolveReleaseVersion(deployLocalVersion)
+	if err != nil {
+		return err
+	}
+
+	if err := downloadReleaseFiles(version, absDir, deployLocalInsecure); err != nil {
+		return err
+	}
+	return installEnvExample(absDir, envPath)
+}
+
+// deployLocalBundle_extract verifies a bundle and installs its compose file
+// and env template into absDir. It returns the directory holding the rest
+// (the saved images), which the caller removes.
+func deployLocalBundle_extract(absDir, envPath string) (string, error) {
+	fmt.Printf("\n📦 Verifying bundle %s...\n", deployLocalBundle)
+	// Extract next to the deployment: the images can be several GB
+	tmpDir, err := os.MkdirTemp(absDir, ".bundle-")
+	if err != nil {
+		return "", err
 	}
+	m, err := bundle.Extract(deployLocalBundle, tmpDir)
+	if err == nil && deployLocalVersion != "latest" && deployLocalVersion != m.Version {
+		err = fmt.Errorf("bundle holds %s, not --version %s", m.Version, deployLocalVersion)
+	}
+	if err != nil {
+		os.RemoveAll(tmpDir)
+		return "", fmt.Errorf("invalid bundle: %w", err)
+	}
+	fmt.Printf("   ✅ DevLake %s, %d images, checksums verified\n", m.Version, len(m.Images))
+
+	for _, name := range []string{bundle.ComposeName, bundle.EnvName} {
+		if err := os.Rename(filepath.Join(tmpDir, name), filepath.Join(absDir, name)); err != nil {
+			os.RemoveAll(tmpDir)
+			return "", err
+		}
+	}
+	if err := installEnvExample(absDir, envPath); err != nil {
+		os.RemoveAll(tmpDir)
+		return "", err
+	}
+	return tmpDir, nil
+}
+
+// officialReleaseFiles are the release assets a local deployment needs.
+var officialReleaseFiles = []string{"docker-compose.yml", "env.example"}
+
+// downloadReleaseFiles downloads the official release assets into dir. Each
+// is checked against the checksum pinned in this binary or published with
+// the release; a mismatch is an error, an asset with no checksum at all is
+// saved with a warning. skipVerify turns the check off.
+func downloadReleaseFiles(version, dir string, skipVerify bool) error {
+	rel := download.Release{Owner: "apache", Repo: "incubator-devlake", Tag: version}
+	showProgress := term.IsTerminal(int(os.Stdout.Fd()))
 
 	fmt.Printf("\n📥 Downloading files for %s...\n", version)
-	for _, f := range files {
-		dest := filepath.Join(absDir, f.name)
-		fmt.Printf("   Downloading %s...", f.name)
-		if err := download.File(f.url, dest); err != nil {
-			return fmt.Errorf("\n   failed to download %s: %w", f.name, err)
+	if skipVerify {
+		fmt.Println("   ⚠️  --insecure-skip-verify: checksums are not checked")
+	}
+	for _, name := range officialReleaseFiles {
+		opts := download.Options{Label: "   Downloading " + name}
+		if showProgress {
+			opts.Progress = os.Stdout
+		} else {
+			fmt.Print(opts.Label + "...")
+		}
+		source, err := rel.Download(name, filepath.Join(dir, name), opts, skipVerify)
+		var mismatch *download.ChecksumError
+		switch {
+		case errors.As(err, &mismatch):
+			return fmt.Errorf("\n   %s failed verification: %w — the file was not saved", name, err)
+		case err != nil:
+			return fmt.Errorf("\n   failed to download %s: %w", name, err)
+		case skipVerify:
+			fmt.Println(" ✅")
+		case source == "":
+			fmt.Println(" ⚠️  not verified — no checksum is pinned in this CLI or published with the release")
+		default:
+			fmt.Printf(" ✅ sha256 verified (%s)\n", source)
 		}
-		fmt.Println(" ✅")
 	}
+	return nil
+}
+
+// resolveReleaseVersion turns "latest" into the newest release tag.
+func resolveReleaseVersion(version string) (string, error) {
+	if version != "latest" {
+		return version, nil
+	}
+	fmt.Println("\n🔍 Fetching latest release version...")
+	tag, err := download.GitHubLatestTag("apache", "incubator-devlake")
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch latest release: %w", err)
+	}
+	fmt.Printf("   Latest version: %s\n", tag)
+	return tag, nil
+}
 
-	// Rename env.example → .env
+// installEnvExample renames env.example in absDir to .env, backing up an
+// existing .env first.
+func installEnvExample(absDir, envPath string) error {
 	envExamplePath := filepath.Join(absDir, "env.example")
 	if _, err := os.Stat(envPath); err == nil {
 		backupPath := envPath + ".bak"
@@ -529,7 +817,7 @@ func startLocalContainers(dir string, build bool, services ...string) (string, e
 	}
 	fmt.Println("   ✅ Containers starting")
 
-	backendURLCandidates := []string{"http://localhost:8080", "http://localhost:8085"}
+	backendURLCandidates := localBackendCandidates(absDir)
 	fmt.Println("\n⏳ Waiting for DevLake to be ready...")
 	fmt.Println("   Giving MySQL time to initialize (this takes ~30s on first run)...")
 	time.Sleep(30 * time.Second)
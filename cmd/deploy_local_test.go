@@ -1,6 +1,15 @@
 package cmd
 
-import "testing"
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DevExpGBB/gh-devlake/internal/bundle"
+	dockerpkg "github.com/DevExpGBB/gh-devlake/internal/docker"
+	"github.com/DevExpGBB/gh-devlake/internal/instance"
+)
 
 func TestRewritePoetryInstallLine_RewritesInstallerLine(t *testing.T) {
 	input := "FROM python:3.9-slim-bookworm\nRUN curl -sSL https://install.python-poetry.org | python3 -\n"
@@ -38,3 +47,103 @@ func TestRewritePoetryInstallLine_NoChangeWhenLineMissing(t *testing.T) {
 		t.Fatalf("content changed unexpectedly")
 	}
 }
+
+func TestPrepareLocalInstance(t *testing.T) {
+	cfg := t.TempDir()
+	origCfg := instance.ConfigDir
+	instance.ConfigDir = func() (string, error) { return cfg, nil }
+	t.Cleanup(func() { instance.ConfigDir = origCfg })
+	origDir, origOverride, origSource := deployLocalDir, deployLocalOverride, deployLocalSource
+	t.Cleanup(func() { deployLocalDir, deployLocalOverride, deployLocalSource = origDir, origOverride, origSource })
+
+	other := filepath.Join(t.TempDir(), "prod")
+	if err := instance.Register(instance.Instance{Name: "prod", Dir: other, PortBlock: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := newDeployLocalCmd()
+	stagingDir := filepath.Join(t.TempDir(), "staging")
+	if err := cmd.Flags().Set("dir", stagingDir); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Flags().Set("grafana-port", "13000"); err != nil {
+		t.Fatal(err)
+	}
+	inst, err := prepareLocalInstance(cmd, "staging")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inst.PortBlock != 2 || inst.Dir != stagingDir {
+		t.Errorf("instance = %+v, want block 2 in %s", inst, stagingDir)
+	}
+	want := dockerpkg.Override{BackendPort: 8100, GrafanaPort: 13000, UIPort: 4020, MySQLPort: 3326, ProjectName: "devlake-staging"}
+	if deployLocalOverride != want {
+		t.Errorf("override = %+v, want %+v", deployLocalOverride, want)
+	}
+
+	// Same name in another directory, or another name in prod's directory, is refused.
+	cmd = newDeployLocalCmd()
+	_ = cmd.Flags().Set("dir", t.TempDir())
+	if _, err := prepareLocalInstance(cmd, "prod"); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("duplicate name error = %v", err)
+	}
+	cmd = newDeployLocalCmd()
+	_ = cmd.Flags().Set("dir", other)
+	if _, err := prepareLocalInstance(cmd, "qa"); err == nil || !strings.Contains(err.Error(), "already holds") {
+		t.Errorf("shared dir error = %v", err)
+	}
+	if _, err := prepareLocalInstance(newDeployLocalCmd(), "Bad_Name"); err == nil {
+		t.Error("expected invalid name error")
+	}
+}
+
+func TestDeployLocalBundleExtract(t *testing.T) {
+	origBundle, origVersion := deployLocalBundle, deployLocalVersion
+	t.Cleanup(func() { deployLocalBundle, deployLocalVersion = origBundle, origVersion })
+
+	src := t.TempDir()
+	var entries []bundle.Entry
+	for name, content := range map[string]string{
+		bundle.ComposeName: "services:\n  mysql:\n    image: mysql:8\n",
+		bundle.EnvName:     "ENCRYPTION_SECRET=\n",
+		bundle.ImagesName:  "saved images",
+	} {
+		path := filepath.Join(src, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, bundle.Entry{Name: name, Path: path})
+	}
+	deployLocalBundle = filepath.Join(src, "devlake-bundle-v1.0.2.tar")
+	if err := bundle.Write(deployLocalBundle, bundle.Manifest{Version: "v1.0.2", Images: []string{"mysql:8"}}, entries); err != nil {
+		t.Fatal(err)
+	}
+
+	// A --version that does not match the bundle is refused, leaving nothing behind
+	absDir := t.TempDir()
+	deployLocalVersion = "v1.0.3"
+	var err error
+	captureStdout(func() { _, err = deployLocalBundle_extract(absDir, filepath.Join(absDir, ".env")) })
+	if err == nil || !strings.Contains(err.Error(), "not --version v1.0.3") {
+		t.Errorf("version mismatch error = %v", err)
+	}
+	if left, _ := os.ReadDir(absDir); len(left) != 0 {
+		t.Errorf("left behind %v", left)
+	}
+
+	deployLocalVersion = "latest"
+	var bundleDir string
+	captureStdout(func() { bundleDir, err = deployLocalBundle_extract(absDir, filepath.Join(absDir, ".env")) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{
+		filepath.Join(absDir, "docker-compose.yml"),
+		filepath.Join(absDir, ".env"),
+		filepath.Join(bundleDir, bundle.ImagesName),
+	} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("missing %s", path)
+		}
+	}
+}
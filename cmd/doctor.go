@@ -0,0 +1,540 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DevExpGBB/gh-devlake/internal/azure"
+	"github.com/DevExpGBB/gh-devlake/internal/devlake"
+	dockerpkg "github.com/DevExpGBB/gh-devlake/internal/docker"
+	"github.com/DevExpGBB/gh-devlake/internal/envfile"
+	"github.com/DevExpGBB/gh-devlake/internal/instance"
+	"github.com/spf13/cobra"
+)
+
+// Doctor check results.
+const (
+	doctorPass = "pass"
+	doctorWarn = "warn"
+	doctorFail = "fail"
+	doctorSkip = "skip"
+)
+
+// doctorPipelineLookback is how many recent pipelines are read to find the
+// last run of each blueprint.
+const doctorPipelineLookback = 50
+
+var doctorInstance string
+
+// Tool checks are variables so tests can stub them.
+var (
+	doctorCheckDocker = dockerpkg.CheckAvailable
+	doctorCheckAzure  = azure.CheckLogin
+)
+
+// doctorCheck is the result of one diagnostic.
+type doctorCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"` // pass, warn, fail or skip
+	Detail string `json:"detail,omitempty"`
+	Fix    string `json:"fix,omitempty"`
+}
+
+// doctorReport is the JSON representation of the doctor command output.
+type doctorReport struct {
+	StateFile string         `json:"stateFile,omitempty"`
+	Method    string         `json:"method,omitempty"`
+	Backend   string         `json:"backend,omitempty"`
+	Checks    []doctorCheck  `json:"checks"`
+	Summary   map[string]int `json:"summary"`
+}
+
+// doctorEnv is what the checks know about the deployment.
+type doctorEnv struct {
+	dir       string
+	stateFile string // base name, "" without a state file
+	state     *devlake.State
+	backend   string          // backend URL, from the state file or discovery
+	client    *devlake.Client // nil when the backend is not ready
+}
+
+func newDoctorCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose a DevLake deployment and suggest fixes",
+		Long: `Runs a series of checks against the deployment in the current directory
+(or --instance) and prints pass, warn or fail for each, with a command or
+step to fix every problem:
+
+  • Docker (local) or Azure CLI login (Azure)
+  • Port conflicts on the backend, Grafana and Config UI ports
+  • ENCRYPTION_SECRET in .env (local)
+  • Backend reachable and no database migration pending
+  • Every saved connection, tested through DevLake
+  • Projects without scopes
+  • The last pipeline of each project
+  • State file consistency with what DevLake holds
+
+The command exits non-zero when a check fails. With --json the report is
+printed instead, for attaching to a support ticket.
+
+Example:
+  gh devlake doctor
+  gh devlake doctor --instance staging
+  gh devlake doctor --json > doctor.json`,
+		Args: cobra.NoArgs,
+		RunE: runDoctor,
+	}
+	cmd.Flags().StringVar(&doctorInstance, "instance", "", "Diagnose a named local instance (see 'deploy local --instance')")
+	cmd.GroupID = "operate"
+	return cmd
+}
+
+func init() {
+	rootCmd.AddCommand(newDoctorCmd())
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	if doctorInstance != "" {
+		inst, err := instance.Get(doctorInstance)
+		if err != nil {
+			return err
+		}
+		if err := os.Chdir(inst.Dir); err != nil {
+			return fmt.Errorf("instance %q directory %s: %w", inst.Name, inst.Dir, err)
+		}
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	if !outputJSON {
+		printBanner("DevLake Doctor")
+		fmt.Println("\n🩺 Running checks...")
+	}
+	report := runDoctorChecks(dir, cfgURL)
+
+	if outputJSON {
+		return printJSON(report)
+	}
+	printDoctorReport(report)
+	if n := report.Summary[doctorFail]; n > 0 {
+		return fmt.Errorf("%d check(s) failed — see the fixes above", n)
+	}
+	return nil
+}
+
+// runDoctorChecks runs every check against the deployment in dir.
+func runDoctorChecks(dir, explicitURL string) *doctorReport {
+	env := &doctorEnv{dir: dir}
+	for _, name := range []string{".devlake-azure.json", ".devlake-k8s.json", ".devlake-local.json"} {
+		if s, err := devlake.LoadState(filepath.Join(dir, name)); err == nil && s != nil {
+			env.stateFile, env.state = name, s
+			break
+		}
+	}
+	switch {
+	case explicitURL != "":
+		env.backend = strings.TrimRight(explicitURL, "/")
+	case env.state != nil && env.state.Endpoints.Backend != "":
+		env.backend = strings.TrimRight(env.state.Endpoints.Backend, "/")
+	default:
+		if disc, err := devlake.Discover(""); err == nil {
+			env.backend = disc.URL
+		}
+	}
+
+	report := &doctorReport{StateFile: env.stateFile, Backend: env.backend}
+	if env.state != nil {
+		report.Method = env.state.Method
+	}
+	add := func(checks ...doctorCheck) {
+		report.Checks = append(report.Checks, checks...)
+	}
+
+	add(doctorToolCheck(env))
+	if env.stateFile == "" || env.stateFile == ".devlake-local.json" {
+		add(doctorPortCheck(env))
+		add(doctorEncryptionSecretCheck(env))
+	}
+	add(doctorBackendChecks(env)...)
+	if env.client != nil {
+		add(doctorConnectionChecks(env)...)
+		add(doctorProjectChecks(env)...)
+	}
+	add(doctorStateChecks(env)...)
+
+	report.Summary = map[string]int{doctorPass: 0, doctorWarn: 0, doctorFail: 0, doctorSkip: 0}
+	for _, c := range report.Checks {
+		report.Summary[c.Status]++
+	}
+	return report
+}
+
+// doctorToolCheck checks the CLI the deployment is managed with.
+func doctorToolCheck(env *doctorEnv) doctorCheck {
+	switch env.stateFile {
+	case ".devlake-azure.json":
+		acct, err := doctorCheckAzure()
+		if err != nil {
+			return doctorCheck{Name: "Azure CLI", Status: doctorFail, Detail: err.Error(),
+				Fix: "Install the Azure CLI and run 'az login'"}
+		}
+		return doctorCheck{Name: "Azure CLI", Status: doctorPass, Detail: "logged in to " + acct.Name}
+	case ".devlake-k8s.json":
+		return doctorCheck{Name: "Docker", Status: doctorSkip, Detail: "Kubernetes deployment"}
+	}
+	if err := doctorCheckDocker(); err != nil {
+		return doctorCheck{Name: "Docker", Status: doctorFail, Detail: err.Error(),
+			Fix: "Start Docker Desktop or the Docker daemon ('sudo systemctl start docker'), then re-run"}
+	}
+	return doctorCheck{Name: "Docker", Status: doctorPass, Detail: "daemon running"}
+}
+
+// doctorPort is a port DevLake or discovery expects a service on.
+type doctorPort struct {
+	url  string
+	kind string // backend, grafana or config-ui
+	own  bool   // used by this deployment, not just a discovery candidate
+}
+
+// doctorPortCheck looks for another program on the deployment's ports and
+// the well-known ports discovery tries.
+func doctorPortCheck(env *doctorEnv) doctorCheck {
+	var ports []doctorPort
+	if env.state != nil {
+		ports = append(ports,
+			doctorPort{env.state.Endpoints.Backend, "backend", true},
+			doctorPort{env.state.Endpoints.Grafana, "grafana", true},
+			doctorPort{env.state.Endpoints.ConfigUI, "config-ui", true})
+	}
+	ports = append(ports,
+		doctorPort{"http://localhost:8080", "backend", env.state == nil},
+		doctorPort{"http://localhost:3002", "grafana", env.state == nil},
+		doctorPort{"http://localhost:4000", "config-ui", env.state == nil},
+		doctorPort{"http://localhost:8085", "backend", false},
+		doctorPort{"http://localhost:3004", "grafana", false},
+		doctorPort{"http://localhost:4004", "config-ui", false})
+
+	seen := map[string]bool{}
+	var own, other []string
+	for _, p := range ports {
+		host := urlHostPort(p.url)
+		if host == "" || seen[host] {
+			continue
+		}
+		seen[host] = true
+		if !strings.HasPrefix(host, "localhost:") && !strings.HasPrefix(host, "127.0.0.1:") {
+			continue
+		}
+		if portConflict(host, p.url, p.kind) {
+			label := fmt.Sprintf("%s (%s)", host, p.kind)
+			if p.own {
+				own = append(own, label)
+			} else {
+				other = append(other, label)
+			}
+		}
+	}
+	switch {
+	case len(own) > 0:
+		return doctorCheck{Name: "Ports", Status: doctorFail,
+			Detail: "in use by something other than DevLake: " + strings.Join(append(own, other...), ", "),
+			Fix:    "Find the owner with 'docker ps --filter publish=PORT' (or 'lsof -i :PORT') and stop it, or redeploy with --backend-port, --grafana-port and --ui-port"}
+	case len(other) > 0:
+		return doctorCheck{Name: "Ports", Status: doctorWarn,
+			Detail: "discovery candidates in use by something other than DevLake: " + strings.Join(other, ", "),
+			Fix:    "Pass --url to commands so discovery does not pick the wrong service"}
+	}
+	return doctorCheck{Name: "Ports", Status: doctorPass, Detail: "no conflicts"}
+}
+
+// portConflict reports whether something listens on host that does not
+// answer like the expected DevLake service.
+func portConflict(host, serviceURL, kind string) bool {
+	conn, err := net.DialTimeout("tcp", host, time.Second)
+	if err != nil {
+		return false // nothing listening
+	}
+	conn.Close()
+	code := endpointStatusCode(serviceURL, kind)
+	switch kind {
+	case "backend":
+		// 428 is a backend waiting for its migration
+		return code != 200 && code != 428
+	case "grafana":
+		return code != 200
+	}
+	return code == 0
+}
+
+func urlHostPort(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	if u.Port() == "" {
+		return ""
+	}
+	return u.Host
+}
+
+// doctorEncryptionSecretCheck checks .env holds an ENCRYPTION_SECRET.
+func doctorEncryptionSecretCheck(env *doctorEnv) doctorCheck {
+	c := doctorCheck{Name: "ENCRYPTION_SECRET"}
+	path := filepath.Join(env.dir, ".env")
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if env.stateFile == "" {
+			c.Status, c.Detail = doctorSkip, "no .env in this directory"
+			return c
+		}
+		c.Status, c.Detail = doctorFail, ".env is missing"
+		c.Fix = "Restore .env from a backup ('gh devlake restore'); a new secret cannot decrypt the existing connections"
+		return c
+	}
+	vals, err := envfile.Load(path)
+	if err != nil {
+		c.Status, c.Detail = doctorFail, err.Error()
+		c.Fix = "Fix the syntax of .env"
+		return c
+	}
+	if strings.TrimSpace(vals["ENCRYPTION_SECRET"]) == "" {
+		c.Status, c.Detail = doctorFail, "ENCRYPTION_SECRET is not set in .env"
+		c.Fix = "Restore it from a backup ('gh devlake restore') — for a new deployment, re-run 'gh devlake deploy local' to generate one"
+		return c
+	}
+	c.Status, c.Detail = doctorPass, "set in .env"
+	return c
+}
+
+// doctorBackendChecks checks the backend answers and has no migration
+// pending. It sets env.client when the backend is ready for API calls.
+func doctorBackendChecks(env *doctorEnv) []doctorCheck {
+	if env.backend == "" {
+		return []doctorCheck{{Name: "Backend", Status: doctorFail, Detail: "no state file and nothing found on the well-known ports",
+			Fix: "Deploy with 'gh devlake deploy', or pass --url"}}
+	}
+	code := endpointStatusCode(env.backend, "backend")
+	switch {
+	case code == 0:
+		fix := "Start it with 'gh devlake start'"
+		if env.stateFile == ".devlake-local.json" {
+			fix += "; if it keeps stopping, check 'docker compose logs devlake'"
+		}
+		return []doctorCheck{
+			{Name: "Backend", Status: doctorFail, Detail: env.backend + " is unreachable", Fix: fix},
+			{Name: "Migration", Status: doctorSkip, Detail: "backend unreachable"},
+		}
+	case code == 428:
+		return []doctorCheck{
+			{Name: "Backend", Status: doctorPass, Detail: env.backend},
+			{Name: "Migration", Status: doctorFail, Detail: "a database migration is pending",
+				Fix: fmt.Sprintf("Confirm it in the Config UI, or run: curl %s/proceed-db-migration", env.backend)},
+		}
+	case code != 200:
+		return []doctorCheck{
+			{Name: "Backend", Status: doctorFail, Detail: fmt.Sprintf("%s/ping returned %d", env.backend, code),
+				Fix: "Check the backend logs ('docker compose logs devlake' for a local deployment)"},
+			{Name: "Migration", Status: doctorSkip, Detail: "backend not healthy"},
+		}
+	}
+	env.client = devlake.NewClient(env.backend)
+	return []doctorCheck{
+		{Name: "Backend", Status: doctorPass, Detail: env.backend},
+		{Name: "Migration", Status: doctorPass, Detail: "none pending"},
+	}
+}
+
+// doctorConnectionChecks tests every connection saved in DevLake.
+func doctorConnectionChecks(env *doctorEnv) []doctorCheck {
+	var checks []doctorCheck
+	for _, def := range AvailableConnections() {
+		conns, err := env.client.ListConnections(def.Plugin)
+		if err != nil {
+			checks = append(checks, doctorCheck{Name: def.DisplayName + " connections", Status: doctorWarn,
+				Detail: "could not list: " + err.Error()})
+			continue
+		}
+		for _, conn := range conns {
+			c := doctorCheck{Name: fmt.Sprintf("Connection %s #%d %q", def.Plugin, conn.ID, conn.Name)}
+			res, err := env.client.TestSavedConnection(def.Plugin, conn.ID)
+			switch {
+			case err != nil:
+				c.Status, c.Detail = doctorFail, err.Error()
+			case !res.Success:
+				c.Status, c.Detail = doctorFail, res.Message
+			default:
+				c.Status = doctorPass
+				checks = append(checks, c)
+				continue
+			}
+			c.Fix = fmt.Sprintf("Check the endpoint and proxy, or rotate the token: gh devlake configure connection update --plugin %s --id %d --token <new token>", def.Plugin, conn.ID)
+			checks = append(checks, c)
+		}
+	}
+	if len(checks) == 0 {
+		checks = append(checks, doctorCheck{Name: "Connections", Status: doctorWarn, Detail: "none saved",
+			Fix: "Create one with 'gh devlake configure connection add'"})
+	}
+	return checks
+}
+
+// doctorProjectChecks flags projects without scopes and projects whose last
+// pipeline failed.
+func doctorProjectChecks(env *doctorEnv) []doctorCheck {
+	projects, err := env.client.ListProjects()
+	if err != nil {
+		return []doctorCheck{{Name: "Projects", Status: doctorWarn, Detail: "could not list: " + err.Error()}}
+	}
+	if len(projects) == 0 {
+		return []doctorCheck{{Name: "Projects", Status: doctorWarn, Detail: "none",
+			Fix: "Create one with 'gh devlake configure project add'"}}
+	}
+
+	// The newest pipeline of each blueprint
+	last := map[int]devlake.Pipeline{}
+	if resp, err := env.client.ListPipelines("", 0, 1, doctorPipelineLookback); err == nil {
+		for _, p := range resp.Pipelines {
+			if cur, ok := last[p.BlueprintID]; !ok || p.ID > cur.ID {
+				last[p.BlueprintID] = p
+			}
+		}
+	}
+
+	sort.Slice(projects, func(i, j int) bool { return projects[i].Name < projects[j].Name })
+	var checks []doctorCheck
+	for _, p := range projects {
+		bp := p.Blueprint
+		if bp == nil {
+			if full, err := env.client.GetProject(p.Name); err == nil {
+				bp = full.Blueprint
+			}
+		}
+		scopes := 0
+		if bp != nil {
+			for _, c := range bp.Connections {
+				scopes += len(c.Scopes)
+			}
+		}
+		c := doctorCheck{Name: fmt.Sprintf("Project %q", p.Name)}
+		if scopes == 0 {
+			c.Status, c.Detail = doctorWarn, "no scopes — nothing will be collected"
+			c.Fix = fmt.Sprintf("Add repos to it: gh devlake configure scope sync --plugin github --connection-id <id> --rule \"my-org/*\" --project %s", p.Name)
+			checks = append(checks, c)
+			continue
+		}
+		c.Status, c.Detail = doctorPass, fmt.Sprintf("%d scope(s)", scopes)
+		if bp != nil {
+			if pl, ok := last[bp.ID]; ok {
+				c.Status, c.Detail, c.Fix = pipelineCheck(pl, scopes)
+			}
+		}
+		checks = append(checks, c)
+	}
+	return checks
+}
+
+// pipelineCheck grades a project by its last pipeline.
+func pipelineCheck(p devlake.Pipeline, scopes int) (status, detail, fix string) {
+	detail = fmt.Sprintf("%d scope(s), last pipeline #%d %s", scopes, p.ID, strings.TrimPrefix(p.Status, "TASK_"))
+	switch p.Status {
+	case "TASK_FAILED":
+		if p.Message != "" {
+			detail += ": " + firstLine(p.Message)
+		}
+		return doctorFail, detail, fmt.Sprintf("See the failed tasks with 'gh devlake query pipelines --status TASK_FAILED', fix the cause, then re-run the blueprint (id %d) from the Config UI", p.BlueprintID)
+	case "TASK_PARTIAL":
+		return doctorWarn, detail, "Some tasks failed; see 'gh devlake query pipelines' for which"
+	}
+	return doctorPass, detail, ""
+}
+
+func firstLine(s string) string {
+	s, _, _ = strings.Cut(strings.TrimSpace(s), "\n")
+	if len(s) > 200 {
+		s = s[:200] + "…"
+	}
+	return s
+}
+
+// doctorStateChecks compares the state file with DevLake and the instance
+// registry.
+func doctorStateChecks(env *doctorEnv) []doctorCheck {
+	if env.state == nil {
+		return []doctorCheck{{Name: "State file", Status: doctorSkip, Detail: "no state file in " + env.dir}}
+	}
+	var problems, fixes []string
+
+	var found []string
+	for _, name := range []string{".devlake-azure.json", ".devlake-k8s.json", ".devlake-local.json"} {
+		if _, err := os.Stat(filepath.Join(env.dir, name)); err == nil {
+			found = append(found, name)
+		}
+	}
+	if len(found) > 1 {
+		problems = append(problems, fmt.Sprintf("several state files (%s); %s is used", strings.Join(found, ", "), env.stateFile))
+		fixes = append(fixes, "remove the state file of the deployment that no longer exists")
+	}
+
+	if env.state.Instance != "" {
+		inst, err := instance.Get(env.state.Instance)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("instance %q is not registered", env.state.Instance))
+			fixes = append(fixes, "re-run 'gh devlake deploy local --instance "+env.state.Instance+"' in this directory to register it")
+		} else if abs, _ := filepath.Abs(env.dir); filepath.Clean(inst.Dir) != abs {
+			problems = append(problems, fmt.Sprintf("instance %q is registered at %s", env.state.Instance, inst.Dir))
+			fixes = append(fixes, "run commands from the registered directory or re-deploy the instance here")
+		}
+	}
+
+	if env.client != nil {
+		missing := len(problems)
+		for _, sc := range env.state.Connections {
+			if _, err := env.client.GetConnection(sc.Plugin, sc.ConnectionID); err != nil {
+				problems = append(problems, fmt.Sprintf("connection %s #%d %q is not in DevLake", sc.Plugin, sc.ConnectionID, sc.Name))
+			}
+		}
+		if p := env.state.Project; p != nil {
+			if _, err := env.client.GetProject(p.Name); err != nil {
+				problems = append(problems, fmt.Sprintf("project %q is not in DevLake", p.Name))
+			}
+		}
+		if len(problems) > missing {
+			fixes = append(fixes, "re-run 'gh devlake configure full', or restore the database if it was reset ('gh devlake restore')")
+		}
+	}
+
+	if len(problems) == 0 {
+		return []doctorCheck{{Name: "State file", Status: doctorPass, Detail: env.stateFile + " matches DevLake"}}
+	}
+	return []doctorCheck{{Name: "State file", Status: doctorWarn, Detail: strings.Join(problems, "; "), Fix: strings.Join(fixes, "; ")}}
+}
+
+// printDoctorReport prints one line per check, with the fix under it.
+func printDoctorReport(r *doctorReport) {
+	icons := map[string]string{doctorPass: "✅", doctorWarn: "⚠️ ", doctorFail: "❌", doctorSkip: "⏭️ "}
+	if r.StateFile != "" {
+		fmt.Printf("   State file: %s\n", r.StateFile)
+	}
+	fmt.Println()
+	for _, c := range r.Checks {
+		line := fmt.Sprintf("   %s %s", icons[c.Status], c.Name)
+		if c.Detail != "" {
+			line += " — " + c.Detail
+		}
+		fmt.Println(line)
+		if c.Fix != "" && (c.Status == doctorFail || c.Status == doctorWarn) {
+			fmt.Printf("      💡 %s\n", c.Fix)
+		}
+	}
+	fmt.Printf("\n   %d passed, %d warnings, %d failed, %d skipped\n",
+		r.Summary[doctorPass], r.Summary[doctorWarn], r.Summary[doctorFail], r.Summary[doctorSkip])
+	fmt.Println()
+}
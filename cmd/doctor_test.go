@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DevExpGBB/gh-devlake/internal/devlake"
+)
+
+func newDoctorTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ping":
+			w.Write([]byte(`{}`))
+		case "/plugins/github/connections":
+			w.Write([]byte(`[{"id": 1, "name": "GitHub - my-org"}]`))
+		case "/plugins/github/connections/1/test":
+			w.Write([]byte(`{"success": false, "message": "Bad credentials"}`))
+		case "/plugins/github/connections/1":
+			w.Write([]byte(`{"id": 1, "name": "GitHub - my-org"}`))
+		case "/projects":
+			w.Write([]byte(`{"count": 2, "projects": [
+				{"name": "team", "blueprint": {"id": 2, "connections": [{"pluginName": "github", "connectionId": 1, "scopes": [{"scopeId": "1"}, {"scopeId": "2"}]}]}},
+				{"name": "empty", "blueprint": {"id": 1, "connections": []}}]}`))
+		case "/projects/team":
+			w.Write([]byte(`{"name": "team"}`))
+		case "/pipelines":
+			w.Write([]byte(`{"count": 2, "pipelines": [
+				{"id": 3, "blueprintId": 2, "status": "TASK_COMPLETED"},
+				{"id": 9, "blueprintId": 2, "status": "TASK_FAILED", "message": "rate limit exceeded\nstack..."}]}`))
+		default:
+			if strings.HasSuffix(r.URL.Path, "/connections") {
+				w.Write([]byte(`[]`))
+				return
+			}
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestRunDoctorChecks(t *testing.T) {
+	origDocker := doctorCheckDocker
+	t.Cleanup(func() { doctorCheckDocker = origDocker })
+	doctorCheckDocker = func() error { return errors.New("docker not available: exit status 1") }
+
+	srv := newDoctorTestServer(t)
+	dir := t.TempDir()
+	state := &devlake.State{
+		Method:    "local",
+		Endpoints: devlake.StateEndpoints{Backend: srv.URL},
+		Connections: []devlake.StateConnection{
+			{Plugin: "github", ConnectionID: 1, Name: "GitHub - my-org"},
+			{Plugin: "github", ConnectionID: 5, Name: "deleted"},
+		},
+		Project: &devlake.StateProject{Name: "team"},
+	}
+	if err := devlake.SaveState(filepath.Join(dir, ".devlake-local.json"), state); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(dir, ".env"), []byte("ENCRYPTION_SECRET=\nDB_URL=x\n"), 0600)
+
+	report := runDoctorChecks(dir, "")
+	byName := map[string]doctorCheck{}
+	for _, c := range report.Checks {
+		byName[c.Name] = c
+	}
+	want := map[string]string{
+		"Docker":                                 doctorFail,
+		"ENCRYPTION_SECRET":                      doctorFail,
+		"Backend":                                doctorPass,
+		"Migration":                              doctorPass,
+		`Connection github #1 "GitHub - my-org"`: doctorFail,
+		`Project "empty"`:                        doctorWarn,
+		`Project "team"`:                         doctorFail,
+		"State file":                             doctorWarn,
+	}
+	for name, status := range want {
+		c, ok := byName[name]
+		if !ok {
+			t.Errorf("no %s check in %+v", name, report.Checks)
+			continue
+		}
+		if c.Status != status {
+			t.Errorf("%s = %s (%s), want %s", name, c.Status, c.Detail, status)
+		}
+		if (status == doctorFail || status == doctorWarn) && c.Fix == "" {
+			t.Errorf("%s has no fix", name)
+		}
+	}
+	if d := byName[`Project "team"`].Detail; d != "2 scope(s), last pipeline #9 FAILED: rate limit exceeded" {
+		t.Errorf("team detail = %q", d)
+	}
+	if f := byName[`Connection github #1 "GitHub - my-org"`].Fix; !strings.Contains(f, "--plugin github --id 1") {
+		t.Errorf("connection fix = %q", f)
+	}
+	if d := byName["State file"].Detail; !strings.Contains(d, `connection github #5 "deleted" is not in DevLake`) || strings.Contains(d, "team") {
+		t.Errorf("state detail = %q", d)
+	}
+	if report.Summary[doctorFail] < 4 {
+		t.Errorf("summary = %v", report.Summary)
+	}
+}
+
+func TestDoctorBackendMigrationPending(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusPreconditionRequired)
+	}))
+	defer srv.Close()
+
+	env := &doctorEnv{backend: srv.URL}
+	checks := doctorBackendChecks(env)
+	if len(checks) != 2 || checks[1].Status != doctorFail || !strings.Contains(checks[1].Fix, srv.URL+"/proceed-db-migration") {
+		t.Errorf("checks = %+v", checks)
+	}
+	if env.client != nil {
+		t.Error("API checks should not run while a migration is pending")
+	}
+}
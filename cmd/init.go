@@ -36,7 +36,7 @@ scope, configure project) for fine-grained control.`,
 		RunE: runInit,
 	}
 
-	cmd.Flags().StringVar(&initToken, "token", "", "Personal access token (avoids interactive prompt)")
+	cmd.Flags().StringVar(&initToken, "token", "", "Personal access token (avoids interactive prompt; accepts env:, file:, cmd:, azkv: references)")
 	cmd.Flags().StringVar(&initEnvFile, "env-file", ".devlake.env", "Path to env file containing PAT")
 	cmd.Flags().BoolVar(&initSkipClean, "skip-cleanup", false, "Do not delete .devlake.env after setup")
 
@@ -47,7 +47,7 @@ func runInit(cmd *cobra.Command, args []string) error {
 	printBanner("DevLake — Setup Wizard\n  Deploy → Connect → Scope → Project")
 
 	// ── Phase 1: Deploy ──────────────────────────────────────────
-	targets := []string{"local - Docker Compose on this machine", "azure - Azure Container Apps"}
+	targets := []string{"local - Docker Compose on this machine", "azure - Azure Container Instances or Container Apps"}
 	choice := prompt.Select("\nWhere would you like to deploy DevLake?", targets)
 	if choice == "" {
 		return fmt.Errorf("deployment target is required")
@@ -130,15 +130,14 @@ func runInit(cmd *cobra.Command, args []string) error {
 }
 
 // runInitLocal handles the local deployment path of the wizard.
-// It delegates entirely to runDeployLocal which owns the image-choice prompt,
+// It delegates entirely to deployLocal which owns the image-choice prompt,
 // download/clone, and container startup.
 func runInitLocal(cmd *cobra.Command, args []string) error {
 	deployLocalDir = "."
 	deployLocalVersion = "latest"
-	deployLocalQuiet = true
-	deployLocalSource = "" // let runDeployLocal prompt interactively
+	deployLocalSource = "" // let deployLocal prompt interactively
 
-	if err := runDeployLocal(cmd, args); err != nil {
+	if err := deployLocal(cmd, deployLocalOpts{Quiet: true}); err != nil {
 		return err
 	}
 
@@ -153,12 +152,10 @@ func runInitLocal(cmd *cobra.Command, args []string) error {
 }
 
 // runInitAzure handles the Azure deployment path of the wizard.
-// It delegates entirely to runDeployAzure which owns the image-choice prompt
+// It delegates entirely to deployAzure which owns the image-choice prompt
 // and Azure provisioning.
 func runInitAzure(cmd *cobra.Command, args []string) error {
-	deployAzureQuiet = true
-
-	if err := runDeployAzure(cmd, args); err != nil {
+	if err := deployAzure(cmd, deployAzureOpts{Quiet: true}); err != nil {
 		return err
 	}
 	if deployAzureDir != "" {
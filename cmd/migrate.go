@@ -0,0 +1,250 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/DevExpGBB/gh-devlake/internal/backup"
+	"github.com/DevExpGBB/gh-devlake/internal/devlake"
+	"github.com/DevExpGBB/gh-devlake/internal/prompt"
+	"github.com/spf13/cobra"
+)
+
+type migrateOpts struct {
+	From          string
+	To            string
+	StateFile     string
+	Dir           string
+	ResourceGroup string
+	Location      string
+	BaseName      string
+	Version       string
+	ClientIP      string
+	Yes           bool
+}
+
+func newMigrateCmd() *cobra.Command {
+	var opts migrateOpts
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Move a DevLake instance between local Docker and Azure",
+		Long: `Moves a DevLake instance to a new deployment target, keeping its data.
+
+The source database is dumped, a new deployment is created on the target with
+official images, the dump is loaded into it and the database is migrated to
+the target's release. The source ENCRYPTION_SECRET is reused on the target so
+connection tokens stored in the database still decrypt.
+
+Connections and project recorded in the source state file are copied into the
+target state file, which holds the target's endpoints. The source deployment
+is left running; remove it with 'gh devlake cleanup' once the target checks
+out. Data collected by the source after the dump is not copied.
+
+Examples:
+  gh devlake migrate --from local --to azure --resource-group devlake-rg --location eastus
+  gh devlake migrate --from azure --to local --dir ./devlake-local
+  gh devlake migrate --from azure --to local --version v1.0.2 --yes`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runMigrate(opts)
+		},
+	}
+	cmd.Flags().StringVar(&opts.From, "from", "", "Source deployment: local or azure")
+	cmd.Flags().StringVar(&opts.To, "to", "", "Target deployment: local or azure")
+	cmd.Flags().StringVar(&opts.StateFile, "state-file", "", "Path to the source state file (auto-detected if omitted)")
+	cmd.Flags().StringVar(&opts.Dir, "dir", ".", "Directory for the target's files and state")
+	cmd.Flags().StringVar(&opts.ResourceGroup, "resource-group", "", "Azure Resource Group name (azure target)")
+	cmd.Flags().StringVar(&opts.Location, "location", "", "Azure region (azure target)")
+	cmd.Flags().StringVar(&opts.BaseName, "base-name", "devlake", "Base name for Azure resources (azure target)")
+	cmd.Flags().StringVar(&opts.Version, "version", "latest", "DevLake version to deploy (local target)")
+	cmd.Flags().StringVar(&opts.ClientIP, "client-ip", "", "Public IP to admit through the Azure MySQL firewall (default: looked up at api.ipify.org)")
+	cmd.Flags().BoolVarP(&opts.Yes, "yes", "y", false, "Skip the confirmation prompt")
+	return cmd
+}
+
+// validateMigrateDirection checks --from/--to name two different targets.
+func validateMigrateDirection(from, to string) error {
+	for _, f := range []struct{ flag, value string }{{"--from", from}, {"--to", to}} {
+		if f.value == "" {
+			return fmt.Errorf("%s is required (local or azure)", f.flag)
+		}
+		if f.value != "local" && f.value != "azure" {
+			return fmt.Errorf("%s must be local or azure, got %q", f.flag, f.value)
+		}
+	}
+	if from == to {
+		return fmt.Errorf("--from and --to are both %s — use 'gh devlake backup' and 'restore' to copy between deployments of the same kind", from)
+	}
+	return nil
+}
+
+// deploymentMarker returns the file that marks a deployment of the given
+// kind (local or azure) in dir, or "" when there is none.
+func deploymentMarker(kind, dir string) string {
+	names := []string{".devlake-azure.json"}
+	if kind == "local" {
+		names = []string{".devlake-local.json", "docker-compose.yml", "docker-compose-dev.yml"}
+	}
+	for _, name := range names {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return name
+		}
+	}
+	return ""
+}
+
+func runMigrate(opts migrateOpts) error {
+	if err := validateMigrateDirection(opts.From, opts.To); err != nil {
+		return err
+	}
+	if opts.Dir == "" {
+		opts.Dir = "."
+	}
+	if existing := deploymentMarker(opts.To, opts.Dir); existing != "" {
+		return fmt.Errorf("%s already has a %s deployment (%s) — choose another --dir, or load into it with 'gh devlake backup' and 'restore'",
+			opts.Dir, opts.To, existing)
+	}
+	found := deploymentMarker(opts.From, ".") != ""
+	if opts.StateFile != "" {
+		found = detectDeploymentMode(false, false, opts.StateFile) == opts.From
+	}
+	if !found {
+		return fmt.Errorf("no %s deployment found — run from the source deployment's directory or pass --state-file", opts.From)
+	}
+
+	printBanner(fmt.Sprintf("DevLake — Migrate (%s → %s)", opts.From, opts.To))
+	fmt.Println("\nThis will:")
+	fmt.Printf("  1. Dump the %s database\n", opts.From)
+	fmt.Printf("  2. Deploy DevLake to %s with the same ENCRYPTION_SECRET\n", opts.To)
+	fmt.Println("  3. Load the dump and migrate it to the target's release")
+	fmt.Printf("  4. Write the target state file in %s\n", opts.Dir)
+	fmt.Printf("\nThe %s deployment is left running.\n", opts.From)
+	if !opts.Yes && !prompt.Confirm("\nContinue?") {
+		fmt.Println("Migration cancelled.")
+		return nil
+	}
+
+	workDir, err := os.MkdirTemp("", "devlake-migrate-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(workDir)
+
+	// ── Dump the source into workDir, laid out like an extracted backup ──
+	dumpPath := filepath.Join(workDir, backup.DumpName)
+	var entries []backup.Entry
+	if opts.From == "local" {
+		entries, _, err = dumpLocal(opts.StateFile, dumpPath, os.Stdout)
+	} else {
+		entries, _, err = dumpAzure(opts.StateFile, opts.ClientIP, dumpPath, os.Stdout)
+	}
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.Data == nil {
+			continue
+		}
+		if err := os.WriteFile(filepath.Join(workDir, e.Name), e.Data, 0600); err != nil {
+			return err
+		}
+	}
+	secret := archiveEncryptionSecret(workDir)
+	if secret == "" {
+		return fmt.Errorf("could not read the source ENCRYPTION_SECRET — connection tokens would not decrypt on the target")
+	}
+
+	var statePath string
+	if opts.To == "azure" {
+		statePath, err = migrateToAzure(opts, workDir, secret)
+	} else {
+		statePath, err = migrateToLocal(opts, workDir, secret)
+	}
+	if err != nil {
+		return err
+	}
+
+	if n, err := mergeRestoredState(statePath, workDir); err != nil {
+		fmt.Printf("   ⚠️  Could not update state file: %v\n", err)
+	} else if n > 0 {
+		fmt.Printf("\n💾 Copied %d connection(s) into %s\n", n, filepath.Base(statePath))
+	}
+
+	printBanner("✅ Migration complete")
+	if state, err := devlake.LoadState(statePath); err == nil && state != nil {
+		fmt.Println("\nEndpoints:")
+		fmt.Printf("  Backend API: %s\n", state.Endpoints.Backend)
+		fmt.Printf("  Config UI:   %s\n", state.Endpoints.ConfigUI)
+		fmt.Printf("  Grafana:     %s\n", state.Endpoints.Grafana)
+	}
+	fmt.Println("\nNext steps:")
+	fmt.Printf("  • Check the new deployment: gh devlake status (from %s)\n", opts.Dir)
+	fmt.Printf("  • When satisfied, remove the source: gh devlake cleanup --%s\n", opts.From)
+	return nil
+}
+
+// migrateToAzure deploys the official Bicep template with the source secret
+// and loads the dump into its Flexible Server.
+func migrateToAzure(opts migrateOpts, workDir, secret string) (string, error) {
+	deployCmd := newDeployAzureCmd()
+	for flag, v := range map[string]string{
+		"official":       "true",
+		"resource-group": opts.ResourceGroup,
+		"location":       opts.Location,
+		"base-name":      opts.BaseName,
+		"dir":            opts.Dir,
+	} {
+		if v == "" {
+			continue
+		}
+		if err := deployCmd.Flags().Set(flag, v); err != nil {
+			return "", err
+		}
+	}
+	if err := deployAzure(deployCmd, deployAzureOpts{Quiet: true, EncryptionSecret: secret}); err != nil {
+		return "", err
+	}
+	return restoreAzure(filepath.Join(deployAzureDir, ".devlake-azure.json"), opts.ClientIP, workDir, secret)
+}
+
+// migrateToLocal deploys the official release with Docker Compose, loads the
+// dump (which also restores the source secret into .env) and records the
+// local endpoints in the state file.
+func migrateToLocal(opts migrateOpts, workDir, secret string) (string, error) {
+	deployCmd := newDeployLocalCmd()
+	for flag, v := range map[string]string{
+		"source":  "official",
+		"dir":     opts.Dir,
+		"version": opts.Version,
+	} {
+		if err := deployCmd.Flags().Set(flag, v); err != nil {
+			return "", err
+		}
+	}
+	if err := deployLocal(deployCmd, deployLocalOpts{Quiet: true}); err != nil {
+		return "", err
+	}
+
+	absDir, _ := filepath.Abs(opts.Dir)
+	statePath, err := restoreLocal(filepath.Join(absDir, ".devlake-local.json"), workDir, secret)
+	if err != nil {
+		return "", err
+	}
+
+	backendURL, err := waitForReadyAny(localBackendCandidates(absDir), 3, 5*time.Second)
+	if err != nil {
+		return statePath, nil
+	}
+	state, _ := devlake.LoadState(statePath)
+	if state == nil {
+		state = &devlake.State{DeployedAt: time.Now().Format(time.RFC3339), Method: "local"}
+	}
+	grafanaURL, configUIURL := localCompanionURLsIn(absDir, backendURL)
+	state.Endpoints.Backend, state.Endpoints.Grafana, state.Endpoints.ConfigUI = backendURL, grafanaURL, configUIURL
+	state.Version = backendVersion(backendURL)
+	if err := devlake.SaveState(statePath, state); err != nil {
+		fmt.Printf("   ⚠️  Could not update state file: %v\n", err)
+	}
+	return statePath, nil
+}
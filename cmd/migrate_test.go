@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateMigrateDirection(t *testing.T) {
+	tests := []struct {
+		from, to string
+		wantErr  bool
+	}{
+		{"local", "azure", false},
+		{"azure", "local", false},
+		{"local", "local", true},
+		{"azure", "azure", true},
+		{"aws", "local", true},
+		{"local", "", true},
+		{"", "azure", true},
+	}
+	for _, tt := range tests {
+		err := validateMigrateDirection(tt.from, tt.to)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("validateMigrateDirection(%q, %q) error = %v, wantErr %v", tt.from, tt.to, err, tt.wantErr)
+		}
+	}
+}
+
+func TestDeploymentMarker(t *testing.T) {
+	dir := t.TempDir()
+	if got := deploymentMarker("local", dir); got != "" {
+		t.Errorf("empty dir local = %q", got)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "docker-compose.yml"), []byte("services: {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := deploymentMarker("local", dir); got != "docker-compose.yml" {
+		t.Errorf("local = %q, want docker-compose.yml", got)
+	}
+	if got := deploymentMarker("azure", dir); got != "" {
+		t.Errorf("azure with compose only = %q, want empty", got)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".devlake-azure.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := deploymentMarker("azure", dir); got != ".devlake-azure.json" {
+		t.Errorf("azure = %q, want .devlake-azure.json", got)
+	}
+}
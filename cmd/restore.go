@@ -0,0 +1,367 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+
+	"github.com/DevExpGBB/gh-devlake/internal/backup"
+	"github.com/DevExpGBB/gh-devlake/internal/devlake"
+	dockerpkg "github.com/DevExpGBB/gh-devlake/internal/docker"
+	"github.com/DevExpGBB/gh-devlake/internal/prompt"
+	"github.com/spf13/cobra"
+)
+
+type restoreOpts struct {
+	Archive    string
+	Passphrase string
+	Azure      bool
+	Local      bool
+	StateFile  string
+	ClientIP   string
+	Yes        bool
+}
+
+func newRestoreCmd() *cobra.Command {
+	var opts restoreOpts
+	cmd := &cobra.Command{
+		Use:   "restore <archive>",
+		Short: "Restore the DevLake database from a backup archive",
+		Long: `Restores a backup created by 'gh devlake backup' into the deployment in the
+current directory. The target can differ from the source: a local backup can
+be restored into an Azure deployment and vice versa.
+
+The DevLake database is replaced. The backend is stopped while the dump loads,
+then restarted and migrated (so backups from older releases upgrade in place).
+
+Connection tokens in the database are encrypted with the ENCRYPTION_SECRET of
+the source deployment. For local targets the .env secret is replaced with the
+one from the backup; for Azure targets a mismatch is reported.
+
+Connections and project recorded in the backup's state file are copied into
+the target's state file.
+
+Examples:
+  gh devlake restore devlake-backup-local-20260101-120000.tar.gz
+  gh devlake restore backup.tar.gz --azure --passphrase env:BACKUP_PASS --yes`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Archive = args[0]
+			return runRestore(opts)
+		},
+	}
+	cmd.Flags().StringVar(&opts.Passphrase, "passphrase", "", "Passphrase for encrypted backups (accepts env:, file:, cmd:, azkv: references)")
+	cmd.Flags().BoolVar(&opts.Azure, "azure", false, "Force Azure mode")
+	cmd.Flags().BoolVar(&opts.Local, "local", false, "Force local (Docker Compose) mode")
+	cmd.Flags().StringVar(&opts.StateFile, "state-file", "", "Path to state file (auto-detected if omitted)")
+	cmd.Flags().StringVar(&opts.ClientIP, "client-ip", "", "Public IP to admit through the Azure MySQL firewall (default: looked up at api.ipify.org)")
+	cmd.Flags().BoolVarP(&opts.Yes, "yes", "y", false, "Skip the confirmation prompt")
+	return cmd
+}
+
+func runRestore(opts restoreOpts) error {
+	manifest, err := backup.ReadManifest(opts.Archive)
+	if err != nil {
+		return err
+	}
+	mode := detectDeploymentMode(opts.Azure, opts.Local, opts.StateFile)
+	if mode == "" {
+		return fmt.Errorf("no deployment found — no state file or docker-compose.yml in current directory\nRun 'gh devlake deploy' first, then restore into it")
+	}
+
+	printBanner(fmt.Sprintf("DevLake — Restore (%s)", mode))
+	fmt.Printf("\n📋 Backup: %s\n", opts.Archive)
+	fmt.Printf("   Created:   %s\n", manifest.CreatedAt)
+	fmt.Printf("   Source:    %s\n", manifest.Method)
+	if manifest.DevLakeVersion != "" {
+		fmt.Printf("   Version:   %s\n", manifest.DevLakeVersion)
+	}
+	fmt.Printf("   Encrypted: %v\n", manifest.Encrypted)
+
+	passphrase, err := backupPassphrase(opts.Passphrase)
+	if err != nil {
+		return err
+	}
+	if manifest.Encrypted && passphrase == "" {
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			return fmt.Errorf("%w — pass --passphrase or set $%s", backup.ErrPassphraseRequired, backupPassphraseEnv)
+		}
+		passphrase = prompt.ReadSecret("Backup passphrase")
+	}
+
+	if !opts.Yes {
+		fmt.Printf("\n⚠️  This replaces the DevLake database of the %s deployment.\n", mode)
+		if !prompt.Confirm("Continue?") {
+			fmt.Println("Restore cancelled.")
+			return nil
+		}
+	}
+
+	workDir, err := os.MkdirTemp("", "devlake-restore-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(workDir)
+	if _, err := backup.Extract(opts.Archive, workDir, passphrase); err != nil {
+		if errors.Is(err, backup.ErrBadPassphrase) {
+			return fmt.Errorf("could not decrypt backup — %w", err)
+		}
+		return err
+	}
+	secret := archiveEncryptionSecret(workDir)
+
+	var statePath string
+	switch mode {
+	case "local":
+		statePath, err = restoreLocal(opts.StateFile, workDir, secret)
+	case "azure":
+		statePath, err = restoreAzure(opts.StateFile, opts.ClientIP, workDir, secret)
+	}
+	if err != nil {
+		return err
+	}
+
+	if n, err := mergeRestoredState(statePath, workDir); err != nil {
+		fmt.Printf("   ⚠️  Could not update state file: %v\n", err)
+	} else if n > 0 {
+		fmt.Printf("\n💾 Copied %d connection(s) from the backup into %s\n", n, filepath.Base(statePath))
+	}
+
+	printBanner("✅ Restore complete")
+	return nil
+}
+
+// restoreLocal loads the dump into the compose mysql service and restores
+// the ENCRYPTION_SECRET into .env. Returns the target state file path.
+func restoreLocal(stateFile, workDir, secret string) (string, error) {
+	dir := deploymentDir(stateFile)
+	if stateFile == "" {
+		stateFile = filepath.Join(dir, ".devlake-local.json")
+	}
+
+	fmt.Println("\n🐳 Checking Docker...")
+	if err := dockerpkg.CheckAvailable(); err != nil {
+		return "", fmt.Errorf("Docker is not available: %w\nMake sure Docker Desktop or the Docker daemon is running", err)
+	}
+
+	fmt.Println("\n🗄️  Starting mysql and stopping the backend...")
+	if err := dockerpkg.ComposeUp(dir, false, "mysql"); err != nil {
+		return "", err
+	}
+	if err := waitForComposeMySQL(dir, 30, 2*time.Second); err != nil {
+		return "", err
+	}
+	if err := dockerpkg.ComposeStop(dir, "devlake"); err != nil {
+		return "", err
+	}
+
+	fmt.Println("\n📥 Loading database dump...")
+	dump, err := os.Open(filepath.Join(workDir, backup.DumpName))
+	if err != nil {
+		return "", err
+	}
+	defer dump.Close()
+	if err := dockerpkg.ComposeExec(dir, "mysql", dump, io.Discard, backup.ComposeLoadCommand()...); err != nil {
+		_ = dockerpkg.ComposeUp(dir, false, "devlake")
+		return "", err
+	}
+	fmt.Println("   ✅ Database restored")
+
+	if secret != "" {
+		envPath := filepath.Join(dir, ".env")
+		data, err := os.ReadFile(envPath)
+		if err != nil && !os.IsNotExist(err) {
+			return "", err
+		}
+		if updated, changed := setEnvValue(string(data), "ENCRYPTION_SECRET", secret); changed {
+			if err := os.WriteFile(envPath, []byte(updated), 0644); err != nil {
+				return "", err
+			}
+			fmt.Println("   ✅ ENCRYPTION_SECRET restored from backup")
+		}
+	}
+
+	fmt.Println("\n🐳 Restarting containers...")
+	if err := dockerpkg.ComposeUp(dir, false); err != nil {
+		return "", err
+	}
+	backendURL, err := waitForReadyAny(localBackendCandidates(dir), 18, 10*time.Second)
+	if err != nil {
+		return "", fmt.Errorf("DevLake not ready after restore — check: docker compose logs devlake: %w", err)
+	}
+	migrateAfterRestore(backendURL)
+	return stateFile, nil
+}
+
+// restoreAzure loads the dump into the Flexible Server with the backend
+// container stopped. Returns the target state file path.
+func restoreAzure(stateFile, clientIP, workDir, secret string) (string, error) {
+	if stateFile == "" {
+		stateFile = ".devlake-azure.json"
+	}
+	state, _, err := loadAzureState(stateFile)
+	if err != nil {
+		return "", err
+	}
+	conn, kvSecret, err := azureMySQLConn(state, os.Stdout)
+	if err != nil {
+		return "", err
+	}
+
+	backend := ""
+	for _, c := range state.Resources.Containers {
+		if strings.Contains(c, "-backend-") {
+			backend = c
+		}
+	}
+	if backend != "" {
+		fmt.Printf("\n⏸️  Stopping %s...\n", backend)
+		if err := state.stopContainer(backend); err != nil {
+			return "", err
+		}
+	}
+
+	fmt.Println("\n📥 Loading database dump into Azure MySQL...")
+	err = withAzureMySQLAccess(state, "gh-devlake-restore", clientIP, os.Stdout, func() error {
+		dump, err := os.Open(filepath.Join(workDir, backup.DumpName))
+		if err != nil {
+			return err
+		}
+		defer dump.Close()
+		return backup.LoadRemote(conn, dump)
+	})
+	if err != nil {
+		if backend != "" {
+			fmt.Printf("   Restarting %s...\n", backend)
+			_ = state.startContainer(backend)
+		}
+		return "", err
+	}
+	fmt.Println("   ✅ Database restored")
+
+	if secret != "" && secret != kvSecret {
+		fmt.Println("\n   ⚠️  The backup's ENCRYPTION_SECRET differs from this deployment's Key Vault secret.")
+		fmt.Println("      Stored connection tokens will not decrypt. Re-enter them with:")
+		fmt.Println("      gh devlake configure connection update --plugin <plugin> --id <id> --token <token>")
+	}
+
+	if backend != "" {
+		fmt.Printf("\n▶️  Starting %s...\n", backend)
+		if err := state.startContainer(backend); err != nil {
+			return "", err
+		}
+	}
+	if state.Endpoints.Backend != "" {
+		fmt.Println("\n⏳ Waiting for backend...")
+		if err := waitForReady(state.Endpoints.Backend, 30, 10*time.Second); err != nil {
+			return "", err
+		}
+		migrateAfterRestore(state.Endpoints.Backend)
+	}
+	return stateFile, nil
+}
+
+// migrateAfterRestore runs the database migration so an older dump is brought
+// up to the running release's schema.
+func migrateAfterRestore(backendURL string) {
+	fmt.Println("\n🔄 Triggering database migration...")
+	if err := devlake.NewClient(backendURL).TriggerMigration(); err != nil {
+		fmt.Printf("   ⚠️  Migration may need manual trigger: %v\n", err)
+		return
+	}
+	if err := waitForMigration(backendURL, 60, 5*time.Second); err != nil {
+		fmt.Printf("   ⚠️  %v\n", err)
+	}
+}
+
+// waitForComposeMySQL polls until the compose mysql service accepts connections.
+func waitForComposeMySQL(dir string, maxAttempts int, interval time.Duration) error {
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if err = dockerpkg.ComposeExec(dir, "mysql", nil, io.Discard, backup.ComposePingCommand()...); err == nil {
+			return nil
+		}
+		time.Sleep(interval)
+	}
+	return fmt.Errorf("mysql service not ready after %d attempts: %w", maxAttempts, err)
+}
+
+// archiveEncryptionSecret returns the ENCRYPTION_SECRET from an extracted
+// archive: the encryption-secret entry (Azure) or the .env entry (local).
+func archiveEncryptionSecret(dir string) string {
+	if data, err := os.ReadFile(filepath.Join(dir, backup.SecretName)); err == nil {
+		return strings.TrimSpace(string(data))
+	}
+	data, err := os.ReadFile(filepath.Join(dir, backup.EnvName))
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if v, ok := strings.CutPrefix(strings.TrimSpace(line), "ENCRYPTION_SECRET="); ok {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
+
+// setEnvValue sets KEY=value in .env content, replacing an existing line or
+// appending one. Reports whether the content changed.
+func setEnvValue(content, key, value string) (string, bool) {
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		if envKey(line) == key {
+			want := key + "=" + value
+			if strings.TrimSpace(line) == want {
+				return content, false
+			}
+			lines[i] = want
+			return strings.Join(lines, "\n"), true
+		}
+	}
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return content + key + "=" + value + "\n", true
+}
+
+// mergeRestoredState copies connections and project from the archived state
+// file into the target state file, keeping the target's endpoints and
+// deployment metadata. Returns the number of connections copied.
+func mergeRestoredState(targetPath, workDir string) (int, error) {
+	var archived *devlake.State
+	for _, name := range []string{".devlake-local.json", ".devlake-azure.json"} {
+		s, err := devlake.LoadState(filepath.Join(workDir, name))
+		if err != nil {
+			return 0, err
+		}
+		if s != nil {
+			archived = s
+			break
+		}
+	}
+	if archived == nil || (len(archived.Connections) == 0 && archived.Project == nil) {
+		return 0, nil
+	}
+
+	target, err := devlake.LoadState(targetPath)
+	if err != nil {
+		return 0, err
+	}
+	if target == nil {
+		target = &devlake.State{DeployedAt: time.Now().Format(time.RFC3339), Method: "local"}
+	}
+	target.Connections = archived.Connections
+	target.ConnectionsConfiguredAt = archived.ConnectionsConfiguredAt
+	target.Project = archived.Project
+	target.ScopesConfiguredAt = archived.ScopesConfiguredAt
+	if err := devlake.SaveState(targetPath, target); err != nil {
+		return 0, err
+	}
+	return len(archived.Connections), nil
+}
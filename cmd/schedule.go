@@ -0,0 +1,280 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/DevExpGBB/gh-devlake/internal/azure"
+	"github.com/spf13/cobra"
+)
+
+var (
+	scheduleUp       string
+	scheduleDown     string
+	scheduleTimeZone string
+	scheduleRemove   bool
+	scheduleDryRun   bool
+	scheduleState    string
+)
+
+func newScheduleCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schedule",
+		Short: "Start and stop a deployment on a schedule",
+		Long: `Start and stop a deployment automatically, so it only runs (and costs
+money) when it is needed.
+
+Example:
+  gh devlake schedule azure --up "0 7 * * 1-5" --down "0 20 * * 1-5"`,
+	}
+	cmd.GroupID = "operate"
+	cmd.AddCommand(newScheduleAzureCmd())
+	return cmd
+}
+
+func init() {
+	rootCmd.AddCommand(newScheduleCmd())
+}
+
+func newScheduleAzureCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "azure",
+		Short: "Start and stop an Azure deployment on a cron schedule",
+		Long: `Deploys Logic Apps into the deployment's resource group that start and stop
+the MySQL server and containers recorded in .devlake-azure.json.
+
+--up and --down take five-field cron expressions (minute hour day-of-month
+month day-of-week). Day-of-month and month must be *; minutes must be fixed.
+Times are in --time-zone, a Windows time zone name such as
+"W. Europe Standard Time" or "Eastern Standard Time".
+
+The Logic Apps run as managed identities with a custom role that can only
+start and stop the MySQL server and containers and update private DNS
+records in the resource group. Deploying them requires permission to create
+roles and role assignments (Owner or User Access Administrator).
+
+For a private deployment, each scheduled start also points the private DNS
+records Config UI and the Application Gateway use at the new container IPs,
+like 'gh devlake start' does.
+
+Running the command again replaces the schedule. --remove deletes it.
+
+Example:
+  gh devlake schedule azure --up "0 7 * * 1-5" --down "0 20 * * 1-5"
+  gh devlake schedule azure --down "0 19 * * *" --time-zone "W. Europe Standard Time"
+  gh devlake schedule azure --up "0 7 * * mon-fri" --down "0 20 * * mon-fri" --dry-run
+  gh devlake schedule azure --remove`,
+		Args: cobra.NoArgs,
+		RunE: runScheduleAzure,
+	}
+
+	cmd.Flags().StringVar(&scheduleUp, "up", "", "Cron expression to start the deployment at")
+	cmd.Flags().StringVar(&scheduleDown, "down", "", "Cron expression to stop the deployment at")
+	cmd.Flags().StringVar(&scheduleTimeZone, "time-zone", "UTC", "Windows time zone name the cron times are in")
+	cmd.Flags().BoolVar(&scheduleRemove, "remove", false, "Delete the schedule")
+	cmd.Flags().BoolVar(&scheduleDryRun, "dry-run", false, "Print the rendered Logic App workflows without deploying them")
+	cmd.Flags().StringVar(&scheduleState, "state-file", "", "Path to state file (default: .devlake-azure.json)")
+
+	return cmd
+}
+
+func runScheduleAzure(cmd *cobra.Command, args []string) error {
+	stateFile := scheduleState
+	if stateFile == "" {
+		stateFile = ".devlake-azure.json"
+	}
+	state, err := loadAzureStateData(stateFile)
+	if err != nil {
+		return err
+	}
+	if state.Suffix == "" {
+		state.Suffix = azure.Suffix(state.ResourceGroup)
+	}
+	if state.BaseName == "" {
+		// State written before baseName was recorded: <base>mysql<suffix>
+		state.BaseName = strings.TrimSuffix(state.Resources.MySQL, "mysql"+state.Suffix)
+	}
+
+	if scheduleRemove {
+		if scheduleUp != "" || scheduleDown != "" {
+			return fmt.Errorf("--remove cannot be combined with --up or --down")
+		}
+		return removeAzureSchedule(state, stateFile)
+	}
+
+	target := azure.ScheduleTarget{
+		SubscriptionID: state.SubscriptionID,
+		ResourceGroup:  state.ResourceGroup,
+		Runtime:        state.Runtime,
+		MySQL:          state.Resources.MySQL,
+		Containers:     state.Resources.Containers,
+	}
+	if state.Private && state.Runtime != azure.RuntimeACA {
+		// The runbook must re-point the DNS records the gateway and Config UI
+		// use, which older private deployments do not have.
+		zone := state.Resources.Network.ServiceZone
+		if zone == "" && scheduleUp != "" {
+			return fmt.Errorf("this private deployment reaches its containers by private IP, which changes on every scheduled start — run 'gh devlake deploy azure --update' once to switch it to DNS names, then schedule it")
+		}
+		target.ServiceZone = zone
+		target.ServiceRecords = map[string]string{}
+		for _, c := range state.Resources.Containers {
+			target.ServiceRecords[c] = azure.ServiceRecord(state.BaseName, state.Suffix, c)
+		}
+	}
+
+	sched := &azure.Schedule{Up: scheduleUp, Down: scheduleDown, TimeZone: scheduleTimeZone}
+	params, err := azure.ScheduleParams(state.BaseName, state.Suffix, sched, target,
+		azure.InstanceTags(state.Sizing.Tags, state.Suffix))
+	if err != nil {
+		return err
+	}
+
+	if scheduleDryRun {
+		var workflows any
+		if err := json.Unmarshal([]byte(params["workflows"]), &workflows); err != nil {
+			return err
+		}
+		out, _ := json.MarshalIndent(workflows, "", "  ")
+		fmt.Println(string(out))
+		return nil
+	}
+
+	printBanner("DevLake Azure Schedule")
+	fmt.Printf("\n📅 %s\n", sched.Describe())
+	fmt.Printf("   Resource Group: %s\n", state.ResourceGroup)
+	if target.ServiceZone != "" && scheduleUp != "" {
+		fmt.Printf("   Each start also points the records in %s at the new container IPs.\n", target.ServiceZone)
+	}
+
+	fmt.Println("\n🔑 Checking Azure login...")
+	if _, err := azure.CheckLogin(); err != nil {
+		return fmt.Errorf("not logged in to Azure CLI — run 'az login' first")
+	}
+	fmt.Println("   ✅ Logged in")
+
+	fmt.Println("\n🚀 Deploying Logic Apps with Bicep...")
+	templatePath, cleanup, err := azure.WriteTemplate(azure.ScheduleTemplate)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if _, err := azure.DeployBicep(state.ResourceGroup, templatePath, params); err != nil {
+		return fmt.Errorf("schedule deployment failed: %w", err)
+	}
+	for _, w := range sched.Workflows {
+		fmt.Printf("   ✅ %s\n", w)
+	}
+
+	if state.Schedule != nil {
+		// Schedules made before the custom role held Contributor on the group
+		if err := azure.RevokeScheduleContributor(sched.Workflows, state.ResourceGroup); err != nil {
+			fmt.Printf("   ⚠️  %v\n", err)
+		}
+
+		// A direction dropped since the last run (e.g. no --up any more)
+		current := map[string]bool{}
+		for _, w := range sched.Workflows {
+			current[w] = true
+		}
+		var stale []string
+		for _, w := range state.Schedule.Workflows {
+			if !current[w] {
+				stale = append(stale, w)
+			}
+		}
+		if len(stale) > 0 {
+			fmt.Printf("\n   Removing %s...\n", strings.Join(stale, ", "))
+			if err := azure.DeleteSchedule(stale, state.ResourceGroup); err != nil {
+				fmt.Printf("   ⚠️  %v\n", err)
+			}
+		}
+	}
+
+	if err := setAzureStateField(stateFile, "schedule", sched); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Could not save state file: %v\n", err)
+	} else {
+		fmt.Printf("\n💾 Schedule saved to %s\n", stateFile)
+	}
+	fmt.Println("\nThe schedule runs in Azure; this machine does not need to be on.")
+	fmt.Println("'gh devlake start' and 'stop' still work in between. Check runs in the portal under the Logic App's Runs history.")
+	return nil
+}
+
+// removeAzureSchedule deletes the schedule's Logic Apps and drops it from the state file.
+func removeAzureSchedule(state *azureStateData, stateFile string) error {
+	if state.Schedule == nil || len(state.Schedule.Workflows) == 0 {
+		fmt.Println("No schedule recorded in " + stateFile + " — nothing to remove.")
+		return nil
+	}
+	fmt.Println("\n🔑 Checking Azure login...")
+	if _, err := azure.CheckLogin(); err != nil {
+		return fmt.Errorf("not logged in to Azure CLI — run 'az login' first")
+	}
+	fmt.Printf("\n🗑️  Deleting %s...\n", strings.Join(state.Schedule.Workflows, ", "))
+	if err := azure.DeleteSchedule(state.Schedule.Workflows, state.ResourceGroup); err != nil {
+		return err
+	}
+	if err := setAzureStateField(stateFile, "schedule", nil); err != nil {
+		return fmt.Errorf("schedule deleted, but the state file could not be updated: %w", err)
+	}
+	fmt.Println("   ✅ Schedule removed")
+	return nil
+}
+
+// loadAzureStateData reads an Azure state file.
+func loadAzureStateData(path string) (*azureStateData, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("state file not found: %s\nUse --state-file to specify the path", path)
+		}
+		return nil, fmt.Errorf("failed to read state file %s: %w", path, err)
+	}
+	var state azureStateData
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("invalid state file: %w", err)
+	}
+	if state.ResourceGroup == "" {
+		return nil, fmt.Errorf("state file %s has no resource group — is this an Azure deployment?", path)
+	}
+	return &state, nil
+}
+
+// setAzureStateField sets one top-level field of a state file, or removes it
+// when value is nil, keeping everything else as written.
+func setAzureStateField(path, key string, value any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	fields := map[string]any{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	if value == nil {
+		delete(fields, key)
+	} else {
+		fields[key] = value
+	}
+	out, err := json.MarshalIndent(fields, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, out, 0644)
+}
+
+// azureScheduleStatus returns the schedule recorded in an Azure state file,
+// or nil when there is none.
+func azureScheduleStatus(stateFile string) *statusSchedule {
+	if stateFile != ".devlake-azure.json" {
+		return nil
+	}
+	state, err := loadAzureStateData(stateFile)
+	if err != nil || state.Schedule == nil {
+		return nil
+	}
+	return &statusSchedule{Schedule: *state.Schedule, Description: state.Schedule.Describe()}
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DevExpGBB/gh-devlake/internal/azure"
+)
+
+const scheduleTestState = `{
+  "method": "bicep",
+  "resourceGroup": "devlake-rg",
+  "region": "eastus",
+  "subscriptionId": "00000000-0000-0000-0000-000000000001",
+  "suffix": "abc12",
+  "resources": {
+    "mysql": "devlakemysqlabc12",
+    "containers": ["devlake-backend-abc12", "devlake-grafana-abc12", "devlake-ui-abc12"]
+  },
+  "connections": [{"plugin": "github", "connectionId": 1, "name": "GitHub"}]
+}`
+
+func TestScheduleAzureDryRun(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), ".devlake-azure.json")
+	if err := os.WriteFile(stateFile, []byte(scheduleTestState), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := newScheduleAzureCmd()
+	for flag, v := range map[string]string{
+		"up": "0 7 * * 1-5", "down": "0 20 * * 1-5", "dry-run": "true", "state-file": stateFile,
+	} {
+		if err := cmd.Flags().Set(flag, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var runErr error
+	out := captureStdout(func() { runErr = runScheduleAzure(cmd, nil) })
+	if runErr != nil {
+		t.Fatal(runErr)
+	}
+
+	var workflows []struct {
+		Name    string         `json:"name"`
+		Actions map[string]any `json:"actions"`
+	}
+	if err := json.Unmarshal([]byte(out), &workflows); err != nil {
+		t.Fatalf("dry run output is not JSON: %v\n%s", err, out)
+	}
+	// The base name is derived from the MySQL name for older state files
+	if len(workflows) != 2 || workflows[0].Name != "devlake-up-abc12" || workflows[1].Name != "devlake-down-abc12" {
+		t.Fatalf("workflows = %+v", workflows)
+	}
+	if len(workflows[1].Actions) != 4 || workflows[1].Actions["stop_devlake_grafana_abc12"] == nil {
+		t.Errorf("down actions = %v", workflows[1].Actions)
+	}
+
+	// A dry run leaves the state file alone
+	data, _ := os.ReadFile(stateFile)
+	if strings.Contains(string(data), "schedule") {
+		t.Error("dry run wrote the schedule to the state file")
+	}
+}
+
+func TestScheduleAzureRequiresDirection(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), ".devlake-azure.json")
+	if err := os.WriteFile(stateFile, []byte(scheduleTestState), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := newScheduleAzureCmd()
+	_ = cmd.Flags().Set("state-file", stateFile)
+	if err := runScheduleAzure(cmd, nil); err == nil || !strings.Contains(err.Error(), "--up, --down") {
+		t.Errorf("error = %v", err)
+	}
+
+	cmd = newScheduleAzureCmd()
+	_ = cmd.Flags().Set("state-file", stateFile)
+	_ = cmd.Flags().Set("remove", "true")
+	_ = cmd.Flags().Set("up", "0 7 * * *")
+	if err := runScheduleAzure(cmd, nil); err == nil || !strings.Contains(err.Error(), "cannot be combined") {
+		t.Errorf("--remove with --up error = %v", err)
+	}
+}
+
+func TestSetAzureStateFieldAndStatus(t *testing.T) {
+	dir := t.TempDir()
+	origWd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(origWd) })
+	if err := os.WriteFile(".devlake-azure.json", []byte(scheduleTestState), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if azureScheduleStatus(".devlake-azure.json") != nil {
+		t.Error("schedule reported before one was set")
+	}
+	sched := &azure.Schedule{Up: "0 7 * * 1-5", Down: "0 20 * * 1-5", TimeZone: "UTC", Workflows: []string{"devlake-up-abc12", "devlake-down-abc12"}}
+	if err := setAzureStateField(".devlake-azure.json", "schedule", sched); err != nil {
+		t.Fatal(err)
+	}
+	got := azureScheduleStatus(".devlake-azure.json")
+	if got == nil || got.Description != "up 07:00 Mon–Fri, down 20:00 Mon–Fri (UTC)" {
+		t.Fatalf("status = %+v", got)
+	}
+
+	state, err := loadAzureStateData(".devlake-azure.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.SubscriptionID == "" || len(state.Resources.Containers) != 3 {
+		t.Errorf("state fields lost: %+v", state)
+	}
+	data, _ := os.ReadFile(".devlake-azure.json")
+	if !strings.Contains(string(data), `"connections"`) {
+		t.Error("connections were dropped")
+	}
+
+	if err := setAzureStateField(".devlake-azure.json", "schedule", nil); err != nil {
+		t.Fatal(err)
+	}
+	if azureScheduleStatus(".devlake-azure.json") != nil {
+		t.Error("schedule still reported after removal")
+	}
+}
+
+func TestScheduleAzurePrivate(t *testing.T) {
+	dir := t.TempDir()
+	write := func(network string) string {
+		state := strings.Replace(scheduleTestState, `"resources": {`, `"private": true, "baseName": "devlake", "resources": {`+network, 1)
+		path := filepath.Join(dir, ".devlake-azure.json")
+		if err := os.WriteFile(path, []byte(state), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	run := func(stateFile string, flags map[string]string) (string, error) {
+		cmd := newScheduleAzureCmd()
+		flags["dry-run"], flags["state-file"] = "true", stateFile
+		for flag, v := range flags {
+			if err := cmd.Flags().Set(flag, v); err != nil {
+				t.Fatal(err)
+			}
+		}
+		var runErr error
+		out := captureStdout(func() { runErr = runScheduleAzure(cmd, nil) })
+		return out, runErr
+	}
+
+	// Without the service zone, a scheduled start would strand the gateway
+	older := write("")
+	if _, err := run(older, map[string]string{"up": "0 7 * * *"}); err == nil || !strings.Contains(err.Error(), "deploy azure --update") {
+		t.Errorf("older private deployment error = %v", err)
+	}
+	if _, err := run(older, map[string]string{"down": "0 20 * * *"}); err != nil {
+		t.Errorf("a stop-only schedule should be allowed: %v", err)
+	}
+
+	out, err := run(write(`"network": {"serviceZone": "devlakeabc12.internal"},`), map[string]string{"up": "0 7 * * *"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "dns_devlake_ui_abc12") || !strings.Contains(out, "/privateDnsZones/devlakeabc12.internal/A/backend") {
+		t.Errorf("up workflow does not refresh the records:\n%s", out)
+	}
+}
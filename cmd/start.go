@@ -10,16 +10,28 @@ import (
 	"time"
 
 	azurepkg "github.com/DevExpGBB/gh-devlake/internal/azure"
+	"github.com/DevExpGBB/gh-devlake/internal/devlake"
 	dockerpkg "github.com/DevExpGBB/gh-devlake/internal/docker"
+	"github.com/DevExpGBB/gh-devlake/internal/instance"
 	"github.com/spf13/cobra"
 )
 
 var (
-	startService string
-	startNoWait  bool
-	startAzure   bool
-	startLocal   bool
-	startState   string
+	startService  string
+	startNoWait   bool
+	startAzure    bool
+	startLocal    bool
+	startK8s      bool
+	startState    string
+	startInstance string
+)
+
+// Azure calls refreshServiceRecord and runAzureStart make; tests replace them.
+var (
+	serviceGroupIP      = azurepkg.ContainerGroupIP
+	serviceRecordIP     = azurepkg.ServiceRecordIP
+	setServiceRecord    = azurepkg.SetServiceRecord
+	restartServiceGroup = azurepkg.ContainerRestart
 )
 
 // startHealthAttempts is the number of 10-second polling intervals used when waiting
@@ -48,9 +60,13 @@ For local deployments (Docker Compose), runs 'docker compose up -d' from the
 deployment directory. This is idempotent — running containers are unaffected,
 and exited or crashed containers are restarted.
 
-For Azure deployments, starts any stopped Container Instances and MySQL server.
+For Azure deployments, starts any stopped Container Instances (or activates the
+Container Apps revisions) and the MySQL server.
 
-Auto-detects deployment type from state files in the current directory.`,
+For Kubernetes deployments, scales the DevLake workloads back to one replica.
+
+Auto-detects deployment type from state files in the current directory.
+Use --instance to start a named local instance from any directory.`,
 		RunE: runStart,
 	}
 
@@ -58,24 +74,35 @@ Auto-detects deployment type from state files in the current directory.`,
 	cmd.Flags().BoolVar(&startNoWait, "no-wait", false, "Skip health polling after start")
 	cmd.Flags().BoolVar(&startAzure, "azure", false, "Force Azure start mode")
 	cmd.Flags().BoolVar(&startLocal, "local", false, "Force local (Docker Compose) start mode")
+	cmd.Flags().BoolVar(&startK8s, "k8s", false, "Force Kubernetes start mode")
 	cmd.Flags().StringVar(&startState, "state-file", "", "Path to state file (auto-detected if omitted)")
+	cmd.Flags().StringVar(&startInstance, "instance", "", "Start a named local instance (see 'deploy local --instance')")
 
 	return cmd
 }
 
 func runStart(cmd *cobra.Command, args []string) error {
+	if startInstance != "" {
+		inst, err := instance.Get(startInstance)
+		if err != nil {
+			return err
+		}
+		startState, startLocal = inst.StateFile(), true
+	}
 	mode := detectStartMode()
 	switch mode {
 	case "local":
 		return runLocalStart()
 	case "azure":
 		return runAzureStart()
+	case "k8s":
+		return runK8sStart()
 	default:
 		return fmt.Errorf("no deployment found — no state file or docker-compose.yml in current directory\nRun 'gh devlake deploy' to create a new deployment")
 	}
 }
 
-// detectStartMode determines whether to start local (Docker Compose) or Azure resources.
+// detectStartMode determines whether to start local (Docker Compose), Azure or Kubernetes resources.
 // Priority: explicit flags → explicit state file (inspected for method) → auto-detect files.
 func detectStartMode() string {
 	if startAzure {
@@ -84,6 +111,9 @@ func detectStartMode() string {
 	if startLocal {
 		return "local"
 	}
+	if startK8s {
+		return "k8s"
+	}
 
 	// Check explicit state file — inspect its content rather than guessing from the filename.
 	if startState != "" {
@@ -98,6 +128,8 @@ func detectStartMode() string {
 					return "azure"
 				case "local", "docker-compose":
 					return "local"
+				case "k8s":
+					return "k8s"
 				}
 				// If method is absent but resourceGroup is set, it's an Azure state file.
 				if meta.ResourceGroup != "" {
@@ -113,6 +145,9 @@ func detectStartMode() string {
 	if _, err := os.Stat(".devlake-azure.json"); err == nil {
 		return "azure"
 	}
+	if _, err := os.Stat(".devlake-k8s.json"); err == nil {
+		return "k8s"
+	}
 	if _, err := os.Stat(".devlake-local.json"); err == nil {
 		return "local"
 	}
@@ -177,7 +212,7 @@ func runLocalStart() error {
 	backendURL := ""
 	if !startNoWait && startService == "" {
 		fmt.Fprintln(prog, "\n⏳ Waiting for DevLake to be ready...")
-		backendURLCandidates := []string{localBackendPort8080, localBackendPort8085}
+		backendURLCandidates := localBackendCandidates(dir)
 		var err error
 		backendURL, err = waitForReadyAny(backendURLCandidates, startHealthAttempts, 10*time.Second)
 		if err != nil {
@@ -197,9 +232,9 @@ func runLocalStart() error {
 
 	// Print accurate URLs based on the healthy backend that responded.
 	if backendURL == "" {
-		backendURL = localBackendPort8080
+		backendURL = localBackendCandidates(dir)[0]
 	}
-	grafanaURL, configUIURL := localCompanionURLs(backendURL)
+	grafanaURL, configUIURL := localCompanionURLsIn(dir, backendURL)
 	fmt.Fprintf(prog, "\n  Backend API: %s\n", backendURL)
 	if configUIURL != "" {
 		fmt.Fprintf(prog, "  Config UI:   %s\n", configUIURL)
@@ -221,6 +256,35 @@ func localCompanionURLs(backendURL string) (grafanaURL, configUIURL string) {
 	return localGrafanaPort8080, localConfigUIPort8080
 }
 
+// localBackendCandidates returns the backend URLs to poll for the local
+// deployment in dir: the one recorded in its state file (custom ports from
+// deploy local) first, then the well-known ports.
+func localBackendCandidates(dir string) []string {
+	candidates := []string{localBackendPort8080, localBackendPort8085}
+	state, _ := devlake.LoadState(filepath.Join(dir, ".devlake-local.json"))
+	if state == nil || state.Endpoints.Backend == "" {
+		return candidates
+	}
+	recorded := strings.TrimRight(state.Endpoints.Backend, "/")
+	out := []string{recorded}
+	for _, c := range candidates {
+		if c != recorded {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// localCompanionURLsIn is localCompanionURLs, preferring the Grafana and
+// Config UI URLs recorded in dir's state file when its backend matches.
+func localCompanionURLsIn(dir, backendURL string) (grafanaURL, configUIURL string) {
+	state, _ := devlake.LoadState(filepath.Join(dir, ".devlake-local.json"))
+	if state != nil && strings.TrimRight(state.Endpoints.Backend, "/") == backendURL && state.Endpoints.Grafana != "" {
+		return state.Endpoints.Grafana, state.Endpoints.ConfigUI
+	}
+	return localCompanionURLs(backendURL)
+}
+
 func runAzureStart() error {
 	// In JSON mode, all progress goes to stderr to keep stdout clean for JSON.
 	var prog io.Writer = os.Stdout
@@ -285,12 +349,46 @@ func runAzureStart() error {
 		containers = filtered
 	}
 
+	// A private ACI deployment reaches its containers through DNS records that
+	// have to follow the new IPs. Config UI resolves the backend and Grafana
+	// names when it starts, so it is restarted if they moved without it.
+	refreshDNS := state.Private && state.Runtime != azurepkg.RuntimeACA && state.Resources.Network.ServiceZone != ""
+	if state.Private && state.Runtime != azurepkg.RuntimeACA && !refreshDNS {
+		fmt.Fprintln(prog, "\n⚠️  This private deployment reaches its containers by private IP, which may have changed.")
+		fmt.Fprintln(prog, "   Run 'gh devlake deploy azure --update' once to switch it to stable DNS names.")
+	}
+	uiStarted, moved := false, false
 	for _, container := range containers {
 		fmt.Fprintf(prog, "\n📦 Starting container %q...\n", container)
-		if err := azurepkg.ContainerStart(container, state.ResourceGroup); err != nil {
+		if err := state.startContainer(container); err != nil {
 			fmt.Fprintf(prog, "   ⚠️  Could not start %s: %v\n", container, err)
+			continue
+		}
+		fmt.Fprintln(prog, "   ✅ Start initiated")
+		if !refreshDNS {
+			continue
+		}
+		record := azurepkg.ServiceRecord(state.BaseName, state.Suffix, container)
+		changed, err := state.refreshServiceRecord(container)
+		switch {
+		case err != nil:
+			fmt.Fprintf(prog, "   ⚠️  Could not update %s.%s: %v\n", record, state.Resources.Network.ServiceZone, err)
+		case changed:
+			fmt.Fprintf(prog, "   ✅ %s.%s points at the new IP\n", record, state.Resources.Network.ServiceZone)
+		}
+		if record == "ui" {
+			uiStarted = true
+		} else if changed {
+			moved = true
+		}
+	}
+	if moved && !uiStarted {
+		ui := fmt.Sprintf("%s-ui-%s", state.BaseName, state.Suffix)
+		fmt.Fprintf(prog, "\n🔄 Restarting %q so it resolves the new addresses...\n", ui)
+		if err := restartServiceGroup(ui, state.ResourceGroup); err != nil {
+			fmt.Fprintf(prog, "   ⚠️  Could not restart %s: %v\n", ui, err)
 		} else {
-			fmt.Fprintln(prog, "   ✅ Start initiated")
+			fmt.Fprintln(prog, "   ✅ Restarted")
 		}
 	}
 
@@ -323,3 +421,94 @@ func runAzureStart() error {
 	fmt.Fprintln(prog)
 	return nil
 }
+
+func runK8sStart() error {
+	// In JSON mode, all progress goes to stderr to keep stdout clean for JSON.
+	var prog io.Writer = os.Stdout
+	if outputJSON {
+		prog = os.Stderr
+	}
+
+	fmt.Fprintln(prog)
+	fmt.Fprintln(prog, "════════════════════════════════════════")
+	fmt.Fprintln(prog, "  DevLake Kubernetes — Start Services")
+	fmt.Fprintln(prog, "════════════════════════════════════════")
+
+	stateFile := startState
+	if stateFile == "" {
+		stateFile = ".devlake-k8s.json"
+	}
+	state, err := loadK8sState(stateFile)
+	if err != nil {
+		return err
+	}
+	kc := state.kubectl()
+
+	fmt.Fprintln(prog, "\n☸️  Checking kubectl...")
+	if err := kc.CheckAvailable(); err != nil {
+		return err
+	}
+	fmt.Fprintln(prog, "   ✅ Connected")
+
+	if startService != "" {
+		fmt.Fprintf(prog, "\n☸️  Scaling %q to 1 in namespace %s...\n", startService, state.Namespace)
+	} else {
+		fmt.Fprintf(prog, "\n☸️  Scaling workloads to 1 in namespace %s...\n", state.Namespace)
+	}
+	if err := kc.Scale(state.selector(startService), 1); err != nil {
+		return fmt.Errorf("failed to start workloads: %w", err)
+	}
+	fmt.Fprintln(prog, "   ✅ Workloads scaling up")
+
+	// ── Rollout polling ──
+	if !startNoWait && startService == "" {
+		fmt.Fprintln(prog, "\n⏳ Waiting for DevLake to be ready...")
+		for _, w := range k8sWorkloads(state.Name) {
+			if err := kc.RolloutStatus(w[0], w[1], "5m"); err != nil {
+				fmt.Fprintf(prog, "   ⚠️  %s not ready yet — run 'gh devlake status' to check\n", w[1])
+			}
+		}
+	}
+
+	if outputJSON {
+		return printJSON(map[string]string{"status": "started", "mode": "k8s"})
+	}
+
+	fmt.Fprintln(prog)
+	fmt.Fprintln(prog, "════════════════════════════════════════")
+	fmt.Fprintln(prog, "  ✅ Services Started!")
+	fmt.Fprintln(prog, "════════════════════════════════════════")
+	if state.Endpoints.Backend != "" {
+		fmt.Fprintf(prog, "\n  Backend API: %s\n", state.Endpoints.Backend)
+	}
+	if state.Endpoints.ConfigUI != "" {
+		fmt.Fprintf(prog, "  Config UI:   %s\n", state.Endpoints.ConfigUI)
+	}
+	if state.Endpoints.Grafana != "" {
+		fmt.Fprintf(prog, "  Grafana:     %s\n", state.Endpoints.Grafana)
+	}
+	if state.ServiceType != "LoadBalancer" {
+		fmt.Fprintf(prog, "\n  Port-forward first: kubectl -n %s port-forward svc/%s-backend 8080:8080\n", state.Namespace, state.Name)
+	}
+	fmt.Fprintln(prog)
+	return nil
+}
+
+// refreshServiceRecord points the service zone record of a private container
+// group at the group's current IP and reports whether the record moved.
+// Groups without a record are left alone.
+func (s *azureStateData) refreshServiceRecord(group string) (bool, error) {
+	record := azurepkg.ServiceRecord(s.BaseName, s.Suffix, group)
+	if record == "" {
+		return false, nil
+	}
+	ip, err := serviceGroupIP(group, s.ResourceGroup)
+	if err != nil {
+		return false, err
+	}
+	zone := s.Resources.Network.ServiceZone
+	if current, err := serviceRecordIP(zone, s.ResourceGroup, record); err == nil && current == ip {
+		return false, nil
+	}
+	return true, setServiceRecord(zone, s.ResourceGroup, record, ip)
+}
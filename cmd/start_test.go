@@ -4,8 +4,11 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+
+	dockerpkg "github.com/DevExpGBB/gh-devlake/internal/docker"
 )
 
 // ── detectStartMode tests ────────────────────────────────────────────────────
@@ -114,6 +117,42 @@ func TestDetectStartMode_AutoDetect_AzureJson(t *testing.T) {
 	}
 }
 
+func TestDetectStartMode_StateFile_K8sMethod(t *testing.T) {
+	dir := t.TempDir()
+	sf := filepath.Join(dir, "mystate.json")
+	if err := os.WriteFile(sf, []byte(`{"method":"k8s","namespace":"devlake","name":"devlake"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	origState := startState
+	startState = sf
+	t.Cleanup(func() { startState = origState })
+
+	if got := detectStartMode(); got != "k8s" {
+		t.Errorf("expected k8s, got %q", got)
+	}
+}
+
+func TestDetectStartMode_AutoDetect_K8sJson(t *testing.T) {
+	dir := t.TempDir()
+	origWd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(origWd) })
+
+	// .devlake-k8s.json wins over a docker-compose.yml in the same directory.
+	for _, f := range []string{".devlake-k8s.json", "docker-compose.yml"} {
+		if err := os.WriteFile(f, []byte(`{}`), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := detectStartMode(); got != "k8s" {
+		t.Errorf("expected k8s from .devlake-k8s.json, got %q", got)
+	}
+}
+
 func TestDetectStartMode_AutoDetect_LocalJson(t *testing.T) {
 	dir := t.TempDir()
 	origWd, _ := os.Getwd()
@@ -183,6 +222,32 @@ func TestLocalCompanionURLs_Port8085(t *testing.T) {
 	}
 }
 
+func TestLocalBackendCandidates_RecordedPorts(t *testing.T) {
+	dir := t.TempDir()
+	if got := localBackendCandidates(dir); len(got) != 2 || got[0] != localBackendPort8080 {
+		t.Errorf("no state = %v, want well-known ports", got)
+	}
+
+	ep := localOverrideEndpoints(dockerpkg.Override{BackendPort: 18080, GrafanaPort: 13002})
+	if err := recordLocalEndpoints(dir, ep, ""); err != nil {
+		t.Fatal(err)
+	}
+	got := localBackendCandidates(dir)
+	want := []string{"http://localhost:18080", localBackendPort8080, localBackendPort8085}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("candidates = %v, want %v", got, want)
+	}
+
+	grafana, configUI := localCompanionURLsIn(dir, "http://localhost:18080")
+	if grafana != "http://localhost:13002" || configUI != "http://localhost:4000" {
+		t.Errorf("recorded companions = %q, %q", grafana, configUI)
+	}
+	grafana, _ = localCompanionURLsIn(dir, localBackendPort8085)
+	if grafana != localGrafanaPort8085 {
+		t.Errorf("unrecorded backend grafana = %q, want %q", grafana, localGrafanaPort8085)
+	}
+}
+
 // ── JSON output tests ─────────────────────────────────────────────────────────
 
 // TestRunStart_JSONMode_NoDeployment verifies that when --json is set and no
@@ -254,3 +319,40 @@ func TestRunStart_JSONPayload_AzureShape(t *testing.T) {
 		t.Errorf("expected mode=azure, got %q", got["mode"])
 	}
 }
+
+// ── refreshServiceRecord tests ───────────────────────────────────────────────
+
+func TestRefreshServiceRecord(t *testing.T) {
+	origIP, origRecord, origSet := serviceGroupIP, serviceRecordIP, setServiceRecord
+	t.Cleanup(func() { serviceGroupIP, serviceRecordIP, setServiceRecord = origIP, origRecord, origSet })
+
+	records := map[string]string{"backend": "10.40.2.4", "ui": "10.40.2.6"}
+	serviceGroupIP = func(name, rg string) (string, error) {
+		return map[string]string{"devlake-backend-abc12": "10.40.2.9", "devlake-ui-abc12": "10.40.2.6"}[name], nil
+	}
+	serviceRecordIP = func(zone, rg, record string) (string, error) { return records[record], nil }
+	setServiceRecord = func(zone, rg, record, ip string) error {
+		if zone != "devlakeabc12.internal" || rg != "devlake-rg" {
+			t.Errorf("set %s in zone %s of %s", record, zone, rg)
+		}
+		records[record] = ip
+		return nil
+	}
+
+	var state azureStateData
+	state.ResourceGroup, state.BaseName, state.Suffix = "devlake-rg", "devlake", "abc12"
+	state.Resources.Network.ServiceZone = "devlakeabc12.internal"
+
+	if changed, err := state.refreshServiceRecord("devlake-backend-abc12"); err != nil || !changed {
+		t.Errorf("backend: changed %v, err %v; want a moved record", changed, err)
+	}
+	if records["backend"] != "10.40.2.9" {
+		t.Errorf("backend record = %s, want 10.40.2.9", records["backend"])
+	}
+	if changed, err := state.refreshServiceRecord("devlake-ui-abc12"); err != nil || changed {
+		t.Errorf("ui: changed %v, err %v; want the unchanged record left alone", changed, err)
+	}
+	if changed, err := state.refreshServiceRecord("something-else"); err != nil || changed {
+		t.Errorf("unknown group: changed %v, err %v", changed, err)
+	}
+}
@@ -8,36 +8,78 @@ import (
 	"strings"
 	"time"
 
+	"github.com/DevExpGBB/gh-devlake/internal/azure"
 	"github.com/DevExpGBB/gh-devlake/internal/devlake"
+	"github.com/DevExpGBB/gh-devlake/internal/instance"
 	"github.com/spf13/cobra"
 )
 
+var (
+	statusInstance string
+	statusAll      bool
+)
+
 var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Show DevLake deployment summary and health",
 	Long: `Displays a summary of the current DevLake deployment:
   • Endpoint health for each service
+  • Backend, Config UI, Grafana and MySQL versions and the running image
+    tags, with a warning on mismatches or a newer upstream release
+  • Start/stop schedule of an Azure deployment
   • Configured plugin connections with display names
-  • Project and scope configuration`,
+  • Project and scope configuration
+
+Use --instance to show a named local instance from any directory, or --all
+to list every named instance with its ports and backend health.`,
 	RunE: runStatus,
 }
 
 func init() {
+	statusCmd.Flags().StringVar(&statusInstance, "instance", "", "Show a named local instance (see 'deploy local --instance')")
+	statusCmd.Flags().BoolVar(&statusAll, "all", false, "List every named local instance")
 	statusCmd.GroupID = "operate"
 	rootCmd.AddCommand(statusCmd)
 }
 
+// statusInstanceEntry is the JSON representation of one row of status --all.
+type statusInstanceEntry struct {
+	Name     string `json:"name"`
+	Project  string `json:"project"`
+	Dir      string `json:"dir"`
+	Backend  string `json:"backend,omitempty"`
+	Grafana  string `json:"grafana,omitempty"`
+	ConfigUI string `json:"configUi,omitempty"`
+	Healthy  bool   `json:"healthy"`
+}
+
 // statusOutput is the JSON representation of the status command output.
 type statusOutput struct {
 	Deployment  *statusDeployment  `json:"deployment"`
 	Endpoints   []statusEndpoint   `json:"endpoints"`
 	Connections []statusConnection `json:"connections"`
 	Project     *statusProject     `json:"project"`
+	Versions    *statusVersions    `json:"versions,omitempty"`
 }
 
 type statusDeployment struct {
-	Method    string `json:"method"`
-	StateFile string `json:"stateFile"`
+	Method    string              `json:"method"`
+	StateFile string              `json:"stateFile"`
+	Namespace string              `json:"namespace,omitempty"`
+	Workloads []statusK8sWorkload `json:"workloads,omitempty"`
+	Schedule  *statusSchedule     `json:"schedule,omitempty"`
+}
+
+// statusSchedule is the start/stop schedule of an Azure deployment.
+type statusSchedule struct {
+	azure.Schedule
+	Description string `json:"description"`
+}
+
+// statusK8sWorkload is the readiness of one workload of a Kubernetes deployment.
+type statusK8sWorkload struct {
+	Name  string `json:"name"`
+	Ready string `json:"ready"`
 }
 
 type statusEndpoint struct {
@@ -60,11 +102,24 @@ type statusProject struct {
 }
 
 func runStatus(cmd *cobra.Command, args []string) error {
+	if statusAll {
+		return runStatusAll()
+	}
+	if statusInstance != "" {
+		inst, err := instance.Get(statusInstance)
+		if err != nil {
+			return err
+		}
+		if err := os.Chdir(inst.Dir); err != nil {
+			return fmt.Errorf("instance %q directory %s: %w", inst.Name, inst.Dir, err)
+		}
+	}
+
 	// ── Load state file ──
 	var state *devlake.State
 	var stateFile string
 	cwd, _ := os.Getwd()
-	for _, name := range []string{".devlake-azure.json", ".devlake-local.json"} {
+	for _, name := range []string{".devlake-azure.json", ".devlake-k8s.json", ".devlake-local.json"} {
 		path := filepath.Join(cwd, name)
 		s, err := devlake.LoadState(path)
 		if err == nil && s != nil {
@@ -100,7 +155,8 @@ func runStatus(cmd *cobra.Command, args []string) error {
 		if disc.GrafanaURL != "" {
 			fmt.Printf("  Grafana:    %s\n", disc.GrafanaURL)
 		}
-		fmt.Println("  Run 'gh devlake configure full' to set up connections.")
+		printStatusVersions(collectStatusVersions("", disc.URL, disc.GrafanaURL), "", sep)
+		fmt.Println("\n  Run 'gh devlake configure full' to set up connections.")
 		return nil
 	}
 
@@ -115,6 +171,25 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	if state.DeployedAt != "" {
 		fmt.Printf("  Deployed:  %s\n", friendlyTime(state.DeployedAt))
 	}
+	if sched := azureScheduleStatus(stateFile); sched != nil {
+		fmt.Printf("  Schedule:  %s\n", sched.Description)
+	}
+	if state.Method == "k8s" {
+		namespace, workloads, err := k8sWorkloadStatus(stateFile)
+		fmt.Printf("  Namespace: %s\n", namespace)
+		fmt.Println("\n  Workloads")
+		fmt.Println(sep)
+		if err != nil {
+			fmt.Printf("  ⚠️  %v\n", err)
+		}
+		for _, w := range workloads {
+			icon := "✅"
+			if ready, desired, _ := strings.Cut(w.Ready, "/"); ready != desired || desired == "0" {
+				icon = "❌"
+			}
+			fmt.Printf("  %-18s  %s  %s\n", w.Name, icon, w.Ready)
+		}
+	}
 
 	// ── Services section ──
 	backendURL := state.Endpoints.Backend
@@ -170,6 +245,11 @@ func runStatus(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// ── Versions section ──
+	if backendURL != "" {
+		printStatusVersions(collectStatusVersions(stateFile, backendURL, grafanaURL), stateFile, sep)
+	}
+
 	// ── Connections section ──
 	fmt.Println("\n  Connections")
 	fmt.Println(sep)
@@ -213,6 +293,52 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// runStatusAll lists every registered named instance with its endpoints and
+// backend health.
+func runStatusAll() error {
+	list, err := instance.List()
+	if err != nil {
+		return err
+	}
+	entries := make([]statusInstanceEntry, 0, len(list))
+	for _, inst := range list {
+		e := statusInstanceEntry{Name: inst.Name, Project: inst.ProjectName(), Dir: inst.Dir}
+		if s, err := devlake.LoadState(inst.StateFile()); err == nil && s != nil {
+			e.Backend, e.Grafana, e.ConfigUI = s.Endpoints.Backend, s.Endpoints.Grafana, s.Endpoints.ConfigUI
+		}
+		if e.Backend != "" {
+			e.Healthy = checkEndpointHealth(e.Backend, "backend")
+		}
+		entries = append(entries, e)
+	}
+
+	if outputJSON {
+		return printJSON(entries)
+	}
+
+	printBanner("DevLake Instances")
+	if len(entries) == 0 {
+		fmt.Println("\n  No named instances. Create one with 'gh devlake deploy local --instance NAME'.")
+		return nil
+	}
+	fmt.Println()
+	fmt.Printf("  %-16s  %-2s  %-24s  %s\n", "NAME", "", "BACKEND", "DIRECTORY")
+	fmt.Println("  " + strings.Repeat("─", 70))
+	for _, e := range entries {
+		icon := "❌"
+		if e.Healthy {
+			icon = "✅"
+		}
+		backend := e.Backend
+		if backend == "" {
+			backend = "(no state file)"
+		}
+		fmt.Printf("  %-16s  %s  %-24s  %s\n", e.Name, icon, backend, e.Dir)
+	}
+	fmt.Println("\n  Details: gh devlake status --instance NAME")
+	return nil
+}
+
 // runStatusJSON outputs the status in JSON format.
 func runStatusJSON(state *devlake.State, stateFile string) error {
 	out := statusOutput{
@@ -229,6 +355,10 @@ func runStatusJSON(state *devlake.State, stateFile string) error {
 			Method:    method,
 			StateFile: stateFile,
 		}
+		if state.Method == "k8s" {
+			out.Deployment.Namespace, out.Deployment.Workloads, _ = k8sWorkloadStatus(stateFile)
+		}
+		out.Deployment.Schedule = azureScheduleStatus(stateFile)
 
 		// Resolve endpoints (same logic as human path)
 		backendURL := state.Endpoints.Backend
@@ -264,6 +394,9 @@ func runStatusJSON(state *devlake.State, stateFile string) error {
 				Healthy: checkEndpointHealth(svc.url, svc.kind),
 			})
 		}
+		if backendURL != "" {
+			out.Versions = collectStatusVersions(stateFile, backendURL, grafanaURL)
+		}
 
 		for _, c := range state.Connections {
 			out.Connections = append(out.Connections, statusConnection{
@@ -308,6 +441,7 @@ func runStatusJSON(state *devlake.State, stateFile string) error {
 				Healthy: checkEndpointHealth(disc.ConfigUIURL, "config-ui"),
 			})
 		}
+		out.Versions = collectStatusVersions("", disc.URL, disc.GrafanaURL)
 	}
 
 	return printJSON(out)
@@ -358,3 +492,25 @@ func friendlyTime(s string) string {
 	}
 	return t.Format("2006-01-02 15:04 UTC")
 }
+
+// k8sWorkloadStatus returns the namespace and the ready/desired replicas of
+// each workload of the Kubernetes deployment recorded in stateFile.
+func k8sWorkloadStatus(stateFile string) (string, []statusK8sWorkload, error) {
+	state, err := loadK8sState(stateFile)
+	if err != nil {
+		return "", nil, err
+	}
+	kc := state.kubectl()
+	var out []statusK8sWorkload
+	for _, w := range k8sWorkloads(state.Name) {
+		ready, err := kc.ReadyReplicas(w[0], w[1])
+		if err != nil {
+			return state.Namespace, out, err
+		}
+		if ready == "" {
+			ready = "missing"
+		}
+		out = append(out, statusK8sWorkload{Name: w[1], Ready: ready})
+	}
+	return state.Namespace, out, nil
+}
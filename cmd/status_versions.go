@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DevExpGBB/gh-devlake/internal/azure"
+	"github.com/DevExpGBB/gh-devlake/internal/devlake"
+	dockerpkg "github.com/DevExpGBB/gh-devlake/internal/docker"
+	"github.com/DevExpGBB/gh-devlake/internal/download"
+)
+
+// statusVersions holds the versions of the running components. Each is
+// best effort: a component that cannot be reached is left empty.
+type statusVersions struct {
+	Backend  string        `json:"backend,omitempty"`
+	ConfigUI string        `json:"configUi,omitempty"` // tag of the config-ui image
+	Grafana  string        `json:"grafana,omitempty"`
+	MySQL    string        `json:"mysql,omitempty"`
+	Images   []statusImage `json:"images,omitempty"`
+	Latest   string        `json:"latest,omitempty"` // newer upstream release, if any
+	Warnings []string      `json:"warnings,omitempty"`
+}
+
+// statusImage is the image a container of the deployment runs.
+type statusImage struct {
+	Component string `json:"component"`
+	Container string `json:"container"`
+	Image     string `json:"image"`
+}
+
+// collectStatusVersions gathers component versions: the backend's /version
+// endpoint, Grafana's health endpoint, the images from docker compose ps
+// (local) or the container definitions (Azure), and the MySQL server. The
+// latest upstream release is looked up last and skipped when offline.
+func collectStatusVersions(stateFile, backendURL, grafanaURL string) *statusVersions {
+	v := &statusVersions{}
+	if backendURL != "" {
+		if info, err := devlake.NewClient(backendURL).Version(); err == nil {
+			v.Backend = info.Version
+		}
+	}
+	if grafanaURL != "" {
+		v.Grafana = grafanaVersion(grafanaURL)
+	}
+
+	switch stateFile {
+	case ".devlake-local.json":
+		dir, _ := os.Getwd()
+		if services, err := dockerpkg.ComposePS(dir); err == nil {
+			for _, s := range services {
+				v.Images = append(v.Images, statusImage{Component: imageComponent(s.Service, s.Image), Container: s.Service, Image: s.Image})
+			}
+		}
+		for _, img := range v.Images {
+			if img.Component == "mysql" {
+				var out bytes.Buffer
+				if err := dockerpkg.ComposeExec(dir, img.Container, nil, &out, "mysqld", "--version"); err == nil {
+					v.MySQL = parseMySQLDVersion(out.String())
+				}
+			}
+		}
+	case ".devlake-azure.json":
+		if state, err := loadAzureStateData(stateFile); err == nil {
+			v.Images = azureImages(state)
+			if state.Resources.MySQL != "" {
+				v.MySQL, _ = azure.MySQLVersion(state.Resources.MySQL, state.ResourceGroup)
+			}
+		}
+	}
+	for _, img := range v.Images {
+		switch img.Component {
+		case "config-ui":
+			v.ConfigUI = imageTag(img.Image)
+		case "mysql":
+			if v.MySQL == "" {
+				v.MySQL = imageTag(img.Image)
+			}
+		}
+	}
+
+	if v.Backend != "" {
+		if tag, err := download.GitHubLatestTag("apache", "incubator-devlake"); err == nil {
+			if cmp, err := compareVersions(v.Backend, tag); err == nil && cmp < 0 {
+				v.Latest = tag
+			}
+		}
+	}
+	v.Warnings = versionWarnings(v)
+	return v
+}
+
+// azureImages returns the images of the container groups (or container
+// apps) recorded in an Azure state file.
+func azureImages(state *azureStateData) []statusImage {
+	var out []statusImage
+	for _, name := range state.Resources.Containers {
+		var images map[string]string
+		var err error
+		if state.Runtime == azure.RuntimeACA {
+			images, err = azure.ContainerAppImages(name, state.ResourceGroup)
+		} else {
+			images, err = azure.ContainerGroupImages(name, state.ResourceGroup)
+		}
+		if err != nil {
+			continue
+		}
+		containers := make([]string, 0, len(images))
+		for c := range images {
+			containers = append(containers, c)
+		}
+		sort.Strings(containers)
+		for _, c := range containers {
+			out = append(out, statusImage{Component: imageComponent(c, images[c]), Container: c, Image: images[c]})
+		}
+	}
+	return out
+}
+
+// imageComponent names the DevLake component a container runs, from its
+// service or container name and its image.
+func imageComponent(container, image string) string {
+	s := strings.ToLower(container + " " + image[strings.LastIndex(image, "/")+1:])
+	switch {
+	case strings.Contains(s, "config-ui"):
+		return "config-ui"
+	case strings.Contains(s, "grafana"), strings.Contains(s, "dashboard"):
+		return "grafana"
+	case strings.Contains(s, "mysql"):
+		return "mysql"
+	case strings.Contains(s, "devlake"), strings.Contains(s, "backend"):
+		return "backend"
+	}
+	return ""
+}
+
+var mysqldVersionRe = regexp.MustCompile(`Ver (\d+\.\d+\.\d+)`)
+
+// parseMySQLDVersion reads the version from mysqld --version output, e.g.
+// "mysqld  Ver 8.0.36 for Linux on x86_64 (MySQL Community Server - GPL)".
+func parseMySQLDVersion(out string) string {
+	if m := mysqldVersionRe.FindStringSubmatch(out); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// grafanaVersion returns the version Grafana reports on /api/health.
+func grafanaVersion(url string) string {
+	client := &http.Client{Timeout: 8 * time.Second}
+	resp, err := client.Get(strings.TrimRight(url, "/") + "/api/health")
+	if err != nil {
+		return ""
+	}
+	defer resp.Body.Close()
+	var health struct {
+		Version string `json:"version"`
+	}
+	if resp.StatusCode != http.StatusOK || json.NewDecoder(resp.Body).Decode(&health) != nil {
+		return ""
+	}
+	return health.Version
+}
+
+// versionWarnings flags components that do not match the backend.
+func versionWarnings(v *statusVersions) []string {
+	var warnings []string
+	backend := normalizeVersion(v.Backend)
+	if backend != "" && v.ConfigUI != "" && v.ConfigUI != "latest" && v.ConfigUI != backend {
+		warnings = append(warnings, fmt.Sprintf("Config UI %s does not match backend %s", v.ConfigUI, backend))
+	}
+	for _, img := range v.Images {
+		tag := imageTag(img.Image)
+		if img.Component == "backend" && backend != "" && tag != "" && tag != "latest" && tag != backend {
+			warnings = append(warnings, fmt.Sprintf("%s runs image tag %s but the backend reports %s", img.Container, tag, backend))
+		}
+	}
+	return warnings
+}
+
+// printStatusVersions prints the Versions section of status.
+func printStatusVersions(v *statusVersions, stateFile, sep string) {
+	fmt.Println("\n  Versions")
+	fmt.Println(sep)
+	rows := [][2]string{{"Backend", v.Backend}, {"Config UI", v.ConfigUI}, {"Grafana", v.Grafana}, {"MySQL", v.MySQL}}
+	for _, r := range rows {
+		value := r[1]
+		if value == "" {
+			value = "unknown"
+		}
+		fmt.Printf("  %-10s %s\n", r[0]+":", value)
+	}
+	if len(v.Images) > 0 {
+		fmt.Println("  Images:")
+		for _, img := range v.Images {
+			fmt.Printf("    %-16s %s\n", img.Container, img.Image)
+		}
+	}
+	for _, w := range v.Warnings {
+		fmt.Printf("  ⚠️  %s\n", w)
+	}
+	if v.Latest != "" {
+		hint := "gh devlake upgrade --to " + v.Latest
+		switch stateFile {
+		case ".devlake-azure.json":
+			hint = "gh devlake deploy azure --update --image-tag " + v.Latest
+		case ".devlake-k8s.json":
+			hint = "gh devlake deploy k8s --version " + v.Latest
+		}
+		fmt.Printf("  💡 DevLake %s is available — run '%s'\n", v.Latest, hint)
+	}
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestImageComponent(t *testing.T) {
+	cases := []struct{ container, image, want string }{
+		{"devlake", "devlake.docker.scarf.sh/apache/devlake:v1.0.2", "backend"},
+		{"config-ui", "devlake.docker.scarf.sh/apache/devlake-config-ui:v1.0.2", "config-ui"},
+		{"grafana", "devlake.docker.scarf.sh/apache/devlake-dashboard:v1.0.2", "grafana"},
+		{"mysql", "mysql:8", "mysql"},
+		{"devlake-ui-abc12", "devlakeacrabc12.azurecr.io/devlake-config-ui:latest", "config-ui"},
+		{"devlake-backend-abc12", "devlakeacrabc12.azurecr.io/devlake-backend:20240101", "backend"},
+		{"redis", "redis:7", ""},
+	}
+	for _, c := range cases {
+		if got := imageComponent(c.container, c.image); got != c.want {
+			t.Errorf("imageComponent(%q, %q) = %q, want %q", c.container, c.image, got, c.want)
+		}
+	}
+}
+
+func TestParseMySQLDVersion(t *testing.T) {
+	if got := parseMySQLDVersion("mysqld  Ver 8.0.36 for Linux on x86_64 (MySQL Community Server - GPL)\n"); got != "8.0.36" {
+		t.Errorf("got %q", got)
+	}
+	if got := parseMySQLDVersion("sh: mysqld: not found"); got != "" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestVersionWarnings(t *testing.T) {
+	v := &statusVersions{
+		Backend:  "v1.0.2@a1b2c3d",
+		ConfigUI: "v1.0.1",
+		Images: []statusImage{
+			{Component: "backend", Container: "devlake", Image: "apache/devlake:v1.0.1"},
+			{Component: "backend", Container: "lake-migrate", Image: "apache/devlake:v1.0.2"},
+		},
+	}
+	want := []string{
+		"Config UI v1.0.1 does not match backend v1.0.2",
+		"devlake runs image tag v1.0.1 but the backend reports v1.0.2",
+	}
+	if got := versionWarnings(v); !reflect.DeepEqual(got, want) {
+		t.Errorf("warnings = %q", got)
+	}
+
+	// latest tags and an unknown backend version are not flagged
+	v = &statusVersions{Backend: "v1.0.2", ConfigUI: "latest"}
+	if got := versionWarnings(v); got != nil {
+		t.Errorf("warnings = %q", got)
+	}
+	if got := versionWarnings(&statusVersions{ConfigUI: "v1.0.1"}); got != nil {
+		t.Errorf("warnings = %q", got)
+	}
+}
+
+func TestGrafanaVersion(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/health" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"commit": "abc", "database": "ok", "version": "11.0.0"}`))
+	}))
+	defer srv.Close()
+	if got := grafanaVersion(srv.URL + "/"); got != "11.0.0" {
+		t.Errorf("grafanaVersion = %q", got)
+	}
+	if got := grafanaVersion(srv.URL + "/missing"); got != "" {
+		t.Errorf("grafanaVersion on 404 = %q", got)
+	}
+}
@@ -10,14 +10,17 @@ import (
 
 	azurepkg "github.com/DevExpGBB/gh-devlake/internal/azure"
 	dockerpkg "github.com/DevExpGBB/gh-devlake/internal/docker"
+	"github.com/DevExpGBB/gh-devlake/internal/instance"
 	"github.com/spf13/cobra"
 )
 
 var (
-	stopService string
-	stopAzure   bool
-	stopLocal   bool
-	stopState   string
+	stopService  string
+	stopAzure    bool
+	stopLocal    bool
+	stopK8s      bool
+	stopState    string
+	stopInstance string
 )
 
 func newStopCmd() *cobra.Command {
@@ -29,9 +32,14 @@ func newStopCmd() *cobra.Command {
 For local deployments (Docker Compose), runs 'docker compose stop', which preserves
 containers and volumes so they can be quickly restarted with 'gh devlake start'.
 
-For Azure deployments, stops Container Instances and the MySQL server using the Azure CLI.
+For Azure deployments, stops Container Instances (or deactivates the Container
+Apps revisions) and the MySQL server using the Azure CLI.
+
+For Kubernetes deployments, scales the DevLake workloads to zero replicas. The
+MySQL volume, Secret and Services are kept.
 
 Auto-detects deployment type from state files in the current directory.
+Use --instance to stop a named local instance from any directory.
 
 This is the non-destructive counterpart to 'gh devlake start'.
 Use 'gh devlake cleanup' to permanently tear down resources.`,
@@ -41,24 +49,35 @@ Use 'gh devlake cleanup' to permanently tear down resources.`,
 	cmd.Flags().StringVar(&stopService, "service", "", "Stop only a specific service (e.g., grafana)")
 	cmd.Flags().BoolVar(&stopAzure, "azure", false, "Force Azure stop mode")
 	cmd.Flags().BoolVar(&stopLocal, "local", false, "Force local (Docker Compose) stop mode")
+	cmd.Flags().BoolVar(&stopK8s, "k8s", false, "Force Kubernetes stop mode")
 	cmd.Flags().StringVar(&stopState, "state-file", "", "Path to state file (auto-detected if omitted)")
+	cmd.Flags().StringVar(&stopInstance, "instance", "", "Stop a named local instance (see 'deploy local --instance')")
 
 	return cmd
 }
 
 func runStop(cmd *cobra.Command, args []string) error {
+	if stopInstance != "" {
+		inst, err := instance.Get(stopInstance)
+		if err != nil {
+			return err
+		}
+		stopState, stopLocal = inst.StateFile(), true
+	}
 	mode := detectStopMode()
 	switch mode {
 	case "local":
 		return runLocalStop()
 	case "azure":
 		return runAzureStop()
+	case "k8s":
+		return runK8sStop()
 	default:
 		return fmt.Errorf("no deployment found — no state file or docker-compose.yml in current directory\nRun 'gh devlake deploy' to create a new deployment")
 	}
 }
 
-// detectStopMode determines whether to stop local (Docker Compose) or Azure resources.
+// detectStopMode determines whether to stop local (Docker Compose), Azure or Kubernetes resources.
 // Priority: explicit flags → explicit state file (inspected for method) → auto-detect files.
 func detectStopMode() string {
 	if stopAzure {
@@ -67,6 +86,9 @@ func detectStopMode() string {
 	if stopLocal {
 		return "local"
 	}
+	if stopK8s {
+		return "k8s"
+	}
 
 	// Check explicit state file — inspect its content rather than guessing from the filename.
 	if stopState != "" {
@@ -81,6 +103,8 @@ func detectStopMode() string {
 					return "azure"
 				case "local", "docker-compose":
 					return "local"
+				case "k8s":
+					return "k8s"
 				}
 				// If method is absent but resourceGroup is set, it's an Azure state file.
 				if meta.ResourceGroup != "" {
@@ -96,6 +120,9 @@ func detectStopMode() string {
 	if _, err := os.Stat(".devlake-azure.json"); err == nil {
 		return "azure"
 	}
+	if _, err := os.Stat(".devlake-k8s.json"); err == nil {
+		return "k8s"
+	}
 	if _, err := os.Stat(".devlake-local.json"); err == nil {
 		return "local"
 	}
@@ -202,6 +229,11 @@ func runAzureStop() error {
 	if state.ResourceGroup == "" {
 		return fmt.Errorf("state file %s has no resource group — cannot stop Azure resources", stateFile)
 	}
+	if state.Private && state.Runtime != azurepkg.RuntimeACA && state.Resources.Network.ServiceZone == "" {
+		fmt.Fprintln(prog, "\n⚠️  Private deployment: containers may get new private IPs when started again,")
+		fmt.Fprintln(prog, "   which the Application Gateway and Config UI will not pick up.")
+		fmt.Fprintln(prog, "   Run 'gh devlake deploy azure --update' once to switch it to stable DNS names.")
+	}
 
 	// ── Check Azure CLI login ──
 	fmt.Fprintln(prog, "\n🔑 Checking Azure login...")
@@ -227,7 +259,7 @@ func runAzureStop() error {
 
 	for _, container := range containers {
 		fmt.Fprintf(prog, "\n📦 Stopping container %q...\n", container)
-		if err := azurepkg.ContainerStop(container, state.ResourceGroup); err != nil {
+		if err := state.stopContainer(container); err != nil {
 			fmt.Fprintf(prog, "   ⚠️  Could not stop %s: %v\n", container, err)
 		} else {
 			fmt.Fprintln(prog, "   ✅ Stop initiated")
@@ -257,3 +289,55 @@ func runAzureStop() error {
 	fmt.Fprintln(prog)
 	return nil
 }
+
+func runK8sStop() error {
+	// In JSON mode, all progress goes to stderr to keep stdout clean for JSON.
+	var prog io.Writer = os.Stdout
+	if outputJSON {
+		prog = os.Stderr
+	}
+
+	fmt.Fprintln(prog)
+	fmt.Fprintln(prog, "════════════════════════════════════════")
+	fmt.Fprintln(prog, "  DevLake Kubernetes — Stop Services")
+	fmt.Fprintln(prog, "════════════════════════════════════════")
+
+	stateFile := stopState
+	if stateFile == "" {
+		stateFile = ".devlake-k8s.json"
+	}
+	state, err := loadK8sState(stateFile)
+	if err != nil {
+		return err
+	}
+	kc := state.kubectl()
+
+	fmt.Fprintln(prog, "\n☸️  Checking kubectl...")
+	if err := kc.CheckAvailable(); err != nil {
+		return err
+	}
+	fmt.Fprintln(prog, "   ✅ Connected")
+
+	if stopService != "" {
+		fmt.Fprintf(prog, "\n☸️  Scaling %q to 0 in namespace %s...\n", stopService, state.Namespace)
+	} else {
+		fmt.Fprintf(prog, "\n☸️  Scaling workloads to 0 in namespace %s...\n", state.Namespace)
+	}
+	if err := kc.Scale(state.selector(stopService), 0); err != nil {
+		return fmt.Errorf("failed to stop workloads: %w", err)
+	}
+	fmt.Fprintln(prog, "   ✅ Workloads scaled down (volume and secrets kept)")
+
+	if outputJSON {
+		return printJSON(map[string]string{"status": "stopped", "mode": "k8s"})
+	}
+
+	fmt.Fprintln(prog)
+	fmt.Fprintln(prog, "════════════════════════════════════════")
+	fmt.Fprintln(prog, "  ✅ Services Stopped!")
+	fmt.Fprintln(prog, "════════════════════════════════════════")
+	fmt.Fprintln(prog)
+	fmt.Fprintln(prog, "  Run 'gh devlake start --k8s' to bring them back up.")
+	fmt.Fprintln(prog)
+	return nil
+}
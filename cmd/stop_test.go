@@ -111,6 +111,22 @@ func TestDetectStopMode_AutoDetect_AzureJson(t *testing.T) {
 	}
 }
 
+func TestDetectStopMode_StateFile_K8sMethod(t *testing.T) {
+	dir := t.TempDir()
+	sf := filepath.Join(dir, "mystate.json")
+	if err := os.WriteFile(sf, []byte(`{"method":"k8s","namespace":"devlake","name":"devlake"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	origState := stopState
+	stopState = sf
+	t.Cleanup(func() { stopState = origState })
+
+	if got := detectStopMode(); got != "k8s" {
+		t.Errorf("expected k8s, got %q", got)
+	}
+}
+
 func TestDetectStopMode_AutoDetect_LocalJson(t *testing.T) {
 	dir := t.TempDir()
 	origWd, _ := os.Getwd()
@@ -0,0 +1,459 @@
+package cmd
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"time"
+
+	"github.com/DevExpGBB/gh-devlake/internal/azure"
+	"github.com/DevExpGBB/gh-devlake/internal/devlake"
+	dockerpkg "github.com/DevExpGBB/gh-devlake/internal/docker"
+	"github.com/DevExpGBB/gh-devlake/internal/envfile"
+	"github.com/DevExpGBB/gh-devlake/internal/instance"
+	"github.com/DevExpGBB/gh-devlake/internal/redact"
+	"github.com/spf13/cobra"
+)
+
+// supportPipelineCount is how many recent pipelines the bundle lists.
+const supportPipelineCount = 20
+
+var (
+	supportOutput   string
+	supportTail     int
+	supportSince    string
+	supportInstance string
+)
+
+// Docker collectors are variables so tests can stub them.
+var (
+	supportComposePS   = dockerpkg.ComposePS
+	supportComposeLogs = dockerpkg.ComposeLogs
+)
+
+// supportBundleResult is the JSON representation of the support-bundle
+// command output.
+type supportBundleResult struct {
+	Archive string   `json:"archive"`
+	Files   []string `json:"files"`
+	Errors  []string `json:"errors"`
+}
+
+// supportInfo is support.json, the bundle's table of contents.
+type supportInfo struct {
+	CLIVersion string   `json:"cliVersion"`
+	GoVersion  string   `json:"goVersion"`
+	OS         string   `json:"os"`
+	Arch       string   `json:"arch"`
+	CreatedAt  string   `json:"createdAt"`
+	StateFile  string   `json:"stateFile,omitempty"`
+	Backend    string   `json:"backend,omitempty"`
+	Files      []string `json:"files"`
+	Errors     []string `json:"errors"`
+}
+
+// supportConnection is a connection without its credentials.
+type supportConnection struct {
+	Plugin       string         `json:"plugin"`
+	ID           int            `json:"id"`
+	Name         string         `json:"name"`
+	Endpoint     string         `json:"endpoint,omitempty"`
+	Proxy        string         `json:"proxy,omitempty"`
+	AuthMethod   string         `json:"authMethod,omitempty"`
+	Organization string         `json:"organization,omitempty"`
+	Enterprise   string         `json:"enterprise,omitempty"`
+	Scopes       []supportScope `json:"scopes"`
+}
+
+type supportScope struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	FullName string `json:"fullName,omitempty"`
+}
+
+type supportInventory struct {
+	Connections []supportConnection `json:"connections"`
+	Projects    []devlake.Project   `json:"projects"`
+}
+
+type supportPipelines struct {
+	Pipelines   []devlake.Pipeline `json:"pipelines"`
+	FailedTasks []devlake.Task     `json:"failedTasks"`
+}
+
+// supportBundle writes redacted entries to a zip archive and records what
+// was collected and what could not be.
+type supportBundle struct {
+	zw     *zip.Writer
+	r      *redact.Redactor
+	files  []string
+	errors []string
+}
+
+// add writes an entry; data must already be redacted.
+func (b *supportBundle) add(name string, data []byte) {
+	w, err := b.zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+	if err == nil {
+		_, err = w.Write(data)
+	}
+	if err != nil {
+		b.fail(name, err)
+		return
+	}
+	b.files = append(b.files, name)
+}
+
+// addText redacts free text and writes it.
+func (b *supportBundle) addText(name string, data []byte) {
+	b.add(name, []byte(b.r.Text(string(data))))
+}
+
+// addJSON marshals v, masks secret-named fields and any credential-shaped
+// text, and writes it.
+func (b *supportBundle) addJSON(name string, v any) {
+	data, err := json.Marshal(v)
+	if err == nil {
+		data, err = b.r.JSON(data)
+	}
+	if err != nil {
+		b.fail(name, err)
+		return
+	}
+	b.add(name, data)
+}
+
+// fail records something that could not be collected.
+func (b *supportBundle) fail(what string, err error) {
+	b.errors = append(b.errors, b.r.Text(what+": "+err.Error()))
+}
+
+func newSupportBundleCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "support-bundle",
+		Short: "Collect a redacted zip of diagnostics for a bug report",
+		Long: `Collects what is needed to diagnose a problem into one zip archive, with
+secrets masked, to attach to a bug report:
+
+  • The state file and .env (passwords, tokens and keys masked)
+  • docker compose ps and recent container logs (local), or
+    container logs from Azure (az container logs / az containerapp logs)
+  • Connections, scopes and projects from the DevLake API (no tokens)
+  • Recent pipelines and the errors of their failed tasks
+  • The 'gh devlake doctor' report and CLI version information
+
+Anything that cannot be collected (Docker not running, backend down) is
+listed in support.json and the rest is still bundled. Secrets are masked
+by name (PASSWORD, SECRET, TOKEN, KEY...), by shape (GitHub tokens, JWTs,
+URL passwords, private keys) and by value (every secret in .env). Review
+the archive before sharing it.
+
+Example:
+  gh devlake support-bundle
+  gh devlake support-bundle --tail 2000 --since 72h
+  gh devlake support-bundle --instance staging --output staging-support.zip`,
+		Args: cobra.NoArgs,
+		RunE: runSupportBundle,
+	}
+	cmd.Flags().StringVarP(&supportOutput, "output", "o", "", "Archive path (default devlake-support-<timestamp>.zip)")
+	cmd.Flags().IntVar(&supportTail, "tail", 500, "Log lines to keep per container")
+	cmd.Flags().StringVar(&supportSince, "since", "24h", "Only keep local container logs newer than this (e.g. 1h, 72h); empty for all")
+	cmd.Flags().StringVar(&supportInstance, "instance", "", "Collect from a named local instance (see 'deploy local --instance')")
+	cmd.GroupID = "operate"
+	return cmd
+}
+
+func init() {
+	rootCmd.AddCommand(newSupportBundleCmd())
+}
+
+func runSupportBundle(cmd *cobra.Command, args []string) error {
+	if supportTail <= 0 {
+		return fmt.Errorf("--tail must be positive")
+	}
+	output := supportOutput
+	if output == "" {
+		output = fmt.Sprintf("devlake-support-%s.zip", time.Now().Format("20060102-150405"))
+	}
+	// Resolve before switching to the instance directory.
+	output, err := filepath.Abs(output)
+	if err != nil {
+		return err
+	}
+	if supportInstance != "" {
+		inst, err := instance.Get(supportInstance)
+		if err != nil {
+			return err
+		}
+		if err := os.Chdir(inst.Dir); err != nil {
+			return fmt.Errorf("instance %q directory %s: %w", inst.Name, inst.Dir, err)
+		}
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	if !outputJSON {
+		printBanner("DevLake Support Bundle")
+		fmt.Println("\n📦 Collecting diagnostics...")
+	}
+	result, err := writeSupportBundle(dir, output, cfgURL, supportTail, supportSince)
+	if err != nil {
+		return err
+	}
+
+	if outputJSON {
+		return printJSON(result)
+	}
+	for _, e := range result.Errors {
+		fmt.Printf("   ⚠️  %s\n", firstLine(e))
+	}
+	fmt.Printf("\n✅ Wrote %s (%d files)\n", result.Archive, len(result.Files))
+	fmt.Println("   Secrets are masked, but review the archive before sharing it.")
+	return nil
+}
+
+// writeSupportBundle collects diagnostics for the deployment in dir into a
+// zip archive at output. Collection failures are recorded in the result and
+// in support.json; only failing to write the archive is an error.
+func writeSupportBundle(dir, output, explicitURL string, tail int, since string) (*supportBundleResult, error) {
+	f, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("creating %s: %w", output, err)
+	}
+	b := &supportBundle{zw: zip.NewWriter(f), r: redact.New()}
+	info := supportInfo{
+		CLIVersion: version,
+		GoVersion:  runtime.Version(),
+		OS:         runtime.GOOS,
+		Arch:       runtime.GOARCH,
+		CreatedAt:  time.Now().UTC().Format(time.RFC3339),
+	}
+
+	// Secret values from .env are masked wherever they appear, so read it
+	// before anything else.
+	envPath := filepath.Join(dir, ".env")
+	if raw, err := os.ReadFile(envPath); err == nil {
+		if values, err := envfile.Load(envPath); err == nil {
+			for k, v := range values {
+				if redact.SensitiveKey(k) {
+					b.r.AddSecret(v)
+				}
+			}
+		}
+		b.add("env.redacted", b.r.Env(raw))
+	} else if !os.IsNotExist(err) {
+		b.fail(".env", err)
+	}
+
+	for _, name := range []string{".devlake-azure.json", ".devlake-k8s.json", ".devlake-local.json"} {
+		raw, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			if !os.IsNotExist(err) {
+				b.fail(name, err)
+			}
+			continue
+		}
+		if info.StateFile == "" {
+			info.StateFile = name
+		}
+		if data, err := b.r.JSON(raw); err == nil {
+			b.add("state/"+name, data)
+		} else {
+			b.addText("state/"+name, raw)
+		}
+	}
+
+	switch info.StateFile {
+	case "", ".devlake-local.json":
+		collectLocalLogs(b, dir, tail, since)
+	case ".devlake-azure.json":
+		collectAzureLogs(b, filepath.Join(dir, info.StateFile), tail)
+	}
+
+	report := runDoctorChecks(dir, explicitURL)
+	b.addJSON("doctor.json", report)
+	info.Backend = report.Backend
+	if report.Backend != "" {
+		client := devlake.NewClient(report.Backend)
+		if err := client.Ping(); err != nil {
+			b.fail("devlake api", err)
+		} else {
+			b.addJSON("devlake/inventory.json", collectSupportInventory(b, client))
+			b.addJSON("devlake/pipelines.json", collectSupportPipelines(b, client))
+		}
+	} else {
+		b.fail("devlake api", fmt.Errorf("no backend found"))
+	}
+
+	info.Files = append(b.files, "support.json")
+	info.Errors = b.errors
+	if info.Errors == nil {
+		info.Errors = []string{}
+	}
+	b.addJSON("support.json", info)
+
+	if err := b.zw.Close(); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("writing %s: %w", output, err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("writing %s: %w", output, err)
+	}
+	return &supportBundleResult{Archive: output, Files: info.Files, Errors: info.Errors}, nil
+}
+
+// collectLocalLogs adds docker compose ps and the logs of every service.
+func collectLocalLogs(b *supportBundle, dir string, tail int, since string) {
+	services, err := supportComposePS(dir)
+	if err != nil {
+		b.fail("docker compose ps", err)
+		return
+	}
+	b.addJSON("docker/compose-ps.json", services)
+	seen := map[string]bool{}
+	for _, s := range services {
+		if seen[s.Service] {
+			continue
+		}
+		seen[s.Service] = true
+		out, err := supportComposeLogs(dir, s.Service, tail, since)
+		if err != nil {
+			b.fail("logs "+s.Service, err)
+			continue
+		}
+		b.addText("logs/"+s.Service+".log", out)
+	}
+}
+
+// collectAzureLogs adds the logs of the container groups or container apps
+// recorded in an Azure state file.
+func collectAzureLogs(b *supportBundle, stateFile string, tail int) {
+	state, err := loadAzureStateData(stateFile)
+	if err != nil {
+		b.fail("azure logs", err)
+		return
+	}
+	for _, name := range state.Resources.Containers {
+		if state.Runtime == azure.RuntimeACA {
+			out, err := azure.ContainerAppLogs(name, state.ResourceGroup, tail)
+			if err != nil {
+				b.fail("logs "+name, err)
+				continue
+			}
+			b.addText("logs/"+name+".log", out)
+			continue
+		}
+		images, err := azure.ContainerGroupImages(name, state.ResourceGroup)
+		if err != nil {
+			b.fail("logs "+name, err)
+			continue
+		}
+		containers := make([]string, 0, len(images))
+		for c := range images {
+			containers = append(containers, c)
+		}
+		sort.Strings(containers)
+		for _, c := range containers {
+			out, err := azure.ContainerLogs(name, state.ResourceGroup, c)
+			if err != nil {
+				b.fail("logs "+name+"/"+c, err)
+				continue
+			}
+			b.addText(fmt.Sprintf("logs/%s-%s.log", name, c), tailLines(out, tail))
+		}
+	}
+}
+
+// tailLines keeps the last n lines of data.
+func tailLines(data []byte, n int) []byte {
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	if len(lines) > 0 && len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) <= n {
+		return data
+	}
+	return bytes.Join(lines[len(lines)-n:], nil)
+}
+
+// collectSupportInventory lists connections (without credentials) with
+// their scopes, and projects.
+func collectSupportInventory(b *supportBundle, client *devlake.Client) supportInventory {
+	inv := supportInventory{Connections: []supportConnection{}, Projects: []devlake.Project{}}
+	for _, def := range AvailableConnections() {
+		conns, err := client.ListConnections(def.Plugin)
+		if err != nil {
+			b.fail(def.Plugin+" connections", err)
+			continue
+		}
+		for _, c := range conns {
+			sc := supportConnection{
+				Plugin:       def.Plugin,
+				ID:           c.ID,
+				Name:         c.Name,
+				Endpoint:     c.Endpoint,
+				Proxy:        c.Proxy,
+				AuthMethod:   c.AuthMethod,
+				Organization: c.Organization,
+				Enterprise:   c.Enterprise,
+				Scopes:       []supportScope{},
+			}
+			scopes, err := client.ListAllScopes(def.Plugin, c.ID)
+			if err != nil {
+				b.fail(fmt.Sprintf("%s connection %d scopes", def.Plugin, c.ID), err)
+			}
+			for i := range scopes {
+				s := &scopes[i]
+				id := s.ScopeName()
+				if def.ScopeIDField != "" {
+					if v := devlake.ExtractScopeID(s.RawScope, def.ScopeIDField); v != "" {
+						id = v
+					}
+				}
+				sc.Scopes = append(sc.Scopes, supportScope{ID: id, Name: s.ScopeName(), FullName: s.ScopeFullName()})
+			}
+			inv.Connections = append(inv.Connections, sc)
+		}
+	}
+	projects, err := client.ListProjects()
+	if err != nil {
+		b.fail("projects", err)
+	} else if projects != nil {
+		inv.Projects = projects
+	}
+	return inv
+}
+
+// collectSupportPipelines lists recent pipelines and the failed tasks of
+// those that did not complete.
+func collectSupportPipelines(b *supportBundle, client *devlake.Client) supportPipelines {
+	out := supportPipelines{Pipelines: []devlake.Pipeline{}, FailedTasks: []devlake.Task{}}
+	resp, err := client.ListPipelines("", 0, 1, supportPipelineCount)
+	if err != nil {
+		b.fail("pipelines", err)
+		return out
+	}
+	out.Pipelines = append(out.Pipelines, resp.Pipelines...)
+	for _, p := range resp.Pipelines {
+		if p.Status != "TASK_FAILED" && p.Status != "TASK_PARTIAL" {
+			continue
+		}
+		tasks, err := client.ListPipelineTasks(p.ID)
+		if err != nil {
+			b.fail(fmt.Sprintf("pipeline %d tasks", p.ID), err)
+			continue
+		}
+		for _, t := range tasks {
+			if t.Status == "TASK_FAILED" {
+				out.FailedTasks = append(out.FailedTasks, t)
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/DevExpGBB/gh-devlake/internal/azure"
+	"github.com/DevExpGBB/gh-devlake/internal/token"
+)
+
+func newTokenCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "token",
+		Short: "Store, remove, and rotate plugin tokens",
+		Long: `Stores plugin tokens in the OS keychain so they don't have to live in a
+plaintext .devlake.env file, and rotates a PAT across every connection using it.
+
+Stored tokens are picked up automatically by token resolution:
+  --token flag → .devlake.env → environment variable → OS keychain
+  → gh auth token (GitHub/Copilot) → masked prompt
+
+On Linux the freedesktop Secret Service is used (via secret-tool). Set
+$GH_DEVLAKE_KEYCHAIN_FILE to use a file-backed store instead.
+
+Examples:
+  gh devlake token store --plugin github
+  gh devlake token store --plugin gitlab --token glpat-xxx
+  gh devlake token remove --plugin github
+  gh devlake token rotate --plugin github,gh-copilot`,
+	}
+	cmd.GroupID = "configure"
+	cmd.AddCommand(newTokenStoreCmd(), newTokenRemoveCmd(), newTokenRotateCmd())
+	return cmd
+}
+
+func init() {
+	rootCmd.AddCommand(newTokenCmd())
+	token.KeyVaultSecret = azure.KeyVaultSecret // for azkv: references
+}
+
+type tokenStoreOpts struct {
+	Plugin string
+	Token  string
+}
+
+func newTokenStoreCmd() *cobra.Command {
+	var opts tokenStoreOpts
+	cmd := &cobra.Command{
+		Use:   "store",
+		Short: "Store a plugin token in the OS keychain",
+		Long: `Stores a plugin token in the OS keychain, replacing any token already stored
+for that plugin.
+
+The token is read from --token, from stdin when piped, or from a masked prompt.
+A secret reference (env:, file:, cmd:, azkv:) is stored as the reference and
+resolved each time the token is used, so the keychain never holds its value.
+
+Examples:
+  gh devlake token store --plugin github
+  echo "$GITLAB_TOKEN" | gh devlake token store --plugin gitlab`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runTokenStore(opts, os.Stdin)
+		},
+	}
+	cmd.Flags().StringVar(&opts.Plugin, "plugin", "", fmt.Sprintf("Plugin slug (%s)", strings.Join(availablePluginSlugs(), ", ")))
+	cmd.Flags().StringVar(&opts.Token, "token", "", "Token to store (prompted if omitted; an env:, file:, cmd:, azkv: reference is stored as the reference)")
+	return cmd
+}
+
+func runTokenStore(opts tokenStoreOpts, stdin io.Reader) error {
+	if opts.Plugin == "" {
+		return errPluginRequired()
+	}
+	def, err := requirePlugin(opts.Plugin)
+	if err != nil {
+		return err
+	}
+	kc, err := token.OpenKeychain()
+	if err != nil {
+		return err
+	}
+
+	tok := strings.TrimSpace(opts.Token)
+	if tok == "" {
+		tok, err = readTokenInput(def, stdin)
+		if err != nil {
+			return err
+		}
+	}
+	// A secret reference is stored as is, so the keychain never holds the
+	// value it points to; resolving it now catches typos early.
+	if _, err := token.ResolveSecretRef(tok); err != nil {
+		return err
+	}
+
+	if err := kc.Set(def.Plugin, tok); err != nil {
+		return fmt.Errorf("storing %s token: %w", def.DisplayName, err)
+	}
+	if outputJSON {
+		return printJSON(map[string]string{"plugin": def.Plugin, "keychain": kc.Name(), "status": "stored"})
+	}
+	shown := maskToken(tok)
+	if token.IsSecretRef(tok) {
+		shown = "reference " + tok
+	}
+	fmt.Printf("🔐 %s token stored in %s (%s)\n", def.DisplayName, kc.Name(), shown)
+	return nil
+}
+
+// errPluginRequired is returned when --plugin is missing.
+func errPluginRequired() error {
+	return fmt.Errorf("--plugin is required — choose: %s", strings.Join(availablePluginSlugs(), ", "))
+}
+
+// readTokenInput reads a token from a masked prompt, or from stdin when it
+// is not a terminal (e.g. piped from a secret manager).
+func readTokenInput(def *ConnectionDef, stdin io.Reader) (string, error) {
+	if f, ok := stdin.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		return token.PromptMasked(def.DisplayName, def.ScopeHint)
+	}
+	data, err := io.ReadAll(io.LimitReader(stdin, 64*1024))
+	if err != nil {
+		return "", fmt.Errorf("reading token from stdin: %w", err)
+	}
+	tok := strings.TrimSpace(string(data))
+	if tok == "" {
+		return "", fmt.Errorf("no token provided — pass --token or pipe it on stdin")
+	}
+	return tok, nil
+}
+
+func newTokenRemoveCmd() *cobra.Command {
+	var plugin string
+	cmd := &cobra.Command{
+		Use:   "remove",
+		Short: "Remove a stored plugin token from the OS keychain",
+		Long: `Removes the token stored for a plugin from the OS keychain.
+
+Existing DevLake connections keep working; only future token resolution is affected.
+
+Example:
+  gh devlake token remove --plugin github`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runTokenRemove(plugin)
+		},
+	}
+	cmd.Flags().StringVar(&plugin, "plugin", "", fmt.Sprintf("Plugin slug (%s)", strings.Join(availablePluginSlugs(), ", ")))
+	return cmd
+}
+
+func runTokenRemove(plugin string) error {
+	if plugin == "" {
+		return errPluginRequired()
+	}
+	def, err := requirePlugin(plugin)
+	if err != nil {
+		return err
+	}
+	kc, err := token.OpenKeychain()
+	if err != nil {
+		return err
+	}
+
+	status := "removed"
+	if err := kc.Delete(def.Plugin); errors.Is(err, token.ErrNotFound) {
+		status = "not-found"
+	} else if err != nil {
+		return fmt.Errorf("removing %s token: %w", def.DisplayName, err)
+	}
+	if outputJSON {
+		return printJSON(map[string]string{"plugin": def.Plugin, "keychain": kc.Name(), "status": status})
+	}
+	if status == "not-found" {
+		fmt.Printf("No %s token stored in %s\n", def.DisplayName, kc.Name())
+		return nil
+	}
+	fmt.Printf("🗑️  %s token removed from %s\n", def.DisplayName, kc.Name())
+	return nil
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/DevExpGBB/gh-devlake/internal/prompt"
+	"github.com/DevExpGBB/gh-devlake/internal/token"
+)
+
+// tokenExpiryWarnWindow is how close to expiry a token must be before the
+// pre-flight check warns about it.
+const tokenExpiryWarnWindow = 14 * 24 * time.Hour
+
+// githubTokenProbes returns fine-grained permission probes for the GitHub plugin.
+func githubTokenProbes(org, _ string) []token.Probe {
+	if org == "" {
+		return []token.Probe{
+			{Name: "Repository metadata (read)", Path: "/user/repos?per_page=1"},
+		}
+	}
+	return []token.Probe{
+		{Name: "Repository metadata (read)", Path: "/orgs/" + org + "/repos?per_page=1"},
+		{Name: "Organization members (read)", Path: "/orgs/" + org + "/members?per_page=1"},
+	}
+}
+
+// copilotTokenProbes returns fine-grained permission probes for the Copilot plugin.
+func copilotTokenProbes(org, enterprise string) []token.Probe {
+	var probes []token.Probe
+	if org != "" {
+		probes = append(probes, token.Probe{Name: "Organization: GitHub Copilot Business (read)", Path: "/orgs/" + org + "/copilot/billing"})
+	}
+	if enterprise != "" {
+		probes = append(probes, token.Probe{Name: "Enterprise: Copilot billing (read)", Path: "/enterprises/" + enterprise + "/copilot/billing/seats?per_page=1"})
+	}
+	return probes
+}
+
+// preflightToken inspects a PAT against the GitHub API and reports missing
+// scopes, failed permission probes, pending SSO authorization, and upcoming
+// expiry. It returns false only when problems were found and the user chose
+// not to continue; in flag mode problems are reported and creation proceeds,
+// leaving the DevLake connection test as the final check.
+func preflightToken(def *ConnectionDef, params ConnectionParams, interactive bool) bool {
+	endpoint := params.Endpoint
+	if endpoint == "" {
+		endpoint = def.Endpoint
+	}
+	fmt.Println("   🔍 Checking token capabilities...")
+	in, err := token.InspectGitHub(token.InspectOpts{
+		Endpoint:       endpoint,
+		Token:          params.Token,
+		Org:            params.Org,
+		RequiredScopes: def.RequiredScopes,
+		Probes:         def.TokenProbes(params.Org, params.Enterprise),
+	})
+	if err != nil {
+		fmt.Printf("   ⚠️  Could not inspect token: %v\n", err)
+		return true
+	}
+
+	problems := printTokenInspection(in, time.Now())
+	if problems == 0 || !interactive {
+		return true
+	}
+	fmt.Println()
+	return prompt.Confirm("   Continue anyway?")
+}
+
+// printTokenInspection prints an inspection report and returns the number of
+// problems that would likely make the connection fail (missing scopes,
+// failed probes, or unauthorized SSO).
+func printTokenInspection(in *token.Inspection, now time.Time) int {
+	problems := 0
+	who := ""
+	if in.Login != "" {
+		who = " for @" + in.Login
+	}
+
+	switch in.Kind {
+	case token.KindClassic:
+		fmt.Printf("   Classic PAT%s — scopes: %s\n", who, orNone(strings.Join(in.Scopes, ", ")))
+		if len(in.Missing) > 0 {
+			fmt.Printf("   ❌ Missing scopes: %s\n", strings.Join(in.Missing, ", "))
+			problems += len(in.Missing)
+		} else {
+			fmt.Println("   ✅ All required scopes granted")
+		}
+	default:
+		label := "Fine-grained PAT"
+		if in.Kind == token.KindUnknown {
+			label = "Token"
+		}
+		fmt.Printf("   %s%s — no OAuth scopes reported, probing permissions\n", label, who)
+		for _, p := range in.Probes {
+			if p.OK {
+				fmt.Printf("   ✅ %s\n", p.Name)
+				continue
+			}
+			status := "unreachable"
+			if p.Status != 0 {
+				status = fmt.Sprintf("HTTP %d", p.Status)
+			}
+			fmt.Printf("   ❌ %s (%s)\n", p.Name, status)
+			problems++
+		}
+	}
+
+	if in.SSOURL != "" {
+		fmt.Println("   ⚠️  Token is not authorized for the organization's SAML SSO")
+		fmt.Printf("      Authorize it at: %s\n", in.SSOURL)
+		problems++
+	}
+
+	if !in.ExpiresAt.IsZero() {
+		left := in.ExpiresAt.Sub(now)
+		switch {
+		case left <= 0:
+			fmt.Printf("   ❌ Token expired on %s\n", in.ExpiresAt.Format("2006-01-02"))
+			problems++
+		case left <= tokenExpiryWarnWindow:
+			fmt.Printf("   ⚠️  Token expires %s (in %d days) — data collection will stop after that\n",
+				in.ExpiresAt.Format("2006-01-02"), int(left.Hours()/24))
+		default:
+			fmt.Printf("   Token expires %s\n", in.ExpiresAt.Format("2006-01-02"))
+		}
+	}
+	return problems
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
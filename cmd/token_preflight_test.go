@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DevExpGBB/gh-devlake/internal/token"
+)
+
+func TestTokenProbes(t *testing.T) {
+	if got := githubTokenProbes("", ""); len(got) != 1 || got[0].Path != "/user/repos?per_page=1" {
+		t.Errorf("github probes without org = %+v", got)
+	}
+	if got := githubTokenProbes("acme", ""); len(got) != 2 || got[0].Path != "/orgs/acme/repos?per_page=1" {
+		t.Errorf("github probes with org = %+v", got)
+	}
+	got := copilotTokenProbes("acme", "bigco")
+	if len(got) != 2 || got[0].Path != "/orgs/acme/copilot/billing" || got[1].Path != "/enterprises/bigco/copilot/billing/seats?per_page=1" {
+		t.Errorf("copilot probes = %+v", got)
+	}
+	if got := copilotTokenProbes("", ""); len(got) != 0 {
+		t.Errorf("copilot probes without org/enterprise = %+v", got)
+	}
+}
+
+func TestPrintTokenInspection_Problems(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		in   token.Inspection
+		want int
+	}{
+		{"classic ok", token.Inspection{Kind: token.KindClassic, Scopes: []string{"repo"}}, 0},
+		{"classic missing", token.Inspection{Kind: token.KindClassic, Missing: []string{"read:org", "read:user"}}, 2},
+		{"fine-grained failed probe", token.Inspection{Kind: token.KindFineGrained, Probes: []token.ProbeResult{
+			{Probe: token.Probe{Name: "a"}, Status: 200, OK: true},
+			{Probe: token.Probe{Name: "b"}, Status: 403},
+		}}, 1},
+		{"sso required", token.Inspection{Kind: token.KindClassic, SSOURL: "https://github.com/orgs/acme/sso"}, 1},
+		{"expiring soon is a warning", token.Inspection{Kind: token.KindClassic, ExpiresAt: now.Add(3 * 24 * time.Hour)}, 0},
+		{"expired", token.Inspection{Kind: token.KindClassic, ExpiresAt: now.Add(-time.Hour)}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := tt.in
+			if got := printTokenInspection(&in, now); got != tt.want {
+				t.Errorf("problems = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,410 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/DevExpGBB/gh-devlake/internal/devlake"
+	"github.com/DevExpGBB/gh-devlake/internal/token"
+)
+
+// tokenRotateOpts holds the flags for `token rotate`.
+type tokenRotateOpts struct {
+	Plugins       string
+	Org           string
+	Token         string
+	PreviousToken string
+	EnvFile       string
+	DryRun        bool
+}
+
+func newTokenRotateCmd() *cobra.Command {
+	var opts tokenRotateOpts
+	cmd := &cobra.Command{
+		Use:   "rotate",
+		Short: "Rotate a PAT on every connection that uses it",
+		Long: `Replaces the token on every matching connection in one step.
+
+GitHub and Copilot connections (often across several orgs) usually share one
+PAT. rotate finds every connection for the given plugins (optionally limited
+to one org), tests the new token against each of them, and only then updates
+them. If any update fails, connections already updated are rolled back to the
+previous token.
+
+DevLake never returns stored tokens, so the previous token for rollback comes
+from --previous-token or the usual resolution chain (.devlake.env, environment,
+OS keychain). Without one, a partial failure is reported but cannot be undone.
+
+The new token is read from --token, from stdin when piped, or from a masked
+prompt. A token stored with 'gh devlake token store' is replaced as well.
+
+GitHub App connections are skipped.
+
+Examples:
+  gh devlake token rotate --plugin github,gh-copilot
+  gh devlake token rotate --plugin github --org my-org --token ghp_new
+  gh devlake token rotate --plugin github,gh-copilot --dry-run`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runTokenRotate(&opts, os.Stdin)
+		},
+	}
+	cmd.Flags().StringVar(&opts.Plugins, "plugin", "", "Comma-separated plugin slugs (e.g. github,gh-copilot)")
+	cmd.Flags().StringVar(&opts.Org, "org", "", "Only rotate connections for this organization")
+	cmd.Flags().StringVar(&opts.Token, "token", "", "New token (prompted if omitted; accepts env:, file:, cmd:, azkv: references)")
+	cmd.Flags().StringVar(&opts.PreviousToken, "previous-token", "", "Current token, used to roll back on partial failure (accepts env:, file:, cmd:, azkv: references)")
+	cmd.Flags().StringVar(&opts.EnvFile, "env-file", ".devlake.env", "Path to env file holding the current token")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Test the new token against each connection without updating")
+	return cmd
+}
+
+// rotateTarget is a connection selected for rotation.
+type rotateTarget struct {
+	def  *ConnectionDef
+	conn devlake.Connection
+}
+
+// rotateConnResult is the outcome for one connection.
+type rotateConnResult struct {
+	Plugin       string `json:"plugin"`
+	ConnectionID int    `json:"connectionId"`
+	Name         string `json:"name"`
+	Organization string `json:"organization,omitempty"`
+	Status       string `json:"status"` // tested, updated, test-failed, update-failed, rolled-back, rollback-failed, not-rolled-back, skipped
+	Error        string `json:"error,omitempty"`
+}
+
+// tokenRotateResult is the JSON summary of a rotation.
+type tokenRotateResult struct {
+	DryRun          bool               `json:"dryRun"`
+	Connections     []rotateConnResult `json:"connections"`
+	RolledBack      bool               `json:"rolledBack"`
+	KeychainUpdated []string           `json:"keychainUpdated,omitempty"`
+}
+
+// parseRotatePlugins resolves a comma-separated plugin list. BasicAuth
+// plugins are rejected because their secret is a password, not a token.
+func parseRotatePlugins(list string) ([]*ConnectionDef, error) {
+	var defs []*ConnectionDef
+	seen := map[string]bool{}
+	for _, slug := range strings.Split(list, ",") {
+		slug = strings.TrimSpace(slug)
+		if slug == "" {
+			continue
+		}
+		def, err := requirePlugin(slug)
+		if err != nil {
+			return nil, err
+		}
+		if def.NeedsUsername {
+			return nil, fmt.Errorf("%s uses username/password auth — rotate it with 'gh devlake configure connection update'", def.DisplayName)
+		}
+		if !seen[def.Plugin] {
+			seen[def.Plugin] = true
+			defs = append(defs, def)
+		}
+	}
+	if len(defs) == 0 {
+		return nil, fmt.Errorf("--plugin is required — pass one or more plugin slugs, e.g. --plugin github,gh-copilot")
+	}
+	return defs, nil
+}
+
+// findRotateTargets lists connections for each plugin, keeping those that
+// match org (case-insensitive; empty = all). GitHub App connections are
+// returned separately as skipped.
+func findRotateTargets(client *devlake.Client, defs []*ConnectionDef, org string) ([]rotateTarget, []rotateConnResult, error) {
+	var targets []rotateTarget
+	var skipped []rotateConnResult
+	for _, def := range defs {
+		conns, err := client.ListConnections(def.Plugin)
+		if err != nil {
+			return nil, nil, fmt.Errorf("listing %s connections: %w", def.Plugin, err)
+		}
+		for _, c := range conns {
+			if org != "" && !strings.EqualFold(c.Organization, org) {
+				continue
+			}
+			if c.AuthMethod == appAuthMethod {
+				skipped = append(skipped, rotateConnResult{
+					Plugin: def.Plugin, ConnectionID: c.ID, Name: c.Name, Organization: c.Organization,
+					Status: "skipped", Error: "uses GitHub App auth",
+				})
+				continue
+			}
+			targets = append(targets, rotateTarget{def: def, conn: c})
+		}
+	}
+	return targets, skipped, nil
+}
+
+func runTokenRotate(opts *tokenRotateOpts, stdin io.Reader) error {
+	defs, err := parseRotatePlugins(opts.Plugins)
+	if err != nil {
+		return err
+	}
+
+	// In JSON mode, progress goes to stderr to keep stdout clean for JSON.
+	var prog io.Writer = os.Stdout
+	if outputJSON {
+		prog = os.Stderr
+	}
+	fmt.Fprintln(prog)
+	fmt.Fprintln(prog, "════════════════════════════════════════")
+	fmt.Fprintln(prog, "  DevLake — Rotate Token")
+	fmt.Fprintln(prog, "════════════════════════════════════════")
+
+	newTok := strings.TrimSpace(opts.Token)
+	if newTok == "" {
+		if newTok, err = readTokenInput(rotatePromptDef(defs), stdin); err != nil {
+			return err
+		}
+	}
+	newRef := newTok // what the keychain stores: the reference, not its value
+	if newTok, err = token.ResolveSecretRef(newTok); err != nil {
+		return err
+	}
+
+	var prevToks map[string]string
+	if !opts.DryRun {
+		if prevToks, err = resolvePreviousTokens(prog, defs, opts.PreviousToken, opts.EnvFile, newTok); err != nil {
+			return err
+		}
+	}
+
+	disc, err := devlake.Discover(cfgURL)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(prog, "\n🔍 Backend API: %s (via %s)\n", disc.URL, disc.Source)
+	client := devlake.NewClient(disc.URL)
+
+	result, err := rotateConnections(client, prog, defs, opts.Org, newTok, prevToks, opts.DryRun)
+	if result != nil && err == nil && !opts.DryRun {
+		result.KeychainUpdated = updateStoredTokens(prog, defs, newRef)
+	}
+	if result != nil {
+		if perr := finishTokenRotate(prog, result); perr != nil {
+			return perr
+		}
+	}
+	return err
+}
+
+// rotatePromptDef describes the new token in the prompt: the plugin itself,
+// or all of them when one token is rotated for several plugins.
+func rotatePromptDef(defs []*ConnectionDef) *ConnectionDef {
+	if len(defs) == 1 {
+		return defs[0]
+	}
+	var names, hints []string
+	seen := map[string]bool{}
+	for _, def := range defs {
+		names = append(names, def.DisplayName)
+		if def.ScopeHint != "" && !seen[def.ScopeHint] {
+			seen[def.ScopeHint] = true
+			hints = append(hints, def.ScopeHint)
+		}
+	}
+	return &ConnectionDef{DisplayName: strings.Join(names, " / "), ScopeHint: strings.Join(hints, "; ")}
+}
+
+// resolvePreviousTokens returns the token each plugin's connections can be
+// rolled back to, keyed by plugin: --previous-token for all of them, or each
+// plugin's own token from the usual resolution chain. A plugin without one,
+// or whose resolved token already equals newTok, is left out.
+func resolvePreviousTokens(prog io.Writer, defs []*ConnectionDef, previous, envFile, newTok string) (map[string]string, error) {
+	prevTok, err := token.ResolveSecretRef(previous)
+	if err != nil {
+		return nil, err
+	}
+	prevToks := map[string]string{}
+	if prevTok != "" {
+		for _, def := range defs {
+			prevToks[def.Plugin] = prevTok
+		}
+		return prevToks, nil
+	}
+	fmt.Fprintln(prog)
+	for _, def := range defs {
+		ro := def.tokenResolveOpts("", envFile)
+		ro.UseGHAuth = false
+		ro.NoPrompt = true
+		r, err := token.Resolve(ro)
+		switch {
+		case err != nil:
+			fmt.Fprintf(prog, "⚠️  Previous %s token not found — its connections cannot be rolled back on a partial failure (pass --previous-token)\n", def.DisplayName)
+		case r.Token == newTok:
+			fmt.Fprintf(prog, "⚠️  The stored %s token (%s) already equals the new token — there is nothing older to roll back to (pass --previous-token)\n", def.DisplayName, r.Describe())
+		default:
+			prevToks[def.Plugin] = r.Token
+			fmt.Fprintf(prog, "🔑 Previous %s token (for rollback) loaded from: %s\n", def.DisplayName, r.Describe())
+		}
+	}
+	return prevToks, nil
+}
+
+// rotateConnections tests newTok against every target, then updates them.
+// On a failed update, connections already updated are reset to their
+// plugin's token in prevToks.
+func rotateConnections(client *devlake.Client, prog io.Writer, defs []*ConnectionDef, org, newTok string, prevToks map[string]string, dryRun bool) (*tokenRotateResult, error) {
+	fmt.Fprintln(prog, "\n📋 Finding connections...")
+	targets, skipped, err := findRotateTargets(client, defs, org)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range skipped {
+		fmt.Fprintf(prog, "   ⏭️  %s/%d %q — %s\n", s.Plugin, s.ConnectionID, s.Name, s.Error)
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no matching connections found")
+	}
+	fmt.Fprintf(prog, "   %d connection(s) to rotate\n", len(targets))
+
+	result := &tokenRotateResult{DryRun: dryRun}
+	results := make([]rotateConnResult, len(targets))
+	for i, t := range targets {
+		results[i] = rotateConnResult{Plugin: t.def.Plugin, ConnectionID: t.conn.ID, Name: t.conn.Name, Organization: t.conn.Organization}
+	}
+	defer func() { result.Connections = append(results, skipped...) }()
+
+	// ── Test the new token against every connection before touching any ──
+	fmt.Fprintln(prog, "\n🔑 Testing new token...")
+	testFailed := 0
+	for i, t := range targets {
+		params := ConnectionParams{
+			Token:      newTok,
+			Org:        t.conn.Organization,
+			Enterprise: t.conn.Enterprise,
+			Endpoint:   t.conn.Endpoint,
+			Proxy:      t.conn.Proxy,
+		}
+		if !t.def.SupportsTest {
+			results[i].Status = "tested"
+			fmt.Fprintf(prog, "   ➖ %s/%d %q — plugin has no connection test\n", t.def.Plugin, t.conn.ID, t.conn.Name)
+			continue
+		}
+		res, err := client.TestConnection(t.def.Plugin, t.def.BuildTestRequest(t.conn.Name, params))
+		if err == nil && !res.Success {
+			err = errors.New(res.Message)
+		}
+		if err != nil {
+			testFailed++
+			results[i].Status = "test-failed"
+			results[i].Error = err.Error()
+			fmt.Fprintf(prog, "   ❌ %s/%d %q: %v\n", t.def.Plugin, t.conn.ID, t.conn.Name, err)
+			continue
+		}
+		results[i].Status = "tested"
+		fmt.Fprintf(prog, "   ✅ %s/%d %q\n", t.def.Plugin, t.conn.ID, t.conn.Name)
+	}
+	if testFailed > 0 {
+		return result, fmt.Errorf("new token failed the connection test for %d connection(s) — nothing was changed", testFailed)
+	}
+	if dryRun {
+		return result, nil
+	}
+
+	// ── Update ──
+	fmt.Fprintln(prog, "\n📡 Updating connections...")
+	var updated []int
+	var updateErr error
+	for i, t := range targets {
+		req := &devlake.ConnectionUpdateRequest{Token: newTok, AuthMethod: t.def.authMethod()}
+		if _, err := client.UpdateConnection(t.def.Plugin, t.conn.ID, req); err != nil {
+			results[i].Status = "update-failed"
+			results[i].Error = err.Error()
+			fmt.Fprintf(prog, "   ❌ %s/%d %q: %v\n", t.def.Plugin, t.conn.ID, t.conn.Name, err)
+			updateErr = fmt.Errorf("updating %s connection %d: %w", t.def.Plugin, t.conn.ID, err)
+			break
+		}
+		results[i].Status = "updated"
+		updated = append(updated, i)
+		fmt.Fprintf(prog, "   ✅ %s/%d %q\n", t.def.Plugin, t.conn.ID, t.conn.Name)
+	}
+	if updateErr == nil {
+		return result, nil
+	}
+
+	// ── Roll back ──
+	if len(updated) == 0 {
+		return result, updateErr
+	}
+	fmt.Fprintf(prog, "\n↩️  Rolling back %d connection(s)...\n", len(updated))
+	for _, i := range updated {
+		t := targets[i]
+		prevTok := prevToks[t.def.Plugin]
+		if prevTok == "" {
+			results[i].Status = "not-rolled-back"
+			fmt.Fprintf(prog, "   ⚠️  %s/%d %q — previous token unknown\n", t.def.Plugin, t.conn.ID, t.conn.Name)
+			continue
+		}
+		result.RolledBack = true
+		req := &devlake.ConnectionUpdateRequest{Token: prevTok, AuthMethod: t.def.authMethod()}
+		if _, err := client.UpdateConnection(t.def.Plugin, t.conn.ID, req); err != nil {
+			results[i].Status = "rollback-failed"
+			results[i].Error = err.Error()
+			fmt.Fprintf(prog, "   ❌ %s/%d %q: %v\n", t.def.Plugin, t.conn.ID, t.conn.Name, err)
+			continue
+		}
+		results[i].Status = "rolled-back"
+		fmt.Fprintf(prog, "   ↩️  %s/%d %q\n", t.def.Plugin, t.conn.ID, t.conn.Name)
+	}
+	return result, updateErr
+}
+
+// updateStoredTokens replaces keychain entries for the rotated plugins with
+// newTok, a literal token or a secret reference. Plugins without a stored
+// token are left alone.
+func updateStoredTokens(prog io.Writer, defs []*ConnectionDef, newTok string) []string {
+	kc, err := token.OpenKeychain()
+	if err != nil {
+		return nil
+	}
+	var updated []string
+	for _, def := range defs {
+		if _, err := kc.Get(def.Plugin); err != nil {
+			continue
+		}
+		if err := kc.Set(def.Plugin, newTok); err != nil {
+			fmt.Fprintf(prog, "   ⚠️  Could not update stored %s token: %v\n", def.DisplayName, err)
+			continue
+		}
+		updated = append(updated, def.Plugin)
+	}
+	if len(updated) > 0 {
+		fmt.Fprintf(prog, "\n🔐 Stored token updated in %s for: %s\n", kc.Name(), strings.Join(updated, ", "))
+	}
+	return updated
+}
+
+// finishTokenRotate prints the rotation summary (or JSON result).
+func finishTokenRotate(prog io.Writer, result *tokenRotateResult) error {
+	if outputJSON {
+		return printJSON(result)
+	}
+	counts := map[string]int{}
+	for _, c := range result.Connections {
+		counts[c.Status]++
+	}
+	fmt.Fprintln(prog, "\n"+strings.Repeat("─", 40))
+	switch {
+	case result.DryRun && counts["test-failed"] == 0:
+		fmt.Fprintf(prog, "✅ Dry run: new token works for %d connection(s)\n", counts["tested"])
+	case counts["updated"] > 0 && counts["update-failed"] == 0:
+		fmt.Fprintf(prog, "✅ Token rotated on %d connection(s)\n", counts["updated"])
+	case result.RolledBack:
+		fmt.Fprintf(prog, "⚠️  Rotation failed — %d rolled back, %d rollback failure(s)\n", counts["rolled-back"], counts["rollback-failed"])
+	default:
+		fmt.Fprintln(prog, "❌ Rotation failed")
+	}
+	if counts["skipped"] > 0 {
+		fmt.Fprintf(prog, "   %d GitHub App connection(s) skipped\n", counts["skipped"])
+	}
+	fmt.Fprintln(prog, strings.Repeat("─", 40))
+	return nil
+}
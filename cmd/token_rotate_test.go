@@ -0,0 +1,270 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/DevExpGBB/gh-devlake/internal/devlake"
+	"github.com/DevExpGBB/gh-devlake/internal/token"
+)
+
+// fakeRotateServer serves connection list/test/update endpoints and records
+// the token sent in each PATCH.
+type fakeRotateServer struct {
+	mu          sync.Mutex
+	conns       map[string][]devlake.Connection
+	failTest    map[string]bool // "plugin/org" whose test fails
+	failPatch   map[string]bool // "plugin/id" whose first PATCH fails
+	patches     []string        // "plugin/id=token"
+	testedNames []string
+}
+
+func (f *fakeRotateServer) handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		// plugins/{plugin}/connections[/{id}] or plugins/{plugin}/test
+		if len(parts) < 3 || parts[0] != "plugins" {
+			http.NotFound(w, r)
+			return
+		}
+		plugin := parts[1]
+		switch {
+		case r.Method == http.MethodGet && len(parts) == 3 && parts[2] == "connections":
+			_ = json.NewEncoder(w).Encode(f.conns[plugin])
+		case r.Method == http.MethodPost && parts[2] == "test":
+			var req devlake.ConnectionTestRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			f.testedNames = append(f.testedNames, req.Name)
+			if f.failTest[plugin+"/"+req.Organization] {
+				_ = json.NewEncoder(w).Encode(devlake.ConnectionTestResult{Success: false, Message: "Bad credentials"})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(devlake.ConnectionTestResult{Success: true})
+		case r.Method == http.MethodPatch && len(parts) == 4:
+			key := plugin + "/" + parts[3]
+			body, _ := io.ReadAll(r.Body)
+			var req devlake.ConnectionUpdateRequest
+			_ = json.Unmarshal(body, &req)
+			if f.failPatch[key] {
+				delete(f.failPatch, key)
+				w.WriteHeader(http.StatusInternalServerError)
+				fmt.Fprint(w, `{"message":"boom"}`)
+				return
+			}
+			f.patches = append(f.patches, key+"="+req.Token)
+			_ = json.NewEncoder(w).Encode(devlake.Connection{Name: key})
+		default:
+			http.NotFound(w, r)
+		}
+	}
+}
+
+func newFakeRotateServer() *fakeRotateServer {
+	return &fakeRotateServer{
+		conns: map[string][]devlake.Connection{
+			"github": {
+				{ID: 1, Name: "GitHub - acme", Organization: "acme"},
+				{ID: 2, Name: "GitHub - other", Organization: "other"},
+				{ID: 3, Name: "GitHub App - acme", Organization: "acme", AuthMethod: "AppKey"},
+			},
+			"gh-copilot": {
+				{ID: 7, Name: "Copilot - acme", Organization: "acme"},
+			},
+		},
+		failTest:  map[string]bool{},
+		failPatch: map[string]bool{},
+	}
+}
+
+func rotateDefs(t *testing.T, list string) []*ConnectionDef {
+	t.Helper()
+	defs, err := parseRotatePlugins(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return defs
+}
+
+func statuses(res *tokenRotateResult) map[string]string {
+	out := map[string]string{}
+	for _, c := range res.Connections {
+		out[fmt.Sprintf("%s/%d", c.Plugin, c.ConnectionID)] = c.Status
+	}
+	return out
+}
+
+func TestRotateConnections_Success(t *testing.T) {
+	fake := newFakeRotateServer()
+	srv := httptest.NewServer(fake.handler())
+	defer srv.Close()
+
+	res, err := rotateConnections(devlake.NewClient(srv.URL), io.Discard, rotateDefs(t, "github,gh-copilot"), "ACME", "ghp_new", map[string]string{"github": "ghp_old", "gh-copilot": "ghp_old"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"github/1=ghp_new", "gh-copilot/7=ghp_new"}
+	if strings.Join(fake.patches, ",") != strings.Join(want, ",") {
+		t.Errorf("patches = %v, want %v", fake.patches, want)
+	}
+	got := statuses(res)
+	if got["github/1"] != "updated" || got["gh-copilot/7"] != "updated" || got["github/3"] != "skipped" {
+		t.Errorf("statuses = %v", got)
+	}
+	if _, ok := got["github/2"]; ok {
+		t.Error("connection for another org should not be rotated")
+	}
+}
+
+func TestRotateConnections_TestFailureChangesNothing(t *testing.T) {
+	fake := newFakeRotateServer()
+	fake.failTest["gh-copilot/acme"] = true
+	srv := httptest.NewServer(fake.handler())
+	defer srv.Close()
+
+	res, err := rotateConnections(devlake.NewClient(srv.URL), io.Discard, rotateDefs(t, "github,gh-copilot"), "", "ghp_new", map[string]string{"github": "ghp_old", "gh-copilot": "ghp_old"}, false)
+	if err == nil || !strings.Contains(err.Error(), "nothing was changed") {
+		t.Fatalf("err = %v", err)
+	}
+	if len(fake.patches) != 0 {
+		t.Errorf("no connection should be updated, got %v", fake.patches)
+	}
+	if statuses(res)["gh-copilot/7"] != "test-failed" {
+		t.Errorf("statuses = %v", statuses(res))
+	}
+}
+
+func TestRotateConnections_RollbackOnPartialFailure(t *testing.T) {
+	fake := newFakeRotateServer()
+	fake.failPatch["gh-copilot/7"] = true
+	srv := httptest.NewServer(fake.handler())
+	defer srv.Close()
+
+	res, err := rotateConnections(devlake.NewClient(srv.URL), io.Discard, rotateDefs(t, "github,gh-copilot"), "", "ghp_new", map[string]string{"github": "ghp_old", "gh-copilot": "ghp_old"}, false)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	want := []string{"github/1=ghp_new", "github/2=ghp_new", "github/1=ghp_old", "github/2=ghp_old"}
+	if strings.Join(fake.patches, ",") != strings.Join(want, ",") {
+		t.Errorf("patches = %v, want %v", fake.patches, want)
+	}
+	if !res.RolledBack {
+		t.Error("RolledBack = false")
+	}
+	got := statuses(res)
+	if got["github/1"] != "rolled-back" || got["github/2"] != "rolled-back" || got["gh-copilot/7"] != "update-failed" {
+		t.Errorf("statuses = %v", got)
+	}
+}
+
+func TestRotateConnections_NoPreviousToken(t *testing.T) {
+	fake := newFakeRotateServer()
+	fake.failPatch["github/2"] = true
+	srv := httptest.NewServer(fake.handler())
+	defer srv.Close()
+
+	res, err := rotateConnections(devlake.NewClient(srv.URL), io.Discard, rotateDefs(t, "github"), "", "ghp_new", nil, false)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if len(fake.patches) != 1 || res.RolledBack || statuses(res)["github/1"] != "not-rolled-back" {
+		t.Errorf("patches=%v statuses=%v", fake.patches, statuses(res))
+	}
+}
+
+func TestRotateConnections_RollbackPerPlugin(t *testing.T) {
+	fake := newFakeRotateServer()
+	fake.failPatch["gh-copilot/7"] = true
+	fake.conns["gitlab"] = []devlake.Connection{{ID: 9, Name: "GitLab"}}
+	srv := httptest.NewServer(fake.handler())
+	defer srv.Close()
+
+	// Each plugin's connections roll back to that plugin's own token.
+	prev := map[string]string{"github": "ghp_old", "gitlab": "glpat_old"}
+	res, err := rotateConnections(devlake.NewClient(srv.URL), io.Discard, rotateDefs(t, "gitlab,github,gh-copilot"), "", "new", prev, false)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	want := []string{"gitlab/9=new", "github/1=new", "github/2=new", "gitlab/9=glpat_old", "github/1=ghp_old", "github/2=ghp_old"}
+	if strings.Join(fake.patches, ",") != strings.Join(want, ",") {
+		t.Errorf("patches = %v, want %v", fake.patches, want)
+	}
+	if !res.RolledBack || statuses(res)["gitlab/9"] != "rolled-back" {
+		t.Errorf("statuses = %v", statuses(res))
+	}
+}
+
+func TestResolvePreviousTokens(t *testing.T) {
+	for _, name := range []string{"GITHUB_PAT", "GITHUB_TOKEN", "GH_TOKEN", "GITLAB_TOKEN"} {
+		t.Setenv(name, "")
+	}
+	t.Setenv(token.KeychainFileEnv, filepath.Join(t.TempDir(), "keychain.json"))
+	kc, err := token.OpenKeychain()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := kc.Set("github", "ghp_old"); err != nil {
+		t.Fatal(err)
+	}
+	if err := kc.Set("gh-copilot", "ghp_new"); err != nil {
+		t.Fatal(err)
+	}
+	envFile := filepath.Join(t.TempDir(), "missing.env")
+	defs := rotateDefs(t, "github,gh-copilot,gitlab")
+
+	var out strings.Builder
+	got, err := resolvePreviousTokens(&out, defs, "", envFile, "ghp_new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, map[string]string{"github": "ghp_old"}) {
+		t.Errorf("prevToks = %v", got)
+	}
+	if !strings.Contains(out.String(), "GitHub Copilot token") || !strings.Contains(out.String(), "already equals the new token") {
+		t.Errorf("output should flag the copilot token as equal:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "Previous GitLab token not found") {
+		t.Errorf("output should report the missing gitlab token:\n%s", out.String())
+	}
+
+	got, err = resolvePreviousTokens(io.Discard, defs, "old", envFile, "ghp_new")
+	if err != nil || len(got) != 3 || got["gitlab"] != "old" {
+		t.Errorf("--previous-token = %v, %v", got, err)
+	}
+}
+
+func TestRotateConnections_DryRun(t *testing.T) {
+	fake := newFakeRotateServer()
+	srv := httptest.NewServer(fake.handler())
+	defer srv.Close()
+
+	res, err := rotateConnections(devlake.NewClient(srv.URL), io.Discard, rotateDefs(t, "github"), "", "ghp_new", nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.patches) != 0 || len(fake.testedNames) != 2 || statuses(res)["github/1"] != "tested" {
+		t.Errorf("patches=%v tested=%v statuses=%v", fake.patches, fake.testedNames, statuses(res))
+	}
+}
+
+func TestParseRotatePlugins(t *testing.T) {
+	defs, err := parseRotatePlugins("github, gh-copilot,github")
+	if err != nil || len(defs) != 2 {
+		t.Errorf("defs=%d err=%v", len(defs), err)
+	}
+	if _, err := parseRotatePlugins("jenkins"); err == nil {
+		t.Error("expected error for BasicAuth plugin")
+	}
+	if _, err := parseRotatePlugins(" , "); err == nil {
+		t.Error("expected error for empty list")
+	}
+}
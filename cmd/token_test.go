@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DevExpGBB/gh-devlake/internal/token"
+)
+
+func TestTokenStoreAndRemove(t *testing.T) {
+	t.Setenv(token.KeychainFileEnv, filepath.Join(t.TempDir(), "keychain.json"))
+
+	if err := runTokenStore(tokenStoreOpts{Plugin: "github", Token: "ghp_flag"}, strings.NewReader("")); err != nil {
+		t.Fatal(err)
+	}
+	// Piped stdin; alias resolves to the canonical slug.
+	if err := runTokenStore(tokenStoreOpts{Plugin: "azure-devops"}, strings.NewReader("ado_piped\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	kc, err := token.OpenKeychain()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := kc.Get("github"); got != "ghp_flag" {
+		t.Errorf("github token = %q", got)
+	}
+	if got, _ := kc.Get("azuredevops_go"); got != "ado_piped" {
+		t.Errorf("azuredevops_go token = %q", got)
+	}
+
+	if err := runTokenRemove("github"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := kc.Get("github"); err == nil {
+		t.Error("github token still stored after remove")
+	}
+	// Removing again is not an error.
+	if err := runTokenRemove("github"); err != nil {
+		t.Errorf("second remove: %v", err)
+	}
+}
+
+func TestTokenStoreKeepsReference(t *testing.T) {
+	t.Setenv(token.KeychainFileEnv, filepath.Join(t.TempDir(), "keychain.json"))
+	t.Setenv("DEVLAKE_TEST_PAT", "glpat-secret")
+
+	if err := runTokenStore(tokenStoreOpts{Plugin: "gitlab", Token: "env:DEVLAKE_TEST_PAT"}, strings.NewReader("")); err != nil {
+		t.Fatal(err)
+	}
+	kc, err := token.OpenKeychain()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := kc.Get("gitlab"); got != "env:DEVLAKE_TEST_PAT" {
+		t.Errorf("stored %q, want the reference rather than its value", got)
+	}
+	// An unresolvable reference is refused before anything is stored.
+	if err := runTokenStore(tokenStoreOpts{Plugin: "gitlab", Token: "env:DEVLAKE_TEST_UNSET"}, strings.NewReader("")); err == nil {
+		t.Error("expected an error for a reference that resolves to nothing")
+	}
+}
+
+func TestTokenStore_Errors(t *testing.T) {
+	t.Setenv(token.KeychainFileEnv, filepath.Join(t.TempDir(), "keychain.json"))
+
+	if err := runTokenStore(tokenStoreOpts{Token: "x"}, strings.NewReader("")); err == nil || !strings.Contains(err.Error(), "--plugin is required") {
+		t.Errorf("missing --plugin err = %v", err)
+	}
+	if err := runTokenRemove(""); err == nil || !strings.Contains(err.Error(), "--plugin is required") {
+		t.Errorf("remove without --plugin err = %v", err)
+	}
+	if err := runTokenStore(tokenStoreOpts{Plugin: "nope", Token: "x"}, strings.NewReader("")); err == nil {
+		t.Error("expected error for unknown plugin")
+	}
+	if err := runTokenStore(tokenStoreOpts{Plugin: "github"}, strings.NewReader("  \n")); err == nil {
+		t.Error("expected error for empty stdin")
+	}
+}
+
+func TestTokenResolveOpts(t *testing.T) {
+	gh := FindConnectionDef("github")
+	opts := gh.tokenResolveOpts("tok", "x.env")
+	if opts.KeychainAccount != "github" || !opts.UseGHAuth || opts.FlagValue != "tok" || opts.EnvFilePath != "x.env" {
+		t.Errorf("github opts = %+v", opts)
+	}
+	if opts := FindConnectionDef("gitlab").tokenResolveOpts("", ""); opts.UseGHAuth || opts.KeychainAccount != "gitlab" {
+		t.Errorf("gitlab opts = %+v", opts)
+	}
+}
@@ -0,0 +1,639 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DevExpGBB/gh-devlake/internal/devlake"
+	dockerpkg "github.com/DevExpGBB/gh-devlake/internal/docker"
+	"github.com/DevExpGBB/gh-devlake/internal/download"
+	"github.com/DevExpGBB/gh-devlake/internal/prompt"
+	"github.com/spf13/cobra"
+)
+
+// upgradeBackupDir holds one timestamped folder per upgrade, each containing
+// the docker-compose.yml and .env that were replaced.
+const upgradeBackupDir = ".devlake-backups"
+
+// upgradeReleaseNoteLines caps how much of the release notes is printed.
+const upgradeReleaseNoteLines = 20
+
+type upgradeOpts struct {
+	Dir      string
+	To       string
+	Rollback bool
+	DryRun   bool
+	Yes      bool
+	Insecure bool
+}
+
+// upgradeBackupMeta is written to backup.json inside each backup folder.
+type upgradeBackupMeta struct {
+	Version   string `json:"version,omitempty"`
+	CreatedAt string `json:"createdAt"`
+}
+
+func newUpgradeCmd() *cobra.Command {
+	var opts upgradeOpts
+	cmd := &cobra.Command{
+		Use:   "upgrade",
+		Short: "Upgrade a local DevLake deployment to a newer release",
+		Long: `Upgrades a local (Docker Compose) deployment of the official Apache release.
+
+The current version is read from the /version endpoint of the deployment in
+--dir (its state file's backend, or --url), falling back to that state file
+and then the devlake image tag in docker-compose.yml. A version that cannot
+be compared, such as a dev build, only prints a warning.
+
+The upgrade:
+  1. Downloads docker-compose.yml and env.example for the target release and
+     verifies them against their checksums (a file without one is used
+     with a warning)
+  2. Shows changed compose services and the release notes
+  3. Backs up docker-compose.yml and .env to .devlake-backups/<timestamp>/
+  4. Merges .env — existing values (including ENCRYPTION_SECRET) are kept,
+     new keys from the release template are appended
+  5. Pulls images, restarts containers, and runs the database migration
+
+--rollback restores the most recent backup and restarts the containers.
+Database migrations are not reversed by a rollback.
+
+Examples:
+  gh devlake upgrade
+  gh devlake upgrade --to v1.0.3 --dir ./devlake
+  gh devlake upgrade --to v1.0.3 --dry-run
+  gh devlake upgrade --rollback`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runUpgrade(opts)
+		},
+	}
+	cmd.Flags().StringVar(&opts.Dir, "dir", ".", "Directory containing the deployment's docker-compose.yml")
+	cmd.Flags().StringVar(&opts.To, "to", "latest", "Target DevLake version (e.g. v1.0.3)")
+	cmd.Flags().BoolVar(&opts.Rollback, "rollback", false, "Restore the most recent pre-upgrade backup")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Show what would change without modifying anything")
+	cmd.Flags().BoolVarP(&opts.Yes, "yes", "y", false, "Skip the confirmation prompt")
+	cmd.Flags().BoolVar(&opts.Insecure, "insecure-skip-verify", false, "Do not verify downloaded release files against their checksums")
+	cmd.MarkFlagsMutuallyExclusive("rollback", "to")
+	return cmd
+}
+
+func runUpgrade(opts upgradeOpts) error {
+	absDir, err := filepath.Abs(opts.Dir)
+	if err != nil {
+		return err
+	}
+	composePath := filepath.Join(absDir, "docker-compose.yml")
+	oldCompose, err := os.ReadFile(composePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no docker-compose.yml in %s — run from the deployment directory or pass --dir", absDir)
+		}
+		return err
+	}
+	if composeBuildsFromSource(string(oldCompose)) {
+		return fmt.Errorf("docker-compose.yml builds images from source — upgrade supports official release deployments; pull your fork and run 'gh devlake deploy local --source fork' instead")
+	}
+
+	if opts.Rollback {
+		return runUpgradeRollback(absDir, opts)
+	}
+
+	printBanner("DevLake — Upgrade")
+	fmt.Printf("\nDeployment directory: %s\n", absDir)
+
+	fmt.Println("\n🔍 Checking current version...")
+	current, source := detectCurrentVersion(absDir, string(oldCompose))
+	if current == "" {
+		fmt.Println("   ⚠️  Could not determine the current version")
+	} else {
+		fmt.Printf("   Current version: %s (from %s)\n", current, source)
+	}
+
+	target := opts.To
+	if target == "" || target == "latest" {
+		tag, err := download.GitHubLatestTag("apache", "incubator-devlake")
+		if err != nil {
+			return fmt.Errorf("failed to fetch latest release: %w", err)
+		}
+		target = tag
+	}
+	fmt.Printf("   Target version:  %s\n", target)
+
+	if current != "" {
+		cmp, err := compareVersions(current, target)
+		if err != nil {
+			// Dev builds and custom tags have no comparable version.
+			fmt.Printf("   ⚠️  Cannot compare %s with %s — upgrading anyway\n", current, target)
+			cmp = -1
+		}
+		if cmp == 0 {
+			fmt.Printf("\n✅ DevLake is already at %s — nothing to do\n", target)
+			return nil
+		}
+		if cmp > 0 {
+			return fmt.Errorf("%s is older than the running %s — downgrades are not supported; use --rollback to restore a previous backup", target, current)
+		}
+	}
+
+	// ── Download release files to a staging directory ──
+	stageDir, err := os.MkdirTemp("", "devlake-upgrade-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(stageDir)
+
+	if err := downloadReleaseFiles(target, stageDir, opts.Insecure); err != nil {
+		return err
+	}
+	newCompose, err := os.ReadFile(filepath.Join(stageDir, "docker-compose.yml"))
+	if err != nil {
+		return err
+	}
+	envTemplate, err := os.ReadFile(filepath.Join(stageDir, "env.example"))
+	if err != nil {
+		return err
+	}
+
+	// ── Show what changes ──
+	fmt.Println("\n📋 Compose service changes:")
+	changes := diffComposeServices(composeServiceImages(string(oldCompose)), composeServiceImages(string(newCompose)))
+	if len(changes) == 0 {
+		fmt.Println("   (no service or image changes)")
+	}
+	for _, c := range changes {
+		fmt.Printf("   %s\n", c)
+	}
+
+	envPath := filepath.Join(absDir, ".env")
+	oldEnv, err := os.ReadFile(envPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	mergedEnv, addedKeys := mergeEnv(string(oldEnv), string(envTemplate), target)
+	if len(addedKeys) > 0 {
+		fmt.Printf("\n📝 New .env keys from %s: %s\n", target, strings.Join(addedKeys, ", "))
+	}
+
+	printReleaseNotes(target)
+
+	if opts.DryRun {
+		fmt.Println("\n🔎 Dry run — no changes made")
+		return nil
+	}
+	if !opts.Yes {
+		fmt.Println()
+		if !prompt.Confirm(fmt.Sprintf("Upgrade DevLake to %s?", target)) {
+			fmt.Println("Upgrade cancelled.")
+			return nil
+		}
+	}
+
+	fmt.Println("\n🐳 Checking Docker...")
+	if err := dockerpkg.CheckAvailable(); err != nil {
+		return fmt.Errorf("Docker is not available: %w\nMake sure Docker Desktop or the Docker daemon is running", err)
+	}
+	fmt.Println("   ✅ Docker is running")
+
+	// ── Back up, then write the new files ──
+	fmt.Println("\n💾 Backing up docker-compose.yml and .env...")
+	backupPath, err := backupDeploymentFiles(absDir, current, time.Now())
+	if err != nil {
+		return fmt.Errorf("backup failed — nothing was changed: %w", err)
+	}
+	rel, _ := filepath.Rel(absDir, backupPath)
+	fmt.Printf("   ✅ Saved to %s\n", rel)
+
+	if err := os.WriteFile(composePath, newCompose, 0644); err != nil {
+		return err
+	}
+	if err := os.WriteFile(envPath, []byte(mergedEnv), 0644); err != nil {
+		return err
+	}
+	fmt.Println("   ✅ docker-compose.yml and .env updated (ENCRYPTION_SECRET preserved)")
+
+	// ── Pull, restart, migrate ──
+	fmt.Println("\n📦 Pulling images...")
+	if err := dockerpkg.ComposePull(absDir); err != nil {
+		fmt.Println("\n💡 To restore the previous version: gh devlake upgrade --rollback")
+		return err
+	}
+	fmt.Println("   ✅ Images pulled")
+
+	backendURL, err := restartAndMigrate(absDir)
+	if err != nil {
+		fmt.Println("\n💡 To restore the previous version: gh devlake upgrade --rollback")
+		return err
+	}
+	recordDeployedVersion(absDir, backendURL, target)
+
+	printBanner(fmt.Sprintf("✅ DevLake upgraded to %s", target))
+	fmt.Printf("\n  Backend API: %s\n", backendURL)
+	fmt.Printf("  Backup:      %s\n", rel)
+	fmt.Println("\nTo undo:")
+	fmt.Println("  gh devlake upgrade --rollback")
+	return nil
+}
+
+// runUpgradeRollback restores the newest backup and restarts the containers.
+func runUpgradeRollback(absDir string, opts upgradeOpts) error {
+	printBanner("DevLake — Upgrade Rollback")
+
+	backupPath, meta, err := latestUpgradeBackup(absDir)
+	if err != nil {
+		return err
+	}
+	rel, _ := filepath.Rel(absDir, backupPath)
+	fmt.Printf("\n📂 Most recent backup: %s\n", rel)
+	if meta.Version != "" {
+		fmt.Printf("   Version: %s\n", meta.Version)
+	}
+	fmt.Printf("   Created: %s\n", meta.CreatedAt)
+	fmt.Println("\n   ⚠️  Database migrations applied by the upgrade are not reversed.")
+	fmt.Println("      If the older release refuses to start, use 'gh devlake restore' with a pre-upgrade backup.")
+
+	if opts.DryRun {
+		fmt.Println("\n🔎 Dry run — no changes made")
+		return nil
+	}
+	if !opts.Yes {
+		fmt.Println()
+		if !prompt.Confirm("Restore this backup and restart DevLake?") {
+			fmt.Println("Rollback cancelled.")
+			return nil
+		}
+	}
+
+	fmt.Println("\n🐳 Checking Docker...")
+	if err := dockerpkg.CheckAvailable(); err != nil {
+		return fmt.Errorf("Docker is not available: %w\nMake sure Docker Desktop or the Docker daemon is running", err)
+	}
+	fmt.Println("   ✅ Docker is running")
+
+	fmt.Println("\n♻️  Restoring files...")
+	for _, name := range []string{"docker-compose.yml", ".env"} {
+		data, err := os.ReadFile(filepath.Join(backupPath, name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(absDir, name), data, 0644); err != nil {
+			return fmt.Errorf("restoring %s: %w", name, err)
+		}
+		fmt.Printf("   ✅ %s\n", name)
+	}
+
+	backendURL, err := restartAndMigrate(absDir)
+	if err != nil {
+		return err
+	}
+	if meta.Version != "" {
+		recordDeployedVersion(absDir, backendURL, meta.Version)
+	}
+	// Consume the backup so a second --rollback steps further back.
+	if err := os.RemoveAll(backupPath); err != nil {
+		fmt.Printf("   ⚠️  Could not remove %s: %v\n", rel, err)
+	}
+
+	printBanner("✅ Rollback complete")
+	fmt.Printf("\n  Backend API: %s\n", backendURL)
+	return nil
+}
+
+// restartAndMigrate recreates the containers, triggers the database
+// migration, and waits for it to finish. Returns the backend URL.
+func restartAndMigrate(absDir string) (string, error) {
+	backendURL, err := startLocalContainers(absDir, false)
+	if err != nil {
+		return "", err
+	}
+	cfgURL = backendURL
+
+	fmt.Println("\n🔄 Triggering database migration...")
+	if err := devlake.NewClient(backendURL).TriggerMigration(); err != nil {
+		fmt.Printf("   ⚠️  Migration may need manual trigger: %v\n", err)
+		return backendURL, nil
+	}
+	fmt.Println("   ✅ Migration triggered")
+	fmt.Println("\n⏳ Waiting for migration to complete...")
+	if err := waitForMigration(backendURL, 60, 5*time.Second); err != nil {
+		return "", err
+	}
+	return backendURL, nil
+}
+
+// detectCurrentVersion returns the version of the deployment in absDir and
+// where it came from: the backend /version endpoint, the state file, or the
+// compose file. The backend is the one --url names or the one recorded in
+// absDir's state file, never one discovered from the working directory.
+func detectCurrentVersion(absDir, compose string) (version, source string) {
+	state, _ := devlake.LoadState(filepath.Join(absDir, ".devlake-local.json"))
+	backendURL := cfgURL
+	if backendURL == "" && state != nil {
+		backendURL = state.Endpoints.Backend
+	}
+	if backendURL != "" {
+		if v, err := devlake.NewClient(backendURL).Version(); err == nil && v.Version != "" {
+			return normalizeVersion(v.Version), "backend /version"
+		}
+	}
+	if state != nil && state.Version != "" {
+		return state.Version, "state file"
+	}
+	if tag := imageTag(composeServiceImages(compose)["devlake"]); tag != "" && tag != "latest" {
+		return tag, "docker-compose.yml"
+	}
+	return "", ""
+}
+
+// recordDeployedVersion stores the version in the local state file, creating
+// it when absent so later upgrades can compare without a running backend.
+func recordDeployedVersion(absDir, backendURL, version string) {
+	path := filepath.Join(absDir, ".devlake-local.json")
+	state, _ := devlake.LoadState(path)
+	if state == nil {
+		state = &devlake.State{
+			DeployedAt: time.Now().Format(time.RFC3339),
+			Method:     "local",
+			Endpoints:  devlake.StateEndpoints{Backend: backendURL},
+		}
+	}
+	state.Version = version
+	if err := devlake.SaveState(path, state); err != nil {
+		fmt.Printf("   ⚠️  Could not update state file: %v\n", err)
+	}
+}
+
+func printReleaseNotes(tag string) {
+	body, url, err := download.GitHubReleaseNotes("apache", "incubator-devlake", tag)
+	if err != nil {
+		fmt.Printf("\n   ⚠️  Could not fetch release notes: %v\n", err)
+		return
+	}
+	fmt.Printf("\n📰 Release notes for %s:\n", tag)
+	lines := strings.Split(strings.ReplaceAll(strings.TrimSpace(body), "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		if i == upgradeReleaseNoteLines {
+			fmt.Printf("   … %d more lines\n", len(lines)-i)
+			break
+		}
+		fmt.Printf("   %s\n", line)
+	}
+	if url != "" {
+		fmt.Printf("   Full notes: %s\n", url)
+	}
+}
+
+// backupDeploymentFiles copies docker-compose.yml and .env (when present)
+// into a new timestamped folder under .devlake-backups.
+func backupDeploymentFiles(absDir, version string, now time.Time) (string, error) {
+	dest := filepath.Join(absDir, upgradeBackupDir, now.Format("20060102-150405"))
+	if err := os.MkdirAll(dest, 0700); err != nil {
+		return "", err
+	}
+	for _, name := range []string{"docker-compose.yml", ".env"} {
+		data, err := os.ReadFile(filepath.Join(absDir, name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		// .env holds ENCRYPTION_SECRET, so backups are owner-only.
+		if err := os.WriteFile(filepath.Join(dest, name), data, 0600); err != nil {
+			return "", err
+		}
+	}
+	meta, _ := json.MarshalIndent(upgradeBackupMeta{Version: version, CreatedAt: now.Format(time.RFC3339)}, "", "  ")
+	if err := os.WriteFile(filepath.Join(dest, "backup.json"), meta, 0600); err != nil {
+		return "", err
+	}
+	return dest, nil
+}
+
+// latestUpgradeBackup returns the newest backup folder and its metadata.
+func latestUpgradeBackup(absDir string) (string, *upgradeBackupMeta, error) {
+	root := filepath.Join(absDir, upgradeBackupDir)
+	entries, err := os.ReadDir(root)
+	if err != nil && !os.IsNotExist(err) {
+		return "", nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	if len(names) == 0 {
+		return "", nil, fmt.Errorf("no upgrade backups found in %s", root)
+	}
+	// Folder names are timestamps, so lexical order is chronological.
+	sort.Strings(names)
+	path := filepath.Join(root, names[len(names)-1])
+
+	meta := &upgradeBackupMeta{}
+	if data, err := os.ReadFile(filepath.Join(path, "backup.json")); err == nil {
+		_ = json.Unmarshal(data, meta)
+	}
+	return path, meta, nil
+}
+
+// mergeEnv keeps every line of the current .env and appends keys that the
+// new release template defines but the current file lacks. Existing values —
+// notably ENCRYPTION_SECRET — are never overwritten.
+func mergeEnv(current, template, version string) (string, []string) {
+	have := map[string]bool{}
+	for _, line := range strings.Split(current, "\n") {
+		if key := envKey(line); key != "" {
+			have[key] = true
+		}
+	}
+	var added, lines []string
+	for _, line := range strings.Split(template, "\n") {
+		key := envKey(line)
+		if key == "" || have[key] {
+			continue
+		}
+		have[key] = true
+		added = append(added, key)
+		lines = append(lines, strings.TrimRight(line, "\r"))
+	}
+	if len(added) == 0 {
+		return current, nil
+	}
+	merged := current
+	if merged != "" && !strings.HasSuffix(merged, "\n") {
+		merged += "\n"
+	}
+	merged += fmt.Sprintf("\n# Added by gh devlake upgrade (%s)\n", version)
+	merged += strings.Join(lines, "\n") + "\n"
+	return merged, added
+}
+
+// envKey returns the variable name of a KEY=value line, or "" for comments
+// and blank lines.
+func envKey(line string) string {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		return ""
+	}
+	key, _, ok := strings.Cut(trimmed, "=")
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimPrefix(key, "export "))
+}
+
+// composeServiceImages maps each service under the top-level services: key
+// to its image. This is a line-based reader for the release compose files,
+// not a general YAML parser.
+func composeServiceImages(content string) map[string]string {
+	services := map[string]string{}
+	inServices := false
+	svcIndent := -1
+	current := ""
+	for _, raw := range strings.Split(content, "\n") {
+		line := strings.TrimRight(raw, " \r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if indent == 0 {
+			inServices = trimmed == "services:"
+			current = ""
+			continue
+		}
+		if !inServices {
+			continue
+		}
+		if svcIndent < 0 {
+			svcIndent = indent
+		}
+		if indent == svcIndent && strings.HasSuffix(trimmed, ":") {
+			current = strings.TrimSuffix(trimmed, ":")
+			services[current] = ""
+			continue
+		}
+		if current != "" && indent > svcIndent && strings.HasPrefix(trimmed, "image:") {
+			services[current] = strings.Trim(strings.TrimSpace(strings.TrimPrefix(trimmed, "image:")), `"'`)
+		}
+	}
+	return services
+}
+
+// composeBuildsFromSource reports whether any service uses a build: context
+// (fork deployments), which upgrade does not manage.
+func composeBuildsFromSource(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "build:") {
+			return true
+		}
+	}
+	return false
+}
+
+// diffComposeServices describes added (+), removed (-), and changed (~)
+// services, sorted by service name.
+func diffComposeServices(before, after map[string]string) []string {
+	names := map[string]bool{}
+	for n := range before {
+		names[n] = true
+	}
+	for n := range after {
+		names[n] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for n := range names {
+		sorted = append(sorted, n)
+	}
+	sort.Strings(sorted)
+
+	var out []string
+	for _, n := range sorted {
+		oldImg, hadOld := before[n]
+		newImg, hasNew := after[n]
+		switch {
+		case !hadOld:
+			out = append(out, fmt.Sprintf("+ %s (%s)", n, orNone(newImg)))
+		case !hasNew:
+			out = append(out, fmt.Sprintf("- %s (%s)", n, orNone(oldImg)))
+		case oldImg != newImg:
+			out = append(out, fmt.Sprintf("~ %s: %s → %s", n, orNone(oldImg), orNone(newImg)))
+		}
+	}
+	return out
+}
+
+// imageTag returns the tag of an image reference ("repo/name:tag" → "tag").
+func imageTag(image string) string {
+	name := image[strings.LastIndex(image, "/")+1:]
+	if _, tag, ok := strings.Cut(name, ":"); ok {
+		return tag
+	}
+	return ""
+}
+
+// normalizeVersion strips the build suffix the backend appends to its
+// version ("v1.0.2@a1b2c3d" → "v1.0.2").
+func normalizeVersion(v string) string {
+	v = strings.TrimSpace(v)
+	if i := strings.IndexAny(v, "@+"); i >= 0 {
+		v = v[:i]
+	}
+	return v
+}
+
+// compareVersions compares two vMAJOR.MINOR.PATCH[-PRERELEASE] versions and
+// returns -1, 0, or 1. A prerelease sorts before its release.
+func compareVersions(a, b string) (int, error) {
+	an, apre, err := parseVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	bn, bpre, err := parseVersion(b)
+	if err != nil {
+		return 0, err
+	}
+	for i := range an {
+		if an[i] != bn[i] {
+			if an[i] < bn[i] {
+				return -1, nil
+			}
+			return 1, nil
+		}
+	}
+	switch {
+	case apre == bpre:
+		return 0, nil
+	case apre == "":
+		return 1, nil
+	case bpre == "":
+		return -1, nil
+	}
+	return strings.Compare(apre, bpre), nil
+}
+
+func parseVersion(v string) ([3]int, string, error) {
+	var nums [3]int
+	core, pre, _ := strings.Cut(strings.TrimPrefix(normalizeVersion(v), "v"), "-")
+	parts := strings.Split(core, ".")
+	if len(parts) > 3 {
+		return nums, "", fmt.Errorf("invalid version %q — expected vMAJOR.MINOR.PATCH", v)
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return nums, "", fmt.Errorf("invalid version %q — expected vMAJOR.MINOR.PATCH", v)
+		}
+		nums[i] = n
+	}
+	return nums, pre, nil
+}
@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testComposeV101 = `version: "3"
+services:
+  mysql:
+    image: mysql:8
+    ports:
+      - 3306:3306
+  devlake:
+    image: devlake.docker.scarf.sh/apache/devlake:v1.0.1
+    environment:
+      LOGGING_DIR: /app/logs
+  grafana:
+    image: "devlake.docker.scarf.sh/apache/devlake-dashboard:v1.0.1"
+volumes:
+  mysql-storage:
+`
+
+const testComposeV102 = `services:
+  mysql:
+    image: mysql:8
+  devlake:
+    image: devlake.docker.scarf.sh/apache/devlake:v1.0.2
+  config-ui:
+    image: devlake.docker.scarf.sh/apache/devlake-config-ui:v1.0.2
+`
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"v1.0.1", "v1.0.2", -1},
+		{"v1.0.2", "v1.0.2", 0},
+		{"v1.0.2@a1b2c3d", "v1.0.2", 0},
+		{"v1.1.0", "v1.0.9", 1},
+		{"v1.0.2-beta1", "v1.0.2", -1},
+		{"v1.0.2-beta1", "v1.0.2-beta2", -1},
+		{"1.0", "v1.0.0", 0},
+	}
+	for _, tt := range tests {
+		got, err := compareVersions(tt.a, tt.b)
+		if err != nil {
+			t.Errorf("compareVersions(%q, %q) error: %v", tt.a, tt.b, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+	if _, err := compareVersions("main", "v1.0.2"); err == nil {
+		t.Error("expected error for non-semver version")
+	}
+}
+
+func TestMergeEnv(t *testing.T) {
+	current := "# DevLake\nENCRYPTION_SECRET=keepme\nPORT=8080\n"
+	template := "ENCRYPTION_SECRET=\nPORT=9999\n# NEW_COMMENTED=1\nNEW_KEY=default\n"
+
+	merged, added := mergeEnv(current, template, "v1.0.2")
+	if !reflect.DeepEqual(added, []string{"NEW_KEY"}) {
+		t.Errorf("added = %v, want [NEW_KEY]", added)
+	}
+	if !strings.Contains(merged, "ENCRYPTION_SECRET=keepme") || strings.Contains(merged, "ENCRYPTION_SECRET=\n") {
+		t.Errorf("ENCRYPTION_SECRET not preserved:\n%s", merged)
+	}
+	if !strings.Contains(merged, "PORT=8080") || strings.Contains(merged, "PORT=9999") {
+		t.Errorf("existing value overwritten:\n%s", merged)
+	}
+	if !strings.HasSuffix(merged, "# Added by gh devlake upgrade (v1.0.2)\nNEW_KEY=default\n") {
+		t.Errorf("new key not appended:\n%s", merged)
+	}
+
+	unchanged, added := mergeEnv(current, "PORT=1\n", "v1.0.2")
+	if unchanged != current || added != nil {
+		t.Errorf("expected no change, got added=%v\n%s", added, unchanged)
+	}
+}
+
+func TestComposeServiceImages(t *testing.T) {
+	got := composeServiceImages(testComposeV101)
+	want := map[string]string{
+		"mysql":   "mysql:8",
+		"devlake": "devlake.docker.scarf.sh/apache/devlake:v1.0.1",
+		"grafana": "devlake.docker.scarf.sh/apache/devlake-dashboard:v1.0.1",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("composeServiceImages = %v, want %v", got, want)
+	}
+	if tag := imageTag(got["devlake"]); tag != "v1.0.1" {
+		t.Errorf("imageTag = %q, want v1.0.1", tag)
+	}
+	if tag := imageTag("localhost:5000/devlake"); tag != "" {
+		t.Errorf("imageTag with registry port = %q, want empty", tag)
+	}
+}
+
+func TestDiffComposeServices(t *testing.T) {
+	got := diffComposeServices(composeServiceImages(testComposeV101), composeServiceImages(testComposeV102))
+	want := []string{
+		"+ config-ui (devlake.docker.scarf.sh/apache/devlake-config-ui:v1.0.2)",
+		"~ devlake: devlake.docker.scarf.sh/apache/devlake:v1.0.1 → devlake.docker.scarf.sh/apache/devlake:v1.0.2",
+		"- grafana (devlake.docker.scarf.sh/apache/devlake-dashboard:v1.0.1)",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("diffComposeServices =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestComposeBuildsFromSource(t *testing.T) {
+	if composeBuildsFromSource(testComposeV101) {
+		t.Error("release compose should not be treated as a source build")
+	}
+	if !composeBuildsFromSource("services:\n  devlake:\n    build:\n      context: backend\n") {
+		t.Error("expected build: context to be detected")
+	}
+}
+
+func TestUpgradeBackupRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "docker-compose.yml"), []byte(testComposeV101), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("ENCRYPTION_SECRET=abc\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := latestUpgradeBackup(dir); err == nil {
+		t.Error("expected error when no backups exist")
+	}
+
+	older := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	if _, err := backupDeploymentFiles(dir, "v1.0.0", older); err != nil {
+		t.Fatal(err)
+	}
+	newest, err := backupDeploymentFiles(dir, "v1.0.1", older.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path, meta, err := latestUpgradeBackup(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != newest || meta.Version != "v1.0.1" {
+		t.Errorf("latest = %s (%+v), want %s v1.0.1", path, meta, newest)
+	}
+	env, err := os.ReadFile(filepath.Join(path, ".env"))
+	if err != nil || string(env) != "ENCRYPTION_SECRET=abc\n" {
+		t.Errorf(".env backup = %q, %v", env, err)
+	}
+}
+
+func TestDetectCurrentVersionUsesDir(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"version":"v1.0.2@a1b2c3d"}`)
+	}))
+	defer srv.Close()
+
+	origURL := cfgURL
+	cfgURL = ""
+	t.Cleanup(func() { cfgURL = origURL })
+
+	dir := t.TempDir()
+	state := `{"version":"v1.0.1","endpoints":{"backend":"` + srv.URL + `"}}`
+	if err := os.WriteFile(filepath.Join(dir, ".devlake-local.json"), []byte(state), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if v, src := detectCurrentVersion(dir, testComposeV101); v != "v1.0.2" || src != "backend /version" {
+		t.Errorf("detectCurrentVersion() = %q, %q; want v1.0.2 from the backend", v, src)
+	}
+
+	// Without a recorded backend the state file of --dir wins.
+	if err := os.WriteFile(filepath.Join(dir, ".devlake-local.json"), []byte(`{"version":"v1.0.1"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if v, src := detectCurrentVersion(dir, testComposeV101); v != "v1.0.1" || src != "state file" {
+		t.Errorf("detectCurrentVersion() = %q, %q; want v1.0.1 from the state file", v, src)
+	}
+}
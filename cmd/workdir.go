@@ -11,8 +11,11 @@ import (
 
 // expectedDirName returns the recommended directory name for a deployment target.
 func expectedDirName(target string) string {
-	if target == "azure" {
+	switch target {
+	case "azure":
 		return "devlake-azure"
+	case "k8s":
+		return "devlake-k8s"
 	}
 	return "devlake-local"
 }
@@ -5,6 +5,7 @@ go 1.22
 require (
 	github.com/spf13/cobra v1.8.1
 	github.com/spf13/pflag v1.0.5
+	golang.org/x/crypto v0.28.0
 	golang.org/x/term v0.25.0
 )
 
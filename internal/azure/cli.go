@@ -53,8 +53,9 @@ func GetSubscription() (string, error) {
 }
 
 // CreateResourceGroup creates or updates a resource group.
-func CreateResourceGroup(name, location string) error {
-	return runAz("group", "create", "--name", name, "--location", location, "--output", "none")
+func CreateResourceGroup(name, location string, tags map[string]string) error {
+	args := []string{"group", "create", "--name", name, "--location", location, "--output", "none"}
+	return runAz(append(args, tagArgs(tags)...)...)
 }
 
 // DeleteResourceGroup deletes a resource group (no-wait).
@@ -71,6 +72,10 @@ type DeploymentOutput struct {
 	ACRName          string
 	KeyVaultName     string
 	MySQLServerName  string
+	GatewayIP        string // public IP of the Application Gateway (private deployments)
+	GatewayFQDN      string
+	EnvironmentName  string // Container Apps environment (--runtime aca)
+	EnvironmentFQDN  string // default domain of the Container Apps environment
 }
 
 // DeployBicep deploys a Bicep template and returns the outputs.
@@ -113,6 +118,10 @@ func DeployBicep(resourceGroup, templatePath string, params map[string]string) (
 		ACRName:          outputs["acrName"].Value,
 		KeyVaultName:     outputs["keyVaultName"].Value,
 		MySQLServerName:  outputs["mysqlServerName"].Value,
+		GatewayIP:        outputs["gatewayPublicIp"].Value,
+		GatewayFQDN:      outputs["gatewayFqdn"].Value,
+		EnvironmentName:  outputs["environmentName"].Value,
+		EnvironmentFQDN:  outputs["environmentDomain"].Value,
 	}, nil
 }
 
@@ -155,26 +164,99 @@ func MySQLState(name, resourceGroup string) (string, error) {
 	return strings.TrimSpace(string(out)), nil
 }
 
-// DeleteResource deletes a specific Azure resource by type.
-func DeleteResource(resourceType, name, resourceGroup string) error {
-	switch resourceType {
-	case "container":
-		return runAz("container", "delete", "--name", name, "--resource-group", resourceGroup, "--yes")
-	case "mysql":
-		return runAz("mysql", "flexible-server", "delete", "--name", name, "--resource-group", resourceGroup, "--yes")
-	case "acr":
-		return runAz("acr", "delete", "--name", name, "--resource-group", resourceGroup, "--yes")
-	case "keyvault":
-		return runAz("keyvault", "delete", "--name", name, "--resource-group", resourceGroup)
-	default:
-		return fmt.Errorf("unknown resource type: %s", resourceType)
+// MySQLVersion returns the MySQL version of a flexible server (e.g. "8.0.21").
+func MySQLVersion(name, resourceGroup string) (string, error) {
+	out, err := exec.Command("az", "mysql", "flexible-server", "show",
+		"--name", name,
+		"--resource-group", resourceGroup,
+		"--query", "fullVersion || version",
+		"-o", "tsv",
+	).Output()
+	if err != nil {
+		return "", fmt.Errorf("az mysql flexible-server show %s failed: %w", name, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// ContainerGroupImages returns the images of the containers in a container
+// group, keyed by container name.
+func ContainerGroupImages(name, resourceGroup string) (map[string]string, error) {
+	out, err := exec.Command("az", "container", "show",
+		"--name", name,
+		"--resource-group", resourceGroup,
+		"--query", "containers[].{name: name, image: image}",
+		"-o", "json",
+	).Output()
+	if err != nil {
+		return nil, fmt.Errorf("az container show %s failed: %w", name, err)
+	}
+	return parseContainerImages(out)
+}
+
+// ContainerLogs returns the logs of one container in a container group.
+func ContainerLogs(group, resourceGroup, container string) ([]byte, error) {
+	out, err := exec.Command("az", "container", "logs",
+		"--name", group,
+		"--resource-group", resourceGroup,
+		"--container-name", container,
+	).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("az container logs %s/%s failed: %s\n%s", group, container, err, strings.TrimSpace(string(out)))
+	}
+	return out, nil
+}
+
+// parseContainerImages reads a JSON list of {name, image} objects.
+func parseContainerImages(data []byte) (map[string]string, error) {
+	var containers []struct {
+		Name  string `json:"name"`
+		Image string `json:"image"`
+	}
+	if err := json.Unmarshal(data, &containers); err != nil {
+		return nil, fmt.Errorf("parsing container list: %w", err)
+	}
+	images := map[string]string{}
+	for _, c := range containers {
+		images[c.Name] = c.Image
 	}
+	return images, nil
 }
 
+// MySQLFQDN returns the fully qualified domain name of a MySQL flexible server.
+func MySQLFQDN(name, resourceGroup string) (string, error) {
+	out, err := exec.Command("az", "mysql", "flexible-server", "show",
+		"--name", name,
+		"--resource-group", resourceGroup,
+		"--query", "fullyQualifiedDomainName",
+		"-o", "tsv",
+	).Output()
+	if err != nil {
+		return "", fmt.Errorf("az mysql flexible-server show %s failed: %w", name, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// MySQLAllowIP adds a firewall rule on a MySQL flexible server for a single IP.
+func MySQLAllowIP(name, resourceGroup, ruleName, ip string) error {
+	return runAz("mysql", "flexible-server", "firewall-rule", "create",
+		"--name", name, "--resource-group", resourceGroup,
+		"--rule-name", ruleName, "--start-ip-address", ip, "--end-ip-address", ip,
+		"--output", "none")
+}
+
+// MySQLDeleteFirewallRule removes a firewall rule from a MySQL flexible server.
+func MySQLDeleteFirewallRule(name, resourceGroup, ruleName string) error {
+	return runAz("mysql", "flexible-server", "firewall-rule", "delete",
+		"--name", name, "--resource-group", resourceGroup,
+		"--rule-name", ruleName, "--yes")
+}
+
+
 // CreateACR creates an Azure Container Registry (idempotent).
-func CreateACR(name, resourceGroup, location string) error {
-	return runAz("acr", "create", "--name", name, "--resource-group", resourceGroup,
-		"--location", location, "--sku", "Basic", "--admin-enabled", "true", "--output", "none")
+func CreateACR(name, resourceGroup, location string, tags map[string]string) error {
+	args := []string{"acr", "create", "--name", name, "--resource-group", resourceGroup,
+		"--location", location, "--sku", "Basic", "--admin-enabled", "true", "--output", "none"}
+	return runAz(append(args, tagArgs(tags)...)...)
 }
 
 // CheckSoftDeletedKeyVault checks if a Key Vault exists in soft-deleted state.
@@ -192,6 +274,25 @@ func CheckSoftDeletedKeyVault(name string) (bool, error) {
 	return len(results) > 0, nil
 }
 
+// KeyVaultSecret returns the current value of a Key Vault secret.
+// The value is never included in returned errors.
+func KeyVaultSecret(vault, name string) (string, error) {
+	out, err := exec.Command("az", "keyvault", "secret", "show",
+		"--vault-name", vault,
+		"--name", name,
+		"--query", "value",
+		"-o", "tsv",
+	).Output()
+	if err != nil {
+		msg := err.Error()
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			msg = strings.TrimSpace(string(exitErr.Stderr))
+		}
+		return "", fmt.Errorf("az keyvault secret show %s/%s failed: %s", vault, name, msg)
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
+
 // PurgeKeyVault permanently purges a soft-deleted Key Vault.
 func PurgeKeyVault(name, location string) error {
 	return runAz("keyvault", "purge", "--name", name, "--location", location)
@@ -0,0 +1,103 @@
+package azure
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Runtimes for the DevLake containers.
+const (
+	RuntimeACI = "aci" // Azure Container Instances
+	RuntimeACA = "aca" // Azure Container Apps
+)
+
+// TemplateName picks the embedded Bicep template for a deployment.
+func TemplateName(runtime string, official, private bool) (string, error) {
+	switch runtime {
+	case RuntimeACI, "":
+		switch {
+		case private:
+			return PrivateTemplate, nil
+		case official:
+			return "main-official.bicep", nil
+		default:
+			return "main.bicep", nil
+		}
+	case RuntimeACA:
+		if private {
+			return "", fmt.Errorf("--private is only supported with --runtime %s", RuntimeACI)
+		}
+		return "main-aca.bicep", nil
+	default:
+		return "", fmt.Errorf("--runtime must be %s or %s, got %q", RuntimeACA, RuntimeACI, runtime)
+	}
+}
+
+// ContainerAppRevision returns the latest revision of a container app.
+func ContainerAppRevision(name, resourceGroup string) (string, error) {
+	out, err := exec.Command("az", "containerapp", "show",
+		"--name", name,
+		"--resource-group", resourceGroup,
+		"--query", "properties.latestRevisionName",
+		"-o", "tsv",
+	).Output()
+	if err != nil {
+		return "", fmt.Errorf("az containerapp show failed for %s: %w", name, err)
+	}
+	rev := strings.TrimSpace(string(out))
+	if rev == "" {
+		return "", fmt.Errorf("container app %s has no revision", name)
+	}
+	return rev, nil
+}
+
+// ContainerAppStart activates the latest revision of a container app.
+func ContainerAppStart(name, resourceGroup string) error {
+	return setRevisionActive(name, resourceGroup, "activate")
+}
+
+// ContainerAppStop deactivates the latest revision of a container app,
+// scaling it to zero replicas until it is activated again.
+func ContainerAppStop(name, resourceGroup string) error {
+	return setRevisionActive(name, resourceGroup, "deactivate")
+}
+
+func setRevisionActive(name, resourceGroup, action string) error {
+	rev, err := ContainerAppRevision(name, resourceGroup)
+	if err != nil {
+		return err
+	}
+	return runAz("containerapp", "revision", action,
+		"--name", name, "--resource-group", resourceGroup, "--revision", rev)
+}
+
+// ContainerAppImages returns the images of the containers in a container
+// app's template, keyed by container name.
+func ContainerAppImages(name, resourceGroup string) (map[string]string, error) {
+	out, err := exec.Command("az", "containerapp", "show",
+		"--name", name,
+		"--resource-group", resourceGroup,
+		"--query", "properties.template.containers[].{name: name, image: image}",
+		"-o", "json",
+	).Output()
+	if err != nil {
+		return nil, fmt.Errorf("az containerapp show failed for %s: %w", name, err)
+	}
+	return parseContainerImages(out)
+}
+
+// ContainerAppLogs returns the last tail console log lines of a Container App.
+func ContainerAppLogs(name, resourceGroup string, tail int) ([]byte, error) {
+	out, err := exec.Command("az", "containerapp", "logs", "show",
+		"--name", name,
+		"--resource-group", resourceGroup,
+		"--format", "text",
+		"--tail", strconv.Itoa(tail),
+	).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("az containerapp logs show failed for %s: %s\n%s", name, err, strings.TrimSpace(string(out)))
+	}
+	return out, nil
+}
@@ -0,0 +1,51 @@
+package azure
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTemplateName(t *testing.T) {
+	cases := []struct {
+		runtime           string
+		official, private bool
+		want              string
+	}{
+		{"aci", false, false, "main.bicep"},
+		{"", true, false, "main-official.bicep"},
+		{"aci", true, true, PrivateTemplate},
+		{"aca", true, false, "main-aca.bicep"},
+		{"aca", false, false, "main-aca.bicep"},
+	}
+	for _, c := range cases {
+		got, err := TemplateName(c.runtime, c.official, c.private)
+		if err != nil || got != c.want {
+			t.Errorf("TemplateName(%q, %v, %v) = %q, %v; want %q", c.runtime, c.official, c.private, got, err, c.want)
+		}
+		if _, err := templateFS.ReadFile("templates/" + got); err != nil {
+			t.Errorf("%s is not embedded: %v", got, err)
+		}
+	}
+
+	if _, err := TemplateName("aca", true, true); err == nil || !strings.Contains(err.Error(), "--private") {
+		t.Errorf("aca + private: got %v", err)
+	}
+	if _, err := TemplateName("aks", true, false); err == nil {
+		t.Error("unknown runtime: expected error")
+	}
+}
+
+func TestACATemplateOutputs(t *testing.T) {
+	data, err := templateFS.ReadFile("templates/main-aca.bicep")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// DeployBicep reads these outputs; container app names must match the
+	// container names deploy azure records in the state file.
+	for _, s := range []string{"output backendEndpoint", "output grafanaEndpoint", "output configUiEndpoint",
+		"output environmentName", "'${baseName}-backend-${uniqueSuffix}'", "'${baseName}-ui-${uniqueSuffix}'"} {
+		if !strings.Contains(string(data), s) {
+			t.Errorf("main-aca.bicep missing %s", s)
+		}
+	}
+}
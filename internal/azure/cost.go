@@ -0,0 +1,154 @@
+package azure
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// HoursPerMonth is the month length the Azure pricing calculator uses.
+const HoursPerMonth = 730
+
+// MySQL size the templates deploy.
+const (
+	DefaultMySQLSKU       = "Standard_B1ms"
+	DefaultMySQLStorageGB = 32
+)
+
+// Approximate pay-as-you-go list prices in USD (East US). They are only
+// good enough for an estimate; regional prices and discounts differ.
+var (
+	// mysqlHourly is the compute price of MySQL Flexible Server SKUs.
+	mysqlHourly = map[string]float64{
+		"Standard_B1s":     0.0085,
+		"Standard_B1ms":    0.017,
+		"Standard_B2s":     0.034,
+		"Standard_B2ms":    0.068,
+		"Standard_D2ds_v4": 0.137,
+		"Standard_D4ds_v4": 0.274,
+	}
+	mysqlStorageGBMonth = 0.115
+
+	aciVCPUHour = 0.0486 // Linux, per vCPU
+	aciGBHour   = 0.0054 // per GB of memory
+
+	acaVCPUSecond   = 0.000024 // consumption plan, active
+	acaGiBSecond    = 0.000003
+	acaFreeVCPUSecs = 180000.0 // monthly free grant per subscription
+	acaFreeGiBSecs  = 360000.0
+
+	acrBasicMonth       = 5.00
+	appGatewayV2Hour    = 0.246 + 0.008 // fixed price plus one capacity unit
+	publicIPHour        = 0.005
+	privateDNSZoneMonth = 0.50
+)
+
+// MySQLSKUs returns the MySQL Flexible Server SKUs in the price table.
+func MySQLSKUs() []string {
+	skus := make([]string, 0, len(mysqlHourly))
+	for s := range mysqlHourly {
+		skus = append(skus, s)
+	}
+	sort.Slice(skus, func(i, j int) bool { return mysqlHourly[skus[i]] < mysqlHourly[skus[j]] })
+	return skus
+}
+
+// ContainerSpec is the size of one DevLake container.
+type ContainerSpec struct {
+	Name     string
+	CPU      float64
+	MemoryGB float64
+	AlwaysOn bool // false for Container Apps that scale to zero
+}
+
+// DefaultContainers returns the container sizes the templates request.
+func DefaultContainers(runtime string) []ContainerSpec {
+	scalesToZero := runtime == RuntimeACA
+	return []ContainerSpec{
+		{Name: "backend", CPU: 2, MemoryGB: 4, AlwaysOn: true},
+		{Name: "grafana", CPU: 1, MemoryGB: 2, AlwaysOn: !scalesToZero},
+		{Name: "config-ui", CPU: 1, MemoryGB: 2, AlwaysOn: !scalesToZero},
+	}
+}
+
+// CostInput describes what a deployment will run.
+type CostInput struct {
+	Runtime        string
+	MySQLSKU       string
+	MySQLStorageGB int
+	Containers     []ContainerSpec
+	ACR            bool
+	Private        bool
+}
+
+// CostLine is one row of an estimate.
+type CostLine struct {
+	Resource string
+	SKU      string
+	Monthly  float64
+}
+
+// EstimateCost returns the estimated monthly cost of each billed resource.
+func EstimateCost(in CostInput) ([]CostLine, error) {
+	hourly, ok := mysqlHourly[in.MySQLSKU]
+	if !ok {
+		return nil, fmt.Errorf("no price for MySQL SKU %q (known: %s)", in.MySQLSKU, strings.Join(MySQLSKUs(), ", "))
+	}
+	lines := []CostLine{
+		{"MySQL Flexible Server", in.MySQLSKU, hourly * HoursPerMonth},
+		{"MySQL storage", fmt.Sprintf("%d GB", in.MySQLStorageGB), float64(in.MySQLStorageGB) * mysqlStorageGBMonth},
+	}
+
+	var vcpuSecs, gibSecs float64
+	for _, c := range in.Containers {
+		size := fmt.Sprintf("%g vCPU / %g GB", c.CPU, c.MemoryGB)
+		switch {
+		case in.Runtime == RuntimeACA && !c.AlwaysOn:
+			lines = append(lines, CostLine{"Container App " + c.Name, size + ", scales to zero", 0})
+		case in.Runtime == RuntimeACA:
+			secs := float64(HoursPerMonth * 3600)
+			vcpuSecs += c.CPU * secs
+			gibSecs += c.MemoryGB * secs
+			lines = append(lines, CostLine{"Container App " + c.Name, size, (c.CPU*acaVCPUSecond + c.MemoryGB*acaGiBSecond) * secs})
+		default:
+			lines = append(lines, CostLine{"Container Instance " + c.Name, size, (c.CPU*aciVCPUHour + c.MemoryGB*aciGBHour) * HoursPerMonth})
+		}
+	}
+	if vcpuSecs > 0 {
+		grant := min(vcpuSecs, acaFreeVCPUSecs)*acaVCPUSecond + min(gibSecs, acaFreeGiBSecs)*acaGiBSecond
+		lines = append(lines, CostLine{"Container Apps free grant", "per subscription", -grant})
+	}
+
+	if in.ACR {
+		lines = append(lines, CostLine{"Container Registry", "Basic", acrBasicMonth})
+	}
+	if in.Private {
+		lines = append(lines,
+			CostLine{"Application Gateway", "Standard_v2, 1 capacity unit", appGatewayV2Hour * HoursPerMonth},
+			CostLine{"Public IP", "Standard, static", publicIPHour * HoursPerMonth},
+			CostLine{"Private DNS zone", "1 zone", privateDNSZoneMonth},
+		)
+	}
+	return lines, nil
+}
+
+// RenderCost writes the estimate as a table with a total.
+func RenderCost(w io.Writer, lines []CostLine) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	total := 0.0
+	for _, l := range lines {
+		fmt.Fprintf(tw, "  %s\t%s\t%10s\n", l.Resource, l.SKU, money(l.Monthly))
+		total += l.Monthly
+	}
+	fmt.Fprintf(tw, "  Estimated total\t\t%10s/month\n", money(total))
+	tw.Flush()
+}
+
+func money(v float64) string {
+	if v < 0 {
+		return fmt.Sprintf("-$%.2f", -v)
+	}
+	return fmt.Sprintf("$%.2f", v)
+}
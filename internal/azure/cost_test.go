@@ -0,0 +1,81 @@
+package azure
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+func total(lines []CostLine) float64 {
+	t := 0.0
+	for _, l := range lines {
+		t += l.Monthly
+	}
+	return math.Round(t*100) / 100
+}
+
+func TestEstimateCost_ACI(t *testing.T) {
+	lines, err := EstimateCost(CostInput{
+		Runtime:        RuntimeACI,
+		MySQLSKU:       "Standard_B1ms",
+		MySQLStorageGB: 32,
+		Containers:     DefaultContainers(RuntimeACI),
+		ACR:            true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// MySQL 12.41 + storage 3.68 + ACI (4 vCPU, 8 GB) 173.45 + ACR 5.00
+	if got := total(lines); got != 194.54 {
+		t.Errorf("total = %.2f, want 194.54", got)
+	}
+
+	var b bytes.Buffer
+	RenderCost(&b, lines)
+	for _, s := range []string{"Container Instance backend", "2 vCPU / 4 GB", "Container Registry", "$194.54/month"} {
+		if !strings.Contains(b.String(), s) {
+			t.Errorf("estimate missing %q:\n%s", s, b.String())
+		}
+	}
+}
+
+func TestEstimateCost_ACAPrivate(t *testing.T) {
+	lines, err := EstimateCost(CostInput{
+		Runtime:        RuntimeACA,
+		MySQLSKU:       "Standard_B1ms",
+		MySQLStorageGB: 32,
+		Containers:     DefaultContainers(RuntimeACA),
+		Private:        true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var grafana, grant, gateway float64
+	for _, l := range lines {
+		switch l.Resource {
+		case "Container App grafana":
+			grafana = l.Monthly
+		case "Container Apps free grant":
+			grant = l.Monthly
+		case "Application Gateway":
+			gateway = l.Monthly
+		}
+	}
+	if grafana != 0 {
+		t.Errorf("scale-to-zero app should cost 0, got %.2f", grafana)
+	}
+	if grant >= 0 {
+		t.Errorf("free grant should be negative, got %.2f", grant)
+	}
+	if gateway == 0 {
+		t.Error("private deployment should include the Application Gateway")
+	}
+}
+
+func TestEstimateCost_UnknownSKU(t *testing.T) {
+	_, err := EstimateCost(CostInput{MySQLSKU: "Standard_X99"})
+	if err == nil || !strings.Contains(err.Error(), "Standard_B1ms") {
+		t.Errorf("got %v, want error listing known SKUs", err)
+	}
+}
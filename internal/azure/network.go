@@ -0,0 +1,283 @@
+package azure
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// PrivateTemplate is the Bicep variant with VNet integration and an
+// Application Gateway in front of Config UI.
+const PrivateTemplate = "main-private.bicep"
+
+var domainLabelRe = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]{0,61}[a-z0-9])?$`)
+
+// PrivateOptions configures a private-network deployment.
+type PrivateOptions struct {
+	CustomDomain string   // host name served by the gateway; empty uses the gateway FQDN
+	CertFile     string   // PFX certificate for the HTTPS listener
+	CertPassword string   // password of the PFX file
+	CertSecretID string   // Key Vault certificate secret ID, instead of CertFile
+	AllowedIPs   []string // IPs or CIDRs allowed to reach the gateway; empty allows all
+}
+
+// Params validates the options and returns the Bicep parameters for
+// main-private.bicep. With CertSecretID the caller must also set
+// "gatewayIdentityId" to an identity that can read the secret (see
+// GrantKeyVaultSecretRead).
+func (o PrivateOptions) Params() (map[string]string, error) {
+	if o.CustomDomain != "" {
+		if err := ValidateDomain(o.CustomDomain); err != nil {
+			return nil, err
+		}
+	}
+	allowed, err := NormalizeAllowlist(o.AllowedIPs)
+	if err != nil {
+		return nil, err
+	}
+	allowedJSON, _ := json.Marshal(allowed)
+	params := map[string]string{
+		"allowedSourceIps": string(allowedJSON),
+	}
+
+	switch {
+	case o.CertFile != "" && o.CertSecretID != "":
+		return nil, fmt.Errorf("use either --tls-cert or --tls-cert-secret-id, not both")
+	case o.CertSecretID != "":
+		if _, _, err := ParseKeyVaultSecretID(o.CertSecretID); err != nil {
+			return nil, err
+		}
+		params["tlsKeyVaultSecretId"] = o.CertSecretID
+	case o.CertFile != "":
+		cert, err := os.ReadFile(o.CertFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading TLS certificate: %w", err)
+		}
+		if len(cert) == 0 {
+			return nil, fmt.Errorf("TLS certificate %s is empty", o.CertFile)
+		}
+		params["tlsCertData"] = base64.StdEncoding.EncodeToString(cert)
+		if o.CertPassword != "" {
+			params["tlsCertPassword"] = o.CertPassword
+		}
+	default:
+		return nil, fmt.Errorf("--private needs a certificate for the HTTPS listener: --tls-cert (a PFX file) or --tls-cert-secret-id (a Key Vault certificate)")
+	}
+	if o.CustomDomain != "" {
+		params["customDomain"] = strings.ToLower(o.CustomDomain)
+	}
+	return params, nil
+}
+
+// NormalizeAllowlist turns IPs and CIDRs into canonical IPv4 CIDRs (a bare IP
+// becomes /32) and drops duplicates, keeping the input order.
+func NormalizeAllowlist(entries []string) ([]string, error) {
+	out := []string{}
+	seen := map[string]bool{}
+	for _, e := range entries {
+		e = strings.TrimSpace(e)
+		if e == "" {
+			continue
+		}
+		cidr := e
+		if !strings.Contains(e, "/") {
+			cidr = e + "/32"
+		}
+		ip, network, err := net.ParseCIDR(cidr)
+		if err != nil || ip.To4() == nil {
+			return nil, fmt.Errorf("--allow-ip %q is not an IPv4 address or CIDR", e)
+		}
+		if !ip.Equal(network.IP) {
+			return nil, fmt.Errorf("--allow-ip %q has host bits set — did you mean %s?", e, network)
+		}
+		if s := network.String(); !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+// ParseKeyVaultSecretID splits a Key Vault secret ID such as
+// https://my-vault.vault.azure.net/secrets/devlake-tls into the vault and
+// secret names. An ID without a version follows certificate renewals.
+func ParseKeyVaultSecretID(id string) (vault, secret string, err error) {
+	u, err := url.Parse(id)
+	if err == nil && u.Scheme == "https" {
+		host, _, _ := strings.Cut(u.Host, ".")
+		parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+		if host != "" && strings.Contains(u.Host, ".vault.") && (len(parts) == 2 || len(parts) == 3) && parts[0] == "secrets" && parts[1] != "" {
+			return host, parts[1], nil
+		}
+	}
+	return "", "", fmt.Errorf("--tls-cert-secret-id %q is not a Key Vault secret ID (https://<vault>.vault.azure.net/secrets/<certificate name>)", id)
+}
+
+// ValidateDomain checks that d is a fully qualified host name such as
+// devlake.example.com.
+func ValidateDomain(d string) error {
+	labels := strings.Split(strings.ToLower(d), ".")
+	if len(d) > 253 || len(labels) < 2 {
+		return fmt.Errorf("--custom-domain %q must be a fully qualified host name (e.g. devlake.example.com)", d)
+	}
+	for _, l := range labels {
+		if !domainLabelRe.MatchString(l) {
+			return fmt.Errorf("--custom-domain %q is not a valid host name", d)
+		}
+	}
+	return nil
+}
+
+// PrivateResources are the networking resources main-private.bicep adds.
+type PrivateResources struct {
+	Gateway  string `json:"gateway"`
+	PublicIP string `json:"publicIp"`
+	VNet     string `json:"vnet"`
+	NSG      string `json:"nsg"`
+	DNSZone  string `json:"dnsZone"`
+	DNSLink  string `json:"dnsLink"`
+	// ServiceZone holds the A records Config UI and the gateway reach the
+	// containers by. Deployments made before it existed leave it empty.
+	ServiceZone string `json:"serviceZone,omitempty"`
+	// GatewayIdentity reads the --tls-cert-secret-id certificate.
+	GatewayIdentity string `json:"gatewayIdentity,omitempty"`
+}
+
+// PrivateResourceNames returns the names main-private.bicep gives the
+// networking resources for baseName and suffix. GatewayIdentity is left
+// empty; it only exists with --tls-cert-secret-id.
+func PrivateResourceNames(baseName, suffix string) PrivateResources {
+	return PrivateResources{
+		Gateway:     fmt.Sprintf("%s-agw-%s", baseName, suffix),
+		PublicIP:    fmt.Sprintf("%s-agw-ip-%s", baseName, suffix),
+		VNet:        fmt.Sprintf("%s-vnet-%s", baseName, suffix),
+		NSG:         fmt.Sprintf("%s-agw-nsg-%s", baseName, suffix),
+		DNSZone:     fmt.Sprintf("%s%s.private.mysql.database.azure.com", baseName, suffix),
+		DNSLink:     baseName + "-vnet-link",
+		ServiceZone: fmt.Sprintf("%s%s.internal", baseName, suffix),
+	}
+}
+
+// GatewayIdentityName names the managed identity the gateway reads a Key
+// Vault certificate with.
+func GatewayIdentityName(baseName, suffix string) string {
+	return fmt.Sprintf("%s-agw-id-%s", baseName, suffix)
+}
+
+// ServiceRecord returns the A record in the service zone that points at a
+// container group of a private deployment ("backend", "grafana" or "ui"),
+// or "" for a name that is not one of them.
+func ServiceRecord(baseName, suffix, containerGroup string) string {
+	for _, r := range []string{"backend", "grafana", "ui"} {
+		if containerGroup == fmt.Sprintf("%s-%s-%s", baseName, r, suffix) {
+			return r
+		}
+	}
+	return ""
+}
+
+// ContainerGroupIP returns the current IP address of a container group.
+func ContainerGroupIP(name, resourceGroup string) (string, error) {
+	out, err := exec.Command("az", "container", "show",
+		"--name", name,
+		"--resource-group", resourceGroup,
+		"--query", "ipAddress.ip",
+		"-o", "tsv",
+	).Output()
+	if err != nil {
+		return "", fmt.Errorf("az container show %s failed: %w", name, err)
+	}
+	ip := strings.TrimSpace(string(out))
+	if ip == "" {
+		return "", fmt.Errorf("container group %s has no IP address", name)
+	}
+	return ip, nil
+}
+
+// ServiceRecordIP returns the address an A record in the service zone
+// points at.
+func ServiceRecordIP(zone, resourceGroup, record string) (string, error) {
+	out, err := exec.Command("az", "network", "private-dns", "record-set", "a", "show",
+		"--zone-name", zone,
+		"--resource-group", resourceGroup,
+		"--name", record,
+		"--query", "aRecords[0].ipv4Address",
+		"-o", "tsv",
+	).Output()
+	if err != nil {
+		return "", fmt.Errorf("az network private-dns record-set a show %s.%s failed: %w", record, zone, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// SetServiceRecord points an A record in the service zone at ip.
+func SetServiceRecord(zone, resourceGroup, record, ip string) error {
+	return runAz("network", "private-dns", "record-set", "a", "update",
+		"--zone-name", zone,
+		"--resource-group", resourceGroup,
+		"--name", record,
+		"--set", "aRecords[0].ipv4Address="+ip,
+		"--output", "none")
+}
+
+// ContainerRestart restarts the containers of a running container group.
+func ContainerRestart(name, resourceGroup string) error {
+	return runAz("container", "restart", "--name", name, "--resource-group", resourceGroup)
+}
+
+// CreateGatewayIdentity creates (or updates) the user-assigned identity the
+// gateway reads its Key Vault certificate with, and returns its resource ID
+// and principal ID.
+func CreateGatewayIdentity(name, resourceGroup, location string, tags map[string]string) (id, principalID string, err error) {
+	args := []string{"identity", "create", "--name", name, "--resource-group", resourceGroup,
+		"--location", location, "--query", "{id: id, principalId: principalId}", "-o", "json"}
+	out, err := exec.Command("az", append(args, tagArgs(tags)...)...).Output()
+	if err != nil {
+		return "", "", fmt.Errorf("az identity create %s failed: %w", name, err)
+	}
+	var identity struct {
+		ID          string `json:"id"`
+		PrincipalID string `json:"principalId"`
+	}
+	if err := json.Unmarshal(out, &identity); err != nil {
+		return "", "", fmt.Errorf("parsing az identity create output: %w", err)
+	}
+	return identity.ID, identity.PrincipalID, nil
+}
+
+// GrantKeyVaultSecretRead lets principalID read the secrets of a Key Vault:
+// "Key Vault Secrets User" on vaults that use Azure RBAC, a get access
+// policy on the others.
+func GrantKeyVaultSecretRead(vault, principalID string) error {
+	out, err := exec.Command("az", "keyvault", "show",
+		"--name", vault,
+		"--query", "{id: id, rbac: properties.enableRbacAuthorization}",
+		"-o", "json",
+	).Output()
+	if err != nil {
+		return fmt.Errorf("az keyvault show %s failed: %w", vault, err)
+	}
+	var kv struct {
+		ID   string `json:"id"`
+		RBAC bool   `json:"rbac"`
+	}
+	if err := json.Unmarshal(out, &kv); err != nil {
+		return fmt.Errorf("parsing az keyvault show output: %w", err)
+	}
+	if kv.RBAC {
+		return runAz("role", "assignment", "create",
+			"--assignee-object-id", principalID,
+			"--assignee-principal-type", "ServicePrincipal",
+			"--role", "Key Vault Secrets User",
+			"--scope", kv.ID,
+			"--output", "none")
+	}
+	return runAz("keyvault", "set-policy", "--name", vault,
+		"--object-id", principalID, "--secret-permissions", "get", "--output", "none")
+}
@@ -0,0 +1,178 @@
+package azure
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeAllowlist(t *testing.T) {
+	got, err := NormalizeAllowlist([]string{"203.0.113.7", " 198.51.100.0/24 ", "", "203.0.113.7/32"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"203.0.113.7/32", "198.51.100.0/24"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	for _, bad := range []string{"not-an-ip", "2001:db8::1", "10.0.0.5/24", "10.0.0.0/33"} {
+		if _, err := NormalizeAllowlist([]string{bad}); err == nil {
+			t.Errorf("%q: expected error", bad)
+		}
+	}
+}
+
+func TestValidateDomain(t *testing.T) {
+	for _, ok := range []string{"devlake.example.com", "DevLake.Example.com", "a.io"} {
+		if err := ValidateDomain(ok); err != nil {
+			t.Errorf("%q: unexpected error %v", ok, err)
+		}
+	}
+	for _, bad := range []string{"localhost", "https://devlake.example.com", "-x.example.com", "dev_lake.example.com", "example.com."} {
+		if err := ValidateDomain(bad); err == nil {
+			t.Errorf("%q: expected error", bad)
+		}
+	}
+}
+
+func TestPrivateOptionsParams(t *testing.T) {
+	cert := filepath.Join(t.TempDir(), "devlake.pfx")
+	if err := os.WriteFile(cert, []byte("pfx-bytes"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	params, err := PrivateOptions{
+		CustomDomain: "DevLake.Example.com",
+		CertFile:     cert,
+		CertPassword: "s3cret",
+		AllowedIPs:   []string{"203.0.113.7"},
+	}.Params()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"tlsCertData":      base64.StdEncoding.EncodeToString([]byte("pfx-bytes")),
+		"tlsCertPassword":  "s3cret",
+		"customDomain":     "devlake.example.com",
+		"allowedSourceIps": `["203.0.113.7/32"]`,
+	}
+	for k, v := range want {
+		if params[k] != v {
+			t.Errorf("%s = %q, want %q", k, params[k], v)
+		}
+	}
+
+	params, err = PrivateOptions{CertFile: cert}.Params()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params["allowedSourceIps"] != "[]" {
+		t.Errorf("allowedSourceIps = %q, want []", params["allowedSourceIps"])
+	}
+	if _, ok := params["customDomain"]; ok {
+		t.Error("customDomain set without --custom-domain")
+	}
+	if _, ok := params["tlsCertPassword"]; ok {
+		t.Error("tlsCertPassword set without a password")
+	}
+
+	if _, err := (PrivateOptions{}).Params(); err == nil || !strings.Contains(err.Error(), "--tls-cert") {
+		t.Errorf("missing cert: got %v", err)
+	}
+}
+
+func TestPrivateResourceNames(t *testing.T) {
+	r := PrivateResourceNames("devlake", "abc12")
+	if r.Gateway != "devlake-agw-abc12" || r.VNet != "devlake-vnet-abc12" ||
+		r.DNSZone != "devlakeabc12.private.mysql.database.azure.com" {
+		t.Errorf("unexpected names: %+v", r)
+	}
+}
+
+func TestPrivateTemplateEmbedded(t *testing.T) {
+	path, cleanup, err := WriteTemplate(PrivateTemplate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The names recorded in the state file must match what the template creates.
+	for _, s := range []string{"'${baseName}-agw-${uniqueSuffix}'", "'${baseName}-vnet-${uniqueSuffix}'",
+		"publicNetworkAccess: 'Disabled'", "output gatewayPublicIp"} {
+		if !strings.Contains(string(data), s) {
+			t.Errorf("template missing %s", s)
+		}
+	}
+}
+
+func TestParseKeyVaultSecretID(t *testing.T) {
+	for _, id := range []string{
+		"https://corp-kv.vault.azure.net/secrets/devlake-tls",
+		"https://corp-kv.vault.azure.net/secrets/devlake-tls/",
+		"https://corp-kv.vault.azure.net/secrets/devlake-tls/0123456789abcdef",
+		"https://corp-kv.vault.usgovcloudapi.net/secrets/devlake-tls",
+	} {
+		vault, name, err := ParseKeyVaultSecretID(id)
+		if err != nil || vault != "corp-kv" || name != "devlake-tls" {
+			t.Errorf("ParseKeyVaultSecretID(%q) = %q, %q, %v", id, vault, name, err)
+		}
+	}
+	for _, id := range []string{
+		"",
+		"corp-kv/devlake-tls",
+		"http://corp-kv.vault.azure.net/secrets/devlake-tls",
+		"https://corp-kv.vault.azure.net/certificates/devlake-tls",
+		"https://example.com/secrets/devlake-tls",
+		"https://corp-kv.vault.azure.net/secrets/",
+	} {
+		if _, _, err := ParseKeyVaultSecretID(id); err == nil {
+			t.Errorf("ParseKeyVaultSecretID(%q) accepted an invalid ID", id)
+		}
+	}
+}
+
+func TestPrivateOptionsKeyVaultCert(t *testing.T) {
+	const id = "https://corp-kv.vault.azure.net/secrets/devlake-tls"
+	params, err := PrivateOptions{CertSecretID: id}.Params()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params["tlsKeyVaultSecretId"] != id || params["tlsCertData"] != "" {
+		t.Errorf("params = %v", params)
+	}
+
+	cert := filepath.Join(t.TempDir(), "devlake.pfx")
+	if err := os.WriteFile(cert, []byte("pfx"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (PrivateOptions{CertFile: cert, CertSecretID: id}).Params(); err == nil || !strings.Contains(err.Error(), "not both") {
+		t.Errorf("both certificates error = %v", err)
+	}
+	if _, err := (PrivateOptions{}).Params(); err == nil || !strings.Contains(err.Error(), "--tls-cert-secret-id") {
+		t.Errorf("no certificate error = %v", err)
+	}
+}
+
+func TestServiceRecord(t *testing.T) {
+	names := PrivateResourceNames("devlake", "abc12")
+	if names.ServiceZone != "devlakeabc12.internal" {
+		t.Errorf("service zone = %q", names.ServiceZone)
+	}
+	for group, want := range map[string]string{
+		"devlake-backend-abc12": "backend",
+		"devlake-grafana-abc12": "grafana",
+		"devlake-ui-abc12":      "ui",
+		"devlake-ui-other":      "",
+		"devlakemysqlabc12":     "",
+	} {
+		if got := ServiceRecord("devlake", "abc12", group); got != want {
+			t.Errorf("ServiceRecord(%q) = %q, want %q", group, got, want)
+		}
+	}
+}
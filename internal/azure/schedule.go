@@ -0,0 +1,517 @@
+package azure
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ScheduleTemplate deploys the Logic Apps of a start/stop schedule.
+const ScheduleTemplate = "schedule.bicep"
+
+// Schedule is a start/stop schedule as recorded in .devlake-azure.json.
+type Schedule struct {
+	Up        string   `json:"up,omitempty"`   // cron expression to start at
+	Down      string   `json:"down,omitempty"` // cron expression to stop at
+	TimeZone  string   `json:"timeZone"`
+	Workflows []string `json:"workflows"` // Logic App names
+}
+
+// Describe returns the schedule in words, e.g.
+// "up 07:00 Mon–Fri, down 20:00 Mon–Fri (UTC)".
+func (s Schedule) Describe() string {
+	var parts []string
+	for _, p := range []struct{ label, expr string }{{"up", s.Up}, {"down", s.Down}} {
+		if p.expr == "" {
+			continue
+		}
+		r, err := ParseCron(p.expr, s.TimeZone)
+		if err != nil {
+			parts = append(parts, fmt.Sprintf("%s %q", p.label, p.expr))
+			continue
+		}
+		parts = append(parts, p.label+" "+r.String())
+	}
+	return fmt.Sprintf("%s (%s)", strings.Join(parts, ", "), s.TimeZone)
+}
+
+// recurrenceStart anchors every recurrence. Logic Apps only apply timeZone
+// when a start time is set; any date in the past works.
+const recurrenceStart = "2024-01-01T00:00:00"
+
+// Recurrence is the recurrence of a Logic App schedule trigger.
+type Recurrence struct {
+	Frequency string             `json:"frequency"` // Day or Week
+	Interval  int                `json:"interval"`
+	StartTime string             `json:"startTime"`
+	TimeZone  string             `json:"timeZone"`
+	Schedule  RecurrenceSchedule `json:"schedule"`
+}
+
+// RecurrenceSchedule is the advanced schedule of a recurrence.
+type RecurrenceSchedule struct {
+	Hours    []int    `json:"hours"`
+	Minutes  []int    `json:"minutes"`
+	WeekDays []string `json:"weekDays,omitempty"`
+}
+
+var weekDayNames = []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+
+var cronDayNumbers = map[string]int{"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6}
+
+// ParseCron converts a five-field cron expression (minute hour day-of-month
+// month day-of-week) into a recurrence. Only what a recurrence can express
+// is accepted: fixed minutes, fixed hours or an hour step, every day of the
+// month, every month, and any days of the week.
+func ParseCron(expr, timeZone string) (Recurrence, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return Recurrence{}, fmt.Errorf("cron %q: want 5 fields (minute hour day-of-month month day-of-week), got %d", expr, len(fields))
+	}
+	if fields[0] == "*" || strings.HasPrefix(fields[0], "*/") {
+		return Recurrence{}, fmt.Errorf("cron %q: the minute must be fixed (e.g. 0 or 0,30)", expr)
+	}
+	minutes, err := parseCronField(fields[0], 0, 59, nil)
+	if err != nil {
+		return Recurrence{}, fmt.Errorf("cron %q: minute: %w", expr, err)
+	}
+	if fields[1] == "*" {
+		return Recurrence{}, fmt.Errorf("cron %q: the hour must be fixed or a step (e.g. 7, 7,19 or */6)", expr)
+	}
+	hours, err := parseCronField(fields[1], 0, 23, nil)
+	if err != nil {
+		return Recurrence{}, fmt.Errorf("cron %q: hour: %w", expr, err)
+	}
+	if fields[2] != "*" || fields[3] != "*" {
+		return Recurrence{}, fmt.Errorf("cron %q: day-of-month and month must be * — schedules repeat daily or weekly", expr)
+	}
+
+	r := Recurrence{
+		Frequency: "Day",
+		Interval:  1,
+		StartTime: recurrenceStart,
+		TimeZone:  timeZone,
+		Schedule:  RecurrenceSchedule{Hours: hours, Minutes: minutes},
+	}
+	if fields[4] != "*" {
+		days, err := parseCronField(strings.ToLower(fields[4]), 0, 7, cronDayNumbers)
+		if err != nil {
+			return Recurrence{}, fmt.Errorf("cron %q: day-of-week: %w", expr, err)
+		}
+		seen := map[int]bool{}
+		for _, d := range days {
+			seen[d%7] = true // 7 is Sunday too
+		}
+		if len(seen) < 7 {
+			r.Frequency = "Week"
+			for d := 0; d < 7; d++ {
+				if seen[d] {
+					r.Schedule.WeekDays = append(r.Schedule.WeekDays, weekDayNames[d])
+				}
+			}
+		}
+	}
+	return r, nil
+}
+
+// parseCronField expands a cron field of numbers, names, lists, ranges and
+// steps ("1-5", "mon,wed", "*/6") into sorted values.
+func parseCronField(field string, lo, hi int, names map[string]int) ([]int, error) {
+	value := func(s string) (int, error) {
+		if n, ok := names[s]; ok {
+			return n, nil
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil || n < lo || n > hi {
+			return 0, fmt.Errorf("%q is not between %d and %d", s, lo, hi)
+		}
+		return n, nil
+	}
+
+	set := map[int]bool{}
+	for _, part := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid step %q", stepStr)
+			}
+			step = n
+		}
+		from, to := lo, hi
+		switch a, b, isRange := strings.Cut(rng, "-"); {
+		case rng == "*":
+		case isRange:
+			var err error
+			if from, err = value(a); err != nil {
+				return nil, err
+			}
+			if to, err = value(b); err != nil {
+				return nil, err
+			}
+			if from > to {
+				return nil, fmt.Errorf("range %q runs backwards", rng)
+			}
+		default:
+			n, err := value(rng)
+			if err != nil {
+				return nil, err
+			}
+			from, to = n, n
+			if hasStep {
+				to = hi
+			}
+		}
+		for v := from; v <= to; v += step {
+			set[v] = true
+		}
+	}
+
+	values := make([]int, 0, len(set))
+	for v := range set {
+		values = append(values, v)
+	}
+	sort.Ints(values)
+	return values, nil
+}
+
+// String describes the recurrence, e.g. "07:00 Mon–Fri" or "06:00, 18:00 daily".
+func (r Recurrence) String() string {
+	var times []string
+	for _, h := range r.Schedule.Hours {
+		for _, m := range r.Schedule.Minutes {
+			times = append(times, fmt.Sprintf("%02d:%02d", h, m))
+		}
+	}
+	days := "daily"
+	if len(r.Schedule.WeekDays) > 0 {
+		days = describeWeekDays(r.Schedule.WeekDays)
+	}
+	return strings.Join(times, ", ") + " " + days
+}
+
+// describeWeekDays abbreviates the days and joins runs of three or more
+// consecutive days with a dash: Mon–Fri, or Mon, Wed, Fri.
+func describeWeekDays(days []string) string {
+	index := map[string]int{}
+	for i, d := range weekDayNames {
+		index[d] = i
+	}
+	var parts []string
+	for i := 0; i < len(days); {
+		j := i
+		for j+1 < len(days) && index[days[j+1]] == index[days[j]]+1 {
+			j++
+		}
+		if j-i >= 2 {
+			parts = append(parts, days[i][:3]+"–"+days[j][:3])
+		} else {
+			for k := i; k <= j; k++ {
+				parts = append(parts, days[k][:3])
+			}
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ScheduleTarget is what a schedule starts and stops.
+type ScheduleTarget struct {
+	SubscriptionID string
+	ResourceGroup  string
+	Runtime        string
+	MySQL          string
+	Containers     []string // container groups, or container apps with runtime aca
+	// ServiceZone and ServiceRecords make a private deployment's start point
+	// the zone's A records (keyed by container group) at the new IPs.
+	ServiceZone    string
+	ServiceRecords map[string]string
+}
+
+// ARM API versions the schedule actions call.
+const (
+	mysqlAPIVersion          = "2023-06-30"
+	containerGroupAPIVersion = "2023-05-01"
+	containerAppAPIVersion   = "2023-05-01"
+	privateDNSAPIVersion     = "2020-06-01"
+)
+
+// anyOutcome lets a step run whether or not the one before it worked, so a
+// resource that is already started or stopped does not block the rest.
+var anyOutcome = []string{"Succeeded", "Failed", "Skipped", "TimedOut"}
+
+// Actions returns the Logic App actions that start (up) or stop the target.
+// Starting brings MySQL up before the containers; stopping takes the
+// containers down before MySQL. With a service zone, each started container
+// group's A record is pointed at its new IP, and Config UI starts only after
+// the backend and Grafana records, since it resolves them once at startup.
+func (t ScheduleTarget) Actions(up bool) map[string]any {
+	verb := "stop"
+	if up {
+		verb = "start"
+	}
+	actions := map[string]any{}
+	mysql := verb + "_mysql"
+	mysqlURI := t.resourceURI("Microsoft.DBforMySQL/flexibleServers", t.MySQL) + "/" + verb + "?api-version=" + mysqlAPIVersion
+
+	refreshDNS := up && t.Runtime != RuntimeACA && t.ServiceZone != ""
+	records := map[string][]string{} // the DNS steps Config UI waits for
+	if refreshDNS {
+		for _, c := range t.Containers {
+			if r := t.ServiceRecords[c]; r != "" && r != "ui" {
+				records["dns_"+actionName(c)] = anyOutcome
+			}
+		}
+	}
+
+	var containerSteps []string
+	for _, c := range t.Containers {
+		first := map[string][]string{}
+		if up {
+			first = map[string][]string{mysql: anyOutcome}
+		}
+		record := ""
+		if refreshDNS {
+			record = t.ServiceRecords[c]
+		}
+		if record == "ui" && len(records) > 0 {
+			first = records
+		}
+		name := verb + "_" + actionName(c)
+		if t.Runtime == RuntimeACA {
+			// Same as 'gh devlake start/stop': (de)activate the latest revision
+			get := "get_" + actionName(c)
+			appURI := t.resourceURI("Microsoft.App/containerApps", c)
+			actions[get] = armAction("GET", appURI+"?api-version="+containerAppAPIVersion, first)
+			action := "activate"
+			if !up {
+				action = "deactivate"
+			}
+			revision := fmt.Sprintf("@{body('%s')?['properties']?['latestRevisionName']}", get)
+			actions[name] = armAction("POST",
+				appURI+"/revisions/"+revision+"/"+action+"?api-version="+containerAppAPIVersion,
+				map[string][]string{get: {"Succeeded"}})
+		} else {
+			groupURI := t.resourceURI("Microsoft.ContainerInstance/containerGroups", c)
+			actions[name] = armAction("POST", groupURI+"/"+verb+"?api-version="+containerGroupAPIVersion, first)
+			if record != "" {
+				// Same as 'gh devlake start': the record follows the group's new IP
+				get := "get_" + actionName(c)
+				actions[get] = armAction("GET", groupURI+"?api-version="+containerGroupAPIVersion,
+					map[string][]string{name: anyOutcome})
+				dns := armAction("PUT",
+					t.resourceURI("Microsoft.Network/privateDnsZones", t.ServiceZone)+"/A/"+record+"?api-version="+privateDNSAPIVersion,
+					map[string][]string{get: {"Succeeded"}})
+				dns["inputs"].(map[string]any)["body"] = map[string]any{
+					"properties": map[string]any{
+						"ttl": 10,
+						"aRecords": []map[string]string{
+							{"ipv4Address": fmt.Sprintf("@{body('%s')?['properties']?['ipAddress']?['ip']}", get)},
+						},
+					},
+				}
+				actions["dns_"+actionName(c)] = dns
+			}
+		}
+		containerSteps = append(containerSteps, name)
+	}
+
+	after := map[string][]string{}
+	if !up {
+		for _, s := range containerSteps {
+			after[s] = anyOutcome
+		}
+	}
+	actions[mysql] = armAction("POST", mysqlURI, after)
+	return actions
+}
+
+func (t ScheduleTarget) resourceURI(provider, name string) string {
+	return fmt.Sprintf("https://management.azure.com/subscriptions/%s/resourceGroups/%s/providers/%s/%s",
+		t.SubscriptionID, t.ResourceGroup, provider, name)
+}
+
+// armAction is an HTTP action that calls Azure Resource Manager as the
+// Logic App's managed identity. Long-running operations are polled until
+// they finish.
+func armAction(method, uri string, runAfter map[string][]string) map[string]any {
+	return map[string]any{
+		"type": "Http",
+		"inputs": map[string]any{
+			"method":         method,
+			"uri":            uri,
+			"authentication": map[string]string{"type": "ManagedServiceIdentity"},
+		},
+		"runAfter": runAfter,
+	}
+}
+
+func actionName(resource string) string {
+	return strings.ReplaceAll(resource, "-", "_")
+}
+
+// ScheduleWorkflowName returns the Logic App name for one direction ("up" or "down").
+func ScheduleWorkflowName(baseName, suffix, direction string) string {
+	return fmt.Sprintf("%s-%s-%s", baseName, direction, suffix)
+}
+
+// ScheduleParams renders the Bicep parameters of the schedule template: one
+// workflow per direction in s. It needs no Azure access. The workflow names
+// are recorded in s.
+func ScheduleParams(baseName, suffix string, s *Schedule, t ScheduleTarget, tags map[string]string) (map[string]string, error) {
+	if s.Up == "" && s.Down == "" {
+		return nil, fmt.Errorf("a schedule needs --up, --down or both")
+	}
+	if s.TimeZone == "" {
+		return nil, fmt.Errorf("a schedule needs a time zone")
+	}
+	if t.SubscriptionID == "" || t.MySQL == "" || len(t.Containers) == 0 {
+		return nil, fmt.Errorf("the state file does not record the subscription, MySQL server and containers to schedule")
+	}
+
+	type workflow struct {
+		Name       string         `json:"name"`
+		Recurrence Recurrence     `json:"recurrence"`
+		Actions    map[string]any `json:"actions"`
+	}
+	var workflows []workflow
+	s.Workflows = nil
+	for _, d := range []struct {
+		direction, expr string
+		up              bool
+	}{{"up", s.Up, true}, {"down", s.Down, false}} {
+		if d.expr == "" {
+			continue
+		}
+		r, err := ParseCron(d.expr, s.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("--%s: %w", d.direction, err)
+		}
+		name := ScheduleWorkflowName(baseName, suffix, d.direction)
+		workflows = append(workflows, workflow{Name: name, Recurrence: r, Actions: t.Actions(d.up)})
+		s.Workflows = append(s.Workflows, name)
+	}
+
+	if tags == nil {
+		tags = map[string]string{}
+	}
+	workflowsJSON, err := json.Marshal(workflows)
+	if err != nil {
+		return nil, err
+	}
+	tagsJSON, _ := json.Marshal(tags)
+	return map[string]string{
+		"workflows": string(workflowsJSON),
+		"tags":      string(tagsJSON),
+	}, nil
+}
+
+// scheduleRolePrefix starts the name of the custom role schedule.bicep
+// defines for the workflow identities.
+const scheduleRolePrefix = "DevLake schedule "
+
+// DeleteSchedule deletes the Logic Apps of a schedule and the role
+// assignments of their managed identities, then the schedule role once
+// nothing in the resource group is assigned it. Missing workflows are skipped.
+func DeleteSchedule(workflows []string, resourceGroup string) error {
+	var failed []string
+	for _, name := range workflows {
+		principal, err := schedulePrincipal(name, resourceGroup)
+		if err != nil {
+			continue // already gone
+		}
+		if principal != "" {
+			if err := runAz("role", "assignment", "delete", "--assignee", principal, "--resource-group", resourceGroup); err != nil {
+				failed = append(failed, fmt.Sprintf("%s role assignment: %v", name, err))
+			}
+		}
+		if err := runAz("resource", "delete", "--resource-group", resourceGroup,
+			"--resource-type", "Microsoft.Logic/workflows", "--name", name); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+	if err := deleteUnusedScheduleRole(resourceGroup); err != nil {
+		failed = append(failed, fmt.Sprintf("schedule role: %v", err))
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("could not delete the schedule:\n  %s", strings.Join(failed, "\n  "))
+	}
+	return nil
+}
+
+// RevokeScheduleContributor removes the Contributor assignments that
+// schedules deployed by earlier versions gave their workflow identities.
+// The schedule role replaces them.
+func RevokeScheduleContributor(workflows []string, resourceGroup string) error {
+	var failed []string
+	for _, name := range workflows {
+		principal, err := schedulePrincipal(name, resourceGroup)
+		if err != nil || principal == "" {
+			continue
+		}
+		if err := runAz("role", "assignment", "delete", "--assignee", principal,
+			"--role", "Contributor", "--resource-group", resourceGroup); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %s", name, ErrorReason(err)))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("could not remove the old Contributor assignment:\n  %s", strings.Join(failed, "\n  "))
+	}
+	return nil
+}
+
+// schedulePrincipal returns the principal ID of a Logic App's managed identity.
+func schedulePrincipal(workflow, resourceGroup string) (string, error) {
+	out, err := exec.Command("az", "resource", "show",
+		"--resource-group", resourceGroup,
+		"--resource-type", "Microsoft.Logic/workflows",
+		"--name", workflow,
+		"--query", "identity.principalId",
+		"-o", "tsv",
+	).Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// deleteUnusedScheduleRole deletes the schedule role of a resource group
+// when no schedule in it is assigned the role any more.
+func deleteUnusedScheduleRole(resourceGroup string) error {
+	out, err := exec.Command("az", "group", "show", "--name", resourceGroup, "--query", "id", "-o", "tsv").Output()
+	if err != nil {
+		return nil // resource group already gone
+	}
+	scope := strings.TrimSpace(string(out))
+	out, err = exec.Command("az", "role", "definition", "list",
+		"--custom-role-only", "true",
+		"--scope", scope,
+		"--query", fmt.Sprintf("[?starts_with(roleName, '%s')].name", scheduleRolePrefix),
+		"-o", "tsv",
+	).Output()
+	if err != nil {
+		return fmt.Errorf("listing custom roles: %w", err)
+	}
+	for _, role := range strings.Fields(string(out)) {
+		out, err := exec.Command("az", "role", "assignment", "list",
+			"--role", role,
+			"--scope", scope,
+			"--query", "length(@)",
+			"-o", "tsv",
+		).Output()
+		if err != nil {
+			return fmt.Errorf("listing assignments of %s: %w", role, err)
+		}
+		if strings.TrimSpace(string(out)) != "0" {
+			continue // another schedule in the group still uses it
+		}
+		if err := runAz("role", "definition", "delete", "--name", role, "--scope", scope, "--custom-role-only", "true"); err != nil && !isNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+package azure
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestParseCron(t *testing.T) {
+	tests := []struct {
+		expr      string
+		frequency string
+		hours     []int
+		minutes   []int
+		weekDays  []string
+		desc      string
+	}{
+		{"0 7 * * 1-5", "Week", []int{7}, []int{0}, []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday"}, "07:00 Mon–Fri"},
+		{"30 20 * * mon,wed,FRI", "Week", []int{20}, []int{30}, []string{"Monday", "Wednesday", "Friday"}, "20:30 Mon, Wed, Fri"},
+		{"0 6,18 * * *", "Day", []int{6, 18}, []int{0}, nil, "06:00, 18:00 daily"},
+		{"0 */8 * * 0-6", "Day", []int{0, 8, 16}, []int{0}, nil, "00:00, 08:00, 16:00 daily"},
+		{"15 9 * * 6,7", "Week", []int{9}, []int{15}, []string{"Sunday", "Saturday"}, "09:15 Sun, Sat"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			r, err := ParseCron(tt.expr, "UTC")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Frequency != tt.frequency || !reflect.DeepEqual(r.Schedule.Hours, tt.hours) ||
+				!reflect.DeepEqual(r.Schedule.Minutes, tt.minutes) || !reflect.DeepEqual(r.Schedule.WeekDays, tt.weekDays) {
+				t.Errorf("ParseCron = %+v", r)
+			}
+			if r.TimeZone != "UTC" || r.StartTime == "" || r.Interval != 1 {
+				t.Errorf("recurrence anchor = %+v", r)
+			}
+			if got := r.String(); got != tt.desc {
+				t.Errorf("String() = %q, want %q", got, tt.desc)
+			}
+		})
+	}
+}
+
+func TestParseCronRejects(t *testing.T) {
+	for expr, want := range map[string]string{
+		"0 7 * *":        "want 5 fields",
+		"* 7 * * *":      "minute must be fixed",
+		"*/15 7 * * *":   "minute must be fixed",
+		"0 * * * *":      "hour must be fixed",
+		"0 24 * * *":     "hour",
+		"0 7 1 * *":      "day-of-month and month",
+		"0 7 * 1-6 *":    "day-of-month and month",
+		"0 7 * * 5-1":    "runs backwards",
+		"0 7 * * funday": "day-of-week",
+	} {
+		if _, err := ParseCron(expr, "UTC"); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ParseCron(%q) error = %v, want %q", expr, err, want)
+		}
+	}
+}
+
+func TestScheduleDescribe(t *testing.T) {
+	s := Schedule{Up: "0 7 * * 1-5", Down: "0 20 * * 1-5", TimeZone: "W. Europe Standard Time"}
+	want := "up 07:00 Mon–Fri, down 20:00 Mon–Fri (W. Europe Standard Time)"
+	if got := s.Describe(); got != want {
+		t.Errorf("Describe() = %q, want %q", got, want)
+	}
+	if got := (Schedule{Down: "0 20 * * *", TimeZone: "UTC"}).Describe(); got != "down 20:00 daily (UTC)" {
+		t.Errorf("down-only Describe() = %q", got)
+	}
+}
+
+var scheduleTarget = ScheduleTarget{
+	SubscriptionID: "00000000-0000-0000-0000-000000000001",
+	ResourceGroup:  "devlake-rg",
+	MySQL:          "devlakemysqlabc12",
+	Containers:     []string{"devlake-backend-abc12", "devlake-ui-abc12"},
+}
+
+func runAfter(t *testing.T, actions map[string]any, name string) map[string][]string {
+	t.Helper()
+	a, ok := actions[name].(map[string]any)
+	if !ok {
+		t.Fatalf("no action %q in %v", name, keys(actions))
+	}
+	return a["runAfter"].(map[string][]string)
+}
+
+func uri(actions map[string]any, name string) string {
+	return actions[name].(map[string]any)["inputs"].(map[string]any)["uri"].(string)
+}
+
+func keys(m map[string]any) []string {
+	var k []string
+	for name := range m {
+		k = append(k, name)
+	}
+	return k
+}
+
+func TestScheduleActionsACI(t *testing.T) {
+	up := scheduleTarget.Actions(true)
+	if len(up) != 3 {
+		t.Fatalf("up actions = %v", keys(up))
+	}
+	if len(runAfter(t, up, "start_mysql")) != 0 {
+		t.Error("start_mysql should run first")
+	}
+	if _, ok := runAfter(t, up, "start_devlake_backend_abc12")["start_mysql"]; !ok {
+		t.Error("containers should start after MySQL")
+	}
+	want := "https://management.azure.com/subscriptions/00000000-0000-0000-0000-000000000001/resourceGroups/devlake-rg/providers/Microsoft.ContainerInstance/containerGroups/devlake-ui-abc12/start?api-version=2023-05-01"
+	if got := uri(up, "start_devlake_ui_abc12"); got != want {
+		t.Errorf("uri = %s", got)
+	}
+
+	down := scheduleTarget.Actions(false)
+	after := runAfter(t, down, "stop_mysql")
+	if len(after) != 2 || after["stop_devlake_backend_abc12"] == nil {
+		t.Errorf("stop_mysql should run after every container stop, got %v", after)
+	}
+	if !strings.HasSuffix(uri(down, "stop_mysql"), "/flexibleServers/devlakemysqlabc12/stop?api-version=2023-06-30") {
+		t.Errorf("stop_mysql uri = %s", uri(down, "stop_mysql"))
+	}
+}
+
+func TestScheduleActionsACA(t *testing.T) {
+	target := scheduleTarget
+	target.Runtime = RuntimeACA
+	down := target.Actions(false)
+	if len(down) != 5 {
+		t.Fatalf("down actions = %v", keys(down))
+	}
+	if _, ok := runAfter(t, down, "stop_devlake_backend_abc12")["get_devlake_backend_abc12"]; !ok {
+		t.Error("deactivate should run after reading the app")
+	}
+	got := uri(down, "stop_devlake_backend_abc12")
+	if !strings.Contains(got, "/containerApps/devlake-backend-abc12/revisions/@{body('get_devlake_backend_abc12')?['properties']?['latestRevisionName']}/deactivate?") {
+		t.Errorf("deactivate uri = %s", got)
+	}
+}
+
+func TestScheduleActionsPrivate(t *testing.T) {
+	target := scheduleTarget
+	target.Containers = []string{"devlake-backend-abc12", "devlake-grafana-abc12", "devlake-ui-abc12"}
+	target.ServiceZone = "devlakeabc12.internal"
+	target.ServiceRecords = map[string]string{
+		"devlake-backend-abc12": "backend",
+		"devlake-grafana-abc12": "grafana",
+		"devlake-ui-abc12":      "ui",
+	}
+
+	up := target.Actions(true)
+	if len(up) != 10 {
+		t.Fatalf("up actions = %v", keys(up))
+	}
+	if _, ok := runAfter(t, up, "get_devlake_backend_abc12")["start_devlake_backend_abc12"]; !ok {
+		t.Error("the IP should be read after the group starts")
+	}
+	if _, ok := runAfter(t, up, "dns_devlake_backend_abc12")["get_devlake_backend_abc12"]; !ok {
+		t.Error("the record should be set after the IP is read")
+	}
+	ui := runAfter(t, up, "start_devlake_ui_abc12")
+	if len(ui) != 2 || ui["dns_devlake_backend_abc12"] == nil || ui["dns_devlake_grafana_abc12"] == nil {
+		t.Errorf("Config UI should start after the backend and Grafana records, got %v", ui)
+	}
+	if !strings.HasSuffix(uri(up, "dns_devlake_ui_abc12"), "/privateDnsZones/devlakeabc12.internal/A/ui?api-version=2020-06-01") {
+		t.Errorf("dns uri = %s", uri(up, "dns_devlake_ui_abc12"))
+	}
+	body, _ := json.Marshal(up["dns_devlake_grafana_abc12"].(map[string]any)["inputs"].(map[string]any)["body"])
+	if !strings.Contains(string(body), `"ipv4Address":"@{body('get_devlake_grafana_abc12')?['properties']?['ipAddress']?['ip']}"`) {
+		t.Errorf("dns body = %s", body)
+	}
+
+	// Stopping leaves the records alone
+	if down := target.Actions(false); len(down) != 4 {
+		t.Errorf("down actions = %v", keys(down))
+	}
+}
+
+func TestScheduleParams(t *testing.T) {
+	s := &Schedule{Up: "0 7 * * 1-5", Down: "0 20 * * 1-5", TimeZone: "UTC"}
+	params, err := ScheduleParams("devlake", "abc12", s, scheduleTarget, map[string]string{"team": "platform"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(s.Workflows, []string{"devlake-up-abc12", "devlake-down-abc12"}) {
+		t.Errorf("workflows = %v", s.Workflows)
+	}
+	var workflows []struct {
+		Name       string         `json:"name"`
+		Recurrence Recurrence     `json:"recurrence"`
+		Actions    map[string]any `json:"actions"`
+	}
+	if err := json.Unmarshal([]byte(params["workflows"]), &workflows); err != nil {
+		t.Fatal(err)
+	}
+	if len(workflows) != 2 || workflows[1].Recurrence.Schedule.Hours[0] != 20 || workflows[1].Actions["stop_mysql"] == nil {
+		t.Errorf("workflows = %s", params["workflows"])
+	}
+	if params["tags"] != `{"team":"platform"}` {
+		t.Errorf("tags = %s", params["tags"])
+	}
+
+	// Only a down schedule
+	s = &Schedule{Down: "0 20 * * *", TimeZone: "UTC"}
+	if _, err := ScheduleParams("devlake", "abc12", s, scheduleTarget, nil); err != nil || len(s.Workflows) != 1 {
+		t.Errorf("down-only: workflows %v, err %v", s.Workflows, err)
+	}
+
+	if _, err := ScheduleParams("devlake", "abc12", &Schedule{TimeZone: "UTC"}, scheduleTarget, nil); err == nil {
+		t.Error("expected an error without --up or --down")
+	}
+	if _, err := ScheduleParams("devlake", "abc12", &Schedule{Up: "0 7 1 * *", TimeZone: "UTC"}, scheduleTarget, nil); err == nil || !strings.Contains(err.Error(), "--up") {
+		t.Errorf("bad cron error = %v", err)
+	}
+}
+
+// The parameters rendered in Go must all be declared by the template.
+func TestScheduleTemplateDeclaresParams(t *testing.T) {
+	data, err := templateFS.ReadFile("templates/" + ScheduleTemplate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	declared := map[string]bool{}
+	for _, m := range regexp.MustCompile(`(?m)^param\s+(\w+)`).FindAllStringSubmatch(string(data), -1) {
+		declared[m[1]] = true
+	}
+	params, err := ScheduleParams("devlake", "abc12", &Schedule{Up: "0 7 * * *", TimeZone: "UTC"}, scheduleTarget, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name := range params {
+		if !declared[name] {
+			t.Errorf("parameter %q is not declared in %s", name, ScheduleTemplate)
+		}
+	}
+}
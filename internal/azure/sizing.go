@@ -0,0 +1,183 @@
+package azure
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Sizing holds the tunable SKUs and sizes of a deployment. It is recorded in
+// .devlake-azure.json and is also the format of a --parameters-file.
+type Sizing struct {
+	MySQLSKU        string            `json:"mysqlSku,omitempty"`
+	MySQLStorageGB  int               `json:"mysqlStorageGB,omitempty"`
+	BackendCPU      float64           `json:"backendCpu,omitempty"`
+	BackendMemoryGB float64           `json:"backendMemoryGB,omitempty"`
+	Tags            map[string]string `json:"tags,omitempty"`
+}
+
+var mysqlSKURe = regexp.MustCompile(`^Standard_([BDE])\w+$`)
+
+// mysqlTiers maps the SKU family letter to the Flexible Server tier.
+var mysqlTiers = map[string]string{"B": "Burstable", "D": "GeneralPurpose", "E": "MemoryOptimized"}
+
+// DefaultSizing returns the sizes the templates used before they were tunable.
+func DefaultSizing() Sizing {
+	return Sizing{
+		MySQLSKU:        DefaultMySQLSKU,
+		MySQLStorageGB:  DefaultMySQLStorageGB,
+		BackendCPU:      2,
+		BackendMemoryGB: 4,
+	}
+}
+
+// LoadSizingFile reads a --parameters-file. Unknown keys are rejected so
+// typos do not silently fall back to defaults.
+func LoadSizingFile(path string) (Sizing, error) {
+	var s Sizing
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return s, fmt.Errorf("reading parameters file: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&s); err != nil {
+		return s, fmt.Errorf("invalid parameters file %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Merge returns s with every field set in over replacing it. Tags are
+// merged key by key.
+func (s Sizing) Merge(over Sizing) Sizing {
+	if over.MySQLSKU != "" {
+		s.MySQLSKU = over.MySQLSKU
+	}
+	if over.MySQLStorageGB != 0 {
+		s.MySQLStorageGB = over.MySQLStorageGB
+	}
+	if over.BackendCPU != 0 {
+		s.BackendCPU = over.BackendCPU
+	}
+	if over.BackendMemoryGB != 0 {
+		s.BackendMemoryGB = over.BackendMemoryGB
+	}
+	if len(over.Tags) > 0 {
+		tags := map[string]string{}
+		for k, v := range s.Tags {
+			tags[k] = v
+		}
+		for k, v := range over.Tags {
+			tags[k] = v
+		}
+		s.Tags = tags
+	}
+	return s
+}
+
+// MySQLTier returns the Flexible Server tier of the SKU.
+func (s Sizing) MySQLTier() string {
+	if m := mysqlSKURe.FindStringSubmatch(s.MySQLSKU); m != nil {
+		return mysqlTiers[m[1]]
+	}
+	return ""
+}
+
+// Validate checks the sizes against the limits of the runtime.
+func (s Sizing) Validate(runtime string) error {
+	if s.MySQLTier() == "" {
+		return fmt.Errorf("--mysql-sku %q is not a MySQL Flexible Server SKU (e.g. Standard_B1ms, Standard_D2ds_v4, Standard_E2ds_v4)", s.MySQLSKU)
+	}
+	if s.MySQLStorageGB < 20 || s.MySQLStorageGB > 16384 {
+		return fmt.Errorf("--mysql-storage-gb must be between 20 and 16384, got %d", s.MySQLStorageGB)
+	}
+	if runtime == RuntimeACA {
+		// Consumption plan: 0.25–4 vCPU in 0.25 steps, with 2 GiB per vCPU
+		if s.BackendCPU < 0.25 || s.BackendCPU > 4 || math.Mod(s.BackendCPU, 0.25) != 0 {
+			return fmt.Errorf("--backend-cpu must be 0.25–4 in steps of 0.25 for Container Apps, got %g", s.BackendCPU)
+		}
+		if s.BackendMemoryGB != 2*s.BackendCPU {
+			return fmt.Errorf("Container Apps need 2 GB per vCPU — use --backend-memory %g with --backend-cpu %g", 2*s.BackendCPU, s.BackendCPU)
+		}
+	} else {
+		if s.BackendCPU < 1 || s.BackendCPU > 4 {
+			return fmt.Errorf("--backend-cpu must be between 1 and 4 for Container Instances, got %g", s.BackendCPU)
+		}
+		if s.BackendMemoryGB < 1 || s.BackendMemoryGB > 16 {
+			return fmt.Errorf("--backend-memory must be between 1 and 16 GB for Container Instances, got %g", s.BackendMemoryGB)
+		}
+	}
+	for k, v := range s.Tags {
+		if k == "" || len(k) > 512 || strings.ContainsAny(k, `<>%&\?/`) {
+			return fmt.Errorf("invalid tag name %q", k)
+		}
+		if strings.HasPrefix(strings.ToLower(k), reservedTagPrefix) {
+			return fmt.Errorf("tag names starting with %q are reserved for gh-devlake", reservedTagPrefix)
+		}
+		if len(v) > 256 {
+			return fmt.Errorf("tag %q: value longer than 256 characters", k)
+		}
+	}
+	// Azure allows 50 tags; InstanceTags adds one
+	if len(s.Tags) > 49 {
+		return fmt.Errorf("at most 49 tags are allowed, got %d", len(s.Tags))
+	}
+	return nil
+}
+
+// Params returns the Bicep parameters for the sizing.
+func (s Sizing) Params() map[string]string {
+	tags := s.Tags
+	if tags == nil {
+		tags = map[string]string{}
+	}
+	tagsJSON, _ := json.Marshal(tags)
+	return map[string]string{
+		"mysqlSku":       s.MySQLSKU,
+		"mysqlTier":      s.MySQLTier(),
+		"mysqlStorageGB": strconv.Itoa(s.MySQLStorageGB),
+		"backendCpu":     strconv.FormatFloat(s.BackendCPU, 'f', -1, 64),
+		"backendMemory":  strconv.FormatFloat(s.BackendMemoryGB, 'f', -1, 64),
+		"tags":           string(tagsJSON),
+	}
+}
+
+// Containers returns the container sizes for a cost estimate.
+func (s Sizing) Containers(runtime string) []ContainerSpec {
+	c := DefaultContainers(runtime)
+	c[0].CPU, c[0].MemoryGB = s.BackendCPU, s.BackendMemoryGB
+	return c
+}
+
+// ParseTags parses key=value pairs.
+func ParseTags(pairs []string) (map[string]string, error) {
+	tags := map[string]string{}
+	for _, p := range pairs {
+		k, v, ok := strings.Cut(p, "=")
+		if !ok || strings.TrimSpace(k) == "" {
+			return nil, fmt.Errorf("--tags %q must be key=value", p)
+		}
+		tags[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return tags, nil
+}
+
+// FormatTags renders tags as sorted key=value pairs.
+func FormatTags(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		parts = append(parts, k+"="+tags[k])
+	}
+	return strings.Join(parts, ", ")
+}
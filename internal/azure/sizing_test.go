@@ -0,0 +1,117 @@
+package azure
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSizingMerge(t *testing.T) {
+	base := DefaultSizing()
+	base.Tags = map[string]string{"team": "platform", "env": "dev"}
+
+	got := base.Merge(Sizing{MySQLStorageGB: 128, Tags: map[string]string{"env": "prod"}})
+	if got.MySQLSKU != DefaultMySQLSKU || got.MySQLStorageGB != 128 || got.BackendCPU != 2 {
+		t.Errorf("Merge = %+v", got)
+	}
+	if got.Tags["team"] != "platform" || got.Tags["env"] != "prod" {
+		t.Errorf("Merge tags = %v", got.Tags)
+	}
+	if base.Tags["env"] != "dev" {
+		t.Error("Merge modified the receiver's tags")
+	}
+}
+
+func TestSizingValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		runtime string
+		over    Sizing
+		wantErr string
+	}{
+		{"defaults aci", RuntimeACI, Sizing{}, ""},
+		{"general purpose", RuntimeACI, Sizing{MySQLSKU: "Standard_D2ds_v4"}, ""},
+		{"unknown sku", RuntimeACI, Sizing{MySQLSKU: "B1ms"}, "not a MySQL Flexible Server SKU"},
+		{"storage too small", RuntimeACI, Sizing{MySQLStorageGB: 10}, "--mysql-storage-gb"},
+		{"aci cpu", RuntimeACI, Sizing{BackendCPU: 8}, "between 1 and 4"},
+		{"aci memory", RuntimeACI, Sizing{BackendMemoryGB: 32}, "between 1 and 16"},
+		{"aca defaults", RuntimeACA, Sizing{}, ""},
+		{"aca quarter step", RuntimeACA, Sizing{BackendCPU: 1.5, BackendMemoryGB: 3}, ""},
+		{"aca odd step", RuntimeACA, Sizing{BackendCPU: 1.3, BackendMemoryGB: 2.6}, "steps of 0.25"},
+		{"aca memory ratio", RuntimeACA, Sizing{BackendCPU: 2, BackendMemoryGB: 8}, "2 GB per vCPU"},
+		{"bad tag", RuntimeACI, Sizing{Tags: map[string]string{"a/b": "x"}}, "invalid tag name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := DefaultSizing().Merge(tt.over).Validate(tt.runtime)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSizingParams(t *testing.T) {
+	s := DefaultSizing().Merge(Sizing{
+		MySQLSKU:        "Standard_E2ds_v4",
+		BackendCPU:      0.5,
+		BackendMemoryGB: 1,
+		Tags:            map[string]string{"team": "platform"},
+	})
+	p := s.Params()
+	want := map[string]string{
+		"mysqlSku":       "Standard_E2ds_v4",
+		"mysqlTier":      "MemoryOptimized",
+		"mysqlStorageGB": "32",
+		"backendCpu":     "0.5",
+		"backendMemory":  "1",
+		"tags":           `{"team":"platform"}`,
+	}
+	for k, v := range want {
+		if p[k] != v {
+			t.Errorf("%s = %q, want %q", k, p[k], v)
+		}
+	}
+	if got := DefaultSizing().Params()["tags"]; got != "{}" {
+		t.Errorf("empty tags = %q, want {}", got)
+	}
+}
+
+func TestLoadSizingFile(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.json")
+	os.WriteFile(good, []byte(`{"mysqlSku":"Standard_B2ms","tags":{"env":"prod"}}`), 0644)
+	s, err := LoadSizingFile(good)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.MySQLSKU != "Standard_B2ms" || s.Tags["env"] != "prod" || s.BackendCPU != 0 {
+		t.Errorf("LoadSizingFile = %+v", s)
+	}
+
+	typo := filepath.Join(dir, "typo.json")
+	os.WriteFile(typo, []byte(`{"mysqlSize":"Standard_B2ms"}`), 0644)
+	if _, err := LoadSizingFile(typo); err == nil || !strings.Contains(err.Error(), "unknown field") {
+		t.Errorf("expected unknown field error, got %v", err)
+	}
+}
+
+func TestParseTags(t *testing.T) {
+	tags, err := ParseTags([]string{"team=platform", " cost-center = 42 ", "empty="})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if FormatTags(tags) != "cost-center=42, empty=, team=platform" {
+		t.Errorf("tags = %s", FormatTags(tags))
+	}
+	if _, err := ParseTags([]string{"novalue"}); err == nil {
+		t.Error("expected error for a pair without '='")
+	}
+}
@@ -0,0 +1,90 @@
+package azure
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ConfigDir is a variable so tests can redirect the state file index.
+var ConfigDir = os.UserConfigDir
+
+// stateIndexPath is the per-user file mapping deployment suffixes to the
+// state files that record them. Only the suffix goes into Azure tags; the
+// local path stays on this machine.
+func stateIndexPath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("locating config directory: %w", err)
+	}
+	return filepath.Join(dir, "gh-devlake", "azure-deployments.json"), nil
+}
+
+// StateFiles returns the state files deploy azure has written on this
+// machine, keyed by deployment suffix.
+func StateFiles() (map[string]string, error) {
+	path, err := stateIndexPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	files := map[string]string{}
+	if err := json.Unmarshal(data, &files); err != nil {
+		return nil, fmt.Errorf("invalid state file index %s: %w", path, err)
+	}
+	return files, nil
+}
+
+// RememberStateFile records the state file of the deployment with the given
+// suffix, as an absolute path.
+func RememberStateFile(suffix, stateFile string) error {
+	abs, err := filepath.Abs(stateFile)
+	if err != nil {
+		return err
+	}
+	files, err := StateFiles()
+	if err != nil {
+		return err
+	}
+	if files[suffix] == abs {
+		return nil
+	}
+	files[suffix] = abs
+	return saveStateFiles(files)
+}
+
+// ForgetStateFile drops the deployment with the given suffix. Forgetting an
+// unknown suffix is not an error.
+func ForgetStateFile(suffix string) error {
+	files, err := StateFiles()
+	if err != nil {
+		return err
+	}
+	if _, ok := files[suffix]; !ok {
+		return nil
+	}
+	delete(files, suffix)
+	return saveStateFiles(files)
+}
+
+func saveStateFiles(files map[string]string) error {
+	path, err := stateIndexPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(files, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
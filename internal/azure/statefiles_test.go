@@ -0,0 +1,52 @@
+package azure
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func useTempConfigDir(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	orig := ConfigDir
+	ConfigDir = func() (string, error) { return dir, nil }
+	t.Cleanup(func() { ConfigDir = orig })
+}
+
+func TestStateFiles(t *testing.T) {
+	useTempConfigDir(t)
+
+	files, err := StateFiles()
+	if err != nil || len(files) != 0 {
+		t.Fatalf("empty index = %v, %v", files, err)
+	}
+
+	dir := t.TempDir()
+	if err := RememberStateFile("abc12", filepath.Join(dir, ".devlake-azure.json")); err != nil {
+		t.Fatal(err)
+	}
+	if err := RememberStateFile("f00d1", "relative.json"); err != nil {
+		t.Fatal(err)
+	}
+	files, err = StateFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if files["abc12"] != filepath.Join(dir, ".devlake-azure.json") {
+		t.Errorf("abc12 = %q", files["abc12"])
+	}
+	if !filepath.IsAbs(files["f00d1"]) {
+		t.Errorf("f00d1 = %q, want an absolute path", files["f00d1"])
+	}
+
+	if err := ForgetStateFile("abc12"); err != nil {
+		t.Fatal(err)
+	}
+	// Forgetting again is not an error.
+	if err := ForgetStateFile("abc12"); err != nil {
+		t.Errorf("second forget: %v", err)
+	}
+	if files, _ := StateFiles(); len(files) != 1 || files["abc12"] != "" {
+		t.Errorf("after forget = %v", files)
+	}
+}
@@ -0,0 +1,309 @@
+package azure
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+)
+
+// InstanceTag is the tag deploy azure puts on every resource it creates, so
+// cleanup can find them without a complete state file. Its value is the
+// deployment suffix.
+const InstanceTag = "gh-devlake-instance"
+
+// legacyStateTag held the absolute path of the state file on resources
+// deployed by earlier versions. It is still read so those deployments are
+// not reported as orphans.
+const legacyStateTag = "gh-devlake-state"
+
+// reservedTagPrefix marks tag names that --tags may not set.
+const reservedTagPrefix = "gh-devlake-"
+
+const resourceGroupType = "Microsoft.Resources/resourceGroups"
+
+// InstanceTags returns a copy of tags with the tag that marks a
+// deployment's resources added.
+func InstanceTags(tags map[string]string, suffix string) map[string]string {
+	out := map[string]string{}
+	for k, v := range tags {
+		out[k] = v
+	}
+	out[InstanceTag] = suffix
+	return out
+}
+
+// tagArgs renders tags for the --tags option of az create commands.
+func tagArgs(tags map[string]string) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	args := []string{"--tags"}
+	for _, k := range keys {
+		args = append(args, k+"="+tags[k])
+	}
+	return args
+}
+
+// Resource is an Azure resource (or resource group) found by its tags.
+type Resource struct {
+	ID            string            `json:"id"`
+	Name          string            `json:"name"`
+	Type          string            `json:"type"`
+	ResourceGroup string            `json:"resourceGroup"`
+	Location      string            `json:"location"`
+	Tags          map[string]string `json:"tags"`
+}
+
+// IsResourceGroup reports whether r is a resource group rather than a
+// resource in one.
+func (r Resource) IsResourceGroup() bool {
+	return strings.EqualFold(r.Type, resourceGroupType)
+}
+
+// ResourceID builds the ID of a resource. typ is the full type, e.g.
+// Microsoft.Network/privateDnsZones/virtualNetworkLinks, and name has one
+// segment per level, e.g. zone.example.com/link.
+func ResourceID(subscription, resourceGroup, typ, name string) string {
+	provider, types, _ := strings.Cut(typ, "/")
+	typeParts := strings.Split(types, "/")
+	nameParts := strings.Split(name, "/")
+	id := fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/%s", subscription, resourceGroup, provider)
+	for i, t := range typeParts {
+		if i < len(nameParts) {
+			id += "/" + t + "/" + nameParts[i]
+		}
+	}
+	return id
+}
+
+// ParseResources parses the JSON of 'az resource list' or 'az group list'.
+func ParseResources(data []byte) ([]Resource, error) {
+	var rs []Resource
+	if err := json.Unmarshal(data, &rs); err != nil {
+		return nil, fmt.Errorf("failed to parse resource list: %w", err)
+	}
+	for i := range rs {
+		if rs[i].Type == "" || rs[i].IsResourceGroup() {
+			rs[i].Type = resourceGroupType
+			rs[i].ResourceGroup = rs[i].Name
+		}
+	}
+	return rs, nil
+}
+
+// TaggedResources lists the resource groups and resources in the current
+// subscription tagged as part of the deployment with the given suffix, or
+// of any deployment when suffix is empty.
+func TaggedResources(suffix string) ([]Resource, error) {
+	tag := InstanceTag
+	if suffix != "" {
+		tag += "=" + suffix
+	}
+	var all []Resource
+	for _, list := range [][]string{
+		{"group", "list", "--tag", tag, "-o", "json"},
+		{"resource", "list", "--tag", tag, "-o", "json"},
+	} {
+		out, err := exec.Command("az", list...).Output()
+		if err != nil {
+			if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+				return nil, fmt.Errorf("az %s list failed: %s", list[0], strings.TrimSpace(string(exitErr.Stderr)))
+			}
+			return nil, fmt.Errorf("az %s list failed: %w", list[0], err)
+		}
+		rs, err := ParseResources(out)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, rs...)
+	}
+	return all, nil
+}
+
+// MergeResources returns the resources of both lists, dropping duplicates
+// by ID. Azure IDs are case-insensitive.
+func MergeResources(a, b []Resource) []Resource {
+	seen := map[string]bool{}
+	var merged []Resource
+	for _, r := range append(append([]Resource{}, a...), b...) {
+		id := strings.ToLower(r.ID)
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// deleteOrder lists resource types so that dependents come before what they
+// depend on. Types not listed go last.
+var deleteOrder = []string{
+	"microsoft.logic/workflows", // the schedule, so it cannot restart anything
+	"microsoft.containerinstance/containergroups",
+	"microsoft.app/containerapps",
+	"microsoft.app/managedenvironments",
+	"microsoft.network/applicationgateways",
+	"microsoft.network/publicipaddresses",
+	"microsoft.dbformysql/flexibleservers",
+	"microsoft.network/privatednszones/virtualnetworklinks",
+	"microsoft.network/privatednszones",
+	"microsoft.network/virtualnetworks",
+	"microsoft.network/networksecuritygroups",
+	"microsoft.containerregistry/registries",
+	"microsoft.keyvault/vaults",
+}
+
+func deleteRank(typ string) int {
+	for i, t := range deleteOrder {
+		if strings.EqualFold(typ, t) {
+			return i
+		}
+	}
+	return len(deleteOrder)
+}
+
+// SortForDeletion orders resources for deletion, by type and then name.
+func SortForDeletion(rs []Resource) {
+	sort.SliceStable(rs, func(i, j int) bool {
+		ri, rj := deleteRank(rs[i].Type), deleteRank(rs[j].Type)
+		if ri != rj {
+			return ri < rj
+		}
+		return rs[i].Name < rs[j].Name
+	})
+}
+
+// DeleteFailure is a resource that could not be deleted.
+type DeleteFailure struct {
+	Resource Resource
+	Err      error
+}
+
+// Reason returns the first line of the Azure error, without the az
+// boilerplate around it.
+func (f DeleteFailure) Reason() string {
+	return ErrorReason(f.Err)
+}
+
+// ErrorReason returns the first meaningful line of an az error.
+func ErrorReason(err error) string {
+	msg := err.Error()
+	if _, rest, ok := strings.Cut(msg, "ERROR: "); ok {
+		msg = rest
+	} else if _, rest, ok := strings.Cut(msg, " failed: "); ok && strings.HasPrefix(msg, "az ") {
+		msg = rest
+	}
+	msg = strings.TrimSpace(msg)
+	if line, _, ok := strings.Cut(msg, "\n"); ok {
+		msg = strings.TrimSpace(line)
+	}
+	if len(msg) > 200 {
+		msg = msg[:197] + "..."
+	}
+	return msg
+}
+
+// isNotFound reports whether an az error says the resource is already gone.
+func isNotFound(err error) bool {
+	msg := err.Error()
+	for _, s := range []string{"NotFound", "could not be found", "was not found"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// deleteResource deletes one resource. A Logic App goes through
+// DeleteSchedule so its role assignment goes too, and a Key Vault is purged
+// so a redeploy can reuse its name. Replaced in tests.
+var deleteResource = func(r Resource) error {
+	if strings.EqualFold(r.Type, "Microsoft.Logic/workflows") {
+		return DeleteSchedule([]string{r.Name}, r.ResourceGroup)
+	}
+	if err := runAz("resource", "delete", "--ids", r.ID); err != nil && !isNotFound(err) {
+		return err
+	}
+	if strings.EqualFold(r.Type, "Microsoft.KeyVault/vaults") && r.Location != "" {
+		if err := PurgeKeyVault(r.Name, r.Location); err != nil && !isNotFound(err) {
+			return fmt.Errorf("deleted, but purge failed: %s", ErrorReason(err))
+		}
+	}
+	return nil
+}
+
+// DeleteResources deletes resources in dependency order and retries the ones
+// that fail, since a resource can be blocked by one still being deleted (a
+// VNet by its gateway, say). It makes up to passes attempts, waiting between
+// them, and returns what is left with the last error for each. progress, if
+// set, is called after every attempt.
+func DeleteResources(rs []Resource, passes int, wait time.Duration, progress func(r Resource, err error)) []DeleteFailure {
+	pending := append([]Resource{}, rs...)
+	SortForDeletion(pending)
+	var failures []DeleteFailure
+	for pass := 1; pass <= passes && len(pending) > 0; pass++ {
+		if pass > 1 {
+			time.Sleep(wait)
+		}
+		failures = nil
+		for _, r := range pending {
+			err := deleteResource(r)
+			if progress != nil {
+				progress(r, err)
+			}
+			if err != nil {
+				failures = append(failures, DeleteFailure{Resource: r, Err: err})
+			}
+		}
+		pending = pending[:0]
+		for _, f := range failures {
+			pending = append(pending, f.Resource)
+		}
+	}
+	return failures
+}
+
+// Instance is one deployment found by its tags.
+type Instance struct {
+	Suffix        string     `json:"suffix"`
+	ResourceGroup string     `json:"resourceGroup"`
+	StateFile     string     `json:"stateFile,omitempty"` // set by the caller from StateFiles, or from a legacy tag
+	Resources     []Resource `json:"resources"`
+}
+
+// GroupInstances groups tagged resources by suffix and resource group,
+// sorted by resource group.
+func GroupInstances(rs []Resource) []Instance {
+	index := map[string]int{}
+	var instances []Instance
+	for _, r := range rs {
+		suffix := r.Tags[InstanceTag]
+		key := suffix + "/" + strings.ToLower(r.ResourceGroup)
+		i, ok := index[key]
+		if !ok {
+			i = len(instances)
+			index[key] = i
+			instances = append(instances, Instance{Suffix: suffix, ResourceGroup: r.ResourceGroup})
+		}
+		if instances[i].StateFile == "" {
+			instances[i].StateFile = r.Tags[legacyStateTag]
+		}
+		instances[i].Resources = append(instances[i].Resources, r)
+	}
+	sort.Slice(instances, func(i, j int) bool {
+		if instances[i].ResourceGroup != instances[j].ResourceGroup {
+			return instances[i].ResourceGroup < instances[j].ResourceGroup
+		}
+		return instances[i].Suffix < instances[j].Suffix
+	})
+	return instances
+}
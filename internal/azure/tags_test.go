@@ -0,0 +1,132 @@
+package azure
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInstanceTags(t *testing.T) {
+	user := map[string]string{"team": "platform"}
+	got := InstanceTags(user, "abc12")
+	want := map[string]string{"team": "platform", InstanceTag: "abc12"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("InstanceTags = %v", got)
+	}
+	if len(user) != 1 {
+		t.Error("InstanceTags modified the user's tags")
+	}
+	if got := tagArgs(want); !reflect.DeepEqual(got, []string{"--tags", "gh-devlake-instance=abc12", "team=platform"}) {
+		t.Errorf("tagArgs = %v", got)
+	}
+}
+
+func TestValidateReservedTags(t *testing.T) {
+	s := DefaultSizing()
+	s.Tags = map[string]string{"GH-DevLake-Instance": "x"}
+	if err := s.Validate(RuntimeACI); err == nil || !strings.Contains(err.Error(), "reserved") {
+		t.Errorf("Validate error = %v", err)
+	}
+}
+
+func TestResourceID(t *testing.T) {
+	got := ResourceID("sub", "devlake-rg", "Microsoft.Network/privateDnsZones/virtualNetworkLinks", "devlakeabc12.private.mysql.database.azure.com/devlake-vnet-link")
+	want := "/subscriptions/sub/resourceGroups/devlake-rg/providers/Microsoft.Network/privateDnsZones/devlakeabc12.private.mysql.database.azure.com/virtualNetworkLinks/devlake-vnet-link"
+	if got != want {
+		t.Errorf("ResourceID = %s", got)
+	}
+}
+
+func TestParseResourcesAndGroupInstances(t *testing.T) {
+	resources, err := ParseResources(readFixture(t, "resources.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	groups, err := ParseResources(readFixture(t, "groups.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !groups[0].IsResourceGroup() || groups[0].ResourceGroup != "devlake-rg" {
+		t.Errorf("group = %+v", groups[0])
+	}
+
+	instances := GroupInstances(append(groups, resources...))
+	if len(instances) != 2 {
+		t.Fatalf("instances = %+v", instances)
+	}
+	// abc12 was deployed before the state path left the tags
+	abc := instances[0]
+	if abc.Suffix != "abc12" || abc.ResourceGroup != "devlake-rg" || len(abc.Resources) != 3 || abc.StateFile != "/home/dev/devlake/.devlake-azure.json" {
+		t.Errorf("abc12 = %+v", abc)
+	}
+	if f := instances[1]; f.Suffix != "f00d1" || f.ResourceGroup != "shared-rg" || f.StateFile != "" {
+		t.Errorf("f00d1 = %+v", f)
+	}
+
+	if _, err := ParseResources([]byte("not json")); err == nil {
+		t.Error("expected a parse error")
+	}
+}
+
+func TestMergeResources(t *testing.T) {
+	a := []Resource{{ID: "/subscriptions/s/resourceGroups/RG/providers/X/y/one", Name: "one"}}
+	b := []Resource{{ID: "/subscriptions/s/resourceGroups/rg/providers/x/y/one", Name: "one"}, {ID: "/two", Name: "two"}}
+	if got := MergeResources(a, b); len(got) != 2 || got[1].Name != "two" {
+		t.Errorf("MergeResources = %+v", got)
+	}
+}
+
+func TestErrorReason(t *testing.T) {
+	err := errors.New("az resource delete failed: ERROR: (InUseSubnetCannotBeDeleted) Subnet gateway is in use by agw.\nCode: InUseSubnetCannotBeDeleted\nMessage: ...")
+	if got := ErrorReason(err); got != "(InUseSubnetCannotBeDeleted) Subnet gateway is in use by agw." {
+		t.Errorf("ErrorReason = %q", got)
+	}
+	if got := ErrorReason(errors.New("az resource delete failed: exit status 1")); got != "exit status 1" {
+		t.Errorf("ErrorReason = %q", got)
+	}
+	if got := ErrorReason(errors.New("deleted, but purge failed: (Forbidden) purge protection is enabled")); got != "deleted, but purge failed: (Forbidden) purge protection is enabled" {
+		t.Errorf("ErrorReason = %q", got)
+	}
+}
+
+func TestDeleteResources(t *testing.T) {
+	orig := deleteResource
+	t.Cleanup(func() { deleteResource = orig })
+
+	// The VNet fails once while the gateway is still going; the vault always fails
+	var calls []string
+	vnetTries := 0
+	deleteResource = func(r Resource) error {
+		calls = append(calls, r.Name)
+		switch r.Name {
+		case "vnet":
+			if vnetTries++; vnetTries == 1 {
+				return errors.New("az resource delete failed: ERROR: subnet in use")
+			}
+		case "kv":
+			return errors.New("az resource delete failed: ERROR: (Forbidden) no permission")
+		}
+		return nil
+	}
+	rs := []Resource{
+		{Name: "kv", Type: "Microsoft.KeyVault/vaults"},
+		{Name: "vnet", Type: "Microsoft.Network/virtualNetworks"},
+		{Name: "mystery", Type: "Microsoft.Insights/components"},
+		{Name: "agw", Type: "Microsoft.Network/applicationGateways"},
+		{Name: "devlake-up-abc12", Type: "Microsoft.Logic/workflows"},
+	}
+	var progress int
+	failures := DeleteResources(rs, 3, 0, func(Resource, error) { progress++ })
+
+	want := []string{"devlake-up-abc12", "agw", "vnet", "kv", "mystery", "vnet", "kv", "kv"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+	if progress != len(calls) {
+		t.Errorf("progress called %d times, want %d", progress, len(calls))
+	}
+	if len(failures) != 1 || failures[0].Resource.Name != "kv" || failures[0].Reason() != "(Forbidden) no permission" {
+		t.Errorf("failures = %+v", failures)
+	}
+}
@@ -0,0 +1,209 @@
+package azure
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// Change is one resource in a what-if result.
+type Change struct {
+	ChangeType string   // Create, Modify, Delete, Deploy, NoChange, Ignore, Unsupported
+	Type       string   // e.g. Microsoft.DBforMySQL/flexibleServers/databases
+	Name       string   // e.g. devlakemysqlabc12/lake
+	Properties []string // changed property paths (Modify and Deploy only)
+}
+
+// changeOrder sorts the table so the changes that matter come first.
+var changeOrder = map[string]int{
+	"Delete": 0, "Create": 1, "Modify": 2, "Deploy": 3, "Unsupported": 4, "NoChange": 5, "Ignore": 6,
+}
+
+// WhatIf runs the Bicep what-if operation against an existing resource group
+// and returns the raw JSON result.
+func WhatIf(resourceGroup, templatePath string, params map[string]string) ([]byte, error) {
+	args := []string{"deployment", "group", "what-if",
+		"--resource-group", resourceGroup,
+		"--template-file", templatePath,
+		"--no-pretty-print",
+		"-o", "json",
+	}
+	var paramParts []string
+	for k, v := range params {
+		paramParts = append(paramParts, fmt.Sprintf("%s=%s", k, v))
+	}
+	if len(paramParts) > 0 {
+		args = append(args, "--parameters")
+		args = append(args, paramParts...)
+	}
+
+	out, err := exec.Command("az", args...).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			return nil, fmt.Errorf("what-if failed: %s", string(exitErr.Stderr))
+		}
+		return nil, fmt.Errorf("what-if failed: %w", err)
+	}
+	return out, nil
+}
+
+// ResourceGroupExists reports whether the resource group exists.
+func ResourceGroupExists(name string) (bool, error) {
+	out, err := exec.Command("az", "group", "exists", "--name", name).Output()
+	if err != nil {
+		return false, fmt.Errorf("az group exists failed: %w", err)
+	}
+	return strings.TrimSpace(string(out)) == "true", nil
+}
+
+// ParseWhatIf parses the JSON of 'az deployment group what-if
+// --no-pretty-print' into changes, ordered by change type, then type and name.
+func ParseWhatIf(data []byte) ([]Change, error) {
+	var result struct {
+		Status string `json:"status"`
+		Error  *struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+		Changes []struct {
+			ResourceID string `json:"resourceId"`
+			ChangeType string `json:"changeType"`
+			Delta      []struct {
+				Path               string `json:"path"`
+				PropertyChangeType string `json:"propertyChangeType"`
+			} `json:"delta"`
+		} `json:"changes"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse what-if result: %w", err)
+	}
+	if result.Error != nil {
+		return nil, fmt.Errorf("what-if failed: %s: %s", result.Error.Code, result.Error.Message)
+	}
+
+	changes := make([]Change, 0, len(result.Changes))
+	for _, c := range result.Changes {
+		typ, name := splitResourceID(c.ResourceID)
+		ch := Change{ChangeType: c.ChangeType, Type: typ, Name: name}
+		for _, d := range c.Delta {
+			// NoEffect deltas are read-only or defaulted properties
+			if d.PropertyChangeType != "NoEffect" {
+				ch.Properties = append(ch.Properties, d.Path)
+			}
+		}
+		changes = append(changes, ch)
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		if changeOrder[a.ChangeType] != changeOrder[b.ChangeType] {
+			return changeOrder[a.ChangeType] < changeOrder[b.ChangeType]
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.Name < b.Name
+	})
+	return changes, nil
+}
+
+// splitResourceID turns
+// /subscriptions/…/providers/Microsoft.DBforMySQL/flexibleServers/db1/databases/lake
+// into ("Microsoft.DBforMySQL/flexibleServers/databases", "db1/lake").
+func splitResourceID(id string) (string, string) {
+	i := strings.LastIndex(strings.ToLower(id), "/providers/")
+	if i < 0 {
+		return "", id
+	}
+	parts := strings.Split(strings.Trim(id[i+len("/providers/"):], "/"), "/")
+	if len(parts) < 3 {
+		return strings.Join(parts, "/"), ""
+	}
+	types := []string{parts[0]}
+	var names []string
+	for k := 1; k+1 < len(parts); k += 2 {
+		types = append(types, parts[k])
+		names = append(names, parts[k+1])
+	}
+	return strings.Join(types, "/"), strings.Join(names, "/")
+}
+
+// changeSymbols mirror the Azure CLI's pretty-printed what-if output.
+var changeSymbols = map[string]string{
+	"Create": "+", "Delete": "-", "Modify": "~", "Deploy": "!", "NoChange": "=", "Ignore": "*", "Unsupported": "x",
+}
+
+// RenderChanges writes the changes as a table. Unchanged and ignored
+// resources are counted but not listed.
+func RenderChanges(w io.Writer, changes []Change) {
+	counts := map[string]int{}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  \tChange\tResource Type\tName\tProperties")
+	fmt.Fprintln(tw, "  \t"+strings.Repeat("─", 8)+"\t"+strings.Repeat("─", 40)+"\t"+strings.Repeat("─", 30)+"\t"+strings.Repeat("─", 20))
+	listed := 0
+	for _, c := range changes {
+		counts[c.ChangeType]++
+		if c.ChangeType == "NoChange" || c.ChangeType == "Ignore" {
+			continue
+		}
+		listed++
+		props := strings.Join(c.Properties, ", ")
+		if len(props) > 60 {
+			props = props[:57] + "..."
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%s\n", changeSymbols[c.ChangeType], c.ChangeType, c.Type, c.Name, props)
+	}
+	if listed == 0 {
+		fmt.Fprintln(tw, "  \t(none)\t\t\t")
+	}
+	tw.Flush()
+
+	var summary []string
+	for _, s := range changeSummary {
+		if counts[s.changeType] > 0 {
+			summary = append(summary, fmt.Sprintf("%d %s", counts[s.changeType], s.label))
+		}
+	}
+	if len(summary) > 0 {
+		fmt.Fprintf(w, "\n  %s\n", strings.Join(summary, ", "))
+	}
+}
+
+var changeSummary = []struct{ changeType, label string }{
+	{"Delete", "to delete"},
+	{"Create", "to create"},
+	{"Modify", "to modify"},
+	{"Deploy", "to redeploy"},
+	{"Unsupported", "not evaluated"},
+	{"NoChange", "unchanged"},
+	{"Ignore", "ignored"},
+}
+
+var bicepResourceRe = regexp.MustCompile(`(?m)^resource\s+(\w+)\s+'([^@']+)@[^']*'(\s+existing)?\s*=\s*(if\b)?`)
+
+// TemplateResources lists the resources an embedded template declares, as
+// Create changes named by their Bicep symbol. It stands in for what-if when
+// the resource group does not exist yet, since what-if needs one.
+// Conditional resources are marked "(if enabled)".
+func TemplateResources(name string) ([]Change, error) {
+	data, err := templateFS.ReadFile("templates/" + name)
+	if err != nil {
+		return nil, fmt.Errorf("embedded template %q not found: %w", name, err)
+	}
+	var changes []Change
+	for _, m := range bicepResourceRe.FindAllStringSubmatch(string(data), -1) {
+		if m[3] != "" {
+			continue
+		}
+		name := m[1]
+		if m[4] != "" {
+			name += " (if enabled)"
+		}
+		changes = append(changes, Change{ChangeType: "Create", Type: m[2], Name: name})
+	}
+	return changes, nil
+}
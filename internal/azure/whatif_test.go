@@ -0,0 +1,101 @@
+package azure
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestParseWhatIf_Create(t *testing.T) {
+	changes, err := ParseWhatIf(readFixture(t, "whatif-create.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Change{
+		{ChangeType: "Create", Type: "Microsoft.ContainerInstance/containerGroups", Name: "devlake-backend-abc12"},
+		{ChangeType: "Create", Type: "Microsoft.DBforMySQL/flexibleServers", Name: "devlakemysqlabc12"},
+		{ChangeType: "Create", Type: "Microsoft.DBforMySQL/flexibleServers/databases", Name: "devlakemysqlabc12/lake"},
+		{ChangeType: "Create", Type: "Microsoft.KeyVault/vaults", Name: "devlakekvabc12"},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("got %d changes, want %d: %+v", len(changes), len(want), changes)
+	}
+	for i, w := range want {
+		c := changes[i]
+		if c.ChangeType != w.ChangeType || c.Type != w.Type || c.Name != w.Name {
+			t.Errorf("change %d = %+v, want %+v", i, c, w)
+		}
+	}
+}
+
+func TestParseWhatIf_Update(t *testing.T) {
+	changes, err := ParseWhatIf(readFixture(t, "whatif-update.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var order []string
+	for _, c := range changes {
+		order = append(order, c.ChangeType)
+	}
+	if got := strings.Join(order, ","); got != "Delete,Modify,Deploy,NoChange,Ignore" {
+		t.Errorf("order = %s", got)
+	}
+	mod := changes[1]
+	if len(mod.Properties) != 1 || mod.Properties[0] != "properties.containers[0].properties.image" {
+		t.Errorf("NoEffect deltas should be dropped: %v", mod.Properties)
+	}
+	if changes[2].Name != "devlakekvabc12/db-admin-password" || changes[2].Type != "Microsoft.KeyVault/vaults/secrets" {
+		t.Errorf("nested resource = %+v", changes[2])
+	}
+
+	var b bytes.Buffer
+	RenderChanges(&b, changes)
+	out := b.String()
+	for _, s := range []string{"-  Delete", "~  Modify", "devlake-backend-abc12", "properties.containers[0].properties.image",
+		"1 to delete, 1 to modify, 1 to redeploy, 1 unchanged, 1 ignored"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("table missing %q:\n%s", s, out)
+		}
+	}
+	if strings.Contains(out, "unrelated") {
+		t.Errorf("ignored resources should not be listed:\n%s", out)
+	}
+}
+
+func TestParseWhatIf_Error(t *testing.T) {
+	_, err := ParseWhatIf(readFixture(t, "whatif-error.json"))
+	if err == nil || !strings.Contains(err.Error(), "InvalidTemplate") {
+		t.Errorf("got %v, want InvalidTemplate error", err)
+	}
+	if _, err := ParseWhatIf([]byte("not json")); err == nil {
+		t.Error("expected parse error")
+	}
+}
+
+func TestTemplateResources(t *testing.T) {
+	changes, err := TemplateResources("main-aca.bicep")
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := map[string]string{}
+	for _, c := range changes {
+		names[c.Name] = c.Type
+	}
+	if names["environment"] != "Microsoft.App/managedEnvironments" {
+		t.Errorf("environment missing: %v", names)
+	}
+	if _, ok := names["acr (if enabled)"]; !ok {
+		t.Errorf("conditional ACR not marked: %v", names)
+	}
+}
@@ -0,0 +1,252 @@
+// Package backup reads and writes DevLake backup archives: a gzip-compressed
+// tar holding a MySQL dump, the deployment's secrets, and its state file.
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Well-known archive entry names.
+const (
+	FormatVersion   = 1
+	ManifestName    = "manifest.json"
+	DumpName        = "lake.sql"
+	EnvName         = ".env"
+	SecretName      = "encryption-secret"
+	DefaultDB       = "lake"
+	encryptedSuffix = ".enc"
+)
+
+// ErrPassphraseRequired is returned by Extract when the archive holds
+// encrypted entries and no passphrase was given.
+var ErrPassphraseRequired = errors.New("backup is encrypted — a passphrase is required")
+
+// Manifest describes an archive's contents. It is always the first entry.
+type Manifest struct {
+	Format         int      `json:"format"`
+	CreatedAt      string   `json:"createdAt"`
+	Method         string   `json:"method"` // "local" or "azure"
+	DevLakeVersion string   `json:"devlakeVersion,omitempty"`
+	Database       string   `json:"database"`
+	Encrypted      bool     `json:"encrypted"`
+	Files          []string `json:"files"`
+}
+
+// Entry is a file to add to an archive. Data is used when Path is empty;
+// Path lets large files such as the SQL dump be streamed from disk.
+type Entry struct {
+	Name   string
+	Data   []byte
+	Path   string
+	Secret bool // encrypted when a passphrase is given
+}
+
+// Write creates a backup archive at dest. Secret entries are encrypted with
+// passphrase when it is non-empty and stored with a ".enc" suffix.
+func Write(dest string, m Manifest, entries []Entry, passphrase string) (err error) {
+	m.Format = FormatVersion
+	if m.CreatedAt == "" {
+		m.CreatedAt = time.Now().Format(time.RFC3339)
+	}
+	m.Files = nil
+	for _, e := range entries {
+		m.Files = append(m.Files, e.Name)
+		if e.Secret && passphrase != "" {
+			m.Encrypted = true
+		}
+	}
+
+	f, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(dest)
+		}
+	}()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	manifest, _ := json.MarshalIndent(m, "", "  ")
+	if err := writeBytes(tw, ManifestName, manifest); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if err := writeEntry(tw, e, passphrase); err != nil {
+			return fmt.Errorf("adding %s: %w", e.Name, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func writeEntry(tw *tar.Writer, e Entry, passphrase string) error {
+	if e.Path == "" || (e.Secret && passphrase != "") {
+		data := e.Data
+		if e.Path != "" {
+			var err error
+			if data, err = os.ReadFile(e.Path); err != nil {
+				return err
+			}
+		}
+		if e.Secret && passphrase != "" {
+			enc, err := Encrypt(data, passphrase)
+			if err != nil {
+				return err
+			}
+			return writeBytes(tw, e.Name+encryptedSuffix, enc)
+		}
+		return writeBytes(tw, e.Name, data)
+	}
+
+	src, err := os.Open(e.Path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: e.Name, Mode: 0600, Size: info.Size(), ModTime: time.Now()}); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, src)
+	return err
+}
+
+func writeBytes(tw *tar.Writer, name string, data []byte) error {
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(data)), ModTime: time.Now()}); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+// ReadManifest returns the manifest of an archive without extracting it.
+func ReadManifest(src string) (*Manifest, error) {
+	f, err := os.Open(src)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	tr, err := openTar(f)
+	if err != nil {
+		return nil, err
+	}
+	hdr, err := tr.Next()
+	if err != nil || hdr.Name != ManifestName {
+		return nil, fmt.Errorf("%s is not a DevLake backup (missing %s)", src, ManifestName)
+	}
+	return decodeManifest(tr)
+}
+
+// Extract unpacks an archive into destDir, decrypting secret entries with
+// passphrase. Files are written with mode 0600 under their logical names.
+func Extract(src, destDir, passphrase string) (*Manifest, error) {
+	f, err := os.Open(src)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	tr, err := openTar(f)
+	if err != nil {
+		return nil, err
+	}
+
+	var m *Manifest
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", src, err)
+		}
+		if m == nil {
+			if hdr.Name != ManifestName {
+				return nil, fmt.Errorf("%s is not a DevLake backup (missing %s)", src, ManifestName)
+			}
+			if m, err = decodeManifest(tr); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		// Entries are flat; reject anything that could escape destDir.
+		if hdr.Typeflag != tar.TypeReg || hdr.Name != filepath.Base(hdr.Name) || strings.Contains(hdr.Name, "..") {
+			return nil, fmt.Errorf("unexpected entry %q in backup", hdr.Name)
+		}
+
+		name := hdr.Name
+		if base, ok := strings.CutSuffix(name, encryptedSuffix); ok {
+			if passphrase == "" {
+				return nil, ErrPassphraseRequired
+			}
+			enc, err := io.ReadAll(tr)
+			if err != nil {
+				return nil, err
+			}
+			data, err := Decrypt(enc, passphrase)
+			if err != nil {
+				return nil, err
+			}
+			if err := os.WriteFile(filepath.Join(destDir, base), data, 0600); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := copyToFile(filepath.Join(destDir, name), tr); err != nil {
+			return nil, err
+		}
+	}
+	if m == nil {
+		return nil, fmt.Errorf("%s is empty", src)
+	}
+	return m, nil
+}
+
+func openTar(r io.Reader) (*tar.Reader, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("not a gzip archive: %w", err)
+	}
+	return tar.NewReader(gz), nil
+}
+
+func decodeManifest(r io.Reader) (*Manifest, error) {
+	var m Manifest
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", ManifestName, err)
+	}
+	if m.Format > FormatVersion {
+		return nil, fmt.Errorf("backup format %d is newer than this CLI supports (%d) — upgrade gh-devlake", m.Format, FormatVersion)
+	}
+	return &m, nil
+}
+
+func copyToFile(path string, r io.Reader) error {
+	out, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
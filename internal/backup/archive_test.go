@@ -0,0 +1,103 @@
+package backup
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeTestArchive(t *testing.T, passphrase string) string {
+	t.Helper()
+	dir := t.TempDir()
+	dump := filepath.Join(dir, "dump.sql")
+	if err := os.WriteFile(dump, []byte("CREATE DATABASE lake;\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	dest := filepath.Join(dir, "backup.tar.gz")
+	entries := []Entry{
+		{Name: DumpName, Path: dump},
+		{Name: EnvName, Data: []byte("ENCRYPTION_SECRET=abc\n"), Secret: true},
+		{Name: ".devlake-local.json", Data: []byte(`{"method":"local"}`)},
+	}
+	if err := Write(dest, Manifest{Method: "local", Database: DefaultDB}, entries, passphrase); err != nil {
+		t.Fatal(err)
+	}
+	return dest
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestWriteExtract_Plain(t *testing.T) {
+	src := writeTestArchive(t, "")
+
+	m, err := ReadManifest(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Encrypted || m.Format != FormatVersion || m.Method != "local" {
+		t.Errorf("manifest = %+v", m)
+	}
+	if want := []string{DumpName, EnvName, ".devlake-local.json"}; !reflect.DeepEqual(m.Files, want) {
+		t.Errorf("Files = %v, want %v", m.Files, want)
+	}
+
+	out := t.TempDir()
+	if _, err := Extract(src, out, ""); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, filepath.Join(out, DumpName)); got != "CREATE DATABASE lake;\n" {
+		t.Errorf("dump = %q", got)
+	}
+	if got := readFile(t, filepath.Join(out, EnvName)); got != "ENCRYPTION_SECRET=abc\n" {
+		t.Errorf(".env = %q", got)
+	}
+}
+
+func TestWriteExtract_Encrypted(t *testing.T) {
+	src := writeTestArchive(t, "correct horse")
+
+	m, err := ReadManifest(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.Encrypted {
+		t.Error("Encrypted = false")
+	}
+
+	if _, err := Extract(src, t.TempDir(), ""); !errors.Is(err, ErrPassphraseRequired) {
+		t.Errorf("no passphrase: err = %v", err)
+	}
+	if _, err := Extract(src, t.TempDir(), "wrong"); !errors.Is(err, ErrBadPassphrase) {
+		t.Errorf("wrong passphrase: err = %v", err)
+	}
+
+	out := t.TempDir()
+	if _, err := Extract(src, out, "correct horse"); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, filepath.Join(out, EnvName)); got != "ENCRYPTION_SECRET=abc\n" {
+		t.Errorf(".env = %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(out, EnvName+encryptedSuffix)); !os.IsNotExist(err) {
+		t.Error("encrypted entry should be written under its logical name only")
+	}
+}
+
+func TestReadManifest_NotABackup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "x.tar.gz")
+	if err := os.WriteFile(path, []byte("hello"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadManifest(path); err == nil {
+		t.Error("expected error for non-archive")
+	}
+}
@@ -0,0 +1,73 @@
+package backup
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// Encrypted entries are: magic | salt | nonce | AES-256-GCM ciphertext, with
+// the key derived from the passphrase by PBKDF2-HMAC-SHA256.
+const (
+	cryptMagic      = "GHDLENC1"
+	cryptSaltSize   = 16
+	cryptIterations = 600_000
+	cryptKeySize    = 32
+)
+
+// ErrBadPassphrase is returned when an entry cannot be decrypted.
+var ErrBadPassphrase = errors.New("wrong passphrase or corrupted backup")
+
+// Encrypt seals data with a key derived from passphrase.
+func Encrypt(data []byte, passphrase string) ([]byte, error) {
+	salt := make([]byte, cryptSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("crypto/rand failed: %w", err)
+	}
+	gcm, err := newGCM(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("crypto/rand failed: %w", err)
+	}
+	out := append([]byte(cryptMagic), salt...)
+	out = append(out, nonce...)
+	return gcm.Seal(out, nonce, data, []byte(cryptMagic)), nil
+}
+
+// Decrypt opens data produced by Encrypt.
+func Decrypt(data []byte, passphrase string) ([]byte, error) {
+	if !bytes.HasPrefix(data, []byte(cryptMagic)) || len(data) < len(cryptMagic)+cryptSaltSize {
+		return nil, ErrBadPassphrase
+	}
+	rest := data[len(cryptMagic):]
+	salt, rest := rest[:cryptSaltSize], rest[cryptSaltSize:]
+	gcm, err := newGCM(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) < gcm.NonceSize() {
+		return nil, ErrBadPassphrase
+	}
+	plain, err := gcm.Open(nil, rest[:gcm.NonceSize()], rest[gcm.NonceSize():], []byte(cryptMagic))
+	if err != nil {
+		return nil, ErrBadPassphrase
+	}
+	return plain, nil
+}
+
+func newGCM(passphrase string, salt []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(pbkdf2.Key([]byte(passphrase), salt, cryptIterations, cryptKeySize, sha256.New))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,47 @@
+package backup
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestDecryptExistingBackup(t *testing.T) {
+	// Written by an earlier release; the key derivation must stay compatible.
+	enc, _ := hex.DecodeString("4748444c454e4331d94b44669132e90248ee41c248f67a8313e1dd20f0b86105" +
+		"a11d9c4f90884877c9462b45474c1745c67ded9cf46d21799982420af9d3d71a" +
+		"28d7f70b9c22a75ad60c99f8")
+	got, err := Decrypt(enc, "pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "ENCRYPTION_SECRET=ABCDEF" {
+		t.Errorf("Decrypt() = %q", got)
+	}
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	plain := []byte("ENCRYPTION_SECRET=ABCDEF")
+	enc, err := Encrypt(plain, "pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(enc, plain) {
+		t.Error("ciphertext contains plaintext")
+	}
+	got, err := Decrypt(enc, "pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, plain) {
+		t.Errorf("Decrypt = %q", got)
+	}
+
+	enc[len(enc)-1] ^= 0xff
+	if _, err := Decrypt(enc, "pass"); err != ErrBadPassphrase {
+		t.Errorf("tampered: err = %v", err)
+	}
+	if _, err := Decrypt([]byte("short"), "pass"); err != ErrBadPassphrase {
+		t.Errorf("short: err = %v", err)
+	}
+}
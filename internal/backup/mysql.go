@@ -0,0 +1,106 @@
+package backup
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// execCommand and lookPath are variables so tests can substitute fakes.
+var (
+	execCommand = exec.Command
+	lookPath    = exec.LookPath
+)
+
+// mysqlClientImage provides mysqldump/mysql when Docker is available, so the
+// client version matches the server regardless of what is installed locally.
+const mysqlClientImage = "mysql:8"
+
+// DumpArgs are the mysqldump options for a consistent dump that replaces the
+// whole database on load.
+var DumpArgs = []string{
+	"--single-transaction",
+	"--routines",
+	"--triggers",
+	"--no-tablespaces",
+	"--set-gtid-purged=OFF",
+	"--add-drop-database",
+}
+
+// ComposeDumpCommand is run inside the compose mysql service. It reads the
+// root password from the container's own environment.
+func ComposeDumpCommand(database string) []string {
+	return []string{"sh", "-c", fmt.Sprintf(`MYSQL_PWD="$MYSQL_ROOT_PASSWORD" exec mysqldump -uroot %s --databases %s`,
+		strings.Join(DumpArgs, " "), database)}
+}
+
+// ComposeLoadCommand loads a dump from stdin inside the compose mysql service.
+func ComposeLoadCommand() []string {
+	return []string{"sh", "-c", `MYSQL_PWD="$MYSQL_ROOT_PASSWORD" exec mysql -uroot`}
+}
+
+// ComposePingCommand succeeds once the compose mysql service accepts connections.
+func ComposePingCommand() []string {
+	return []string{"sh", "-c", `MYSQL_PWD="$MYSQL_ROOT_PASSWORD" exec mysqladmin -uroot ping`}
+}
+
+// MySQLConn describes a MySQL server reachable from this machine.
+type MySQLConn struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+	TLS      bool
+}
+
+func (c MySQLConn) args() []string {
+	port := c.Port
+	if port == 0 {
+		port = 3306
+	}
+	args := []string{"-h", c.Host, "-P", strconv.Itoa(port), "-u", c.User}
+	if c.TLS {
+		args = append(args, "--ssl-mode=REQUIRED")
+	}
+	return args
+}
+
+// DumpRemote writes a dump of database on c to w.
+func DumpRemote(c MySQLConn, database string, w io.Writer) error {
+	args := append(c.args(), DumpArgs...)
+	args = append(args, "--databases", database)
+	return runClient(c, "mysqldump", args, nil, w)
+}
+
+// LoadRemote loads a dump read from r into c.
+func LoadRemote(c MySQLConn, r io.Reader) error {
+	return runClient(c, "mysql", c.args(), r, io.Discard)
+}
+
+// runClient runs a MySQL client tool in the mysql:8 image when Docker is on
+// PATH, otherwise the locally installed tool. The password is passed through
+// MYSQL_PWD so it never appears in the process list.
+func runClient(c MySQLConn, tool string, args []string, stdin io.Reader, stdout io.Writer) error {
+	var cmd *exec.Cmd
+	if _, err := lookPath("docker"); err == nil {
+		dockerArgs := append([]string{"run", "--rm", "-i", "-e", "MYSQL_PWD", mysqlClientImage, tool}, args...)
+		cmd = execCommand("docker", dockerArgs...)
+	} else if _, err := lookPath(tool); err == nil {
+		cmd = execCommand(tool, args...)
+	} else {
+		return fmt.Errorf("%s not found — install Docker or the MySQL 8 client tools", tool)
+	}
+	cmd.Env = append(os.Environ(), "MYSQL_PWD="+c.Password)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s failed: %s\n%s", tool, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package backup
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeClient records the argv of the command runClient would start and runs
+// a no-op instead. onPath lists the tools lookPath should find.
+func fakeClient(t *testing.T, onPath ...string) *[]string {
+	t.Helper()
+	var argv []string
+	origExec, origLook := execCommand, lookPath
+	execCommand = func(name string, args ...string) *exec.Cmd {
+		argv = append([]string{name}, args...)
+		cmd := exec.Command(os.Args[0], "-test.run=TestHelperProcess", "--")
+		cmd.Env = []string{"GO_TEST_HELPER_NOOP=1"}
+		return cmd
+	}
+	lookPath = func(file string) (string, error) {
+		for _, p := range onPath {
+			if p == file {
+				return "/usr/bin/" + file, nil
+			}
+		}
+		return "", errors.New("not found")
+	}
+	t.Cleanup(func() { execCommand, lookPath = origExec, origLook })
+	return &argv
+}
+
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("GO_TEST_HELPER_NOOP") != "1" {
+		return
+	}
+	os.Exit(0)
+}
+
+var testConn = MySQLConn{Host: "db.example.com", User: "merico", Password: "s3cret", TLS: true}
+
+func TestDumpRemote_Docker(t *testing.T) {
+	argv := fakeClient(t, "docker", "mysqldump")
+	if err := DumpRemote(testConn, "lake", io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"docker", "run", "--rm", "-i", "-e", "MYSQL_PWD", "mysql:8", "mysqldump",
+		"-h", "db.example.com", "-P", "3306", "-u", "merico", "--ssl-mode=REQUIRED"}
+	want = append(want, DumpArgs...)
+	want = append(want, "--databases", "lake")
+	if !reflect.DeepEqual(*argv, want) {
+		t.Errorf("argv = %v\nwant %v", *argv, want)
+	}
+	if strings.Contains(strings.Join(*argv, " "), "s3cret") {
+		t.Error("password must not appear in argv")
+	}
+}
+
+func TestLoadRemote_LocalClient(t *testing.T) {
+	argv := fakeClient(t, "mysql")
+	if err := LoadRemote(MySQLConn{Host: "localhost", Port: 3307, User: "root"}, strings.NewReader("")); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"mysql", "-h", "localhost", "-P", "3307", "-u", "root"}
+	if !reflect.DeepEqual(*argv, want) {
+		t.Errorf("argv = %v, want %v", *argv, want)
+	}
+}
+
+func TestRunClient_NoTools(t *testing.T) {
+	fakeClient(t)
+	if err := DumpRemote(testConn, "lake", io.Discard); err == nil || !strings.Contains(err.Error(), "install Docker") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestComposeDumpCommand(t *testing.T) {
+	cmd := ComposeDumpCommand("lake")
+	if len(cmd) != 3 || cmd[0] != "sh" || !strings.HasSuffix(cmd[2], "--databases lake") || !strings.Contains(cmd[2], "--single-transaction") {
+		t.Errorf("ComposeDumpCommand = %q", cmd)
+	}
+}
@@ -0,0 +1,259 @@
+// Package bundle reads and writes offline deployment bundles: a tar holding
+// a DevLake release's compose file and env template, its images as written
+// by docker save, and a manifest with the SHA-256 of each.
+package bundle
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Well-known bundle entry names.
+const (
+	FormatVersion = 1
+	ManifestName  = "manifest.json"
+	ComposeName   = "docker-compose.yml"
+	EnvName       = "env.example"
+	ImagesName    = "images.tar"
+)
+
+// Manifest describes a bundle. It is always the first entry.
+type Manifest struct {
+	Format    int      `json:"format"`
+	Version   string   `json:"version"` // DevLake release, e.g. v1.0.2
+	CreatedAt string   `json:"createdAt"`
+	Images    []string `json:"images"`
+	Files     []File   `json:"files"`
+}
+
+// File is a bundle entry and its checksum.
+type File struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Entry is a file on disk to add to a bundle.
+type Entry struct {
+	Name string
+	Path string
+}
+
+// Write creates a bundle at dest. The checksums are computed from the files
+// before the manifest is written, so the manifest can come first.
+func Write(dest string, m Manifest, entries []Entry) (err error) {
+	m.Format = FormatVersion
+	if m.CreatedAt == "" {
+		m.CreatedAt = time.Now().Format(time.RFC3339)
+	}
+	m.Files = nil
+	for _, e := range entries {
+		sum, size, err := fileSHA256(e.Path)
+		if err != nil {
+			return err
+		}
+		m.Files = append(m.Files, File{Name: e.Name, Size: size, SHA256: sum})
+	}
+
+	f, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(dest)
+		}
+	}()
+	tw := tar.NewWriter(f)
+
+	manifest, _ := json.MarshalIndent(m, "", "  ")
+	if err := tw.WriteHeader(&tar.Header{Name: ManifestName, Mode: 0644, Size: int64(len(manifest)), ModTime: time.Now()}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(manifest); err != nil {
+		return err
+	}
+	for i, e := range entries {
+		if err := writeFile(tw, e, m.Files[i].Size); err != nil {
+			return fmt.Errorf("adding %s: %w", e.Name, err)
+		}
+	}
+	return tw.Close()
+}
+
+func writeFile(tw *tar.Writer, e Entry, size int64) error {
+	src, err := os.Open(e.Path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	if err := tw.WriteHeader(&tar.Header{Name: e.Name, Mode: 0644, Size: size, ModTime: time.Now()}); err != nil {
+		return err
+	}
+	_, err = io.CopyN(tw, src, size)
+	return err
+}
+
+func fileSHA256(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
+
+// Extract unpacks a bundle into destDir and verifies every entry against the
+// manifest. It fails on a checksum mismatch, a missing entry or one the
+// manifest does not list; destDir may then hold partial files.
+func Extract(src, destDir string) (*Manifest, error) {
+	f, err := os.Open(src)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	tr, err := openTar(f)
+	if err != nil {
+		return nil, err
+	}
+
+	var m *Manifest
+	want := map[string]File{}
+	seen := map[string]bool{}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", src, err)
+		}
+		if m == nil {
+			if hdr.Name != ManifestName {
+				return nil, fmt.Errorf("%s is not a DevLake bundle (missing %s)", src, ManifestName)
+			}
+			if m, err = decodeManifest(tr); err != nil {
+				return nil, err
+			}
+			for _, file := range m.Files {
+				want[file.Name] = file
+			}
+			continue
+		}
+		// Entries are flat; reject anything that could escape destDir.
+		if hdr.Typeflag != tar.TypeReg || hdr.Name != filepath.Base(hdr.Name) || strings.Contains(hdr.Name, "..") {
+			return nil, fmt.Errorf("unexpected entry %q in bundle", hdr.Name)
+		}
+		file, ok := want[hdr.Name]
+		if !ok {
+			return nil, fmt.Errorf("entry %q is not listed in the bundle manifest", hdr.Name)
+		}
+		sum, size, err := copyToFile(filepath.Join(destDir, hdr.Name), tr)
+		if err != nil {
+			return nil, err
+		}
+		if size != file.Size || sum != file.SHA256 {
+			return nil, fmt.Errorf("checksum mismatch for %s: got sha256 %s (%d bytes), manifest has %s (%d bytes) — the bundle is corrupt or was modified", hdr.Name, sum, size, file.SHA256, file.Size)
+		}
+		seen[hdr.Name] = true
+	}
+	if m == nil {
+		return nil, fmt.Errorf("%s is empty", src)
+	}
+	for _, file := range m.Files {
+		if !seen[file.Name] {
+			return nil, fmt.Errorf("bundle is missing %s", file.Name)
+		}
+	}
+	for _, name := range []string{ComposeName, EnvName, ImagesName} {
+		if _, ok := want[name]; !ok {
+			return nil, fmt.Errorf("bundle has no %s", name)
+		}
+	}
+	return m, nil
+}
+
+// openTar reads a plain tar, or a gzip-compressed one (a bundle someone
+// compressed for transfer).
+func openTar(r io.Reader) (*tar.Reader, error) {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		return tar.NewReader(gz), nil
+	}
+	return tar.NewReader(br), nil
+}
+
+func decodeManifest(r io.Reader) (*Manifest, error) {
+	var m Manifest
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", ManifestName, err)
+	}
+	if m.Format > FormatVersion {
+		return nil, fmt.Errorf("bundle format %d is newer than this CLI supports (%d) — upgrade gh-devlake", m.Format, FormatVersion)
+	}
+	return &m, nil
+}
+
+func copyToFile(path string, r io.Reader) (string, int64, error) {
+	out, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return "", 0, err
+	}
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(out, h), r)
+	if err != nil {
+		out.Close()
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, out.Close()
+}
+
+var imageRe = regexp.MustCompile(`^\s*image:\s*["']?([^"'\s#]+)`)
+
+// ComposeImages returns the images a compose file uses, in order and without
+// duplicates. An image set through a variable cannot be bundled.
+func ComposeImages(compose []byte) ([]string, error) {
+	var images []string
+	seen := map[string]bool{}
+	for _, line := range strings.Split(string(bytes.ReplaceAll(compose, []byte("\r\n"), []byte("\n"))), "\n") {
+		m := imageRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		if strings.Contains(m[1], "${") || strings.HasPrefix(m[1], "$") {
+			return nil, fmt.Errorf("image %q is set through a variable — pin it in the compose file", m[1])
+		}
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			images = append(images, m[1])
+		}
+	}
+	if len(images) == 0 {
+		return nil, fmt.Errorf("no images found in the compose file")
+	}
+	return images, nil
+}
@@ -0,0 +1,145 @@
+package bundle
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testCompose = `services:
+  mysql:
+    image: mysql:8
+  devlake:
+    image: "devlake.docker.scarf.sh/apache/devlake:v1.0.2" # backend
+  grafana:
+    image: devlake.docker.scarf.sh/apache/devlake-dashboard:v1.0.2
+  lake-migrate:
+    image: devlake.docker.scarf.sh/apache/devlake:v1.0.2
+`
+
+func writeTestBundle(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		ComposeName: testCompose,
+		EnvName:     "ENCRYPTION_SECRET=\n",
+		ImagesName:  "not really a docker save archive",
+	}
+	var entries []Entry
+	for _, name := range []string{ComposeName, EnvName, ImagesName} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(files[name]), 0644); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, Entry{Name: name, Path: path})
+	}
+	dest := filepath.Join(dir, "bundle.tar")
+	if err := Write(dest, Manifest{Version: "v1.0.2", Images: []string{"mysql:8"}}, entries); err != nil {
+		t.Fatal(err)
+	}
+	return dest
+}
+
+func TestWriteExtract(t *testing.T) {
+	src := writeTestBundle(t)
+	out := t.TempDir()
+	m, err := Extract(src, out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Version != "v1.0.2" || m.Format != FormatVersion || len(m.Files) != 3 {
+		t.Errorf("manifest = %+v", m)
+	}
+	sum := sha256.Sum256([]byte("ENCRYPTION_SECRET=\n"))
+	if f := m.Files[1]; f.Name != EnvName || f.Size != 19 || f.SHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("env file = %+v", f)
+	}
+	data, err := os.ReadFile(filepath.Join(out, ComposeName))
+	if err != nil || string(data) != testCompose {
+		t.Errorf("compose = %q, %v", data, err)
+	}
+}
+
+// rewriteBundle copies a bundle, replacing the content of one entry.
+func rewriteBundle(t *testing.T, src, name, content string, extra bool) string {
+	t.Helper()
+	in, err := os.Open(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+	dest := filepath.Join(t.TempDir(), "tampered.tar")
+	out, err := os.Create(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+	tr := tar.NewReader(in)
+	tw := tar.NewWriter(out)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			break
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Name == name {
+			data = []byte(content)
+			hdr.Size = int64(len(data))
+		}
+		tw.WriteHeader(hdr)
+		tw.Write(data)
+	}
+	if extra {
+		tw.WriteHeader(&tar.Header{Name: "evil.sh", Mode: 0755, Size: 2, Typeflag: tar.TypeReg})
+		tw.Write([]byte("hi"))
+	}
+	tw.Close()
+	return dest
+}
+
+func TestExtractRejectsTampering(t *testing.T) {
+	src := writeTestBundle(t)
+
+	tampered := rewriteBundle(t, src, ComposeName, strings.Replace(testCompose, "mysql:8", "evil/mysql:8", 1), false)
+	if _, err := Extract(tampered, t.TempDir()); err == nil || !strings.Contains(err.Error(), "checksum mismatch for docker-compose.yml") {
+		t.Errorf("tampered compose error = %v", err)
+	}
+
+	extra := rewriteBundle(t, src, "", "", true)
+	if _, err := Extract(extra, t.TempDir()); err == nil || !strings.Contains(err.Error(), "not listed") {
+		t.Errorf("extra entry error = %v", err)
+	}
+
+	notBundle := filepath.Join(t.TempDir(), "x.tar")
+	os.WriteFile(notBundle, []byte("plain text"), 0644)
+	if _, err := Extract(notBundle, t.TempDir()); err == nil {
+		t.Error("expected an error for a file that is not a bundle")
+	}
+}
+
+func TestComposeImages(t *testing.T) {
+	images, err := ComposeImages([]byte(strings.ReplaceAll(testCompose, "\n", "\r\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"mysql:8", "devlake.docker.scarf.sh/apache/devlake:v1.0.2", "devlake.docker.scarf.sh/apache/devlake-dashboard:v1.0.2"}
+	if !reflect.DeepEqual(images, want) {
+		t.Errorf("images = %v", images)
+	}
+
+	if _, err := ComposeImages([]byte("services:\n  db:\n    image: ${MYSQL_IMAGE}\n")); err == nil || !strings.Contains(err.Error(), "variable") {
+		t.Errorf("variable image error = %v", err)
+	}
+	if _, err := ComposeImages([]byte("services: {}\n")); err == nil {
+		t.Error("expected an error without images")
+	}
+}
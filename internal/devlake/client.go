@@ -49,6 +49,11 @@ type Connection struct {
 	Token        string `json:"token,omitempty"`
 	Organization string `json:"organization,omitempty"`
 	Enterprise   string `json:"enterprise,omitempty"`
+
+	// GitHub App auth (authMethod "AppKey"); the secret key is never returned.
+	AuthMethod     string `json:"authMethod,omitempty"`
+	AppID          string `json:"appId,omitempty"`
+	InstallationID int    `json:"installationId,omitempty"`
 }
 
 // ConnectionUpdateRequest is the payload for PATCH /plugins/{plugin}/connections/{id}.
@@ -62,6 +67,10 @@ type ConnectionUpdateRequest struct {
 	Token        string `json:"token,omitempty"`
 	Organization string `json:"organization,omitempty"`
 	Enterprise   string `json:"enterprise,omitempty"`
+
+	AppID          string `json:"appId,omitempty"`
+	InstallationID int    `json:"installationId,omitempty"`
+	SecretKey      string `json:"secretKey,omitempty"`
 }
 
 // ConnectionCreateRequest is the payload for creating a plugin connection.
@@ -79,6 +88,11 @@ type ConnectionCreateRequest struct {
 	Enterprise            string `json:"enterprise,omitempty"`
 	TokenExpiresAt        string `json:"tokenExpiresAt,omitempty"`
 	RefreshTokenExpiresAt string `json:"refreshTokenExpiresAt,omitempty"`
+
+	// GitHub App auth (authMethod "AppKey")
+	AppID          string `json:"appId,omitempty"`
+	InstallationID int    `json:"installationId,omitempty"`
+	SecretKey      string `json:"secretKey,omitempty"`
 }
 
 // ConnectionTestRequest is the payload for testing a connection before creating.
@@ -94,6 +108,11 @@ type ConnectionTestRequest struct {
 	Proxy            string `json:"proxy"`
 	Organization     string `json:"organization,omitempty"`
 	Enterprise       string `json:"enterprise,omitempty"`
+
+	// GitHub App auth (authMethod "AppKey")
+	AppID          string `json:"appId,omitempty"`
+	InstallationID int    `json:"installationId,omitempty"`
+	SecretKey      string `json:"secretKey,omitempty"`
 }
 
 // ConnectionTestResult is the response from testing a connection.
@@ -237,6 +256,16 @@ func (c *Client) Health() (*HealthStatus, error) {
 	return nil, fmt.Errorf("health check returned %d: %s", resp.StatusCode, body)
 }
 
+// VersionInfo is the response from GET /version.
+type VersionInfo struct {
+	Version string `json:"version"`
+}
+
+// Version returns the DevLake backend version (e.g. "v1.0.2@a1b2c3d").
+func (c *Client) Version() (*VersionInfo, error) {
+	return doGet[VersionInfo](c, "/version")
+}
+
 // doPost is a generic helper for POST requests that return JSON.
 func doPost[T any](c *Client, path string, payload any) (*T, error) {
 	jsonBody, err := json.Marshal(payload)
@@ -382,6 +411,23 @@ func (c *Client) ListScopes(plugin string, connID int) (*ScopeListResponse, erro
 	return doGet[ScopeListResponse](c, fmt.Sprintf("/plugins/%s/connections/%d/scopes?pageSize=100&page=1", plugin, connID))
 }
 
+// ListAllScopes pages through every scope configured on a plugin connection.
+// Use this instead of ListScopes when the connection may hold more than one page.
+func (c *Client) ListAllScopes(plugin string, connID int) ([]ScopeListWrapper, error) {
+	const pageSize = 100
+	var all []ScopeListWrapper
+	for page := 1; ; page++ {
+		resp, err := doGet[ScopeListResponse](c, fmt.Sprintf("/plugins/%s/connections/%d/scopes?pageSize=%d&page=%d", plugin, connID, pageSize, page))
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, resp.Scopes...)
+		if len(resp.Scopes) < pageSize || (resp.Count > 0 && len(all) >= resp.Count) {
+			return all, nil
+		}
+	}
+}
+
 // ListProjects returns all DevLake projects.
 func (c *Client) ListProjects() ([]Project, error) {
 	result, err := doGet[ProjectListResponse](c, "/projects")
@@ -503,6 +549,15 @@ func (c *Client) SearchRemoteScopes(plugin string, connID int, search string, pa
 	return doGet[RemoteScopeResponse](c, path)
 }
 
+// ListPipelineTasks returns the tasks of a pipeline.
+func (c *Client) ListPipelineTasks(pipelineID int) ([]Task, error) {
+	result, err := doGet[TaskListResponse](c, fmt.Sprintf("/pipelines/%d/tasks", pipelineID))
+	if err != nil {
+		return nil, err
+	}
+	return result.Tasks, nil
+}
+
 // TriggerMigration triggers the DevLake database migration endpoint.
 func (c *Client) TriggerMigration() error {
 	resp, err := c.HTTPClient.Get(c.BaseURL + "/proceed-db-migration")
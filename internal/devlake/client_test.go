@@ -2,6 +2,7 @@ package devlake
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -594,6 +595,38 @@ func TestListScopes(t *testing.T) {
 	}
 }
 
+// TestListAllScopes tests that ListAllScopes follows pagination until a short page.
+func TestListAllScopes(t *testing.T) {
+	var pages []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		pages = append(pages, page)
+		var items []string
+		n := 100
+		if page == "2" {
+			n = 3
+		}
+		for i := 0; i < n; i++ {
+			items = append(items, fmt.Sprintf(`{"scope": {"githubId": %s%03d}}`, page, i))
+		}
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"scopes": [%s], "count": 103}`, strings.Join(items, ","))
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL)
+	scopes, err := client.ListAllScopes("github", 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(scopes) != 103 {
+		t.Errorf("len(scopes) = %d, want 103", len(scopes))
+	}
+	if strings.Join(pages, ",") != "1,2" {
+		t.Errorf("pages requested = %v, want [1 2]", pages)
+	}
+}
+
 // TestDeleteScope tests the DeleteScope method.
 func TestDeleteScope(t *testing.T) {
 	tests := []struct {
@@ -909,6 +942,43 @@ func TestHealth(t *testing.T) {
 	}
 }
 
+// TestVersion tests the Version method.
+func TestVersion(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/version" {
+			t.Errorf("path = %s, want /version", r.URL.Path)
+		}
+		w.Write([]byte(`{"version": "v1.0.2@a1b2c3d"}`))
+	}))
+	defer srv.Close()
+
+	result, err := NewClient(srv.URL).Version()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Version != "v1.0.2@a1b2c3d" {
+		t.Errorf("Version = %q, want %q", result.Version, "v1.0.2@a1b2c3d")
+	}
+}
+
+func TestListPipelineTasks(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/pipelines/9/tasks" {
+			t.Errorf("path = %s, want /pipelines/9/tasks", r.URL.Path)
+		}
+		w.Write([]byte(`{"count": 1, "tasks": [{"id": 31, "pipelineId": 9, "plugin": "github", "status": "TASK_FAILED", "failedSubTask": "collectApiIssues", "message": "403 rate limited"}]}`))
+	}))
+	defer srv.Close()
+
+	tasks, err := NewClient(srv.URL).ListPipelineTasks(9)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].FailedSubTask != "collectApiIssues" || tasks[0].Plugin != "github" {
+		t.Errorf("tasks = %+v", tasks)
+	}
+}
+
 // TestTestSavedConnection tests the TestSavedConnection method.
 func TestTestSavedConnection(t *testing.T) {
 	tests := []struct {
@@ -26,13 +26,16 @@ func Discover(explicitURL string) (*DiscoveryResult, error) {
 		if err := pingURL(url); err != nil {
 			return nil, fmt.Errorf("cannot reach DevLake at %s: %w", url, err)
 		}
-		grafanaURL, configUIURL := inferLocalCompanionURLs(url)
+		grafanaURL, configUIURL := recordedCompanionURLs(url)
+		if grafanaURL == "" && configUIURL == "" {
+			grafanaURL, configUIURL = inferLocalCompanionURLs(url)
+		}
 		return &DiscoveryResult{URL: url, GrafanaURL: grafanaURL, ConfigUIURL: configUIURL, Source: "parameter"}, nil
 	}
 
-	// 2. State files
+	// 2. State files (these carry custom ports chosen at deploy time)
 	cwd, _ := os.Getwd()
-	for _, name := range []string{".devlake-azure.json", ".devlake-local.json"} {
+	for _, name := range []string{".devlake-azure.json", ".devlake-k8s.json", ".devlake-local.json"} {
 		path := filepath.Join(cwd, name)
 		if result := tryStateFile(path); result != nil {
 			return result, nil
@@ -63,7 +66,7 @@ func Discover(explicitURL string) (*DiscoveryResult, error) {
 		"Checked: state files, localhost:8080, localhost:8085.\n\n" +
 		"To deploy a new instance:\n" +
 		"  gh devlake deploy local     # Docker Compose on this machine\n" +
-		"  gh devlake deploy azure     # Azure Container Apps\n\n" +
+		"  gh devlake deploy azure     # Azure Container Instances or Container Apps\n\n" +
 		"Or specify an existing instance with --url <DevLake API URL>")
 }
 
@@ -92,6 +95,22 @@ func tryStateFile(path string) *DiscoveryResult {
 	}
 }
 
+// recordedCompanionURLs returns the Grafana and Config UI URLs from a state
+// file in the current directory whose backend matches backendURL.
+func recordedCompanionURLs(backendURL string) (grafanaURL, configUIURL string) {
+	cwd, _ := os.Getwd()
+	for _, name := range []string{".devlake-azure.json", ".devlake-k8s.json", ".devlake-local.json"} {
+		state, err := LoadState(filepath.Join(cwd, name))
+		if err != nil || state == nil {
+			continue
+		}
+		if strings.TrimRight(state.Endpoints.Backend, "/") == backendURL {
+			return state.Endpoints.Grafana, state.Endpoints.ConfigUI
+		}
+	}
+	return "", ""
+}
+
 func inferLocalCompanionURLs(backendURL string) (grafanaURL, configUIURL string) {
 	// When the backend is running on a well-known localhost port, the sibling
 	// services are typically on matching well-known ports.
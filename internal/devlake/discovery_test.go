@@ -98,6 +98,45 @@ func TestDiscoverExplicitURL(t *testing.T) {
 	}
 }
 
+// TestDiscoverExplicitURLRecordedPorts verifies that companion URLs for an
+// explicit URL come from a state file recording custom ports.
+func TestDiscoverExplicitURLRecordedPorts(t *testing.T) {
+	tmpDir := t.TempDir()
+	origDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get working directory: %v", err)
+	}
+	defer os.Chdir(origDir)
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("failed to change directory: %v", err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	state := &State{
+		Method: "local",
+		Endpoints: StateEndpoints{
+			Backend:  srv.URL,
+			Grafana:  "http://localhost:13002",
+			ConfigUI: "http://localhost:14000",
+		},
+	}
+	if err := SaveState(filepath.Join(tmpDir, ".devlake-local.json"), state); err != nil {
+		t.Fatalf("failed to save state: %v", err)
+	}
+
+	result, err := Discover(srv.URL + "/")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.GrafanaURL != "http://localhost:13002" || result.ConfigUIURL != "http://localhost:14000" {
+		t.Errorf("companions = %q, %q; want recorded ports", result.GrafanaURL, result.ConfigUIURL)
+	}
+}
+
 // TestDiscoverExplicitURLUnreachable tests discovery with unreachable explicit URL.
 func TestDiscoverExplicitURLUnreachable(t *testing.T) {
 	result, err := Discover(closedLocalURL(t))
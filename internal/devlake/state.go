@@ -12,6 +12,8 @@ import (
 type State struct {
 	DeployedAt              string            `json:"deployedAt"`
 	Method                  string            `json:"method"`
+	Version                 string            `json:"version,omitempty"`
+	Instance                string            `json:"instance,omitempty"` // named local instance (deploy local --instance)
 	Endpoints               StateEndpoints    `json:"endpoints"`
 	Connections             []StateConnection `json:"connections,omitempty"`
 	ConnectionsConfiguredAt string            `json:"connectionsConfiguredAt,omitempty"`
@@ -32,6 +34,7 @@ type StateEndpoints struct {
 	Backend  string `json:"backend"`
 	Grafana  string `json:"grafana,omitempty"`
 	ConfigUI string `json:"configUi,omitempty"`
+	MySQL    string `json:"mysql,omitempty"` // host:port, local deployments with --mysql-port
 }
 
 // StateConnection records a created connection.
@@ -63,7 +66,7 @@ func LoadState(path string) (*State, error) {
 // Returns nil,nil when no state file exists.
 func LoadStateFromCwd() (*State, error) {
 	cwd, _ := os.Getwd()
-	for _, name := range []string{".devlake-azure.json", ".devlake-local.json"} {
+	for _, name := range []string{".devlake-azure.json", ".devlake-k8s.json", ".devlake-local.json"} {
 		path := filepath.Join(cwd, name)
 		state, err := LoadState(path)
 		if err == nil && state != nil {
@@ -81,7 +84,7 @@ func FindStateFile(apiURL, grafanaURL string) (string, *State) {
 	// Prefer an existing state file that matches the discovered endpoints.
 	// This avoids accidentally writing local connection state into an unrelated
 	// Azure state file that happens to be present in the directory.
-	for _, name := range []string{".devlake-local.json", ".devlake-azure.json"} {
+	for _, name := range []string{".devlake-local.json", ".devlake-azure.json", ".devlake-k8s.json"} {
 		path := filepath.Join(cwd, name)
 		state, err := LoadState(path)
 		if err != nil || state == nil {
@@ -96,7 +99,7 @@ func FindStateFile(apiURL, grafanaURL string) (string, *State) {
 	}
 
 	// Fall back to the first existing state file (for backward compatibility).
-	for _, name := range []string{".devlake-local.json", ".devlake-azure.json"} {
+	for _, name := range []string{".devlake-local.json", ".devlake-azure.json", ".devlake-k8s.json"} {
 		path := filepath.Join(cwd, name)
 		state, err := LoadState(path)
 		if err == nil && state != nil {
@@ -282,3 +282,22 @@ type Pipeline struct {
 	BlueprintID   int    `json:"blueprintId,omitempty"`
 	SkipOnFail    bool   `json:"skipOnFail,omitempty"`
 }
+
+// Task is one plugin task of a pipeline.
+type Task struct {
+	ID            int    `json:"id"`
+	PipelineID    int    `json:"pipelineId"`
+	Plugin        string `json:"plugin"`
+	Status        string `json:"status"`
+	Message       string `json:"message,omitempty"`
+	ErrorName     string `json:"errorName,omitempty"`
+	FailedSubTask string `json:"failedSubTask,omitempty"`
+	BeganAt       string `json:"beganAt,omitempty"`
+	FinishedAt    string `json:"finishedAt,omitempty"`
+}
+
+// TaskListResponse is the response from GET /pipelines/{id}/tasks.
+type TaskListResponse struct {
+	Tasks []Task `json:"tasks"`
+	Count int    `json:"count"`
+}
@@ -2,8 +2,12 @@
 package docker
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os/exec"
+	"strconv"
 	"strings"
 )
 
@@ -43,6 +47,31 @@ func TagAndPush(localTag, remoteTag string) error {
 	return nil
 }
 
+// Pull pulls an image from its registry.
+func Pull(image string) error {
+	if out, err := execCommand("docker", "pull", image).CombinedOutput(); err != nil {
+		return fmt.Errorf("docker pull %s failed: %s\n%s", image, err, string(out))
+	}
+	return nil
+}
+
+// Save writes images to a tar archive that Load can read back.
+func Save(dest string, images ...string) error {
+	args := append([]string{"save", "-o", dest}, images...)
+	if out, err := execCommand("docker", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("docker save failed: %s\n%s", err, string(out))
+	}
+	return nil
+}
+
+// Load loads images from an archive written by Save.
+func Load(src string) error {
+	if out, err := execCommand("docker", "load", "-i", src).CombinedOutput(); err != nil {
+		return fmt.Errorf("docker load failed: %s\n%s", err, string(out))
+	}
+	return nil
+}
+
 // ComposeDown runs docker compose down in the specified directory.
 // If removeVolumes is true, it passes the -v flag to also remove data volumes.
 // Images built from local Dockerfiles are always removed (--rmi local).
@@ -74,6 +103,98 @@ func ComposeStop(dir string, services ...string) error {
 	return nil
 }
 
+// ComposePull runs docker compose pull in the specified directory so that a
+// subsequent ComposeUp recreates containers on the updated images.
+func ComposePull(dir string) error {
+	cmd := execCommand("docker", "compose", "pull")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("docker compose pull failed: %s\n%s", err, string(out))
+	}
+	return nil
+}
+
+// ComposeExec runs a command inside a running compose service without a TTY,
+// streaming stdin and stdout. Stderr is included in the returned error.
+func ComposeExec(dir, service string, stdin io.Reader, stdout io.Writer, command ...string) error {
+	args := append([]string{"compose", "exec", "-T", service}, command...)
+	cmd := execCommand("docker", args...)
+	cmd.Dir = dir
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("docker compose exec %s failed: %s\n%s", service, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// ComposeService is one container listed by docker compose ps.
+type ComposeService struct {
+	Service string `json:"Service"`
+	Name    string `json:"Name"`
+	Image   string `json:"Image"`
+	State   string `json:"State"`
+	Status  string `json:"Status"`
+	Health  string `json:"Health"`
+}
+
+// ComposePS lists the containers of the compose project in dir.
+func ComposePS(dir string) ([]ComposeService, error) {
+	cmd := execCommand("docker", "compose", "ps", "--all", "--format", "json")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("docker compose ps failed: %w", err)
+	}
+	return ParseComposePS(out)
+}
+
+// ComposeLogs returns the last tail log lines of a compose service, with
+// timestamps. since limits the logs to a duration (e.g. "24h") when set.
+func ComposeLogs(dir, service string, tail int, since string) ([]byte, error) {
+	args := []string{"compose", "logs", "--no-color", "--timestamps", "--tail", strconv.Itoa(tail)}
+	if since != "" {
+		args = append(args, "--since", since)
+	}
+	args = append(args, service)
+	cmd := execCommand("docker", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("docker compose logs %s failed: %s\n%s", service, err, strings.TrimSpace(string(out)))
+	}
+	return out, nil
+}
+
+// ParseComposePS reads docker compose ps --format json output: a JSON array
+// before Compose 2.21, one object per line since.
+func ParseComposePS(data []byte) ([]ComposeService, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var services []ComposeService
+	if data[0] == '[' {
+		if err := json.Unmarshal(data, &services); err != nil {
+			return nil, fmt.Errorf("parsing docker compose ps output: %w", err)
+		}
+		return services, nil
+	}
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var s ComposeService
+		if err := json.Unmarshal(line, &s); err != nil {
+			return nil, fmt.Errorf("parsing docker compose ps output: %w", err)
+		}
+		services = append(services, s)
+	}
+	return services, nil
+}
+
 // ComposeUp runs docker compose up -d in the specified directory.
 // If build is true, images are rebuilt from local Dockerfiles (--build).
 // If services are provided, only those services are started.
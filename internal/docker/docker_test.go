@@ -1,6 +1,7 @@
 package docker
 
 import (
+	"io"
 	"os"
 	"os/exec"
 	"reflect"
@@ -149,6 +150,32 @@ func TestComposeDown_CommandArgs(t *testing.T) {
 	}
 }
 
+func TestComposePull_CommandArgs(t *testing.T) {
+	var captured []string
+	execCommand = fakeExecCommand(&captured)
+	t.Cleanup(func() { execCommand = exec.Command })
+
+	_ = ComposePull(t.TempDir())
+
+	want := []string{"docker", "compose", "pull"}
+	if !reflect.DeepEqual(captured, want) {
+		t.Errorf("args = %v, want %v", captured, want)
+	}
+}
+
+func TestComposeExec_CommandArgs(t *testing.T) {
+	var captured []string
+	execCommand = fakeExecCommand(&captured)
+	t.Cleanup(func() { execCommand = exec.Command })
+
+	_ = ComposeExec(t.TempDir(), "mysql", nil, io.Discard, "mysqldump", "lake")
+
+	want := []string{"docker", "compose", "exec", "-T", "mysql", "mysqldump", "lake"}
+	if !reflect.DeepEqual(captured, want) {
+		t.Errorf("args = %v, want %v", captured, want)
+	}
+}
+
 func TestBuild_CommandArgs(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -187,3 +214,45 @@ func TestBuild_CommandArgs(t *testing.T) {
 		})
 	}
 }
+
+func TestSaveLoad_CommandArgs(t *testing.T) {
+	var captured []string
+	execCommand = fakeExecCommand(&captured)
+	t.Cleanup(func() { execCommand = exec.Command })
+
+	_ = Save("images.tar", "mysql:8", "apache/devlake:v1.0.2")
+	want := []string{"docker", "save", "-o", "images.tar", "mysql:8", "apache/devlake:v1.0.2"}
+	if !reflect.DeepEqual(captured, want) {
+		t.Errorf("save args = %v, want %v", captured, want)
+	}
+
+	_ = Load("images.tar")
+	want = []string{"docker", "load", "-i", "images.tar"}
+	if !reflect.DeepEqual(captured, want) {
+		t.Errorf("load args = %v, want %v", captured, want)
+	}
+}
+
+func TestParseComposePS(t *testing.T) {
+	want := []ComposeService{
+		{Service: "devlake", Name: "devlake-devlake-1", Image: "devlake.docker.scarf.sh/apache/devlake:v1.0.2", State: "running"},
+		{Service: "mysql", Name: "devlake-mysql-1", Image: "mysql:8", State: "exited"},
+	}
+	lines := `{"Service":"devlake","Name":"devlake-devlake-1","Image":"devlake.docker.scarf.sh/apache/devlake:v1.0.2","State":"running","Ports":""}
+{"Service":"mysql","Name":"devlake-mysql-1","Image":"mysql:8","State":"exited"}
+`
+	array := `[{"Service":"devlake","Name":"devlake-devlake-1","Image":"devlake.docker.scarf.sh/apache/devlake:v1.0.2","State":"running"},
+{"Service":"mysql","Name":"devlake-mysql-1","Image":"mysql:8","State":"exited"}]`
+	for name, data := range map[string]string{"lines": lines, "array": array} {
+		got, err := ParseComposePS([]byte(data))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %+v", name, got)
+		}
+	}
+	if got, err := ParseComposePS([]byte("\n")); err != nil || got != nil {
+		t.Errorf("empty output = %v, %v", got, err)
+	}
+}
@@ -0,0 +1,184 @@
+package docker
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// OverrideFile is merged automatically by docker compose on top of
+// docker-compose.yml in the same directory.
+const OverrideFile = "docker-compose.override.yml"
+
+// Container ports of the DevLake compose services.
+const (
+	BackendContainerPort  = 8080
+	GrafanaContainerPort  = 3000
+	ConfigUIContainerPort = 4000
+	MySQLContainerPort    = 3306
+)
+
+// Host ports published by the upstream compose file.
+const (
+	DefaultBackendHostPort  = 8080
+	DefaultGrafanaHostPort  = 3002
+	DefaultConfigUIHostPort = 4000
+	DefaultMySQLHostPort    = 3306
+)
+
+// Override holds local deployment settings that differ from the upstream
+// compose file. Zero values keep the upstream setting.
+type Override struct {
+	BackendPort int
+	GrafanaPort int
+	UIPort      int
+	MySQLPort   int
+	BindAddress string // host address ports are published on; empty = all interfaces
+	MemoryLimit string // devlake service limit, e.g. "4g"
+	Network     string // existing external network to join
+	ProjectName string // compose project name; prefixes containers and volumes
+}
+
+const overrideHeader = "# Generated by gh devlake deploy local — re-run deploy to change.\n"
+
+var memoryLimitRe = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?[bkmgBKMG]?$`)
+
+// IsZero reports whether o changes nothing.
+func (o Override) IsZero() bool {
+	return o == Override{}
+}
+
+// Validate checks port ranges, port clashes and the memory limit format.
+func (o Override) Validate() error {
+	seen := map[int]string{}
+	for _, p := range []struct {
+		flag string
+		port int
+	}{
+		{"--backend-port", o.BackendPort},
+		{"--grafana-port", o.GrafanaPort},
+		{"--ui-port", o.UIPort},
+		{"--mysql-port", o.MySQLPort},
+	} {
+		if p.port == 0 {
+			continue
+		}
+		if p.port < 1 || p.port > 65535 {
+			return fmt.Errorf("%s must be between 1 and 65535, got %d", p.flag, p.port)
+		}
+		if other, ok := seen[p.port]; ok {
+			return fmt.Errorf("%s and %s both use port %d", other, p.flag, p.port)
+		}
+		seen[p.port] = p.flag
+	}
+	if o.BindAddress != "" && net.ParseIP(o.BindAddress) == nil {
+		return fmt.Errorf("--bind-address %q is not an IP address", o.BindAddress)
+	}
+	if o.MemoryLimit != "" && !memoryLimitRe.MatchString(o.MemoryLimit) {
+		return fmt.Errorf("--memory-limit %q is not a Docker memory size (e.g. 512m, 4g)", o.MemoryLimit)
+	}
+	if strings.ContainsAny(o.Network, " \t/:") {
+		return fmt.Errorf("--network %q is not a valid network name", o.Network)
+	}
+	return nil
+}
+
+// Host returns the host name the published ports are reached at: localhost,
+// unless ports are bound to one specific non-loopback address.
+func (o Override) Host() string {
+	ip := net.ParseIP(o.BindAddress)
+	if ip == nil || ip.IsLoopback() || ip.IsUnspecified() {
+		return "localhost"
+	}
+	if ip.To4() == nil {
+		return "[" + o.BindAddress + "]"
+	}
+	return o.BindAddress
+}
+
+// Render returns the override file content. Port lists use the !override
+// tag (Docker Compose 2.24+) so they replace the upstream mappings instead
+// of being appended to them. Like upstream, ports are published on all
+// interfaces unless BindAddress is set; then every service is re-published
+// on that address, at its default port when no other is given.
+func (o Override) Render() string {
+	var b strings.Builder
+	b.WriteString(overrideHeader)
+	if o.ProjectName != "" {
+		fmt.Fprintf(&b, "name: %s\n", o.ProjectName)
+	}
+	b.WriteString("services:\n")
+	services := []struct {
+		name          string
+		host, target  int
+		defaultHost   int
+		memoryLimited bool
+	}{
+		{"devlake", o.BackendPort, BackendContainerPort, DefaultBackendHostPort, true},
+		{"grafana", o.GrafanaPort, GrafanaContainerPort, DefaultGrafanaHostPort, false},
+		{"config-ui", o.UIPort, ConfigUIContainerPort, DefaultConfigUIHostPort, false},
+		{"mysql", o.MySQLPort, MySQLContainerPort, DefaultMySQLHostPort, false},
+	}
+	bind := ""
+	if o.BindAddress != "" {
+		bind = o.BindAddress + ":"
+		if strings.Contains(o.BindAddress, ":") {
+			bind = "[" + o.BindAddress + "]:"
+		}
+	}
+	empty := true
+	for _, s := range services {
+		if s.host == 0 && bind != "" {
+			s.host = s.defaultHost
+		}
+		limit := s.memoryLimited && o.MemoryLimit != ""
+		if s.host == 0 && !limit {
+			continue
+		}
+		empty = false
+		fmt.Fprintf(&b, "  %s:\n", s.name)
+		if s.host != 0 {
+			b.WriteString("    ports: !override\n")
+			fmt.Fprintf(&b, "      - \"%s%d:%d\"\n", bind, s.host, s.target)
+		}
+		if limit {
+			b.WriteString("    deploy:\n      resources:\n        limits:\n")
+			fmt.Fprintf(&b, "          memory: %s\n", o.MemoryLimit)
+		}
+	}
+	if empty {
+		b.WriteString("  {}\n")
+	}
+	if o.Network != "" {
+		b.WriteString("networks:\n  default:\n")
+		fmt.Fprintf(&b, "    name: %s\n    external: true\n", o.Network)
+	}
+	return b.String()
+}
+
+// WriteOverride writes the override file into dir. When o is zero, a file
+// generated earlier is removed so the upstream settings apply again; a
+// hand-written override file is never touched.
+func WriteOverride(dir string, o Override) error {
+	path := filepath.Join(dir, OverrideFile)
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(existing) > 0 && !strings.HasPrefix(string(existing), overrideHeader) {
+		if o.IsZero() {
+			return nil
+		}
+		return fmt.Errorf("%s exists and was not generated by gh devlake — merge the settings into it by hand or remove it", OverrideFile)
+	}
+	if o.IsZero() {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return os.WriteFile(path, []byte(o.Render()), 0644)
+}
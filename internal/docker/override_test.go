@@ -0,0 +1,129 @@
+package docker
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOverrideValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		o       Override
+		wantErr bool
+	}{
+		{"zero", Override{}, false},
+		{"ports", Override{BackendPort: 18080, GrafanaPort: 13002, UIPort: 14000, MySQLPort: 13306}, false},
+		{"out of range", Override{BackendPort: 70000}, true},
+		{"clash", Override{BackendPort: 9000, UIPort: 9000}, true},
+		{"memory", Override{MemoryLimit: "4g"}, false},
+		{"memory decimal", Override{MemoryLimit: "1.5G"}, false},
+		{"bad memory", Override{MemoryLimit: "lots"}, true},
+		{"network", Override{Network: "shared-net"}, false},
+		{"bad network", Override{Network: "a b"}, true},
+		{"bind address", Override{BindAddress: "127.0.0.1"}, false},
+		{"bind address v6", Override{BindAddress: "::1"}, false},
+		{"bad bind address", Override{BindAddress: "localhost"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.o.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOverrideRender(t *testing.T) {
+	got := Override{BackendPort: 18080, MySQLPort: 13306, MemoryLimit: "4g", Network: "shared"}.Render()
+	want := overrideHeader + `services:
+  devlake:
+    ports: !override
+      - "18080:8080"
+    deploy:
+      resources:
+        limits:
+          memory: 4g
+  mysql:
+    ports: !override
+      - "13306:3306"
+networks:
+  default:
+    name: shared
+    external: true
+`
+	if got != want {
+		t.Errorf("Render() =\n%s\nwant\n%s", got, want)
+	}
+
+	if got := (Override{Network: "shared"}).Render(); !strings.Contains(got, "services:\n  {}\n") {
+		t.Errorf("network-only render should have empty services:\n%s", got)
+	}
+	if got := (Override{ProjectName: "devlake-staging"}).Render(); got != overrideHeader+"name: devlake-staging\nservices:\n  {}\n" {
+		t.Errorf("project-only render =\n%s", got)
+	}
+}
+
+func TestOverrideRenderBindAddress(t *testing.T) {
+	got := Override{BindAddress: "127.0.0.1", UIPort: 14000}.Render()
+	for _, want := range []string{
+		`- "127.0.0.1:8080:8080"`,
+		`- "127.0.0.1:3002:3000"`,
+		`- "127.0.0.1:14000:4000"`,
+		`- "127.0.0.1:3306:3306"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Render() is missing %s:\n%s", want, got)
+		}
+	}
+	if got := (Override{BindAddress: "::1", BackendPort: 18080}).Render(); !strings.Contains(got, `- "[::1]:18080:8080"`) {
+		t.Errorf("IPv6 render =\n%s", got)
+	}
+
+	for addr, want := range map[string]string{
+		"":            "localhost",
+		"127.0.0.1":   "localhost",
+		"0.0.0.0":     "localhost",
+		"192.168.1.5": "192.168.1.5",
+		"fd00::5":     "[fd00::5]",
+	} {
+		if got := (Override{BindAddress: addr}).Host(); got != want {
+			t.Errorf("Host() with %q = %q, want %q", addr, got, want)
+		}
+	}
+}
+
+func TestWriteOverride(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, OverrideFile)
+
+	if err := WriteOverride(dir, Override{UIPort: 14000}); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); !strings.Contains(string(data), `- "14000:4000"`) {
+		t.Errorf("override not written:\n%s", data)
+	}
+
+	// Zero settings remove a generated file.
+	if err := WriteOverride(dir, Override{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("generated override not removed: %v", err)
+	}
+
+	// A hand-written file is left alone, and not overwritten.
+	if err := os.WriteFile(path, []byte("services: {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteOverride(dir, Override{}); err != nil {
+		t.Errorf("zero override with hand-written file: %v", err)
+	}
+	if err := WriteOverride(dir, Override{UIPort: 14000}); err == nil {
+		t.Error("expected error overwriting a hand-written override")
+	}
+	if data, _ := os.ReadFile(path); string(data) != "services: {}\n" {
+		t.Errorf("hand-written override changed:\n%s", data)
+	}
+}
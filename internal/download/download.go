@@ -2,36 +2,180 @@
 package download
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 )
 
 var httpClient = &http.Client{Timeout: 60 * time.Second}
 
+// Retry settings for Fetch; tests shorten the wait.
+var (
+	fetchAttempts  = 3
+	fetchRetryWait = 2 * time.Second
+)
+
+// Options controls a download made with Fetch.
+type Options struct {
+	SHA256   string    // expected checksum (hex); empty skips verification
+	Progress io.Writer // where to draw a progress bar; nil for none
+	Label    string    // printed in front of the progress bar
+}
+
+// ChecksumError is returned when a downloaded file does not match its
+// expected checksum.
+type ChecksumError struct {
+	URL       string
+	Want, Got string
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("checksum mismatch for %s: got sha256 %s, expected %s", e.URL, e.Got, e.Want)
+}
+
 // File downloads a URL to the given destination path.
 func File(url, destPath string) error {
-	resp, err := httpClient.Get(url)
+	return Fetch(url, destPath, Options{})
+}
+
+// Fetch downloads a URL to destPath through destPath+".part". An interrupted
+// download is resumed with a Range request, both on retry and on the next
+// run. If opts.SHA256 is set the file is verified before it is renamed into
+// place; on a mismatch nothing is written to destPath.
+func Fetch(url, destPath string, opts Options) error {
+	part := destPath + ".part"
+	resumed := false
+	if info, err := os.Stat(part); err == nil && info.Size() > 0 {
+		resumed = true
+	}
+
+	var err error
+	for attempt := 1; attempt <= fetchAttempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(fetchRetryWait)
+		}
+		var retry bool
+		if retry, err = fetchPart(url, part, opts); err == nil || !retry {
+			break
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if opts.SHA256 != "" {
+		got, err := fileSHA256(part)
+		if err != nil {
+			return err
+		}
+		if !strings.EqualFold(got, opts.SHA256) {
+			os.Remove(part)
+			// A leftover .part may be from a different file; start over once.
+			if resumed {
+				return Fetch(url, destPath, opts)
+			}
+			return &ChecksumError{URL: url, Want: strings.ToLower(opts.SHA256), Got: got}
+		}
+	}
+	return os.Rename(part, destPath)
+}
+
+// fetchPart makes one request, appending to part from its current size. It
+// reports whether a failure is worth another attempt: network errors and
+// server-side statuses are, the rest are not.
+func fetchPart(url, part string, opts Options) (bool, error) {
+	var offset int64
+	if info, err := os.Stat(part); err == nil {
+		offset = info.Size()
+	}
+	req, err := http.NewRequest(http.MethodGet, url, nil)
 	if err != nil {
-		return fmt.Errorf("download %s: %w", url, err)
+		return false, err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("download %s: %w", url, err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("download %s returned %d", url, resp.StatusCode)
+	flags := os.O_CREATE | os.O_WRONLY
+	total := resp.ContentLength
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && rangeStart(resp) == offset:
+		flags |= os.O_APPEND
+		if total >= 0 {
+			total += offset
+		}
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// The part is already complete
+		return false, nil
+	case resp.StatusCode == http.StatusOK:
+		// The server ignored the Range header: start over
+		flags |= os.O_TRUNC
+		offset = 0
+	default:
+		code := resp.StatusCode
+		return code >= 500 || code == http.StatusTooManyRequests, fmt.Errorf("download %s returned %d", url, code)
 	}
 
-	f, err := os.Create(destPath)
+	f, err := os.OpenFile(part, flags, 0644)
 	if err != nil {
-		return err
+		return false, err
 	}
-	defer f.Close()
+	var w io.Writer = f
+	var bar *progressBar
+	if opts.Progress != nil {
+		bar = newProgressBar(opts.Progress, opts.Label, offset, total)
+		w = io.MultiWriter(f, bar)
+	}
+	_, err = io.Copy(w, resp.Body)
+	if bar != nil {
+		bar.finish()
+	}
+	if cerr := f.Close(); cerr != nil && err == nil {
+		return false, cerr
+	}
+	if err != nil {
+		return true, fmt.Errorf("download %s: %w", url, err)
+	}
+	return false, nil
+}
 
-	_, err = io.Copy(f, resp.Body)
-	return err
+// rangeStart returns the first byte of a 206 response's Content-Range.
+func rangeStart(resp *http.Response) int64 {
+	cr := strings.TrimPrefix(resp.Header.Get("Content-Range"), "bytes ")
+	start, _, ok := strings.Cut(cr, "-")
+	if !ok {
+		return -1
+	}
+	n, err := strconv.ParseInt(start, 10, 64)
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
 }
 
 // GitHubLatestTag fetches the latest release tag_name from a GitHub repo.
@@ -58,3 +202,26 @@ func GitHubLatestTag(owner, repo string) (string, error) {
 	}
 	return release.TagName, nil
 }
+
+// GitHubReleaseNotes fetches the body and web URL of a GitHub release by tag.
+func GitHubReleaseNotes(owner, repo, tag string) (body, htmlURL string, err error) {
+	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases/tags/%s", owner, repo, tag)
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return "", "", fmt.Errorf("fetch release %s: %w", tag, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("GitHub releases API returned %d for %s", resp.StatusCode, tag)
+	}
+
+	var release struct {
+		Body    string `json:"body"`
+		HTMLURL string `json:"html_url"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return "", "", err
+	}
+	return release.Body, release.HTMLURL, nil
+}
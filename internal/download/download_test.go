@@ -0,0 +1,228 @@
+package download
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testContent = []byte(strings.Repeat("services:\n  devlake:\n    image: apache/devlake:v1.0.2\n", 100))
+
+func testSum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func serveContent(t *testing.T, data []byte, ranges *[]string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ranges != nil {
+			*ranges = append(*ranges, r.Header.Get("Range"))
+		}
+		http.ServeContent(w, r, "docker-compose.yml", time.Time{}, bytes.NewReader(data))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestFetchResumesPart(t *testing.T) {
+	var ranges []string
+	srv := serveContent(t, testContent, &ranges)
+	dest := filepath.Join(t.TempDir(), "docker-compose.yml")
+	os.WriteFile(dest+".part", testContent[:1000], 0644)
+
+	var progress bytes.Buffer
+	opts := Options{SHA256: testSum(testContent), Progress: &progress, Label: "compose"}
+	if err := Fetch(srv.URL, dest, opts); err != nil {
+		t.Fatal(err)
+	}
+	if len(ranges) != 1 || ranges[0] != "bytes=1000-" {
+		t.Errorf("Range headers = %q", ranges)
+	}
+	if data, _ := os.ReadFile(dest); !bytes.Equal(data, testContent) {
+		t.Error("resumed file differs from the original")
+	}
+	if _, err := os.Stat(dest + ".part"); !os.IsNotExist(err) {
+		t.Error(".part file was left behind")
+	}
+	if !strings.Contains(progress.String(), "100%") {
+		t.Errorf("progress = %q", progress.String())
+	}
+}
+
+func TestFetchChecksumMismatch(t *testing.T) {
+	srv := serveContent(t, testContent, nil)
+	dest := filepath.Join(t.TempDir(), "docker-compose.yml")
+
+	err := Fetch(srv.URL, dest, Options{SHA256: testSum([]byte("something else"))})
+	var mismatch *ChecksumError
+	if !errors.As(err, &mismatch) || mismatch.Got != testSum(testContent) {
+		t.Fatalf("error = %v", err)
+	}
+	for _, path := range []string{dest, dest + ".part"} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s exists after a checksum mismatch", filepath.Base(path))
+		}
+	}
+}
+
+func TestFetchStalePartStartsOver(t *testing.T) {
+	srv := serveContent(t, testContent, nil)
+	dest := filepath.Join(t.TempDir(), "docker-compose.yml")
+	// A leftover from another release: resuming it produces a bad file
+	os.WriteFile(dest+".part", []byte("old release"), 0644)
+
+	if err := Fetch(srv.URL, dest, Options{SHA256: testSum(testContent)}); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(dest); !bytes.Equal(data, testContent) {
+		t.Error("file was not downloaded again")
+	}
+}
+
+func TestFetchRetries(t *testing.T) {
+	fetchRetryWait = 0
+	t.Cleanup(func() { fetchRetryWait = 2 * time.Second })
+
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch {
+		case r.URL.Path == "/missing":
+			http.NotFound(w, r)
+		case calls == 1:
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			w.Write(testContent)
+		}
+	}))
+	defer srv.Close()
+	dir := t.TempDir()
+
+	if err := Fetch(srv.URL+"/file", filepath.Join(dir, "file"), Options{}); err != nil || calls != 2 {
+		t.Errorf("err = %v after %d calls", err, calls)
+	}
+	calls = 0
+	if err := Fetch(srv.URL+"/missing", filepath.Join(dir, "missing"), Options{}); err == nil || calls != 1 {
+		t.Errorf("404 should fail without a retry: err = %v after %d calls", err, calls)
+	}
+}
+
+func TestReleaseChecksum(t *testing.T) {
+	sum := testSum(testContent)
+	assets := map[string]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := assets[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+	githubBase = srv.URL
+	t.Cleanup(func() { githubBase = "https://github.com" })
+
+	rel := Release{Owner: "apache", Repo: "incubator-devlake", Tag: "v9.9.9"}
+	const base = "/apache/incubator-devlake/releases/download/v9.9.9/"
+
+	if got, source, err := rel.Checksum("docker-compose.yml"); got != "" || source != "" || err != nil {
+		t.Errorf("unpublished checksum = %q, %q, %v", got, source, err)
+	}
+
+	assets[base+"docker-compose.yml.sha256"] = strings.ToUpper(sum) + "\n"
+	if got, source, _ := rel.Checksum("docker-compose.yml"); got != sum || source != "release docker-compose.yml.sha256" {
+		t.Errorf(".sha256 asset = %q, %q", got, source)
+	}
+
+	assets[base+"SHA256SUMS"] = testSum([]byte("env")) + "  env.example\n" + sum + " *docker-compose.yml\n"
+	if got, source, _ := rel.Checksum("docker-compose.yml"); got != sum || source != "release SHA256SUMS" {
+		t.Errorf("SHA256SUMS = %q, %q", got, source)
+	}
+
+	pinned = map[string]map[string]map[string]string{"apache/incubator-devlake": {"v9.9.9": {"docker-compose.yml": "pinned-sum"}}}
+	t.Cleanup(func() { pinned = parsePinned(pinnedJSON) })
+	if got, source, _ := rel.Checksum("docker-compose.yml"); got != "pinned-sum" || source != SourcePinned {
+		t.Errorf("pinned = %q, %q", got, source)
+	}
+}
+
+func TestPinnedChecksums(t *testing.T) {
+	var m map[string]map[string]map[string]string
+	if err := json.Unmarshal(pinnedJSON, &m); err != nil {
+		t.Fatalf("checksums.json is invalid: %v", err)
+	}
+	for repo, tags := range m {
+		for tag, sums := range tags {
+			for name, sum := range sums {
+				if !isSHA256(sum) {
+					t.Errorf("%s %s %s: %q is not a SHA-256", repo, tag, name, sum)
+				}
+			}
+		}
+	}
+}
+
+func TestReleaseDownloadWithoutChecksum(t *testing.T) {
+	assets := map[string][]byte{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := assets[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(body)
+	}))
+	defer srv.Close()
+	githubBase = srv.URL
+	t.Cleanup(func() { githubBase = "https://github.com" })
+
+	rel := Release{Owner: "apache", Repo: "incubator-devlake", Tag: "v9.9.9"}
+	const base = "/apache/incubator-devlake/releases/download/v9.9.9/"
+	assets[base+"docker-compose.yml"] = testContent
+	dest := filepath.Join(t.TempDir(), "docker-compose.yml")
+
+	if source, err := rel.Download("docker-compose.yml", dest, Options{}, false); err != nil || source != "" {
+		t.Fatalf("no checksum = %q, %v", source, err)
+	}
+	os.Remove(dest)
+
+	if source, err := rel.Download("docker-compose.yml", dest, Options{}, true); err != nil || source != "" {
+		t.Fatalf("skip verify = %q, %v", source, err)
+	}
+	os.Remove(dest)
+
+	assets[base+"SHA256SUMS"] = []byte(testSum(testContent) + "  docker-compose.yml\n")
+	if source, err := rel.Download("docker-compose.yml", dest, Options{}, false); err != nil || source != "release SHA256SUMS" {
+		t.Fatalf("verified = %q, %v", source, err)
+	}
+
+	// A caller-supplied sum is ignored: only the release's checksum counts
+	os.Remove(dest)
+	if _, err := rel.Download("docker-compose.yml", dest, Options{SHA256: "bogus"}, false); err != nil {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestParseChecksum(t *testing.T) {
+	sum := testSum(testContent)
+	cases := map[string]string{
+		"SHA256 (docker-compose.yml) = " + sum + "\n": sum,
+		sum + "  other.yml\n":                         "",
+		"not a checksum\n":                            "",
+	}
+	for data, want := range cases {
+		if got, _ := parseChecksum([]byte(data), "docker-compose.yml"); got != want {
+			t.Errorf("parseChecksum(%q) = %q, want %q", data, got, want)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+// Command pinsums records the SHA-256 of DevLake release files in
+// internal/download/checksums.json, the checksums the CLI verifies
+// downloads against. Run it from the repository root:
+//
+//	go run ./internal/download/pinsums v1.0.2 v1.0.3
+//
+// Each file is downloaded twice and must hash the same both times. Review
+// the printed sums before committing the updated manifest.
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+const (
+	repo     = "apache/incubator-devlake"
+	manifest = "internal/download/checksums.json"
+)
+
+// files are the release assets the CLI downloads (cmd.officialReleaseFiles).
+var files = []string{"docker-compose.yml", "env.example"}
+
+var client = &http.Client{Timeout: 60 * time.Second}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: go run ./internal/download/pinsums <tag>...")
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, "pinsums:", err)
+		os.Exit(1)
+	}
+}
+
+func run(tags []string) error {
+	data, err := os.ReadFile(manifest)
+	if err != nil {
+		return err
+	}
+	pinned := map[string]map[string]map[string]string{}
+	if err := json.Unmarshal(data, &pinned); err != nil {
+		return fmt.Errorf("%s: %w", manifest, err)
+	}
+	if pinned[repo] == nil {
+		pinned[repo] = map[string]map[string]string{}
+	}
+
+	for _, tag := range tags {
+		sums := map[string]string{}
+		for _, name := range files {
+			url := fmt.Sprintf("https://github.com/%s/releases/download/%s/%s", repo, tag, name)
+			first, err := hashURL(url)
+			if err != nil {
+				return err
+			}
+			second, err := hashURL(url)
+			if err != nil {
+				return err
+			}
+			if first != second {
+				return fmt.Errorf("%s changed between two downloads (%s, %s)", url, first, second)
+			}
+			sums[name] = first
+			fmt.Printf("%s  %s %s\n", first, tag, name)
+		}
+		pinned[repo][tag] = sums
+	}
+
+	out, err := json.MarshalIndent(pinned, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(manifest, append(out, '\n'), 0644)
+}
+
+func hashURL(url string) (string, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("download %s returned %d", url, resp.StatusCode)
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, resp.Body); err != nil {
+		return "", fmt.Errorf("download %s: %w", url, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
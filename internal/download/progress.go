@@ -0,0 +1,65 @@
+package download
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const barWidth = 24
+
+// progressBar redraws a single line as bytes are written to it.
+type progressBar struct {
+	w     io.Writer
+	label string
+	done  int64
+	total int64 // -1 when the server sent no length
+	drawn time.Time
+}
+
+func newProgressBar(w io.Writer, label string, done, total int64) *progressBar {
+	return &progressBar{w: w, label: label, done: done, total: total}
+}
+
+func (b *progressBar) Write(p []byte) (int, error) {
+	b.done += int64(len(p))
+	if time.Since(b.drawn) >= 100*time.Millisecond {
+		b.draw()
+	}
+	return len(p), nil
+}
+
+// finish draws the final state. The line is left open so the caller can
+// append a result to it.
+func (b *progressBar) finish() {
+	b.draw()
+}
+
+func (b *progressBar) draw() {
+	b.drawn = time.Now()
+	if b.total <= 0 {
+		fmt.Fprintf(b.w, "\r%s %s", b.label, formatBytes(b.done))
+		return
+	}
+	frac := float64(b.done) / float64(b.total)
+	if frac > 1 {
+		frac = 1
+	}
+	filled := int(frac * barWidth)
+	fmt.Fprintf(b.w, "\r%s [%s%s] %3.0f%% %s / %s", b.label,
+		strings.Repeat("█", filled), strings.Repeat("░", barWidth-filled),
+		frac*100, formatBytes(b.done), formatBytes(b.total))
+}
+
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
@@ -0,0 +1,172 @@
+// Package instance tracks named local DevLake deployments so commands can
+// target one by name from any directory.
+//
+// Each instance lives in its own directory with its own compose project
+// name (devlake-<name>), host port block and state file. The registry is a
+// JSON file in the user's config directory mapping names to directories.
+package instance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// ConfigDir is a variable so tests can redirect the registry.
+var ConfigDir = os.UserConfigDir
+
+// PortBlockStep is the offset between the host port blocks of instances:
+// block 1 publishes the backend on 8090, block 2 on 8100, and so on.
+const PortBlockStep = 10
+
+// ErrNotFound is returned when no instance with the given name is registered.
+var ErrNotFound = errors.New("instance not found")
+
+var nameRe = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,29}$`)
+
+// Instance is a registered named local deployment.
+type Instance struct {
+	Name      string `json:"name"`
+	Dir       string `json:"dir"`
+	PortBlock int    `json:"portBlock"`
+	CreatedAt string `json:"createdAt"`
+}
+
+// ProjectName is the compose project name, which also prefixes container
+// and volume names.
+func (i Instance) ProjectName() string {
+	return ProjectName(i.Name)
+}
+
+// StateFile is the path of the instance's state file.
+func (i Instance) StateFile() string {
+	return filepath.Join(i.Dir, ".devlake-local.json")
+}
+
+// ProjectName returns the compose project name for an instance name.
+func ProjectName(name string) string {
+	return "devlake-" + name
+}
+
+// ValidateName checks that name is usable in compose project, container and
+// volume names.
+func ValidateName(name string) error {
+	if !nameRe.MatchString(name) {
+		return fmt.Errorf("invalid instance name %q — use up to 30 lowercase letters, digits and dashes", name)
+	}
+	return nil
+}
+
+func registryPath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("locating config directory: %w", err)
+	}
+	return filepath.Join(dir, "gh-devlake", "instances.json"), nil
+}
+
+// List returns the registered instances sorted by name.
+func List() ([]Instance, error) {
+	path, err := registryPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var list []Instance
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("invalid instance registry %s: %w", path, err)
+	}
+	sort.Slice(list, func(a, b int) bool { return list[a].Name < list[b].Name })
+	return list, nil
+}
+
+// Get returns the named instance, or ErrNotFound.
+func Get(name string) (*Instance, error) {
+	list, err := List()
+	if err != nil {
+		return nil, err
+	}
+	for _, inst := range list {
+		if inst.Name == name {
+			return &inst, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s — run 'gh devlake status --all' to list instances", ErrNotFound, name)
+}
+
+// Register adds or replaces an instance. CreatedAt is set when empty.
+func Register(inst Instance) error {
+	list, err := List()
+	if err != nil {
+		return err
+	}
+	if inst.CreatedAt == "" {
+		inst.CreatedAt = time.Now().Format(time.RFC3339)
+	}
+	out := []Instance{inst}
+	for _, existing := range list {
+		if existing.Name != inst.Name {
+			out = append(out, existing)
+		}
+	}
+	return save(out)
+}
+
+// Remove unregisters the named instance. Removing an unknown name is not an error.
+func Remove(name string) error {
+	list, err := List()
+	if err != nil {
+		return err
+	}
+	out := make([]Instance, 0, len(list))
+	for _, inst := range list {
+		if inst.Name != name {
+			out = append(out, inst)
+		}
+	}
+	if len(out) == len(list) {
+		return nil
+	}
+	return save(out)
+}
+
+// NextPortBlock returns the lowest port block (≥1) not used by list.
+// Block 0 is left to the default, unnamed deployment.
+func NextPortBlock(list []Instance) int {
+	used := map[int]bool{}
+	for _, inst := range list {
+		used[inst.PortBlock] = true
+	}
+	block := 1
+	for used[block] {
+		block++
+	}
+	return block
+}
+
+func save(list []Instance) error {
+	path, err := registryPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	sort.Slice(list, func(a, b int) bool { return list[a].Name < list[b].Name })
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
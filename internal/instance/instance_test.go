@@ -0,0 +1,86 @@
+package instance
+
+import (
+	"errors"
+	"testing"
+)
+
+func useTempRegistry(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	orig := ConfigDir
+	ConfigDir = func() (string, error) { return dir, nil }
+	t.Cleanup(func() { ConfigDir = orig })
+}
+
+func TestValidateName(t *testing.T) {
+	for name, ok := range map[string]bool{
+		"staging":                            true,
+		"v1-canary":                          true,
+		"a":                                  true,
+		"":                                   false,
+		"Staging":                            false,
+		"-lead":                              false,
+		"has_under":                          false,
+		"way-too-long-instance-name-over-30": false,
+	} {
+		if err := ValidateName(name); (err == nil) != ok {
+			t.Errorf("ValidateName(%q) error = %v, want ok=%v", name, err, ok)
+		}
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	useTempRegistry(t)
+
+	list, err := List()
+	if err != nil || len(list) != 0 {
+		t.Fatalf("empty registry = %v, %v", list, err)
+	}
+	if _, err := Get("staging"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get missing = %v, want ErrNotFound", err)
+	}
+
+	if err := Register(Instance{Name: "staging", Dir: "/srv/staging", PortBlock: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Register(Instance{Name: "canary", Dir: "/srv/canary", PortBlock: 2}); err != nil {
+		t.Fatal(err)
+	}
+	// Re-registering replaces the entry.
+	if err := Register(Instance{Name: "staging", Dir: "/srv/staging2", PortBlock: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	list, _ = List()
+	if len(list) != 2 || list[0].Name != "canary" || list[1].Dir != "/srv/staging2" {
+		t.Errorf("list = %+v", list)
+	}
+	got, err := Get("staging")
+	if err != nil || got.ProjectName() != "devlake-staging" || got.CreatedAt == "" {
+		t.Errorf("Get = %+v, %v", got, err)
+	}
+
+	if err := Remove("staging"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Remove("staging"); err != nil {
+		t.Errorf("removing twice: %v", err)
+	}
+	list, _ = List()
+	if len(list) != 1 || list[0].Name != "canary" {
+		t.Errorf("after remove = %+v", list)
+	}
+}
+
+func TestNextPortBlock(t *testing.T) {
+	if got := NextPortBlock(nil); got != 1 {
+		t.Errorf("empty = %d, want 1", got)
+	}
+	if got := NextPortBlock([]Instance{{PortBlock: 1}, {PortBlock: 3}}); got != 2 {
+		t.Errorf("gap = %d, want 2", got)
+	}
+	if got := NextPortBlock([]Instance{{PortBlock: 1}, {PortBlock: 2}}); got != 3 {
+		t.Errorf("full = %d, want 3", got)
+	}
+}
@@ -0,0 +1,130 @@
+package k8s
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// execCommand is a variable so tests can substitute a fake command runner.
+var execCommand = exec.Command
+
+// Kubectl runs kubectl against one context and namespace. An empty Context
+// uses the current kubeconfig context.
+type Kubectl struct {
+	Context   string
+	Namespace string
+}
+
+// CheckAvailable checks that kubectl is installed and can reach the cluster.
+func (k Kubectl) CheckAvailable() error {
+	if _, err := exec.LookPath("kubectl"); err != nil {
+		return fmt.Errorf("kubectl not found on PATH")
+	}
+	if _, err := k.output(false, "version", "-o", "json"); err != nil {
+		return fmt.Errorf("cannot reach the cluster: %w", err)
+	}
+	return nil
+}
+
+// CurrentContext returns the kubeconfig context kubectl will use.
+func (k Kubectl) CurrentContext() (string, error) {
+	if k.Context != "" {
+		return k.Context, nil
+	}
+	return k.output(false, "config", "current-context")
+}
+
+// Apply applies every manifest in dir.
+func (k Kubectl) Apply(dir string) error {
+	return k.run(false, "apply", "-f", dir)
+}
+
+// RolloutStatus waits for a deployment or statefulset rollout to finish.
+func (k Kubectl) RolloutStatus(kind, name, timeout string) error {
+	return k.run(true, "rollout", "status", kind+"/"+name, "--timeout", timeout)
+}
+
+// Scale sets the replica count of every deployment and statefulset
+// matching selector.
+func (k Kubectl) Scale(selector string, replicas int) error {
+	return k.run(true, "scale", "deployment,statefulset", "-l", selector, "--replicas", strconv.Itoa(replicas))
+}
+
+// Delete removes objects of the given kinds matching selector.
+func (k Kubectl) Delete(selector string, kinds ...string) error {
+	return k.run(true, "delete", strings.Join(kinds, ","), "-l", selector, "--ignore-not-found", "--wait=false")
+}
+
+// DeleteNamespace removes the namespace and everything in it.
+func (k Kubectl) DeleteNamespace() error {
+	return k.run(false, "delete", "namespace", k.Namespace, "--ignore-not-found", "--wait=false")
+}
+
+// SecretValue returns one decoded key of a Secret, or "" if the Secret or
+// key does not exist.
+func (k Kubectl) SecretValue(name, key string) (string, error) {
+	out, err := k.output(true, "get", "secret", name, "--ignore-not-found",
+		"-o", "jsonpath={.data."+key+"}")
+	if err != nil || out == "" {
+		return "", err
+	}
+	v, err := base64.StdEncoding.DecodeString(out)
+	if err != nil {
+		return "", fmt.Errorf("decoding secret %s/%s: %w", name, key, err)
+	}
+	return string(v), nil
+}
+
+// ServiceAddress returns the external IP or hostname of a LoadBalancer
+// service, or "" while it is still pending.
+func (k Kubectl) ServiceAddress(name string) (string, error) {
+	return k.output(true, "get", "service", name,
+		"-o", "jsonpath={.status.loadBalancer.ingress[0].ip}{.status.loadBalancer.ingress[0].hostname}")
+}
+
+// ReadyReplicas returns "ready/desired" for a deployment or statefulset.
+func (k Kubectl) ReadyReplicas(kind, name string) (string, error) {
+	out, err := k.output(true, "get", kind, name, "--ignore-not-found",
+		"-o", "jsonpath={.status.readyReplicas}/{.spec.replicas}")
+	if err != nil || out == "" {
+		return "", err
+	}
+	if strings.HasPrefix(out, "/") {
+		out = "0" + out
+	}
+	return out, nil
+}
+
+func (k Kubectl) args(namespaced bool, args ...string) []string {
+	var out []string
+	if k.Context != "" {
+		out = append(out, "--context", k.Context)
+	}
+	if namespaced && k.Namespace != "" {
+		out = append(out, "--namespace", k.Namespace)
+	}
+	return append(out, args...)
+}
+
+func (k Kubectl) run(namespaced bool, args ...string) error {
+	cmd := execCommand("kubectl", k.args(namespaced, args...)...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("kubectl %s failed: %s\n%s", args[0], err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func (k Kubectl) output(namespaced bool, args ...string) (string, error) {
+	cmd := execCommand("kubectl", k.args(namespaced, args...)...)
+	out, err := cmd.Output()
+	if err != nil {
+		if ee, ok := err.(*exec.ExitError); ok {
+			return "", fmt.Errorf("kubectl %s failed: %s", args[0], strings.TrimSpace(string(ee.Stderr)))
+		}
+		return "", fmt.Errorf("kubectl %s failed: %w", args[0], err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,78 @@
+package k8s
+
+import (
+	"os"
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+func fakeExecCommand(capturedArgs *[]string) func(string, ...string) *exec.Cmd {
+	return func(name string, args ...string) *exec.Cmd {
+		*capturedArgs = append([]string{name}, args...)
+		cmd := exec.Command(os.Args[0], "-test.run=TestHelperProcess", "--")
+		cmd.Env = append(os.Environ(), "GO_TEST_HELPER_NOOP=1")
+		return cmd
+	}
+}
+
+// TestHelperProcess is a no-op stand-in for kubectl during tests.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("GO_TEST_HELPER_NOOP") != "1" {
+		return
+	}
+	os.Exit(0)
+}
+
+func TestKubectlArgs(t *testing.T) {
+	sel := "app.kubernetes.io/part-of=devlake,app.kubernetes.io/instance=devlake"
+	tests := []struct {
+		name string
+		k    Kubectl
+		call func(Kubectl) error
+		want []string
+	}{
+		{
+			name: "apply is not namespaced",
+			k:    Kubectl{Namespace: "devlake"},
+			call: func(k Kubectl) error { return k.Apply("k8s") },
+			want: []string{"kubectl", "apply", "-f", "k8s"},
+		},
+		{
+			name: "scale with context",
+			k:    Kubectl{Context: "aks", Namespace: "devlake"},
+			call: func(k Kubectl) error { return k.Scale(sel, 0) },
+			want: []string{"kubectl", "--context", "aks", "--namespace", "devlake",
+				"scale", "deployment,statefulset", "-l", sel, "--replicas", "0"},
+		},
+		{
+			name: "delete kinds",
+			k:    Kubectl{Namespace: "devlake"},
+			call: func(k Kubectl) error { return k.Delete(sel, "service", "pvc") },
+			want: []string{"kubectl", "--namespace", "devlake",
+				"delete", "service,pvc", "-l", sel, "--ignore-not-found", "--wait=false"},
+		},
+		{
+			name: "rollout status",
+			k:    Kubectl{Namespace: "devlake"},
+			call: func(k Kubectl) error { return k.RolloutStatus("statefulset", "devlake-mysql", "5m") },
+			want: []string{"kubectl", "--namespace", "devlake",
+				"rollout", "status", "statefulset/devlake-mysql", "--timeout", "5m"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			orig := execCommand
+			execCommand = fakeExecCommand(&got)
+			defer func() { execCommand = orig }()
+
+			if err := tt.call(tt.k); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("args = %v\nwant   %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,286 @@
+// Package k8s renders Kubernetes manifests (or Helm values for the upstream
+// chart) for DevLake and wraps the kubectl commands used to manage them.
+package k8s
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// PartOf is the app.kubernetes.io/part-of label on every rendered object.
+const PartOf = "devlake"
+
+// SecretsFile is the rendered Secret manifest; it is written with 0600.
+const SecretsFile = "01-secrets.yaml"
+
+// manifestFiles maps output file names to templates, in apply order.
+var manifestFiles = []struct{ file, tmpl string }{
+	{"00-namespace.yaml", "namespace.yaml.tmpl"},
+	{SecretsFile, "secret.yaml.tmpl"},
+	{"02-mysql.yaml", "mysql.yaml.tmpl"},
+	{"03-backend.yaml", "backend.yaml.tmpl"},
+	{"04-grafana.yaml", "grafana.yaml.tmpl"},
+	{"05-config-ui.yaml", "config-ui.yaml.tmpl"},
+}
+
+// Service types accepted for the backend, Grafana and Config UI services.
+var ServiceTypes = []string{"ClusterIP", "NodePort", "LoadBalancer"}
+
+var (
+	dnsLabelRe = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+	quantityRe = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?(Ki|Mi|Gi|Ti)?$`)
+)
+
+// Config holds everything the templates need.
+type Config struct {
+	Namespace         string
+	CreateNamespace   bool
+	Name              string // prefix for object names and the instance label
+	ImageTag          string // apache/devlake* image tag, e.g. v1.0.2
+	MySQLImage        string
+	MySQLUser         string
+	MySQLPassword     string
+	MySQLRootPassword string
+	EncryptionSecret  string
+	StorageClass      string
+	StorageSize       string
+	ServiceType       string
+}
+
+// Image returns the official apache/<repo> image at the configured tag.
+func (c Config) Image(repo string) string {
+	return "apache/" + repo + ":" + c.ImageTag
+}
+
+// DBURL is the backend's connection string to the in-cluster MySQL service.
+func (c Config) DBURL() string {
+	return fmt.Sprintf("mysql://%s:%s@%s-mysql:3306/lake?charset=utf8mb4&parseTime=True&loc=UTC",
+		c.MySQLUser, c.MySQLPassword, c.Name)
+}
+
+// Selector is the label selector matching every object of this deployment.
+func (c Config) Selector() string {
+	return fmt.Sprintf("app.kubernetes.io/part-of=%s,app.kubernetes.io/instance=%s", PartOf, c.Name)
+}
+
+// WithDefaults fills unset optional fields.
+func (c Config) WithDefaults() Config {
+	if c.Name == "" {
+		c.Name = "devlake"
+	}
+	if c.Namespace == "" {
+		c.Namespace = "devlake"
+	}
+	if c.MySQLImage == "" {
+		c.MySQLImage = "mysql:8"
+	}
+	if c.MySQLUser == "" {
+		c.MySQLUser = "merico"
+	}
+	if c.StorageSize == "" {
+		c.StorageSize = "20Gi"
+	}
+	if c.ServiceType == "" {
+		c.ServiceType = "ClusterIP"
+	}
+	return c
+}
+
+// Validate checks names, sizes and that all secrets are set.
+func (c Config) Validate() error {
+	for flag, v := range map[string]string{"--namespace": c.Namespace, "--name": c.Name} {
+		if len(v) > 40 || !dnsLabelRe.MatchString(v) {
+			return fmt.Errorf("%s %q must be a lowercase DNS label (letters, digits, dashes; max 40)", flag, v)
+		}
+	}
+	if c.ImageTag == "" {
+		return fmt.Errorf("image tag is required")
+	}
+	if !quantityRe.MatchString(c.StorageSize) {
+		return fmt.Errorf("--storage-size %q is not a Kubernetes quantity (e.g. 20Gi)", c.StorageSize)
+	}
+	valid := false
+	for _, t := range ServiceTypes {
+		valid = valid || c.ServiceType == t
+	}
+	if !valid {
+		return fmt.Errorf("--service-type must be one of %s, got %q", strings.Join(ServiceTypes, ", "), c.ServiceType)
+	}
+	if c.MySQLPassword == "" || c.MySQLRootPassword == "" || c.EncryptionSecret == "" {
+		return fmt.Errorf("MySQL passwords and the encryption secret must be set")
+	}
+	return nil
+}
+
+// Manifest is one rendered file.
+type Manifest struct {
+	Name    string
+	Content []byte
+}
+
+// helpers are shared template definitions. Labels are emitted at four
+// spaces of indentation; callers shift them with indent.
+const helpers = `
+{{- define "labels" }}    app.kubernetes.io/name: {{ .Component }}
+    app.kubernetes.io/instance: {{ .Config.Name }}
+    app.kubernetes.io/part-of: ` + PartOf + `
+    app.kubernetes.io/managed-by: gh-devlake
+    app.kubernetes.io/version: {{ .Config.ImageTag }}
+{{- end -}}
+{{- define "selector" }}    app.kubernetes.io/name: {{ .Component }}
+    app.kubernetes.io/instance: {{ .Config.Name }}
+{{- end -}}
+`
+
+type componentData struct {
+	Config    Config
+	Component string
+}
+
+func parseTemplates() (*template.Template, error) {
+	t := template.New("k8s")
+	t.Funcs(template.FuncMap{
+		"include": func(name string, data any) (string, error) {
+			var b bytes.Buffer
+			err := t.ExecuteTemplate(&b, name, data)
+			return b.String(), err
+		},
+		"component": func(c Config, name string) componentData {
+			return componentData{Config: c, Component: name}
+		},
+		"indent": func(n int, s string) string {
+			pad := strings.Repeat(" ", n)
+			return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+		},
+		"quote": func(s string) string {
+			var b bytes.Buffer
+			enc := json.NewEncoder(&b)
+			enc.SetEscapeHTML(false)
+			_ = enc.Encode(s)
+			return strings.TrimSuffix(b.String(), "\n")
+		},
+	})
+	if _, err := t.Parse(helpers); err != nil {
+		return nil, err
+	}
+	return t.ParseFS(templateFS, "templates/*.tmpl")
+}
+
+// Render returns the manifests for c in apply order. The namespace manifest
+// is included only when c.CreateNamespace is set.
+func Render(c Config) ([]Manifest, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	t, err := parseTemplates()
+	if err != nil {
+		return nil, err
+	}
+	var out []Manifest
+	for _, f := range manifestFiles {
+		if f.tmpl == "namespace.yaml.tmpl" && !c.CreateNamespace {
+			continue
+		}
+		var b bytes.Buffer
+		if err := t.ExecuteTemplate(&b, f.tmpl, c); err != nil {
+			return nil, fmt.Errorf("rendering %s: %w", f.file, err)
+		}
+		out = append(out, Manifest{Name: f.file, Content: b.Bytes()})
+	}
+	return out, nil
+}
+
+// RenderHelmValues returns a values.yaml for the upstream DevLake Helm chart.
+func RenderHelmValues(c Config) ([]byte, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	t, err := parseTemplates()
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	if err := t.ExecuteTemplate(&b, "values.yaml.tmpl", c); err != nil {
+		return nil, fmt.Errorf("rendering values.yaml: %w", err)
+	}
+	return b.Bytes(), nil
+}
+
+// WriteManifests writes the manifests into dir, removing stale *.yaml files
+// from an earlier render so 'kubectl apply -f dir' sees exactly this set.
+func WriteManifests(dir string, manifests []Manifest) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	keep := map[string]bool{}
+	for _, m := range manifests {
+		keep[m.Name] = true
+	}
+	for _, f := range manifestFiles {
+		if !keep[f.file] {
+			if err := os.Remove(filepath.Join(dir, f.file)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	for _, m := range manifests {
+		mode := os.FileMode(0644)
+		if m.Name == SecretsFile {
+			mode = 0600
+		}
+		if err := os.WriteFile(filepath.Join(dir, m.Name), m.Content, mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadSecrets returns the stringData of a Secret manifest previously
+// rendered into dir, so a re-render keeps the same passwords and
+// encryption secret. Returns an empty map when there is none.
+func ReadSecrets(dir string) (map[string]string, error) {
+	out := map[string]string{}
+	f, err := os.Open(filepath.Join(dir, SecretsFile))
+	if os.IsNotExist(err) {
+		return out, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	inData := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "stringData:" {
+			inData = true
+			continue
+		}
+		if !inData || !strings.HasPrefix(line, "  ") {
+			inData = inData && strings.HasPrefix(line, "  ")
+			continue
+		}
+		key, raw, ok := strings.Cut(strings.TrimSpace(line), ": ")
+		if !ok {
+			continue
+		}
+		var v string
+		if err := json.Unmarshal([]byte(raw), &v); err != nil {
+			v = raw
+		}
+		out[key] = v
+	}
+	return out, scanner.Err()
+}
@@ -0,0 +1,103 @@
+package repofile
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Catalog is a service catalog CSV: a header row naming the columns,
+// followed by one row per service.
+type Catalog struct {
+	Columns []string
+	Rows    []CatalogRow
+}
+
+// CatalogRow is one data row, keyed by lower-cased column name.
+type CatalogRow struct {
+	Line   int
+	Values map[string]string
+}
+
+// Get returns the trimmed value for column, or "" when absent.
+func (r CatalogRow) Get(column string) string {
+	return r.Values[strings.ToLower(column)]
+}
+
+// ParseCatalog reads a CSV file whose first non-comment row is a header.
+// Column names are matched case-insensitively. Lines starting with # and
+// rows with every cell empty are skipped.
+func ParseCatalog(path string) (*Catalog, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.Comment = '#'
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("catalog is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+	cat := &Catalog{}
+	seen := make(map[string]bool, len(header))
+	for _, h := range header {
+		name := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+		if name == "" {
+			return nil, fmt.Errorf("catalog header has an empty column name")
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("catalog header has duplicate column %q", name)
+		}
+		seen[name] = true
+		cat.Columns = append(cat.Columns, name)
+	}
+
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := r.FieldPos(0)
+		row := CatalogRow{Line: line, Values: make(map[string]string, len(cat.Columns))}
+		empty := true
+		for i, v := range record {
+			if i >= len(cat.Columns) {
+				return nil, fmt.Errorf("line %d: %d fields, header has %d", line, len(record), len(cat.Columns))
+			}
+			v = strings.TrimSpace(v)
+			if v != "" {
+				empty = false
+			}
+			row.Values[cat.Columns[i]] = v
+		}
+		if !empty {
+			cat.Rows = append(cat.Rows, row)
+		}
+	}
+	return cat, nil
+}
+
+// HasColumn reports whether the catalog header contains column.
+func (c *Catalog) HasColumn(column string) bool {
+	column = strings.ToLower(column)
+	for _, col := range c.Columns {
+		if col == column {
+			return true
+		}
+	}
+	return false
+}
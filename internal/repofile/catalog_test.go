@@ -0,0 +1,78 @@
+package repofile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeCatalog(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "catalog.csv")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseCatalog(t *testing.T) {
+	path := writeCatalog(t, "\ufeffRepo, Team ,jenkins_job,sonar_project\n"+
+		"# comment\n"+
+		"org/api,payments,payments/api-build,api\n"+
+		",,,\n"+
+		"org/web,\"web, frontend\",,\n")
+
+	cat, err := ParseCatalog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantCols := []string{"repo", "team", "jenkins_job", "sonar_project"}
+	if strings.Join(cat.Columns, ",") != strings.Join(wantCols, ",") {
+		t.Errorf("Columns = %v, want %v", cat.Columns, wantCols)
+	}
+	if !cat.HasColumn("Jenkins_Job") || cat.HasColumn("pagerduty_service") {
+		t.Errorf("HasColumn gave unexpected results")
+	}
+	if len(cat.Rows) != 2 {
+		t.Fatalf("got %d rows, want 2", len(cat.Rows))
+	}
+	if cat.Rows[0].Line != 3 || cat.Rows[1].Line != 5 {
+		t.Errorf("lines = %d,%d, want 3,5", cat.Rows[0].Line, cat.Rows[1].Line)
+	}
+	if got := cat.Rows[0].Get("JENKINS_JOB"); got != "payments/api-build" {
+		t.Errorf("jenkins_job = %q", got)
+	}
+	if got := cat.Rows[1].Get("team"); got != "web, frontend" {
+		t.Errorf("quoted team = %q", got)
+	}
+	if got := cat.Rows[1].Get("missing"); got != "" {
+		t.Errorf("missing column = %q, want empty", got)
+	}
+}
+
+func TestParseCatalog_ShortRowsAllowed(t *testing.T) {
+	cat, err := ParseCatalog(writeCatalog(t, "repo,team,jenkins_job\norg/api,core\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cat.Rows[0].Get("jenkins_job"); got != "" {
+		t.Errorf("jenkins_job = %q, want empty", got)
+	}
+}
+
+func TestParseCatalog_Errors(t *testing.T) {
+	tests := map[string]string{
+		"empty":          "",
+		"duplicate":      "repo,Repo\n",
+		"blank header":   "repo,,team\n",
+		"too many cells": "repo,team\norg/api,core,extra\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseCatalog(writeCatalog(t, content)); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package token
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/DevExpGBB/gh-devlake/internal/envfile"
+)
+
+// GitHub App credential keys, checked in .devlake.env and the environment.
+const (
+	AppIDKey             = "GITHUB_APP_ID"
+	AppInstallationIDKey = "GITHUB_APP_INSTALLATION_ID"
+	AppPrivateKeyKey     = "GITHUB_APP_PRIVATE_KEY"
+	AppPrivateKeyFileKey = "GITHUB_APP_PRIVATE_KEY_FILE"
+)
+
+// AppKeyOpts holds the lookup data for a GitHub App private key.
+type AppKeyOpts struct {
+	FilePath    string // explicit --private-key-file value
+	EnvFilePath string // path to .devlake.env
+}
+
+// ResolveAppPrivateKey loads a GitHub App private key (PEM).
+//
+// Priority order:
+//  1. --private-key-file
+//  2. .devlake.env GITHUB_APP_PRIVATE_KEY (inline PEM, "\n" escapes allowed)
+//     or GITHUB_APP_PRIVATE_KEY_FILE (path)
+//  3. $GITHUB_APP_PRIVATE_KEY or $GITHUB_APP_PRIVATE_KEY_FILE
+//
+// The returned ResolveResult carries the PEM in Token. Source is "file",
+// "envfile", or "environment".
+func ResolveAppPrivateKey(opts AppKeyOpts) (*ResolveResult, error) {
+	if opts.FilePath != "" {
+		pem, err := readPrivateKeyFile(opts.FilePath)
+		if err != nil {
+			return nil, err
+		}
+		return &ResolveResult{Token: pem, Source: "file"}, nil
+	}
+
+	envFilePath := opts.EnvFilePath
+	if envFilePath == "" {
+		envFilePath = ".devlake.env"
+	}
+	if vals, err := envfile.Load(envFilePath); err == nil {
+		if v := vals[AppPrivateKeyKey]; v != "" {
+			pem, err := normalizePEM(v)
+			if err != nil {
+				return nil, fmt.Errorf("%s in %s: %w", AppPrivateKeyKey, envFilePath, err)
+			}
+			return &ResolveResult{Token: pem, Source: "envfile", EnvFilePath: envFilePath}, nil
+		}
+		if v := vals[AppPrivateKeyFileKey]; v != "" {
+			pem, err := readPrivateKeyFile(v)
+			if err != nil {
+				return nil, err
+			}
+			return &ResolveResult{Token: pem, Source: "envfile", EnvFilePath: envFilePath}, nil
+		}
+	}
+
+	if v := os.Getenv(AppPrivateKeyKey); v != "" {
+		pem, err := normalizePEM(v)
+		if err != nil {
+			return nil, fmt.Errorf("$%s: %w", AppPrivateKeyKey, err)
+		}
+		return &ResolveResult{Token: pem, Source: "environment"}, nil
+	}
+	if v := os.Getenv(AppPrivateKeyFileKey); v != "" {
+		pem, err := readPrivateKeyFile(v)
+		if err != nil {
+			return nil, err
+		}
+		return &ResolveResult{Token: pem, Source: "environment"}, nil
+	}
+
+	return nil, fmt.Errorf("no GitHub App private key found.\n"+
+		"Provide one via --private-key-file, %s/%s in .devlake.env, or $%s", AppPrivateKeyKey, AppPrivateKeyFileKey, AppPrivateKeyFileKey)
+}
+
+// LookupAppValue returns a GitHub App setting (e.g. AppIDKey) from
+// .devlake.env or the environment, or "" if unset.
+func LookupAppValue(envFilePath, key string) string {
+	if envFilePath == "" {
+		envFilePath = ".devlake.env"
+	}
+	if vals, err := envfile.Load(envFilePath); err == nil {
+		if v := vals[key]; v != "" {
+			return v
+		}
+	}
+	return os.Getenv(key)
+}
+
+func readPrivateKeyFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("reading private key file: %w", err)
+	}
+	pem, err := normalizePEM(string(data))
+	if err != nil {
+		return "", fmt.Errorf("private key file %s: %w", path, err)
+	}
+	return pem, nil
+}
+
+// normalizePEM expands literal "\n" escapes (common in single-line env
+// values), trims surrounding whitespace, and checks for a PEM header.
+func normalizePEM(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if !strings.Contains(s, "\n") {
+		s = strings.ReplaceAll(s, `\n`, "\n")
+	}
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	if !strings.HasPrefix(s, "-----BEGIN ") || !strings.Contains(s, "PRIVATE KEY-----") {
+		return "", fmt.Errorf("not a PEM-encoded private key")
+	}
+	return s + "\n", nil
+}
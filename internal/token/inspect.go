@@ -0,0 +1,241 @@
+package token
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// httpClient is used for GitHub API calls. Tests may replace it.
+var httpClient = &http.Client{Timeout: 15 * time.Second}
+
+// Token kinds reported by InspectGitHub.
+const (
+	KindClassic     = "classic"
+	KindFineGrained = "fine-grained"
+	KindUnknown     = "unknown"
+)
+
+// Probe is a GitHub API request used to verify a permission when the token
+// does not advertise OAuth scopes (fine-grained PATs).
+type Probe struct {
+	Name string // human-readable permission, e.g. "Organization: Copilot Business (read)"
+	Path string // API path relative to the endpoint, e.g. "/orgs/my-org/copilot/billing"
+}
+
+// ProbeResult is the outcome of one Probe.
+type ProbeResult struct {
+	Probe
+	Status int
+	OK     bool
+}
+
+// InspectOpts describes the token and what it needs to do.
+type InspectOpts struct {
+	Endpoint       string   // API base URL (default https://api.github.com/)
+	Token          string   // token to inspect
+	Org            string   // organization to check SSO authorization against
+	RequiredScopes []string // classic OAuth scopes the plugin needs
+	Probes         []Probe  // permission probes for tokens without OAuth scopes
+}
+
+// Inspection is what GitHub reports about a token.
+type Inspection struct {
+	Kind      string
+	Login     string
+	Scopes    []string      // granted OAuth scopes (classic PATs only)
+	Missing   []string      // required scopes not granted (classic PATs only)
+	Probes    []ProbeResult // probe results (fine-grained or unknown tokens)
+	ExpiresAt time.Time     // zero if the token does not expire
+	SSOURL    string        // non-empty if the org requires SSO authorization for this token
+}
+
+// FailedProbes returns the probes that did not succeed.
+func (in *Inspection) FailedProbes() []ProbeResult {
+	var out []ProbeResult
+	for _, p := range in.Probes {
+		if !p.OK {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// impliedScopes lists classic OAuth scopes granted implicitly by a parent scope.
+var impliedScopes = map[string][]string{
+	"repo":             {"repo:status", "repo_deployment", "public_repo", "repo:invite", "security_events"},
+	"admin:org":        {"write:org", "read:org", "manage_runners:org"},
+	"write:org":        {"read:org"},
+	"user":             {"read:user", "user:email", "user:follow"},
+	"admin:enterprise": {"manage_runners:enterprise", "manage_billing:enterprise", "read:enterprise"},
+	"write:packages":   {"read:packages"},
+	"admin:repo_hook":  {"write:repo_hook", "read:repo_hook"},
+	"write:repo_hook":  {"read:repo_hook"},
+}
+
+// expandScopes returns the granted scopes plus every scope they imply.
+func expandScopes(granted []string) map[string]bool {
+	out := map[string]bool{}
+	var add func(s string)
+	add = func(s string) {
+		if out[s] {
+			return
+		}
+		out[s] = true
+		for _, child := range impliedScopes[s] {
+			add(child)
+		}
+	}
+	for _, s := range granted {
+		add(s)
+	}
+	return out
+}
+
+// MissingScopes returns the required scopes not covered by granted,
+// taking implied scopes (e.g. repo ⊃ public_repo) into account.
+func MissingScopes(granted, required []string) []string {
+	have := expandScopes(granted)
+	var missing []string
+	for _, r := range required {
+		if !have[r] {
+			missing = append(missing, r)
+		}
+	}
+	return missing
+}
+
+// parseScopesHeader splits an X-OAuth-Scopes header value.
+func parseScopesHeader(v string) []string {
+	var scopes []string
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			scopes = append(scopes, s)
+		}
+	}
+	sort.Strings(scopes)
+	return scopes
+}
+
+// parseExpiration parses GitHub-Authentication-Token-Expiration values such
+// as "2025-01-31 12:00:00 UTC" or "2025-01-31 12:00:00 -0800".
+func parseExpiration(v string) (time.Time, bool) {
+	v = strings.TrimSpace(v)
+	for _, layout := range []string{"2006-01-02 15:04:05 MST", "2006-01-02 15:04:05 -0700", time.RFC3339} {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseSSOHeader extracts the authorization URL from an X-GitHub-SSO
+// header of the form "required; url=https://github.com/orgs/...".
+func parseSSOHeader(v string) string {
+	if !strings.HasPrefix(strings.TrimSpace(v), "required") {
+		return ""
+	}
+	if i := strings.Index(v, "url="); i >= 0 {
+		return strings.TrimSpace(v[i+len("url="):])
+	}
+	return "(no URL provided)"
+}
+
+// InspectGitHub asks the GitHub API what a token can do. It reads the
+// X-OAuth-Scopes header (classic PATs), runs permission probes when no
+// scopes are advertised, checks org SSO authorization, and reads the
+// token's expiration. An error is returned only when GitHub rejects the
+// token outright or cannot be reached.
+func InspectGitHub(opts InspectOpts) (*Inspection, error) {
+	base := strings.TrimRight(opts.Endpoint, "/")
+	if base == "" {
+		base = "https://api.github.com"
+	}
+
+	resp, body, err := githubGet(base+"/user", opts.Token)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, fmt.Errorf("GitHub rejected the token (401 Bad credentials) — it may be revoked or expired")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GitHub returned %d from /user: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	in := &Inspection{Login: parseLogin(body)}
+	if exp, ok := parseExpiration(resp.Header.Get("GitHub-Authentication-Token-Expiration")); ok {
+		in.ExpiresAt = exp
+	}
+
+	scopesHeader, hasScopes := resp.Header["X-Oauth-Scopes"]
+	switch {
+	case strings.HasPrefix(opts.Token, "github_pat_"):
+		in.Kind = KindFineGrained
+	case hasScopes:
+		in.Kind = KindClassic
+	default:
+		in.Kind = KindUnknown
+	}
+
+	if in.Kind == KindClassic {
+		in.Scopes = parseScopesHeader(strings.Join(scopesHeader, ","))
+		in.Missing = MissingScopes(in.Scopes, opts.RequiredScopes)
+	} else {
+		for _, p := range opts.Probes {
+			r, _, err := githubGet(base+p.Path, opts.Token)
+			res := ProbeResult{Probe: p}
+			if err == nil {
+				res.Status = r.StatusCode
+				res.OK = r.StatusCode >= 200 && r.StatusCode < 300
+				if url := parseSSOHeader(r.Header.Get("X-GitHub-SSO")); url != "" && in.SSOURL == "" {
+					in.SSOURL = url
+				}
+			}
+			in.Probes = append(in.Probes, res)
+		}
+	}
+
+	if opts.Org != "" && in.SSOURL == "" {
+		if r, _, err := githubGet(base+"/orgs/"+opts.Org+"/repos?per_page=1", opts.Token); err == nil {
+			in.SSOURL = parseSSOHeader(r.Header.Get("X-GitHub-SSO"))
+		}
+	}
+	return in, nil
+}
+
+func githubGet(url, tok string) (*http.Response, []byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+tok)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot reach GitHub API: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, body, nil
+}
+
+// parseLogin returns the login of a /user response, or "" if the body is not
+// a user object.
+func parseLogin(body []byte) string {
+	var user struct {
+		Login string `json:"login"`
+	}
+	if err := json.Unmarshal(body, &user); err != nil {
+		return ""
+	}
+	return user.Login
+}
@@ -0,0 +1,179 @@
+package token
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMissingScopes(t *testing.T) {
+	tests := []struct {
+		name     string
+		granted  []string
+		required []string
+		want     []string
+	}{
+		{"all granted", []string{"repo", "read:org", "read:user"}, []string{"repo", "read:org", "read:user"}, nil},
+		{"implied by parent", []string{"repo", "admin:org", "user"}, []string{"public_repo", "read:org", "read:user"}, nil},
+		{"implied transitively", []string{"admin:org"}, []string{"read:org"}, nil},
+		{"missing", []string{"repo"}, []string{"repo", "read:org", "read:user"}, []string{"read:org", "read:user"}},
+		{"child does not imply parent", []string{"read:org"}, []string{"admin:org"}, []string{"admin:org"}},
+		{"no scopes", nil, []string{"manage_billing:copilot"}, []string{"manage_billing:copilot"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MissingScopes(tt.granted, tt.required)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MissingScopes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseExpiration(t *testing.T) {
+	for _, v := range []string{"2025-01-31 12:00:00 UTC", "2025-01-31 04:00:00 -0800"} {
+		got, ok := parseExpiration(v)
+		if !ok {
+			t.Fatalf("parseExpiration(%q) failed", v)
+		}
+		if want := time.Date(2025, 1, 31, 12, 0, 0, 0, time.UTC); !got.Equal(want) {
+			t.Errorf("parseExpiration(%q) = %v, want %v", v, got, want)
+		}
+	}
+	if _, ok := parseExpiration("never"); ok {
+		t.Error("expected failure for invalid value")
+	}
+}
+
+func TestParseSSOHeader(t *testing.T) {
+	if got := parseSSOHeader("required; url=https://github.com/orgs/acme/sso?authorization_request=abc"); got != "https://github.com/orgs/acme/sso?authorization_request=abc" {
+		t.Errorf("got %q", got)
+	}
+	if got := parseSSOHeader("partial-results; organizations=1,2"); got != "" {
+		t.Errorf("partial-results should not require SSO, got %q", got)
+	}
+}
+
+func TestInspectGitHub_Classic(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer ghp_test" {
+			t.Errorf("Authorization = %q", got)
+		}
+		switch r.URL.Path {
+		case "/user":
+			w.Header().Set("X-OAuth-Scopes", "repo, read:user")
+			w.Header().Set("GitHub-Authentication-Token-Expiration", "2030-06-01 00:00:00 UTC")
+			w.Write([]byte(`{"login": "octocat", "id": 1}`))
+		case "/orgs/acme/repos":
+			w.Header().Set("X-GitHub-SSO", "required; url=https://github.com/orgs/acme/sso?authorization_request=xyz")
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	in, err := InspectGitHub(InspectOpts{
+		Endpoint:       srv.URL + "/",
+		Token:          "ghp_test",
+		Org:            "acme",
+		RequiredScopes: []string{"repo", "read:org", "read:user"},
+		Probes:         []Probe{{Name: "unused", Path: "/never"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if in.Kind != KindClassic || in.Login != "octocat" {
+		t.Errorf("kind=%q login=%q", in.Kind, in.Login)
+	}
+	if !reflect.DeepEqual(in.Scopes, []string{"read:user", "repo"}) {
+		t.Errorf("scopes = %v", in.Scopes)
+	}
+	if !reflect.DeepEqual(in.Missing, []string{"read:org"}) {
+		t.Errorf("missing = %v", in.Missing)
+	}
+	if !strings.Contains(in.SSOURL, "authorization_request=xyz") {
+		t.Errorf("SSOURL = %q", in.SSOURL)
+	}
+	if in.ExpiresAt.Year() != 2030 {
+		t.Errorf("ExpiresAt = %v", in.ExpiresAt)
+	}
+	if len(in.Probes) != 0 {
+		t.Errorf("classic tokens should not be probed, got %v", in.Probes)
+	}
+}
+
+func TestInspectGitHub_FineGrained(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/user":
+			w.Write([]byte(`{"login":"octocat"}`))
+		case "/orgs/acme/repos":
+			w.Write([]byte(`[]`))
+		case "/orgs/acme/copilot/billing":
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	in, err := InspectGitHub(InspectOpts{
+		Endpoint: srv.URL,
+		Token:    "github_pat_abc",
+		Org:      "acme",
+		Probes: []Probe{
+			{Name: "repos", Path: "/orgs/acme/repos?per_page=1"},
+			{Name: "copilot", Path: "/orgs/acme/copilot/billing"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if in.Kind != KindFineGrained {
+		t.Errorf("kind = %q", in.Kind)
+	}
+	if len(in.Missing) != 0 || len(in.Scopes) != 0 {
+		t.Errorf("fine-grained tokens have no scopes, got scopes=%v missing=%v", in.Scopes, in.Missing)
+	}
+	failed := in.FailedProbes()
+	if len(in.Probes) != 2 || len(failed) != 1 || failed[0].Name != "copilot" || failed[0].Status != http.StatusForbidden {
+		t.Errorf("probes = %+v", in.Probes)
+	}
+	if in.SSOURL != "" || !in.ExpiresAt.IsZero() {
+		t.Errorf("unexpected SSO/expiry: %q %v", in.SSOURL, in.ExpiresAt)
+	}
+}
+
+func TestInspectGitHub_Rejected(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"message":"Bad credentials"}`))
+	}))
+	defer srv.Close()
+
+	_, err := InspectGitHub(InspectOpts{Endpoint: srv.URL, Token: "ghp_revoked"})
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("expected 401 error, got %v", err)
+	}
+}
+
+func TestParseLogin(t *testing.T) {
+	tests := []struct {
+		body, want string
+	}{
+		{`{"login": "octocat", "id": 1}`, "octocat"},
+		{`{"plan": {"name": "pro", "login": "nested"}, "login": "octocat"}`, "octocat"},
+		{`{"bio": "\"login\": \"spoofed\"", "login": "octocat"}`, "octocat"},
+		{`{"message": "Bad credentials"}`, ""},
+		{`not json`, ""},
+	}
+	for _, tt := range tests {
+		if got := parseLogin([]byte(tt.body)); got != tt.want {
+			t.Errorf("parseLogin(%s) = %q, want %q", tt.body, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package token
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// KeychainService is the service name tokens are stored under.
+const KeychainService = "gh-devlake"
+
+// KeychainFileEnv selects a file-backed keychain instead of the OS secret
+// store. Intended for tests and headless hosts without a Secret Service.
+const KeychainFileEnv = "GH_DEVLAKE_KEYCHAIN_FILE"
+
+// ErrNotFound is returned when no secret is stored for an account.
+var ErrNotFound = errors.New("no token stored")
+
+// Keychain stores tokens keyed by account (the plugin slug).
+type Keychain interface {
+	Name() string
+	Get(account string) (string, error)
+	Set(account, secret string) error
+	Delete(account string) error
+}
+
+// OpenKeychain returns the keychain for this host: the file named by
+// $GH_DEVLAKE_KEYCHAIN_FILE if set, otherwise the OS secret store.
+func OpenKeychain() (Keychain, error) {
+	if p := os.Getenv(KeychainFileEnv); p != "" {
+		return &FileKeychain{Path: p}, nil
+	}
+	if runtime.GOOS == "linux" {
+		if _, err := exec.LookPath("secret-tool"); err != nil {
+			return nil, fmt.Errorf("secret-tool not found — install libsecret-tools to use the Secret Service keychain, or set $%s", KeychainFileEnv)
+		}
+		return secretService{}, nil
+	}
+	return nil, fmt.Errorf("no OS keychain support on %s — set $%s to use a file-backed store", runtime.GOOS, KeychainFileEnv)
+}
+
+// ── Secret Service (Linux) ──────────────────────────────────────
+
+// secretService talks to the freedesktop Secret Service via secret-tool.
+type secretService struct{}
+
+func (secretService) Name() string { return "Secret Service" }
+
+func (secretService) Get(account string) (string, error) {
+	out, err := exec.Command("secret-tool", "lookup", "service", KeychainService, "account", account).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(bytes.TrimSpace(exitErr.Stderr)) == 0 {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("secret-tool lookup: %w", err)
+	}
+	secret := strings.TrimSpace(string(out))
+	if secret == "" {
+		return "", ErrNotFound
+	}
+	return secret, nil
+}
+
+func (secretService) Set(account, secret string) error {
+	c := exec.Command("secret-tool", "store", "--label", KeychainService+" "+account,
+		"service", KeychainService, "account", account)
+	// The secret is passed on stdin so it never appears in the process list.
+	c.Stdin = strings.NewReader(secret)
+	if out, err := c.CombinedOutput(); err != nil {
+		return fmt.Errorf("secret-tool store: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func (secretService) Delete(account string) error {
+	if _, err := (secretService{}).Get(account); err != nil {
+		return err
+	}
+	if out, err := exec.Command("secret-tool", "clear", "service", KeychainService, "account", account).CombinedOutput(); err != nil {
+		return fmt.Errorf("secret-tool clear: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// ── File-backed fallback ────────────────────────────────────────
+
+// FileKeychain stores tokens in a JSON file with 0600 permissions.
+type FileKeychain struct {
+	Path string
+}
+
+func (f *FileKeychain) Name() string { return "file " + f.Path }
+
+func (f *FileKeychain) load() (map[string]string, error) {
+	data, err := os.ReadFile(f.Path)
+	if os.IsNotExist(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]string{}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return m, nil
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parsing keychain file %s: %w", f.Path, err)
+	}
+	return m, nil
+}
+
+func (f *FileKeychain) save(m map[string]string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(f.Path); dir != "" {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(f.Path, append(data, '\n'), 0600)
+}
+
+func (f *FileKeychain) Get(account string) (string, error) {
+	m, err := f.load()
+	if err != nil {
+		return "", err
+	}
+	v, ok := m[account]
+	if !ok || v == "" {
+		return "", ErrNotFound
+	}
+	return v, nil
+}
+
+func (f *FileKeychain) Set(account, secret string) error {
+	m, err := f.load()
+	if err != nil {
+		return err
+	}
+	m[account] = secret
+	return f.save(m)
+}
+
+func (f *FileKeychain) Delete(account string) error {
+	m, err := f.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := m[account]; !ok {
+		return ErrNotFound
+	}
+	delete(m, account)
+	return f.save(m)
+}
+
+// ── gh auth token ───────────────────────────────────────────────
+
+// ghAuthToken returns the token the GitHub CLI is logged in with.
+// Tests may replace it.
+var ghAuthToken = func() (string, error) {
+	if _, err := exec.LookPath("gh"); err != nil {
+		return "", err
+	}
+	out, err := exec.Command("gh", "auth", "token").Output()
+	if err != nil {
+		return "", fmt.Errorf("gh auth token: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,125 @@
+package token
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func useFileKeychain(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "keychain.json")
+	t.Setenv(KeychainFileEnv, path)
+	return path
+}
+
+func stubGHAuth(t *testing.T, tok string, err error) {
+	t.Helper()
+	orig := ghAuthToken
+	ghAuthToken = func() (string, error) { return tok, err }
+	t.Cleanup(func() { ghAuthToken = orig })
+}
+
+func TestFileKeychain_RoundTrip(t *testing.T) {
+	path := useFileKeychain(t)
+	kc, err := OpenKeychain()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := kc.Get("github"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get on empty keychain: err = %v, want ErrNotFound", err)
+	}
+	if err := kc.Set("github", "ghp_one"); err != nil {
+		t.Fatal(err)
+	}
+	if err := kc.Set("gitlab", "glpat_two"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := kc.Get("github"); err != nil || got != "ghp_one" {
+		t.Errorf("Get(github) = %q, %v", got, err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("keychain file mode = %o, want 600", perm)
+	}
+
+	if err := kc.Delete("github"); err != nil {
+		t.Fatal(err)
+	}
+	if err := kc.Delete("github"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Delete: err = %v, want ErrNotFound", err)
+	}
+	if got, _ := kc.Get("gitlab"); got != "glpat_two" {
+		t.Errorf("other account affected: %q", got)
+	}
+}
+
+func TestResolve_Keychain(t *testing.T) {
+	useFileKeychain(t)
+	stubGHAuth(t, "gho_cli", nil)
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GH_TOKEN", "")
+	kc, _ := OpenKeychain()
+	if err := kc.Set("github", "ghp_stored"); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := ghOpts("", filepath.Join(t.TempDir(), "missing.env"))
+	opts.KeychainAccount = "github"
+	opts.UseGHAuth = true
+	result, err := Resolve(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Token != "ghp_stored" || result.Source != "keychain" {
+		t.Errorf("got token=%q source=%q, want keychain token", result.Token, result.Source)
+	}
+
+	// Environment variables still win over the keychain.
+	t.Setenv("GITHUB_TOKEN", "ghp_env")
+	result, err = Resolve(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Source != "environment" {
+		t.Errorf("source = %q, want environment", result.Source)
+	}
+}
+
+func TestResolve_GHAuthFallback(t *testing.T) {
+	useFileKeychain(t)
+	stubGHAuth(t, "gho_cli", nil)
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GH_TOKEN", "")
+
+	opts := ghOpts("", filepath.Join(t.TempDir(), "missing.env"))
+	opts.KeychainAccount = "github"
+	opts.UseGHAuth = true
+	result, err := Resolve(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Token != "gho_cli" || result.Source != "gh-auth" {
+		t.Errorf("got token=%q source=%q, want gh-auth token", result.Token, result.Source)
+	}
+}
+
+func TestResolve_GHAuthNotUsedWhenDisabled(t *testing.T) {
+	useFileKeychain(t)
+	stubGHAuth(t, "gho_cli", nil)
+	t.Setenv("GITLAB_TOKEN", "")
+
+	opts := glOpts("", filepath.Join(t.TempDir(), "missing.env"))
+	opts.KeychainAccount = "gitlab"
+	// stdin is not a terminal under go test, so resolution must fail
+	// rather than pick up the gh CLI token.
+	if result, err := Resolve(opts); err == nil {
+		t.Errorf("expected error, got source=%q", result.Source)
+	}
+}
@@ -4,7 +4,15 @@
 //  1. Explicit flag value (--token)
 //  2. .devlake.env file (plugin-specific key, e.g. GITLAB_TOKEN=...)
 //  3. Plugin-specific environment variable (e.g. $GITLAB_TOKEN)
-//  4. Interactive masked prompt (terminal)
+//  4. OS keychain (stored with 'gh devlake token store')
+//  5. GitHub CLI login ('gh auth token', GitHub-family plugins only)
+//  6. Interactive masked prompt (terminal)
+//
+// Values from the flag, .devlake.env, environment and keychain may be secret
+// references (env:NAME, file:/path, cmd:<command>, azkv:<vault>/<secret>).
+// A reference is resolved only when its source is the one selected. cmd:
+// runs a command, so it is only accepted from the flag and the keychain
+// (both typed by the user) unless $GH_DEVLAKE_ALLOW_CMD_REFS is set.
 package token
 
 import (
@@ -20,8 +28,43 @@ import (
 // ResolveResult contains the resolved token and its source.
 type ResolveResult struct {
 	Token       string
-	Source      string // "flag", "envfile", "environment", "prompt"
+	Source      string // "flag", "envfile", "environment", "keychain", "gh-auth", "prompt"
 	EnvFilePath string // non-empty if loaded from envfile (for cleanup)
+	Reference   string // secret reference the token came from, if any (never the value)
+}
+
+// Describe returns the source for display, including the secret reference
+// when there is one (e.g. "flag (azkv:my-kv/github-pat)").
+func (r *ResolveResult) Describe() string {
+	if r.Reference != "" {
+		return fmt.Sprintf("%s (%s)", r.Source, r.Reference)
+	}
+	return r.Source
+}
+
+// AllowCmdRefsEnv opts in to cmd: references in .devlake.env and
+// environment variables, which could otherwise run commands planted in a
+// checked-out repository or an inherited environment.
+const AllowCmdRefsEnv = "GH_DEVLAKE_ALLOW_CMD_REFS"
+
+// fromValue builds a ResolveResult, resolving v if it is a secret reference.
+func fromValue(v, source, envFilePath string) (*ResolveResult, error) {
+	r := &ResolveResult{Token: v, Source: source, EnvFilePath: envFilePath}
+	if strings.HasPrefix(v, RefCmd) && (source == "envfile" || source == "environment") && os.Getenv(AllowCmdRefsEnv) == "" {
+		where := "environment variable"
+		if source == "envfile" {
+			where = envFilePath
+		}
+		return nil, fmt.Errorf("a cmd: reference in the %s was not run — cmd: is only accepted from --token or the keychain; set %s=1 to allow it in .devlake.env and the environment", where, AllowCmdRefsEnv)
+	}
+	if IsSecretRef(v) {
+		tok, err := ResolveSecretRef(v)
+		if err != nil {
+			return nil, err
+		}
+		r.Token, r.Reference = tok, v
+	}
+	return r, nil
 }
 
 // ResolveOpts holds the plugin-specific lookup data for token resolution.
@@ -32,6 +75,10 @@ type ResolveOpts struct {
 	EnvVarNames []string // environment variable names (e.g. ["GITHUB_TOKEN", "GH_TOKEN"])
 	DisplayName string   // plugin display name for prompts (e.g. "GitHub Copilot")
 	ScopeHint   string   // required PAT scopes hint
+
+	KeychainAccount string // keychain account to check (the plugin slug); empty = skip
+	UseGHAuth       bool   // fall back to 'gh auth token' (GitHub-family plugins)
+	NoPrompt        bool   // return an error instead of prompting
 }
 
 // Resolve attempts to find a PAT using the priority chain.
@@ -40,7 +87,7 @@ type ResolveOpts struct {
 func Resolve(opts ResolveOpts) (*ResolveResult, error) {
 	// 1. Explicit flag
 	if opts.FlagValue != "" {
-		return &ResolveResult{Token: opts.FlagValue, Source: "flag"}, nil
+		return fromValue(opts.FlagValue, "flag", "")
 	}
 
 	// 2. .devlake.env file
@@ -51,7 +98,7 @@ func Resolve(opts ResolveOpts) (*ResolveResult, error) {
 	if vals, err := envfile.Load(envFilePath); err == nil {
 		for _, key := range opts.EnvFileKeys {
 			if v, ok := vals[key]; ok && v != "" {
-				return &ResolveResult{Token: v, Source: "envfile", EnvFilePath: envFilePath}, nil
+				return fromValue(v, "envfile", envFilePath)
 			}
 		}
 	}
@@ -59,32 +106,49 @@ func Resolve(opts ResolveOpts) (*ResolveResult, error) {
 	// 3. Environment variables
 	for _, key := range opts.EnvVarNames {
 		if v := os.Getenv(key); v != "" {
-			return &ResolveResult{Token: v, Source: "environment"}, nil
+			return fromValue(v, "environment", "")
+		}
+	}
+
+	// 4. OS keychain
+	if opts.KeychainAccount != "" {
+		if kc, err := OpenKeychain(); err == nil {
+			if v, err := kc.Get(opts.KeychainAccount); err == nil {
+				return fromValue(v, "keychain", "")
+			}
+		}
+	}
+
+	// 5. GitHub CLI login
+	if opts.UseGHAuth {
+		if v, err := ghAuthToken(); err == nil && v != "" {
+			return &ResolveResult{Token: v, Source: "gh-auth"}, nil
 		}
 	}
 
-	// 4. Interactive masked prompt
+	// 6. Interactive masked prompt
 	displayName := opts.DisplayName
 	if displayName == "" {
 		displayName = "PAT"
 	}
-	if !term.IsTerminal(int(syscall.Stdin)) {
+	if opts.NoPrompt || !term.IsTerminal(int(syscall.Stdin)) {
 		envVarExample := ""
 		if len(opts.EnvVarNames) > 0 {
 			envVarExample = opts.EnvVarNames[0]
 		}
 		return nil, fmt.Errorf("no %s token found and stdin is not a terminal.\n"+
-			"Provide a token via --token, .devlake.env file, or $%s", displayName, envVarExample)
+			"Provide a token via --token, .devlake.env file, $%s, or 'gh devlake token store'", displayName, envVarExample)
 	}
 
-	tok, err := promptMasked(displayName, opts.ScopeHint)
+	tok, err := PromptMasked(displayName, opts.ScopeHint)
 	if err != nil {
 		return nil, err
 	}
 	return &ResolveResult{Token: tok, Source: "prompt"}, nil
 }
 
-func promptMasked(displayName, scopeHint string) (string, error) {
+// PromptMasked reads a token from the terminal without echoing it.
+func PromptMasked(displayName, scopeHint string) (string, error) {
 	if scopeHint != "" {
 		fmt.Fprintf(os.Stderr, "Required PAT scopes: %s\n", scopeHint)
 	}
@@ -0,0 +1,126 @@
+package token
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Secret reference prefixes accepted wherever a token or password is expected.
+const (
+	RefEnv   = "env:"  // env:NAME — environment variable
+	RefFile  = "file:" // file:/path — file contents
+	RefCmd   = "cmd:"  // cmd:<command> — stdout of a shell command
+	RefAzKV  = "azkv:" // azkv:<vault>/<secret> — Azure Key Vault secret
+	refLimit = 64 * 1024
+)
+
+// KeyVaultSecret fetches an Azure Key Vault secret for azkv: references.
+// The CLI sets it at startup so this package does not depend on the Azure
+// wrappers; while it is nil, azkv: references fail.
+var KeyVaultSecret func(vault, name string) (string, error)
+
+// IsSecretRef reports whether v is a secret reference rather than a literal value.
+func IsSecretRef(v string) bool {
+	for _, p := range []string{RefEnv, RefFile, RefCmd, RefAzKV} {
+		if strings.HasPrefix(v, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveSecretRef returns the value a secret reference points to. Values
+// that are not references are returned unchanged. Errors name the reference,
+// never the secret value.
+func ResolveSecretRef(v string) (string, error) {
+	var (
+		val string
+		err error
+	)
+	switch {
+	case strings.HasPrefix(v, RefEnv):
+		name := strings.TrimPrefix(v, RefEnv)
+		if name == "" {
+			return "", fmt.Errorf("secret reference %q: missing variable name", v)
+		}
+		val = os.Getenv(name)
+	case strings.HasPrefix(v, RefFile):
+		val, err = readSecretFile(strings.TrimPrefix(v, RefFile))
+	case strings.HasPrefix(v, RefCmd):
+		val, err = runSecretCommand(strings.TrimPrefix(v, RefCmd))
+	case strings.HasPrefix(v, RefAzKV):
+		vault, name, ok := strings.Cut(strings.TrimPrefix(v, RefAzKV), "/")
+		if !ok || vault == "" || name == "" {
+			return "", fmt.Errorf("secret reference %q: expected azkv:<vault>/<secret>", v)
+		}
+		if KeyVaultSecret == nil {
+			return "", fmt.Errorf("secret reference %q: Azure Key Vault references are not available", v)
+		}
+		val, err = KeyVaultSecret(vault, name)
+	default:
+		return v, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("secret reference %q: %w", v, err)
+	}
+	val = strings.TrimSpace(val)
+	if val == "" {
+		return "", fmt.Errorf("secret reference %q resolved to an empty value", v)
+	}
+	return val, nil
+}
+
+func readSecretFile(path string) (string, error) {
+	if path == "" {
+		return "", errors.New("missing file path")
+	}
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(home, rest)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, refLimit+1))
+	if err != nil {
+		return "", err
+	}
+	if len(data) > refLimit {
+		return "", fmt.Errorf("file is larger than %d KB", refLimit/1024)
+	}
+	return string(data), nil
+}
+
+func runSecretCommand(command string) (string, error) {
+	if strings.TrimSpace(command) == "" {
+		return "", errors.New("missing command")
+	}
+	var c *exec.Cmd
+	if runtime.GOOS == "windows" {
+		c = exec.Command("cmd", "/C", command)
+	} else {
+		c = exec.Command("sh", "-c", command)
+	}
+	c.Stdin = os.Stdin
+	out, err := c.Output()
+	if err != nil {
+		// Report stderr (diagnostics) but never stdout (the secret).
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("command failed: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("command failed: %w", err)
+	}
+	return string(out), nil
+}
@@ -0,0 +1,209 @@
+package token
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func stubKeyVault(t *testing.T, secrets map[string]string) {
+	t.Helper()
+	orig := KeyVaultSecret
+	KeyVaultSecret = func(vault, name string) (string, error) {
+		if v, ok := secrets[vault+"/"+name]; ok {
+			return v, nil
+		}
+		return "", fmt.Errorf("SecretNotFound")
+	}
+	t.Cleanup(func() { KeyVaultSecret = orig })
+}
+
+func TestResolveSecretRef(t *testing.T) {
+	dir := t.TempDir()
+	secretFile := filepath.Join(dir, "pat.txt")
+	if err := os.WriteFile(secretFile, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DEVLAKE_TEST_SECRET", "from-env")
+	stubKeyVault(t, map[string]string{"my-kv/jira-token": "from-kv"})
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"ghp_literal", "ghp_literal"},
+		{"env:DEVLAKE_TEST_SECRET", "from-env"},
+		{"file:" + secretFile, "from-file"},
+		{"azkv:my-kv/jira-token", "from-kv"},
+	}
+	if runtime.GOOS != "windows" {
+		tests = append(tests, struct{ in, want string }{"cmd:printf 'from-cmd\\n'", "from-cmd"})
+	}
+	for _, tt := range tests {
+		got, err := ResolveSecretRef(tt.in)
+		if err != nil {
+			t.Errorf("ResolveSecretRef(%q) error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ResolveSecretRef(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestResolveSecretRef_Errors(t *testing.T) {
+	stubKeyVault(t, nil)
+	t.Setenv("DEVLAKE_TEST_EMPTY", "")
+
+	refs := []string{
+		"env:",
+		"env:DEVLAKE_TEST_EMPTY",
+		"file:" + filepath.Join(t.TempDir(), "missing"),
+		"azkv:no-slash",
+		"azkv:my-kv/missing",
+		"cmd:",
+	}
+	for _, ref := range refs {
+		if _, err := ResolveSecretRef(ref); err == nil {
+			t.Errorf("ResolveSecretRef(%q): expected error", ref)
+		} else if !strings.Contains(err.Error(), ref) {
+			t.Errorf("error should name the reference %q: %v", ref, err)
+		}
+	}
+}
+
+func TestResolveSecretRef_CommandErrorHidesStdout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	_, err := ResolveSecretRef("cmd:printf '%s-%s' s3cret value; echo oops >&2; exit 3")
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if strings.Contains(err.Error(), "s3cret-value") {
+		t.Errorf("error leaks command stdout: %v", err)
+	}
+	if !strings.Contains(err.Error(), "oops") {
+		t.Errorf("error should include stderr: %v", err)
+	}
+}
+
+func TestResolve_SecretRefs(t *testing.T) {
+	stubKeyVault(t, map[string]string{"kv/gh": "ghp_from_kv"})
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GH_TOKEN", "")
+
+	result, err := Resolve(ghOpts("azkv:kv/gh", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Token != "ghp_from_kv" || result.Source != "flag" || result.Reference != "azkv:kv/gh" {
+		t.Errorf("got %+v", result)
+	}
+	if got := result.Describe(); got != "flag (azkv:kv/gh)" {
+		t.Errorf("Describe() = %q", got)
+	}
+
+	envFile := filepath.Join(t.TempDir(), ".devlake.env")
+	if err := os.WriteFile(envFile, []byte("GITHUB_PAT=azkv:kv/gh\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	result, err = Resolve(ghOpts("", envFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Token != "ghp_from_kv" || result.Source != "envfile" || result.Reference != "azkv:kv/gh" {
+		t.Errorf("got %+v", result)
+	}
+
+	if _, err := Resolve(ghOpts("azkv:kv/missing", "")); err == nil {
+		t.Error("expected error for unresolvable flag reference")
+	}
+}
+
+func TestResolveSecretRef_FileLimit(t *testing.T) {
+	big := filepath.Join(t.TempDir(), "big")
+	if err := os.WriteFile(big, []byte(strings.Repeat("x", refLimit+1)), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ResolveSecretRef("file:" + big); err == nil || !strings.Contains(err.Error(), "larger than") {
+		t.Errorf("oversized file error = %v", err)
+	}
+	exact := filepath.Join(t.TempDir(), "exact")
+	if err := os.WriteFile(exact, []byte(strings.Repeat("x", refLimit)), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ResolveSecretRef("file:" + exact); err != nil || len(got) != refLimit {
+		t.Errorf("file at the limit: %d bytes, err %v", len(got), err)
+	}
+}
+
+func TestResolveSecretRef_NoKeyVault(t *testing.T) {
+	orig := KeyVaultSecret
+	KeyVaultSecret = nil
+	t.Cleanup(func() { KeyVaultSecret = orig })
+	if _, err := ResolveSecretRef("azkv:kv/gh"); err == nil || !strings.Contains(err.Error(), "not available") {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func TestResolve_CmdRefsNeedOptIn(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	t.Setenv(AllowCmdRefsEnv, "")
+	t.Setenv("GH_TOKEN", "")
+	marker := filepath.Join(t.TempDir(), "ran")
+	ref := "cmd:touch " + marker + "; echo ghp_from_cmd"
+
+	envFile := filepath.Join(t.TempDir(), ".devlake.env")
+	if err := os.WriteFile(envFile, []byte("GITHUB_PAT="+ref+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GITHUB_TOKEN", "")
+	if _, err := Resolve(ghOpts("", envFile)); err == nil || !strings.Contains(err.Error(), AllowCmdRefsEnv) {
+		t.Errorf(".devlake.env cmd: error = %v", err)
+	}
+	t.Setenv("GITHUB_TOKEN", ref)
+	if _, err := Resolve(ghOpts("", filepath.Join(t.TempDir(), "none.env"))); err == nil || !strings.Contains(err.Error(), AllowCmdRefsEnv) {
+		t.Errorf("environment cmd: error = %v", err)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Fatal("a cmd: reference from .devlake.env or the environment was run")
+	}
+
+	// The flag is typed by the user; the opt-in allows the other sources.
+	if r, err := Resolve(ghOpts(ref, "")); err != nil || r.Token != "ghp_from_cmd" {
+		t.Errorf("flag cmd: = %+v, %v", r, err)
+	}
+	t.Setenv(AllowCmdRefsEnv, "1")
+	if r, err := Resolve(ghOpts("", envFile)); err != nil || r.Token != "ghp_from_cmd" {
+		t.Errorf("opted-in .devlake.env cmd: = %+v, %v", r, err)
+	}
+}
+
+func TestResolve_KeychainReference(t *testing.T) {
+	t.Setenv(KeychainFileEnv, filepath.Join(t.TempDir(), "keychain.json"))
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GH_TOKEN", "")
+	t.Setenv("DEVLAKE_TEST_PAT", "ghp_from_env_ref")
+	kc, err := OpenKeychain()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := kc.Set("github", "env:DEVLAKE_TEST_PAT"); err != nil {
+		t.Fatal(err)
+	}
+	opts := ghOpts("", filepath.Join(t.TempDir(), "none.env"))
+	opts.KeychainAccount = "github"
+	r, err := Resolve(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Token != "ghp_from_env_ref" || r.Source != "keychain" || r.Reference != "env:DEVLAKE_TEST_PAT" {
+		t.Errorf("got %+v", r)
+	}
+}